@@ -1,30 +1,56 @@
 package api
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/no-src/gofs/action"
 	"github.com/no-src/gofs/api/apiclient"
 	"github.com/no-src/gofs/api/apiserver"
+	authapi "github.com/no-src/gofs/api/auth"
+	"github.com/no-src/gofs/api/file"
 	"github.com/no-src/gofs/api/monitor"
+	"github.com/no-src/gofs/api/push"
 	"github.com/no-src/gofs/api/task"
+	"github.com/no-src/gofs/audit"
 	"github.com/no-src/gofs/auth"
 	"github.com/no-src/gofs/conf"
+	"github.com/no-src/gofs/contract"
+	pushcontract "github.com/no-src/gofs/contract/push"
 	"github.com/no-src/gofs/logger"
 	"github.com/no-src/gofs/report"
+	"github.com/no-src/nsgo/hashutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
-	certFile      = "../integration/testdata/cert/cert.pem"
-	keyFile       = "../integration/testdata/cert/key.pem"
-	serverAddr    = "https://127.0.0.1"
-	apiServerHost = "127.0.0.1"
-	apiServerPort = 8128
-	tokenSecret   = "123456abcdefghij"
-	taskConfFile  = "file://./testdata/tasks.yaml"
-	taskLabels    = "local-disk-sync-once-test,local-disk-sync-test"
+	certFile       = "../integration/testdata/cert/cert.pem"
+	keyFile        = "../integration/testdata/cert/key.pem"
+	serverAddr     = "https://127.0.0.1"
+	apiServerHost  = "127.0.0.1"
+	apiServerPort  = 8128
+	tokenSecret    = "123456abcdefghij"
+	taskConfFile   = "file://./testdata/tasks.yaml"
+	taskLabels     = "local-disk-sync-once-test,local-disk-sync-test"
+	monitorLogSize = 100
+	sourcePath     = "./testdata"
+	chunkSize      = 1024
 )
 
 func TestApiServerAndClient(t *testing.T) {
@@ -36,8 +62,297 @@ func TestApiServerAndClient_WithAnonymous(t *testing.T) {
 	runApiServerAndClient(t, nil)
 }
 
+func TestApiServerAndClient_WithClientCert(t *testing.T) {
+	dir := t.TempDir()
+	caFile, certFiles, keyFiles, err := generateClientCerts(dir, "gofs-client", "unknown-client")
+	if err != nil {
+		t.Fatalf("generate the client certs error => %v", err)
+	}
+	certUsers, err := auth.ParseCertUsers("gofs-client|rw")
+	if err != nil {
+		t.Fatalf("parse cert users error => %v", err)
+	}
+	port := apiServerPort + 1
+	srv, err := apiserver.New(apiserver.Option{
+		IP:                apiServerHost,
+		Port:              port,
+		EnableTLS:         true,
+		CertFile:          certFile,
+		KeyFile:           keyFile,
+		ClientCAFile:      caFile,
+		TokenSecret:       tokenSecret,
+		CertUsers:         certUsers,
+		Reporter:          report.NewReporter(),
+		HttpServerAddr:    serverAddr,
+		Logger:            logger.NewTestLogger(),
+		TaskConf:          taskConfFile,
+		ChecksumAlgorithm: hashutil.DefaultHash,
+	})
+	if err != nil {
+		t.Fatalf("create api server error => %v", err)
+	}
+	go func() {
+		if err := srv.Start(); err != nil {
+			t.Errorf("start api server error => %v", err)
+		}
+	}()
+	defer srv.Stop()
+	stopSend := make(chan struct{})
+	defer close(stopSend)
+	go func() {
+		for {
+			select {
+			case <-stopSend:
+				return
+			case <-time.After(time.Millisecond * 10):
+				srv.SendMonitorMessage(&monitor.MonitorMessage{BaseUrl: serverAddr})
+			}
+		}
+	}()
+
+	testCases := []struct {
+		name           string
+		clientCertFile string
+		clientKeyFile  string
+		expectLogin    bool
+	}{
+		{"with the mapped client cert", certFiles[0], keyFiles[0], true},
+		{"with the unknown client cert", certFiles[1], keyFiles[1], false},
+		{"without client cert", "", "", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := apiclient.New(apiServerHost, port, true, certFile, tc.clientCertFile, tc.clientKeyFile, nil)
+			for i := 0; i < 3; i++ {
+				err = c.Start()
+				if err == nil || c.IsUnauthenticated(err) {
+					break
+				}
+				time.Sleep(time.Second)
+			}
+			defer c.Stop()
+			if tc.expectLogin && err != nil {
+				t.Errorf("login with the client cert error => %v", err)
+				return
+			}
+			if !tc.expectLogin && !c.IsUnauthenticated(err) {
+				t.Errorf("expect login failed with unauthenticated error, but get %v", err)
+				return
+			}
+			if tc.expectLogin {
+				if _, err = c.GetInfo(); err != nil {
+					t.Errorf("get info with the client cert error => %v", err)
+				}
+			}
+
+			// the monitor stream must be authenticated too, even if there are only cert users
+			ms, err := c.Monitor(&monitor.MonitorRequest{})
+			if err == nil {
+				var msg *monitor.MonitorMessage
+				msg, err = ms.Recv()
+				if err == nil && msg.GetBaseUrl() != serverAddr {
+					t.Errorf("expect to receive the monitor message with base url [%s], but get [%s]", serverAddr, msg.GetBaseUrl())
+				}
+			}
+			if tc.expectLogin && err != nil {
+				t.Errorf("monitor with the client cert error => %v", err)
+			}
+			if !tc.expectLogin && !c.IsUnauthenticated(err) {
+				t.Errorf("expect monitor failed with unauthenticated error, but get %v", err)
+			}
+		})
+	}
+}
+
+func TestApiServerAndClient_WithUsersFile(t *testing.T) {
+	hash, err := auth.HashPassword("gofs_password", auth.Argon2idAlgorithm)
+	if err != nil {
+		t.Fatalf("hash password error => %v", err)
+	}
+	fileUser, _ := auth.NewHashedUser(1, "gofs", hash, auth.FullPerm)
+	usersFile := filepath.Join(t.TempDir(), "users.yaml")
+	if err = auth.SaveUsersFile(usersFile, []*auth.User{fileUser}); err != nil {
+		t.Fatalf("save users file error => %v", err)
+	}
+	port := apiServerPort + 2
+	srv, err := apiserver.New(apiserver.Option{
+		IP:                apiServerHost,
+		Port:              port,
+		EnableTLS:         true,
+		CertFile:          certFile,
+		KeyFile:           keyFile,
+		TokenSecret:       tokenSecret,
+		UsersFile:         usersFile,
+		Reporter:          report.NewReporter(),
+		HttpServerAddr:    serverAddr,
+		Logger:            logger.NewTestLogger(),
+		TaskConf:          taskConfFile,
+		ChecksumAlgorithm: hashutil.DefaultHash,
+	})
+	if err != nil {
+		t.Fatalf("create api server error => %v", err)
+	}
+	go func() {
+		if err := srv.Start(); err != nil {
+			t.Errorf("start api server error => %v", err)
+		}
+	}()
+	defer srv.Stop()
+
+	testCases := []struct {
+		name        string
+		password    string
+		expectLogin bool
+	}{
+		{"with the correct password", "gofs_password", true},
+		{"with the wrong password", "gofs_password_x", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			user, _ := auth.NewUser(1, "gofs", tc.password, auth.FullPerm)
+			c := apiclient.New(apiServerHost, port, true, certFile, "", "", user)
+			for i := 0; i < 3; i++ {
+				err = c.Start()
+				if err == nil || c.IsUnauthenticated(err) {
+					break
+				}
+				time.Sleep(time.Second)
+			}
+			defer c.Stop()
+			if tc.expectLogin && err != nil {
+				t.Errorf("login with the user in the users file error => %v", err)
+			}
+			if !tc.expectLogin && !c.IsUnauthenticated(err) {
+				t.Errorf("expect login failed with unauthenticated error, but get %v", err)
+			}
+		})
+	}
+}
+
+func TestApiServerAndClient_WithLoginLockout(t *testing.T) {
+	reporter := report.NewReporter()
+	reporter.Enable(true)
+	limiter, err := auth.NewLoginLimiter(auth.LoginLimiterOption{
+		MaxAttempts: 2,
+		Lockout:     time.Minute,
+		MaxLockout:  time.Hour,
+		OnLockout:   reporter.PutLockout,
+	})
+	if err != nil {
+		t.Fatalf("create login limiter error => %v", err)
+	}
+	serverUser, _ := auth.NewUser(1, "gofs", "gofs_password", auth.FullPerm)
+	port := apiServerPort + 4
+	srv, err := apiserver.New(apiserver.Option{
+		IP:                apiServerHost,
+		Port:              port,
+		EnableTLS:         true,
+		CertFile:          certFile,
+		KeyFile:           keyFile,
+		TokenSecret:       tokenSecret,
+		Users:             []*auth.User{serverUser},
+		Reporter:          reporter,
+		LoginLimiter:      limiter,
+		HttpServerAddr:    serverAddr,
+		Logger:            logger.NewTestLogger(),
+		TaskConf:          taskConfFile,
+		ChecksumAlgorithm: hashutil.DefaultHash,
+	})
+	if err != nil {
+		t.Fatalf("create api server error => %v", err)
+	}
+	go func() {
+		if err := srv.Start(); err != nil {
+			t.Errorf("start api server error => %v", err)
+		}
+	}()
+	defer srv.Stop()
+
+	testCases := []struct {
+		name       string
+		password   string
+		expectCode codes.Code
+	}{
+		{"first failed attempt", "wrong_password", codes.Unauthenticated},
+		{"second failed attempt", "wrong_password", codes.Unauthenticated},
+		{"locked out with the correct password", "gofs_password", codes.ResourceExhausted},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			user, _ := auth.NewUser(1, "gofs", tc.password, auth.FullPerm)
+			c := apiclient.New(apiServerHost, port, true, certFile, "", "", user)
+			for i := 0; i < 3; i++ {
+				err = c.Start()
+				if status.Code(err) == tc.expectCode {
+					break
+				}
+				time.Sleep(time.Second)
+			}
+			defer c.Stop()
+			if status.Code(err) != tc.expectCode {
+				t.Errorf("expect login failed with the code %s, but get %v", tc.expectCode, err)
+			}
+		})
+	}
+
+	time.Sleep(time.Millisecond * 100)
+	if count := reporter.GetReport().Lockouts.Len(); count != 2 {
+		t.Errorf("expect to get 2 lockout events of the ip and the username, but get %d", count)
+	}
+}
+
+func TestApiServerAndClient_WithTokenRefresh(t *testing.T) {
+	user, _ := auth.NewUser(1, "root", "123990", auth.FullPerm)
+	port := apiServerPort + 3
+	srv, err := apiserver.New(apiserver.Option{
+		IP:                  apiServerHost,
+		Port:                port,
+		EnableTLS:           true,
+		CertFile:            certFile,
+		KeyFile:             keyFile,
+		TokenAlgorithm:      authapi.EdDSAAlgorithm,
+		TokenExpires:        time.Second,
+		TokenRefreshExpires: time.Minute,
+		Users:               []*auth.User{user},
+		Reporter:            report.NewReporter(),
+		HttpServerAddr:      serverAddr,
+		Logger:              logger.NewTestLogger(),
+		TaskConf:            taskConfFile,
+		ChecksumAlgorithm:   hashutil.DefaultHash,
+	})
+	if err != nil {
+		t.Fatalf("create api server error => %v", err)
+	}
+	go func() {
+		if err := srv.Start(); err != nil {
+			t.Errorf("start api server error => %v", err)
+		}
+	}()
+	defer srv.Stop()
+
+	c := apiclient.New(apiServerHost, port, true, certFile, "", "", user)
+	for i := 0; i < 3; i++ {
+		if err = c.Start(); err == nil {
+			break
+		}
+		time.Sleep(time.Second)
+	}
+	defer c.Stop()
+	if err != nil {
+		t.Fatalf("start api client error => %v", err)
+	}
+	// the access token is expired, the client refreshes it transparently
+	for i := 0; i < 3; i++ {
+		time.Sleep(time.Second * 2)
+		if _, err = c.GetInfo(); err != nil {
+			t.Errorf("get info with the refreshed token error => %v", err)
+		}
+	}
+}
+
 func runApiServerAndClient(t *testing.T, user *auth.User) {
-	server, err := runApiServer(t, user)
+	auditLog := &testAuditLog{}
+	server, err := runApiServer(t, user, auditLog)
 	if err != nil {
 		t.Errorf("running api server error => %v", err)
 		return
@@ -48,14 +363,73 @@ func runApiServerAndClient(t *testing.T, user *auth.User) {
 		return
 	}
 	server.Stop()
+
+	userName := auth.GetAnonymousUser().UserName()
+	if user != nil {
+		userName = user.UserName()
+	}
+	for _, op := range []string{audit.OpStat, audit.OpBrowse, audit.OpDownload, audit.OpWrite, audit.PushOp(action.WriteAction, pushcontract.WritePushAction)} {
+		if !auditLog.contains(userName, op) {
+			t.Errorf("expect to get the audit record of the user [%s] and the operation [%s], but get nothing", userName, op)
+		}
+	}
+	if auditLog.contains(userName, "") {
+		t.Errorf("expect the compare requests are not audited")
+	}
+}
+
+// testAuditLog store the audit records in memory
+type testAuditLog struct {
+	records []audit.Record
+	mu      sync.Mutex
+}
+
+func (l *testAuditLog) Write(r audit.Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, r)
+	return nil
 }
 
-func runApiServer(t *testing.T, user *auth.User) (apiserver.Server, error) {
+func (l *testAuditLog) Close() error {
+	return nil
+}
+
+func (l *testAuditLog) contains(userName string, op string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, r := range l.records {
+		if r.User == userName && r.Op == op && r.IP == apiServerHost && strings.HasPrefix(r.Path, "/source/") {
+			return true
+		}
+	}
+	return false
+}
+
+func runApiServer(t *testing.T, user *auth.User, auditLog audit.AuditLog) (apiserver.Server, error) {
 	var users []*auth.User
 	if user != nil {
 		users = append(users, user)
 	}
-	srv, err := apiserver.New(apiServerHost, apiServerPort, true, certFile, keyFile, tokenSecret, users, report.NewReporter(), serverAddr, logger.NewTestLogger(), taskConfFile)
+	srv, err := apiserver.New(apiserver.Option{
+		IP:                apiServerHost,
+		Port:              apiServerPort,
+		EnableTLS:         true,
+		CertFile:          certFile,
+		KeyFile:           keyFile,
+		TokenSecret:       tokenSecret,
+		Users:             users,
+		Reporter:          report.NewReporter(),
+		AuditLog:          auditLog,
+		HttpServerAddr:    serverAddr,
+		Logger:            logger.NewTestLogger(),
+		TaskConf:          taskConfFile,
+		MonitorLogSize:    monitorLogSize,
+		SourcePath:        sourcePath,
+		ChunkSize:         chunkSize,
+		ChecksumAlgorithm: hashutil.DefaultHash,
+		EnablePushServer:  true,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -76,7 +450,7 @@ func runApiServer(t *testing.T, user *auth.User) (apiserver.Server, error) {
 }
 
 func runApiClient(user *auth.User) (err error) {
-	c := apiclient.New(apiServerHost, apiServerPort, true, certFile, user)
+	c := apiclient.New(apiServerHost, apiServerPort, true, certFile, "", "", user)
 	for i := 0; i < 3; i++ {
 		err = c.Start()
 		if err == nil {
@@ -93,11 +467,12 @@ func runApiClient(user *auth.User) (err error) {
 		return errors.New("invalid server addr")
 	}
 
-	ms, err := c.Monitor()
+	ms, err := c.Monitor(&monitor.MonitorRequest{})
 	if err != nil {
 		return err
 	}
 
+	var last *monitor.MonitorMessage
 	for i := 0; i < 5; i++ {
 		msg, err := ms.Recv()
 		if err != nil {
@@ -109,6 +484,50 @@ func runApiClient(user *auth.User) (err error) {
 		if msg.GetBaseUrl() != serverAddr {
 			return errors.New("invalid baseurl")
 		}
+		if last != nil && msg.GetSeq() != last.GetSeq()+1 {
+			return fmt.Errorf("expect the monitor message seq %d, but get %d", last.GetSeq()+1, msg.GetSeq())
+		}
+		last = msg
+	}
+
+	// acknowledge the received messages
+	err = c.Ack(&monitor.AckRequest{Epoch: last.GetEpoch(), Seq: last.GetSeq(), SuccessCount: 5})
+	if err != nil {
+		return err
+	}
+
+	// resume the monitor stream from the last received message
+	ms, err = c.Monitor(&monitor.MonitorRequest{Epoch: last.GetEpoch(), FromSeq: last.GetSeq()})
+	if err != nil {
+		return err
+	}
+	msg, err := ms.Recv()
+	if err != nil {
+		return err
+	}
+	if msg.GetSeq() != last.GetSeq()+1 {
+		return fmt.Errorf("expect to resume the monitor stream from seq %d, but get %d", last.GetSeq()+1, msg.GetSeq())
+	}
+
+	// the epoch is not matched, expect to receive a full sync message
+	ms, err = c.Monitor(&monitor.MonitorRequest{Epoch: last.GetEpoch() + 1, FromSeq: last.GetSeq()})
+	if err != nil {
+		return err
+	}
+	msg, err = ms.Recv()
+	if err != nil {
+		return err
+	}
+	if !msg.GetFullSync() {
+		return errors.New("expect to receive a full sync monitor message")
+	}
+
+	if err = runFileClient(c); err != nil {
+		return err
+	}
+
+	if err = runPushClient(c); err != nil {
+		return err
 	}
 
 	rc, err := c.SubscribeTask(&task.ClientInfo{
@@ -137,6 +556,210 @@ func runApiClient(user *auth.User) (err error) {
 		if i == 1 && (c.SyncOnce || c.Source.Path().Base() != "source" || c.Dest.Path().Base() != "dest") {
 			return errors.New("unexpect arguments")
 		}
+		// the file reference in the task content from the task server must not be expanded
+		if i == 1 && c.Users != "file:./tasks.yaml" {
+			return fmt.Errorf("expect to keep the file reference in the task content, but get %s", c.Users)
+		}
 	}
 	return c.Stop()
 }
+
+func runFileClient(c apiclient.Client) (err error) {
+	name := "tasks.yaml"
+	expect, err := os.ReadFile(filepath.Join(sourcePath, name))
+	if err != nil {
+		return err
+	}
+
+	fi, err := c.Stat(&file.StatRequest{Path: name, NeedHash: true})
+	if err != nil {
+		return err
+	}
+	if fi.GetPath() != name || fi.GetSize() != int64(len(expect)) || len(fi.GetHash()) == 0 {
+		return fmt.Errorf("unexpected file info => %v", fi)
+	}
+
+	if _, err = c.Stat(&file.StatRequest{Path: "../api_test.go"}); status.Code(err) != codes.NotFound {
+		return fmt.Errorf("expect the path can't escape the source path, but get %v", err)
+	}
+
+	lc, err := c.List(&file.ListRequest{Path: "/"})
+	if err != nil {
+		return err
+	}
+	found := false
+	for {
+		fi, err = lc.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if fi.GetPath() == name {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("expect to list the file %s", name)
+	}
+
+	offset := int64(10)
+	rc, err := c.Read(&file.ReadRequest{Path: name, Offset: offset})
+	if err != nil {
+		return err
+	}
+	var actual []byte
+	for {
+		reply, err := rc.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		actual = append(actual, reply.GetData()...)
+	}
+	if !bytes.Equal(expect[offset:], actual) {
+		return errors.New("the file content read from the file service is unexpected")
+	}
+
+	writeName := "grpc_write_test.txt"
+	defer os.Remove(filepath.Join(sourcePath, writeName))
+	wc, err := c.Write()
+	if err != nil {
+		return err
+	}
+	if err = wc.Send(&file.WriteRequest{Path: writeName, Data: []byte("hello ")}); err != nil {
+		return err
+	}
+	if err = wc.Send(&file.WriteRequest{Data: []byte("gofs")}); err != nil {
+		return err
+	}
+	reply, err := wc.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(filepath.Join(sourcePath, writeName))
+	if err != nil {
+		return err
+	}
+	if reply.GetSize() != 10 || string(data) != "hello gofs" {
+		return fmt.Errorf("unexpected write result, size=%d content=%s", reply.GetSize(), string(data))
+	}
+	return nil
+}
+
+func runPushClient(c apiclient.Client) (err error) {
+	name := "grpc_push_test.txt"
+	path := filepath.Join(sourcePath, name)
+	defer os.Remove(path)
+	content := []byte("hello gofs")
+	hash, err := hashutil.NewHash(hashutil.DefaultHash)
+	if err != nil {
+		return err
+	}
+	fi := &monitor.FileInfo{Path: name, Size: int64(len(content)), Hash: hash.Hash(content), MTime: time.Now().Unix()}
+
+	pc, err := c.Push()
+	if err != nil {
+		return err
+	}
+	// the requests are pipelined, send all of them before receiving the replies
+	requests := []struct {
+		req        *push.PushRequest
+		expectCode contract.Code
+	}{
+		{&push.PushRequest{Action: int32(action.WriteAction), PushAction: int32(pushcontract.WritePushAction), FileInfo: fi, Chunk: &push.Chunk{Size: 5}, Data: content[:5]}, contract.Success},
+		{&push.PushRequest{Action: int32(action.WriteAction), PushAction: int32(pushcontract.WritePushAction), FileInfo: fi, Chunk: &push.Chunk{Offset: 5, Size: 5}, Data: content[5:]}, contract.Success},
+		{&push.PushRequest{Action: int32(action.WriteAction), PushAction: int32(pushcontract.TruncatePushAction), FileInfo: fi, Chunk: &push.Chunk{Offset: 10}}, contract.Success},
+		{&push.PushRequest{Action: int32(action.WriteAction), PushAction: int32(pushcontract.CompareFilePushAction), FileInfo: fi, ForceChecksum: true}, contract.NotModified},
+		{&push.PushRequest{Action: int32(action.WriteAction), PushAction: int32(pushcontract.CompareChunkPushAction), FileInfo: fi, Chunk: &push.Chunk{Offset: 5, Size: 5, Hash: hash.Hash([]byte("hello"))}}, contract.ChunkModified},
+	}
+	for i, r := range requests {
+		r.req.Id = uint64(i + 1)
+		if err = pc.Send(r.req); err != nil {
+			return err
+		}
+	}
+	for _, r := range requests {
+		reply, err := pc.Recv()
+		if err != nil {
+			return err
+		}
+		if reply.GetId() != r.req.GetId() || contract.Code(reply.GetCode()) != r.expectCode {
+			return fmt.Errorf("expect reply id %d code %d, but get id %d code %d => %s", r.req.GetId(), r.expectCode, reply.GetId(), reply.GetCode(), reply.GetMessage())
+		}
+	}
+	if err = pc.CloseSend(); err != nil {
+		return err
+	}
+	if _, err = pc.Recv(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("expect the push stream is closed, but get %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(data, content) {
+		return fmt.Errorf("unexpected push result => %s", string(data))
+	}
+	return nil
+}
+
+// generateClientCerts generate a CA and the client certificates signed by it with the specified common names
+func generateClientCerts(dir string, commonNames ...string) (caFile string, certFiles []string, keyFiles []string, err error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gofs-test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return
+	}
+	caFile = filepath.Join(dir, "ca.pem")
+	if err = os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDer}), 0600); err != nil {
+		return
+	}
+	for i, commonName := range commonNames {
+		var clientKey *ecdsa.PrivateKey
+		if clientKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			return
+		}
+		clientTemplate := &x509.Certificate{
+			SerialNumber: big.NewInt(int64(i + 2)),
+			Subject:      pkix.Name{CommonName: commonName},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+		var clientDer, clientKeyDer []byte
+		if clientDer, err = x509.CreateCertificate(rand.Reader, clientTemplate, caTemplate, &clientKey.PublicKey, caKey); err != nil {
+			return
+		}
+		if clientKeyDer, err = x509.MarshalECPrivateKey(clientKey); err != nil {
+			return
+		}
+		clientCertFile := filepath.Join(dir, commonName+"-cert.pem")
+		clientKeyFile := filepath.Join(dir, commonName+"-key.pem")
+		if err = os.WriteFile(clientCertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: clientDer}), 0600); err != nil {
+			return
+		}
+		if err = os.WriteFile(clientKeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: clientKeyDer}), 0600); err != nil {
+			return
+		}
+		certFiles = append(certFiles, clientCertFile)
+		keyFiles = append(keyFiles, clientKeyFile)
+	}
+	return
+}
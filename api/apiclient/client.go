@@ -1,8 +1,10 @@
 package apiclient
 
 import (
+	"github.com/no-src/gofs/api/file"
 	"github.com/no-src/gofs/api/info"
 	"github.com/no-src/gofs/api/monitor"
+	"github.com/no-src/gofs/api/push"
 	"github.com/no-src/gofs/api/task"
 )
 
@@ -14,14 +16,26 @@ type Client interface {
 	Stop() error
 	// GetInfo get the file server info
 	GetInfo() (*info.FileServerInfo, error)
-	// Monitor monitor the remote server
-	Monitor() (monitor.MonitorService_MonitorClient, error)
+	// Monitor monitor the remote server, resume the monitor stream from the specified sequence number of the request
+	Monitor(req *monitor.MonitorRequest) (monitor.MonitorService_MonitorClient, error)
+	// Ack acknowledge the monitor messages that are applied by the client
+	Ack(req *monitor.AckRequest) error
 	// IsClosed is connection closed of the current client
 	IsClosed(err error) bool
 	// IsUnauthenticated check whether the error is unauthorized
 	IsUnauthenticated(err error) bool
 	// SubscribeTask register a task client to the task server and wait to receive task
 	SubscribeTask(clientInfo *task.ClientInfo) (task.TaskService_SubscribeTaskClient, error)
+	// Stat get the file info of the path in the source path of the server
+	Stat(req *file.StatRequest) (*monitor.FileInfo, error)
+	// List list the file info of the files in the directory of the source path of the server
+	List(req *file.ListRequest) (file.FileService_ListClient, error)
+	// Read read the file content from the source path of the server
+	Read(req *file.ReadRequest) (file.FileService_ReadClient, error)
+	// Write write the file content to the source path of the server
+	Write() (file.FileService_WriteClient, error)
+	// Push push the file changes to the server through a bidirectional stream
+	Push() (push.PushService_PushClient, error)
 	// Login login to the server
 	Login() (err error)
 }
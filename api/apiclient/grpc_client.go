@@ -6,10 +6,13 @@ import (
 	"time"
 
 	authapi "github.com/no-src/gofs/api/auth"
+	"github.com/no-src/gofs/api/file"
 	"github.com/no-src/gofs/api/info"
 	"github.com/no-src/gofs/api/monitor"
+	"github.com/no-src/gofs/api/push"
 	"github.com/no-src/gofs/api/task"
 	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/internal/tlsutil"
 	"golang.org/x/oauth2"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -25,27 +28,33 @@ type client struct {
 	monitor.MonitorServiceClient
 	authapi.AuthServiceClient
 	task.TaskServiceClient
+	file.FileServiceClient
+	push.PushServiceClient
 
-	host       string
-	port       int
-	enableTLS  bool
-	certFile   string
-	user       *auth.User
-	clientConn *grpc.ClientConn
-	creds      credentials.PerRPCCredentials
+	host           string
+	port           int
+	enableTLS      bool
+	certFile       string
+	clientCertFile string
+	clientKeyFile  string
+	user           *auth.User
+	clientConn     *grpc.ClientConn
+	creds          credentials.PerRPCCredentials
 }
 
-// New create the instance of the Client
-func New(host string, port int, enableTLS bool, certFile string, user *auth.User) Client {
+// New create the instance of the Client, present the client certificate to the server if the clientCertFile is not empty
+func New(host string, port int, enableTLS bool, certFile string, clientCertFile string, clientKeyFile string, user *auth.User) Client {
 	if user == nil {
 		user = auth.GetAnonymousUser()
 	}
 	return &client{
-		host:      host,
-		port:      port,
-		enableTLS: enableTLS,
-		certFile:  certFile,
-		user:      user,
+		host:           host,
+		port:           port,
+		enableTLS:      enableTLS,
+		certFile:       certFile,
+		clientCertFile: clientCertFile,
+		clientKeyFile:  clientKeyFile,
+		user:           user,
 	}
 }
 
@@ -60,9 +69,11 @@ func (c *client) connect() (err error) {
 	addr := fmt.Sprintf("%s:%d", c.host, c.port)
 	tranCreds := insecure.NewCredentials()
 	if c.enableTLS {
-		if tranCreds, err = credentials.NewClientTLSFromFile(c.certFile, c.host); err != nil {
+		tlsConfig, err := tlsutil.NewClientTLSConfig(c.certFile, c.host, c.clientCertFile, c.clientKeyFile)
+		if err != nil {
 			return err
 		}
+		tranCreds = credentials.NewTLS(tlsConfig)
 	}
 	clientConn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(tranCreds))
 	if err != nil {
@@ -72,6 +83,8 @@ func (c *client) connect() (err error) {
 	c.MonitorServiceClient = monitor.NewMonitorServiceClient(clientConn)
 	c.AuthServiceClient = authapi.NewAuthServiceClient(clientConn)
 	c.TaskServiceClient = task.NewTaskServiceClient(clientConn)
+	c.FileServiceClient = file.NewFileServiceClient(clientConn)
+	c.PushServiceClient = push.NewPushServiceClient(clientConn)
 	c.clientConn = clientConn
 	return nil
 }
@@ -98,19 +111,35 @@ func (c *client) GetInfo() (*info.FileServerInfo, error) {
 	return c.getInfo()
 }
 
-func (c *client) monitor() (monitor.MonitorService_MonitorClient, error) {
-	return c.MonitorServiceClient.Monitor(context.Background(), &emptypb.Empty{}, grpc.PerRPCCredentials(c.creds))
+func (c *client) monitor(req *monitor.MonitorRequest) (monitor.MonitorService_MonitorClient, error) {
+	return c.MonitorServiceClient.Monitor(context.Background(), req, grpc.PerRPCCredentials(c.creds))
 }
 
-func (c *client) Monitor() (monitor.MonitorService_MonitorClient, error) {
-	fsi, err := c.monitor()
+func (c *client) Monitor(req *monitor.MonitorRequest) (monitor.MonitorService_MonitorClient, error) {
+	fsi, err := c.monitor(req)
 	if !c.IsUnauthenticated(err) {
 		return fsi, err
 	}
 	if err = c.Login(); err != nil {
 		return nil, err
 	}
-	return c.monitor()
+	return c.monitor(req)
+}
+
+func (c *client) ack(req *monitor.AckRequest) error {
+	_, err := c.MonitorServiceClient.Ack(context.Background(), req, grpc.PerRPCCredentials(c.creds))
+	return err
+}
+
+func (c *client) Ack(req *monitor.AckRequest) error {
+	err := c.ack(req)
+	if !c.IsUnauthenticated(err) {
+		return err
+	}
+	if err = c.Login(); err != nil {
+		return err
+	}
+	return c.ack(req)
 }
 
 func (c *client) IsClosed(err error) bool {
@@ -136,22 +165,93 @@ func (c *client) SubscribeTask(clientInfo *task.ClientInfo) (task.TaskService_Su
 	return c.subscribeTask(clientInfo)
 }
 
-func (c *client) getToken() (token string, err error) {
-	reply, err := c.AuthServiceClient.Login(context.Background(), &authapi.LoginUser{
+func (c *client) stat(req *file.StatRequest) (*monitor.FileInfo, error) {
+	return c.FileServiceClient.Stat(context.Background(), req, grpc.PerRPCCredentials(c.creds))
+}
+
+func (c *client) Stat(req *file.StatRequest) (*monitor.FileInfo, error) {
+	fi, err := c.stat(req)
+	if !c.IsUnauthenticated(err) {
+		return fi, err
+	}
+	if err = c.Login(); err != nil {
+		return nil, err
+	}
+	return c.stat(req)
+}
+
+func (c *client) list(req *file.ListRequest) (file.FileService_ListClient, error) {
+	return c.FileServiceClient.List(context.Background(), req, grpc.PerRPCCredentials(c.creds))
+}
+
+func (c *client) List(req *file.ListRequest) (file.FileService_ListClient, error) {
+	lc, err := c.list(req)
+	if !c.IsUnauthenticated(err) {
+		return lc, err
+	}
+	if err = c.Login(); err != nil {
+		return nil, err
+	}
+	return c.list(req)
+}
+
+func (c *client) read(req *file.ReadRequest) (file.FileService_ReadClient, error) {
+	return c.FileServiceClient.Read(context.Background(), req, grpc.PerRPCCredentials(c.creds))
+}
+
+func (c *client) Read(req *file.ReadRequest) (file.FileService_ReadClient, error) {
+	rc, err := c.read(req)
+	if !c.IsUnauthenticated(err) {
+		return rc, err
+	}
+	if err = c.Login(); err != nil {
+		return nil, err
+	}
+	return c.read(req)
+}
+
+func (c *client) write() (file.FileService_WriteClient, error) {
+	return c.FileServiceClient.Write(context.Background(), grpc.PerRPCCredentials(c.creds))
+}
+
+func (c *client) Write() (file.FileService_WriteClient, error) {
+	wc, err := c.write()
+	if !c.IsUnauthenticated(err) {
+		return wc, err
+	}
+	if err = c.Login(); err != nil {
+		return nil, err
+	}
+	return c.write()
+}
+
+func (c *client) push() (push.PushService_PushClient, error) {
+	return c.PushServiceClient.Push(context.Background(), grpc.PerRPCCredentials(c.creds))
+}
+
+func (c *client) Push() (push.PushService_PushClient, error) {
+	pc, err := c.push()
+	if !c.IsUnauthenticated(err) {
+		return pc, err
+	}
+	if err = c.Login(); err != nil {
+		return nil, err
+	}
+	return c.push()
+}
+
+func (c *client) getToken() (*authapi.LoginReply, error) {
+	return c.AuthServiceClient.Login(context.Background(), &authapi.LoginUser{
 		Username:  c.user.UserName(),
 		Password:  c.user.Password(),
 		Timestamp: time.Now().Unix(),
 	})
-	if err != nil {
-		return token, err
-	}
-	return reply.Token, nil
 }
 
 func (c *client) Login() (err error) {
-	token, err := c.getToken()
+	reply, err := c.getToken()
 	if err == nil {
-		oauth2Token := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+		oauth2Token := oauth2.ReuseTokenSource(toOAuth2Token(reply), &refreshTokenSource{client: c, refreshToken: reply.GetRefreshToken()})
 		if c.enableTLS {
 			c.creds = &oauth.TokenSource{TokenSource: oauth2Token}
 		} else {
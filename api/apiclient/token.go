@@ -3,7 +3,9 @@ package apiclient
 import (
 	"context"
 	"fmt"
+	"time"
 
+	authapi "github.com/no-src/gofs/api/auth"
 	"golang.org/x/oauth2"
 	"google.golang.org/grpc/credentials"
 )
@@ -32,3 +34,37 @@ func (ts insecureTokenSource) GetRequestMetadata(ctx context.Context, uri ...str
 func (ts insecureTokenSource) RequireTransportSecurity() bool {
 	return false
 }
+
+// refreshTokenSource refresh the access token with the refresh token, and login again if the refresh token is invalid,
+// it is wrapped by the oauth2.ReuseTokenSource, so the access token is refreshed transparently before it expires
+type refreshTokenSource struct {
+	client       *client
+	refreshToken string
+}
+
+// Token return a new access token
+func (ts *refreshTokenSource) Token() (*oauth2.Token, error) {
+	reply, err := ts.client.AuthServiceClient.Refresh(context.Background(), &authapi.RefreshRequest{
+		RefreshToken: ts.refreshToken,
+	})
+	if err != nil {
+		// the refresh token is expired or revoked, try to login again
+		reply, err = ts.client.getToken()
+	}
+	if err != nil {
+		return nil, err
+	}
+	ts.refreshToken = reply.GetRefreshToken()
+	return toOAuth2Token(reply), nil
+}
+
+// toOAuth2Token convert the login reply to the oauth2.Token
+func toOAuth2Token(reply *authapi.LoginReply) *oauth2.Token {
+	token := &oauth2.Token{
+		AccessToken: reply.GetToken(),
+	}
+	if reply.GetExpires() > 0 {
+		token.Expiry = time.Unix(reply.GetExpires(), 0)
+	}
+	return token
+}
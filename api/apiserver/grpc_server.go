@@ -6,67 +6,142 @@ import (
 	"time"
 
 	authapi "github.com/no-src/gofs/api/auth"
+	"github.com/no-src/gofs/api/file"
 	"github.com/no-src/gofs/api/info"
 	"github.com/no-src/gofs/api/monitor"
+	"github.com/no-src/gofs/api/push"
 	"github.com/no-src/gofs/api/task"
+	"github.com/no-src/gofs/audit"
 	"github.com/no-src/gofs/auth"
 	"github.com/no-src/gofs/internal/clist"
+	"github.com/no-src/gofs/internal/tlsutil"
 	"github.com/no-src/gofs/logger"
 	"github.com/no-src/gofs/report"
+	"github.com/no-src/nsgo/hashutil"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
+const (
+	// defaultMonitorLogSize the default max number of the retained monitor messages
+	defaultMonitorLogSize = 10000
+	// loginSource the source of the login attempts of the grpc server
+	loginSource = "grpc"
+)
+
 type grpcServer struct {
-	network         string
-	ip              net.IP
-	port            int
-	users           []*auth.User
-	token           authapi.Token
-	certFile        string
-	keyFile         string
-	enableTLS       bool
-	reporter        report.Reporter
-	httpServerAddr  string
-	server          *grpc.Server
-	monitors        *sync.Map
-	monitorMessages *clist.CList
-	logger          *logger.Logger
-	taskConf        string
+	network               string
+	ip                    net.IP
+	port                  int
+	store                 auth.UserStore
+	certUsers             []*auth.User
+	token                 authapi.Token
+	certFile              string
+	keyFile               string
+	clientCAFile          string
+	enableTLS             bool
+	reporter              report.Reporter
+	limiter               auth.LoginLimiter
+	auditLog              audit.AuditLog
+	httpServerAddr        string
+	server                *grpc.Server
+	monitors              *sync.Map
+	monitorMessages       *clist.CList
+	messageLog            *monitor.MessageLog
+	logger                *logger.Logger
+	taskConf              string
+	sourcePath            string
+	chunkSize             int64
+	checkpointCount       int
+	hash                  hashutil.Hash
+	maxTranRate           int64
+	enablePushServer      bool
+	enableLogicallyDelete bool
 }
 
 // New create the instance of the Server
-func New(ip string, port int, enableTLS bool, certFile string, keyFile string, tokenSecret string, users []*auth.User, reporter report.Reporter, httpServerAddr string, logger *logger.Logger, taskConf string) (Server, error) {
-	if len(users) == 0 {
-		logger.Warn("the grpc server allows anonymous access, you should set some server users by the -users or -rand_user_count flag for security reasons")
-		users = append(users, auth.GetAnonymousUser())
+func New(opt Option) (Server, error) {
+	certUsers := opt.CertUsers
+	logger := opt.Logger
+	monitorLogSize := opt.MonitorLogSize
+
+	// the user store is shared with the file server if it is specified
+	store := opt.UserStore
+	if store == nil {
+		var err error
+		if store, err = auth.NewUserStore(opt.Users, opt.UsersFile); err != nil {
+			return nil, err
+		}
 	}
-	token, err := authapi.NewToken(users, tokenSecret)
+	if len(store.Users()) == 0 && len(certUsers) == 0 {
+		logger.Warn("the grpc server allows anonymous access, you should set some server users by the -users, -users_file or -rand_user_count flag for security reasons")
+		store, _ = auth.NewUserStore([]*auth.User{auth.GetAnonymousUser()}, "")
+	}
+	token, err := authapi.NewToken(store, certUsers, authapi.TokenOption{
+		Algorithm:      opt.TokenAlgorithm,
+		Secret:         opt.TokenSecret,
+		KeyFile:        opt.TokenKeyFile,
+		Expires:        opt.TokenExpires,
+		RefreshExpires: opt.TokenRefreshExpires,
+	})
+	if err != nil {
+		return nil, err
+	}
+	limiter := opt.LoginLimiter
+	if limiter == nil {
+		limiter = auth.NewEmptyLoginLimiter()
+	}
+	auditLog := opt.AuditLog
+	if auditLog == nil {
+		auditLog = audit.NewEmptyAuditLog()
+	}
+	if monitorLogSize <= 0 {
+		monitorLogSize = defaultMonitorLogSize
+	}
+	messageLog, err := monitor.NewMessageLog(monitorLogSize)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := hashutil.NewHash(opt.ChecksumAlgorithm)
 	if err != nil {
 		return nil, err
 	}
 	srv := &grpcServer{
-		network:         "tcp",
-		ip:              net.ParseIP(ip),
-		port:            port,
-		users:           users,
-		token:           token,
-		enableTLS:       enableTLS,
-		certFile:        certFile,
-		keyFile:         keyFile,
-		reporter:        reporter,
-		httpServerAddr:  httpServerAddr,
-		monitors:        &sync.Map{},
-		monitorMessages: clist.New(),
-		logger:          logger,
-		taskConf:        taskConf,
+		network:               "tcp",
+		ip:                    net.ParseIP(opt.IP),
+		port:                  opt.Port,
+		store:                 store,
+		certUsers:             certUsers,
+		token:                 token,
+		enableTLS:             opt.EnableTLS,
+		certFile:              opt.CertFile,
+		keyFile:               opt.KeyFile,
+		clientCAFile:          opt.ClientCAFile,
+		reporter:              opt.Reporter,
+		limiter:               auth.WithSource(limiter, loginSource),
+		auditLog:              auditLog,
+		httpServerAddr:        opt.HttpServerAddr,
+		monitors:              &sync.Map{},
+		monitorMessages:       clist.New(),
+		messageLog:            messageLog,
+		logger:                logger,
+		taskConf:              opt.TaskConf,
+		sourcePath:            opt.SourcePath,
+		chunkSize:             opt.ChunkSize,
+		checkpointCount:       opt.CheckpointCount,
+		hash:                  hash,
+		maxTranRate:           opt.MaxTranRate,
+		enablePushServer:      opt.EnablePushServer,
+		enableLogicallyDelete: opt.EnableLogicallyDelete,
 	}
 	creds := insecure.NewCredentials()
-	if enableTLS {
-		if creds, err = credentials.NewServerTLSFromFile(srv.certFile, srv.keyFile); err != nil {
+	if srv.enableTLS {
+		tlsConfig, err := tlsutil.NewServerTLSConfig(srv.certFile, srv.keyFile, srv.clientCAFile)
+		if err != nil {
 			return nil, err
 		}
+		creds = credentials.NewTLS(tlsConfig)
 	} else {
 		logger.Warn("the grpc server is not enable enableTLS, it is not a security connection")
 	}
@@ -101,8 +176,14 @@ func (gs *grpcServer) SendMonitorMessage(message *monitor.MonitorMessage) {
 
 func (gs *grpcServer) initRoute(s *grpc.Server) (err error) {
 	info.RegisterServer(s, gs.httpServerAddr)
-	monitor.RegisterServer(s, gs.monitors, gs.reporter, gs.token)
-	authapi.RegisterServer(s, gs.token)
+	monitor.RegisterServer(s, gs.monitors, gs.messageLog, gs.reporter, gs.token)
+	authapi.RegisterServer(s, gs.token, gs.limiter, gs.logger)
+	if len(gs.sourcePath) > 0 {
+		file.RegisterServer(s, gs.sourcePath, gs.token, gs.chunkSize, gs.checkpointCount, gs.hash, gs.maxTranRate, gs.auditLog, gs.logger)
+		if gs.enablePushServer {
+			push.RegisterServer(s, gs.sourcePath, gs.token, gs.enableLogicallyDelete, gs.hash, gs.auditLog, gs.logger)
+		}
+	}
 	err = task.RegisterServer(s, gs.taskConf)
 	return err
 }
@@ -112,6 +193,7 @@ func (gs *grpcServer) processMonitorMessage() {
 		e := gs.monitorMessages.Front()
 		if e != nil {
 			msg := e.Value.(*monitor.MonitorMessage)
+			gs.messageLog.Append(msg)
 			gs.monitors.Range(func(key, value any) bool {
 				msgChan := value.(chan *monitor.MonitorMessage)
 				msgChan <- msg
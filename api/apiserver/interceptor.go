@@ -10,10 +10,10 @@ import (
 )
 
 func (gs *grpcServer) UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-	if info.FullMethod == auth.AuthService_Login_FullMethodName {
+	if info.FullMethod == auth.AuthService_Login_FullMethodName || info.FullMethod == auth.AuthService_Refresh_FullMethodName {
 		return handler(ctx, req)
 	}
-	if len(gs.users) == 0 {
+	if !gs.needAuth() {
 		return handler(ctx, req)
 	}
 	loginUser, err := gs.token.IsLogin(ctx)
@@ -28,8 +28,8 @@ func (gs *grpcServer) UnaryServerInterceptor(ctx context.Context, req interface{
 }
 
 func (gs *grpcServer) StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-	if len(gs.users) == 0 {
-		return nil
+	if !gs.needAuth() {
+		return handler(srv, ss)
 	}
 	loginUser, err := gs.token.IsLogin(ss.Context())
 	if err != nil {
@@ -41,3 +41,8 @@ func (gs *grpcServer) StreamServerInterceptor(srv interface{}, ss grpc.ServerStr
 	}
 	return handler(srv, ss)
 }
+
+// needAuth return true if any server user or cert user exists, the cert users must log in with the client certificate first
+func (gs *grpcServer) needAuth() bool {
+	return len(gs.store.Users()) > 0 || len(gs.certUsers) > 0
+}
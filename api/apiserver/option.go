@@ -0,0 +1,43 @@
+package apiserver
+
+import (
+	"time"
+
+	"github.com/no-src/gofs/audit"
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/report"
+)
+
+// Option the api server option
+type Option struct {
+	IP                    string
+	Port                  int
+	EnableTLS             bool
+	CertFile              string
+	KeyFile               string
+	ClientCAFile          string
+	TokenSecret           string
+	TokenAlgorithm        string
+	TokenKeyFile          string
+	TokenExpires          time.Duration
+	TokenRefreshExpires   time.Duration
+	Users                 []*auth.User
+	UsersFile             string
+	CertUsers             []*auth.User
+	UserStore             auth.UserStore
+	Reporter              report.Reporter
+	LoginLimiter          auth.LoginLimiter
+	AuditLog              audit.AuditLog
+	HttpServerAddr        string
+	Logger                *logger.Logger
+	TaskConf              string
+	MonitorLogSize        int
+	SourcePath            string
+	ChunkSize             int64
+	CheckpointCount       int
+	ChecksumAlgorithm     string
+	MaxTranRate           int64
+	EnablePushServer      bool
+	EnableLogicallyDelete bool
+}
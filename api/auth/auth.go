@@ -2,31 +2,67 @@ package auth
 
 import (
 	"context"
+	"net"
+	"time"
 
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/logger"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
-// RegisterServer register the auth server
-func RegisterServer(s grpc.ServiceRegistrar, token Token) {
+// RegisterServer register the auth server, the failed login attempts are limited by the LoginLimiter
+func RegisterServer(s grpc.ServiceRegistrar, token Token, limiter auth.LoginLimiter, logger *logger.Logger) {
 	RegisterAuthServiceServer(s, &server{
-		token: token,
+		token:   token,
+		limiter: limiter,
+		logger:  logger,
 	})
 }
 
 type server struct {
 	UnimplementedAuthServiceServer
 
-	token Token
+	token   Token
+	limiter auth.LoginLimiter
+	logger  *logger.Logger
 }
 
 func (s *server) Login(ctx context.Context, in *LoginUser) (*LoginReply, error) {
-	token, err := s.token.GenerateToken(in)
+	ip := ClientIP(ctx)
+	userName := in.GetUsername()
+	if lockout := s.limiter.Check(ip, userName); lockout > 0 {
+		s.logger.Warn("[grpc auth server] login is locked out, username=%s remote=%s", userName, ip)
+		return nil, status.Errorf(codes.ResourceExhausted, "too many failed login attempts, retry after %s", lockout.Round(time.Second))
+	}
+	reply, err := s.token.GenerateToken(ctx, in)
+	if err != nil {
+		s.limiter.Fail(ip, userName)
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	s.limiter.Success(ip, userName)
+	return reply, nil
+}
+
+func (s *server) Refresh(ctx context.Context, in *RefreshRequest) (*LoginReply, error) {
+	reply, err := s.token.RefreshToken(ctx, in)
 	if err != nil {
 		return nil, status.Error(codes.Unauthenticated, err.Error())
 	}
-	return &LoginReply{
-		Token: token,
-	}, nil
+	return reply, nil
+}
+
+// ClientIP return the ip address of the client connection
+func ClientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
 }
@@ -2,63 +2,190 @@ package auth
 
 import (
 	"context"
-	"crypto/aes"
-	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/no-src/gofs/auth"
-	"github.com/no-src/nsgo/jsonutil"
+	"github.com/no-src/gofs/internal/tlsutil"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
+const (
+	// HS256Algorithm sign the token with the HMAC-SHA256 and the token secret
+	HS256Algorithm = "HS256"
+	// EdDSAAlgorithm sign the token with the Ed25519 private key
+	EdDSAAlgorithm = "EdDSA"
+	// DefaultTokenAlgorithm the default algorithm to sign the token
+	DefaultTokenAlgorithm = HS256Algorithm
+	// DefaultTokenExpires the default lifetime of the access token
+	DefaultTokenExpires = time.Minute * 30
+	// DefaultRefreshTokenExpires the default lifetime of the refresh token
+	DefaultRefreshTokenExpires = time.Hour * 24
+
+	tokenIssuer      = "gofs"
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
+	minSecretLength  = 16
+)
+
+var (
+	errLoginFailed        = errors.New("login failed")
+	errRefreshTokenReused = errors.New("the refresh token has been used")
+)
+
 // Token an authentication and token component
 type Token interface {
-	// GenerateToken generate a new token by user info
-	GenerateToken(in *LoginUser) (token string, err error)
+	// GenerateToken generate a new access token and refresh token by user info, or by the verified client certificate of the connection in the context.Context
+	GenerateToken(ctx context.Context, in *LoginUser) (reply *LoginReply, err error)
+	// RefreshToken generate a new access token and refresh token by the refresh token, the refresh token can be used only once
+	RefreshToken(ctx context.Context, in *RefreshRequest) (reply *LoginReply, err error)
 	// IsLogin resolve the token in the context.Context
 	IsLogin(ctx context.Context) (user *auth.User, err error)
 }
 
+// TokenOption the option of the token component
+type TokenOption struct {
+	// Algorithm the algorithm to sign the token, current supported algorithms: HS256, EdDSA
+	Algorithm string
+	// Secret the secret string to sign the token with the HS256 algorithm
+	Secret string
+	// KeyFile the PEM file of the Ed25519 private key to sign the token with the EdDSA algorithm,
+	// generate a random key if it is empty, then all the tokens are invalid after restart
+	KeyFile string
+	// Expires the lifetime of the access token
+	Expires time.Duration
+	// RefreshExpires the lifetime of the refresh token
+	RefreshExpires time.Duration
+}
+
 type token struct {
-	users               []*auth.User
-	timeoutSeconds      int64
-	tokenExpiresSeconds int64
-	secret              string
-	iv                  string
+	store          auth.UserStore
+	certUsers      []*auth.User
+	timeoutSeconds int64
+	expires        time.Duration
+	refreshExpires time.Duration
+	method         jwt.SigningMethod
+	signKey        any
+	verifyKey      any
+	// usedRefreshIds the jti of the used refresh tokens and their expiration time, a used refresh token is rejected until it is expired
+	usedRefreshIds map[string]time.Time
+	mu             sync.Mutex
+}
+
+// tokenClaims the claims of the access token and the refresh token
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	UserId int    `json:"uid"`
+	Type   string `json:"typ"`
 }
 
-// NewToken create a default implementation of the Token
-func NewToken(users []*auth.User, secret string) (Token, error) {
-	err := checkTokenSecret(secret)
+// ValidateTokenOption check the algorithm, the secret and the key file of the token option like the NewToken does
+func ValidateTokenOption(opt TokenOption) error {
+	_, err := NewToken(nil, nil, opt)
+	return err
+}
+
+// NewToken create a default implementation of the Token, the certUsers are mapped from the client certificates
+func NewToken(store auth.UserStore, certUsers []*auth.User, opt TokenOption) (Token, error) {
+	t := &token{
+		store:          store,
+		certUsers:      certUsers,
+		timeoutSeconds: 60,
+		expires:        opt.Expires,
+		refreshExpires: opt.RefreshExpires,
+		usedRefreshIds: make(map[string]time.Time),
+	}
+	if t.expires <= 0 {
+		t.expires = DefaultTokenExpires
+	}
+	if t.refreshExpires <= 0 {
+		t.refreshExpires = DefaultRefreshTokenExpires
+	}
+	algorithm := opt.Algorithm
+	if len(algorithm) == 0 {
+		algorithm = DefaultTokenAlgorithm
+	}
+	switch strings.ToUpper(algorithm) {
+	case strings.ToUpper(HS256Algorithm):
+		if err := checkTokenSecret(opt.Secret); err != nil {
+			return nil, err
+		}
+		t.method = jwt.SigningMethodHS256
+		t.signKey = []byte(opt.Secret)
+		t.verifyKey = t.signKey
+	case strings.ToUpper(EdDSAAlgorithm):
+		privateKey, err := loadEd25519PrivateKey(opt.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		t.method = jwt.SigningMethodEdDSA
+		t.signKey = privateKey
+		t.verifyKey = privateKey.Public()
+	default:
+		return nil, fmt.Errorf("unsupported token algorithm => %s, please check the -token_algorithm flag", algorithm)
+	}
+	return t, nil
+}
+
+func (t *token) GenerateToken(ctx context.Context, in *LoginUser) (reply *LoginReply, err error) {
+	var user *auth.User
+	if in.GetTimestamp()+t.timeoutSeconds > time.Now().Unix() {
+		user = t.store.Login(in.GetUsername(), in.GetPassword())
+	}
+	if user == nil {
+		user = t.certUser(ctx)
+	}
+	if user == nil {
+		return nil, errLoginFailed
+	}
+	return t.newLoginReply(user)
+}
+
+func (t *token) RefreshToken(ctx context.Context, in *RefreshRequest) (reply *LoginReply, err error) {
+	user, claims, err := t.parseToken(in.GetRefreshToken(), refreshTokenType)
 	if err != nil {
 		return nil, err
 	}
-	return &token{
-		users:               users,
-		timeoutSeconds:      60,
-		tokenExpiresSeconds: 60 * 30,
-		secret:              secret,
-		iv:                  "nosrc-gofs-token",
-	}, nil
+	if err = t.useRefreshToken(claims); err != nil {
+		return nil, err
+	}
+	return t.newLoginReply(user)
 }
 
-func (t *token) GenerateToken(in *LoginUser) (token string, err error) {
-	var user *auth.User
-	for _, u := range t.users {
-		if u.UserName() == in.GetUsername() && u.Password() == in.GetPassword() && in.GetTimestamp()+t.timeoutSeconds > time.Now().Unix() {
-			user = u
+// useRefreshToken mark the refresh token as used, return an error if it has been used already,
+// a new refresh token is issued on every refresh, so a replayed refresh token is always rejected
+func (t *token) useRefreshToken(claims *tokenClaims) error {
+	if len(claims.ID) == 0 {
+		return errors.New("the refresh token has no jti")
+	}
+	now := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// the expired refresh tokens are rejected by the verification, so remove them from the denylist
+	for id, expires := range t.usedRefreshIds {
+		if !expires.After(now) {
+			delete(t.usedRefreshIds, id)
 		}
 	}
-	if user != nil {
-		return t.encodeToken(user)
+	if _, ok := t.usedRefreshIds[claims.ID]; ok {
+		return errRefreshTokenReused
 	}
-	return token, errors.New("login failed")
+	t.usedRefreshIds[claims.ID] = claims.ExpiresAt.Time
+	return nil
 }
 
 func (t *token) IsLogin(ctx context.Context) (user *auth.User, err error) {
@@ -71,62 +198,128 @@ func (t *token) IsLogin(ctx context.Context) (user *auth.User, err error) {
 	if len(mdv) > 0 {
 		authorization = strings.TrimPrefix(mdv[0], "Bearer ")
 	}
-	tu, err := t.decodeToken(authorization)
-	if err != nil {
-		return nil, err
+	return t.verifyToken(authorization, accessTokenType)
+}
+
+// certUser return the user that is mapped from the verified client certificate of the connection
+func (t *token) certUser(ctx context.Context) *auth.User {
+	if len(t.certUsers) == 0 {
+		return nil
 	}
-	for _, u := range t.users {
-		if u.UserName() == tu.GetUsername() && tu.Expires > time.Now().Unix() {
-			user = u
-		}
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
 	}
-	return user, nil
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+	return auth.MatchCertUser(t.certUsers, tlsutil.VerifiedClientCert(&tlsInfo.State))
 }
 
-func (t *token) encodeToken(u *auth.User) (token string, err error) {
-	tu := TokenUser{
-		UserId:   int32(u.UserId()),
-		Username: u.UserName(),
-		Expires:  time.Now().Unix() + t.tokenExpiresSeconds,
+func (t *token) newLoginReply(u *auth.User) (reply *LoginReply, err error) {
+	now := time.Now()
+	reply = &LoginReply{
+		Expires:        now.Add(t.expires).Unix(),
+		RefreshExpires: now.Add(t.refreshExpires).Unix(),
 	}
-	data, err := jsonutil.Marshal(tu)
-	if err != nil {
-		return token, err
+	if reply.Token, err = t.signToken(u, accessTokenType, now, t.expires); err != nil {
+		return nil, err
+	}
+	if reply.RefreshToken, err = t.signToken(u, refreshTokenType, now, t.refreshExpires); err != nil {
+		return nil, err
 	}
-	block, err := aes.NewCipher([]byte(t.secret))
+	return reply, nil
+}
+
+func (t *token) signToken(u *auth.User, tokenType string, now time.Time, expires time.Duration) (string, error) {
+	id, err := newTokenId()
 	if err != nil {
-		return token, err
+		return "", err
 	}
-	stream := cipher.NewCFBEncrypter(block, []byte(t.iv))
-	dst := make([]byte, len(data))
-	stream.XORKeyStream(dst, data)
-	token = base64.StdEncoding.EncodeToString(dst)
-	return token, nil
+	claims := tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        id,
+			Issuer:    tokenIssuer,
+			Subject:   u.UserName(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expires)),
+		},
+		UserId: u.UserId(),
+		Type:   tokenType,
+	}
+	return jwt.NewWithClaims(t.method, claims).SignedString(t.signKey)
 }
 
-func (t *token) decodeToken(token string) (tu *TokenUser, err error) {
-	if len(token) == 0 {
-		return nil, errors.New("token can't be empty")
+// newTokenId generate a random id as the jti of the token
+func newTokenId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
-	data, err := base64.StdEncoding.DecodeString(token)
-	if err != nil {
-		return nil, err
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// verifyToken verify the signature, the expiration time and the type of the token, return the user that the token belongs to,
+// the user must exist currently, so the token is invalid after the user is removed
+func (t *token) verifyToken(tokenString string, tokenType string) (user *auth.User, err error) {
+	user, _, err = t.parseToken(tokenString, tokenType)
+	return user, err
+}
+
+// parseToken verify the token like the verifyToken does, and return the claims of the token too
+func (t *token) parseToken(tokenString string, tokenType string) (user *auth.User, claims *tokenClaims, err error) {
+	if len(tokenString) == 0 {
+		return nil, nil, errors.New("token can't be empty")
 	}
-	block, err := aes.NewCipher([]byte(t.secret))
+	claims = &tokenClaims{}
+	_, err = jwt.ParseWithClaims(tokenString, claims, func(*jwt.Token) (any, error) {
+		return t.verifyKey, nil
+	}, jwt.WithValidMethods([]string{t.method.Alg()}), jwt.WithIssuer(tokenIssuer), jwt.WithExpirationRequired())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if claims.Type != tokenType {
+		return nil, nil, fmt.Errorf("invalid token type => %s", claims.Type)
+	}
+	users := append(t.store.Users(), t.certUsers...)
+	for _, u := range users {
+		if u.UserName() == claims.Subject {
+			return u, claims, nil
+		}
 	}
-	stream := cipher.NewCFBDecrypter(block, []byte(t.iv))
-	dst := make([]byte, len(data))
-	stream.XORKeyStream(dst, data)
-	err = jsonutil.Unmarshal(dst, &tu)
-	return tu, err
+	return nil, nil, errLoginFailed
 }
 
 func checkTokenSecret(secret string) error {
 	length := len(secret)
-	if length == 16 || length == 24 || length == 32 {
+	if length >= minSecretLength {
 		return nil
 	}
-	return fmt.Errorf("invalid token secret size => %d, current must be either 16, 24, or 32 bytes, please check the -token_secret flag", length)
+	return fmt.Errorf("invalid token secret size => %d, current must be at least %d bytes, please check the -token_secret flag", length, minSecretLength)
+}
+
+// loadEd25519PrivateKey load the Ed25519 private key from the PKCS #8 PEM file, generate a random key if the keyFile is empty
+func loadEd25519PrivateKey(keyFile string) (ed25519.PrivateKey, error) {
+	if len(keyFile) == 0 {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	}
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM file of the token key => %s", keyFile)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the token key is not an Ed25519 private key => %s", keyFile)
+	}
+	return privateKey, nil
 }
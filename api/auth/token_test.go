@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/no-src/gofs/auth"
+	"google.golang.org/grpc/metadata"
+)
+
+const testTokenSecret = "123456abcdefghij"
+
+func TestToken(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "token.key")
+	if err := generateEd25519KeyFile(keyFile); err != nil {
+		t.Fatalf("generate the Ed25519 key file error => %v", err)
+	}
+	testCases := []struct {
+		name string
+		opt  TokenOption
+	}{
+		{"default", TokenOption{Secret: testTokenSecret}},
+		{"HS256", TokenOption{Algorithm: HS256Algorithm, Secret: testTokenSecret, Expires: time.Minute, RefreshExpires: time.Hour}},
+		{"EdDSA with random key", TokenOption{Algorithm: EdDSAAlgorithm}},
+		{"EdDSA with key file", TokenOption{Algorithm: EdDSAAlgorithm, KeyFile: keyFile}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tk, user := newTestToken(t, tc.opt)
+			reply, err := tk.GenerateToken(context.Background(), &LoginUser{Username: user.UserName(), Password: user.Password(), Timestamp: time.Now().Unix()})
+			if err != nil {
+				t.Fatalf("generate token error => %v", err)
+			}
+			if reply.GetExpires() <= time.Now().Unix() || reply.GetRefreshExpires() < reply.GetExpires() {
+				t.Errorf("invalid expiration time of the token => %d %d", reply.GetExpires(), reply.GetRefreshExpires())
+			}
+			loginUser, err := tk.IsLogin(withToken(reply.GetToken()))
+			if err != nil || loginUser == nil || loginUser.UserName() != user.UserName() {
+				t.Fatalf("resolve the access token expect get the user [%s], but get %v %v", user.UserName(), loginUser, err)
+			}
+			if _, err = tk.IsLogin(withToken(reply.GetRefreshToken())); err == nil {
+				t.Errorf("resolve the refresh token as the access token expect get an error, but get nil")
+			}
+			if _, err = tk.RefreshToken(context.Background(), &RefreshRequest{RefreshToken: reply.GetToken()}); err == nil {
+				t.Errorf("refresh with the access token expect get an error, but get nil")
+			}
+			refreshReply, err := tk.RefreshToken(context.Background(), &RefreshRequest{RefreshToken: reply.GetRefreshToken()})
+			if err != nil {
+				t.Fatalf("refresh token error => %v", err)
+			}
+			if _, err = tk.IsLogin(withToken(refreshReply.GetToken())); err != nil {
+				t.Errorf("resolve the refreshed access token error => %v", err)
+			}
+			if _, err = tk.IsLogin(withToken(reply.GetToken() + "x")); err == nil {
+				t.Errorf("resolve the tampered token expect get an error, but get nil")
+			}
+		})
+	}
+}
+
+func TestToken_RefreshTokenReplay(t *testing.T) {
+	tk, user := newTestToken(t, TokenOption{Secret: testTokenSecret})
+	reply, err := tk.GenerateToken(context.Background(), &LoginUser{Username: user.UserName(), Password: user.Password(), Timestamp: time.Now().Unix()})
+	if err != nil {
+		t.Fatalf("generate token error => %v", err)
+	}
+	refreshReply, err := tk.RefreshToken(context.Background(), &RefreshRequest{RefreshToken: reply.GetRefreshToken()})
+	if err != nil {
+		t.Fatalf("refresh token error => %v", err)
+	}
+	if refreshReply.GetRefreshToken() == reply.GetRefreshToken() {
+		t.Errorf("expect to issue a new refresh token on every refresh")
+	}
+	if _, err = tk.RefreshToken(context.Background(), &RefreshRequest{RefreshToken: reply.GetRefreshToken()}); !errors.Is(err, errRefreshTokenReused) {
+		t.Errorf("replay the refresh token expect get error => [%v] but get [%v]", errRefreshTokenReused, err)
+	}
+	if _, err = tk.RefreshToken(context.Background(), &RefreshRequest{RefreshToken: refreshReply.GetRefreshToken()}); err != nil {
+		t.Errorf("refresh with the new refresh token error => %v", err)
+	}
+}
+
+func TestToken_ReturnError(t *testing.T) {
+	tk, user := newTestToken(t, TokenOption{Secret: testTokenSecret})
+	otherToken, _ := newTestToken(t, TokenOption{Secret: testTokenSecret + "x"})
+	expiredToken, _ := newTestToken(t, TokenOption{Secret: testTokenSecret, Expires: time.Nanosecond})
+
+	testCases := []struct {
+		name  string
+		token Token
+		in    *LoginUser
+	}{
+		{"wrong password", tk, &LoginUser{Username: user.UserName(), Password: "wrong", Timestamp: time.Now().Unix()}},
+		{"timeout", tk, &LoginUser{Username: user.UserName(), Password: user.Password(), Timestamp: time.Now().Unix() - 3600}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tc.token.GenerateToken(context.Background(), tc.in); err == nil {
+				t.Errorf("generate token expect get an error, but get nil")
+			}
+		})
+	}
+
+	in := &LoginUser{Username: user.UserName(), Password: user.Password(), Timestamp: time.Now().Unix()}
+	reply, err := otherToken.GenerateToken(context.Background(), in)
+	if err != nil {
+		t.Fatalf("generate token error => %v", err)
+	}
+	if _, err = tk.IsLogin(withToken(reply.GetToken())); err == nil {
+		t.Errorf("resolve the token signed by another secret expect get an error, but get nil")
+	}
+
+	reply, err = expiredToken.GenerateToken(context.Background(), in)
+	if err != nil {
+		t.Fatalf("generate token error => %v", err)
+	}
+	time.Sleep(time.Second)
+	if _, err = expiredToken.IsLogin(withToken(reply.GetToken())); err == nil {
+		t.Errorf("resolve the expired token expect get an error, but get nil")
+	}
+
+	if _, err = tk.IsLogin(context.Background()); err == nil {
+		t.Errorf("resolve the token without metadata expect get an error, but get nil")
+	}
+	if _, err = tk.IsLogin(withToken("")); err == nil {
+		t.Errorf("resolve the empty token expect get an error, but get nil")
+	}
+}
+
+func TestNewToken_ReturnError(t *testing.T) {
+	invalidKeyFile := filepath.Join(t.TempDir(), "invalid.key")
+	if err := os.WriteFile(invalidKeyFile, []byte("invalid key"), 0600); err != nil {
+		t.Fatalf("write the key file error => %v", err)
+	}
+	testCases := []struct {
+		name string
+		opt  TokenOption
+	}{
+		{"empty secret", TokenOption{}},
+		{"short secret", TokenOption{Secret: "123456"}},
+		{"unsupported algorithm", TokenOption{Algorithm: "RS256", Secret: testTokenSecret}},
+		{"key file not exist", TokenOption{Algorithm: EdDSAAlgorithm, KeyFile: filepath.Join(t.TempDir(), "not_exist.key")}},
+		{"invalid key file", TokenOption{Algorithm: EdDSAAlgorithm, KeyFile: invalidKeyFile}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store, _ := auth.NewUserStore(nil, "")
+			if _, err := NewToken(store, nil, tc.opt); err == nil {
+				t.Errorf("create token expect get an error, but get nil")
+			}
+			if err := ValidateTokenOption(tc.opt); err == nil {
+				t.Errorf("validate token option expect get an error, but get nil")
+			}
+		})
+	}
+}
+
+func newTestToken(t *testing.T, opt TokenOption) (Token, *auth.User) {
+	user, err := auth.NewUser(1, "gofs", "gofs_password", auth.FullPerm)
+	if err != nil {
+		t.Fatalf("create user error => %v", err)
+	}
+	store, err := auth.NewUserStore([]*auth.User{user}, "")
+	if err != nil {
+		t.Fatalf("create user store error => %v", err)
+	}
+	tk, err := NewToken(store, nil, opt)
+	if err != nil {
+		t.Fatalf("create token error => %v", err)
+	}
+	return tk, user
+}
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func generateEd25519KeyFile(keyFile string) error {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	data, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: data}), 0600)
+}
@@ -0,0 +1,305 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	authapi "github.com/no-src/gofs/api/auth"
+	"github.com/no-src/gofs/api/monitor"
+	"github.com/no-src/gofs/audit"
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/contract"
+	"github.com/no-src/gofs/internal/rate"
+	"github.com/no-src/gofs/logger"
+	srv "github.com/no-src/gofs/server"
+	"github.com/no-src/nsgo/fsutil"
+	"github.com/no-src/nsgo/hashutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// readChunkSize the max size of the data in a ReadReply
+	readChunkSize = 1024 * 64
+)
+
+// RegisterServer register the file server, all the paths of the requests are relative to the root
+func RegisterServer(s grpc.ServiceRegistrar, root string, token authapi.Token, chunkSize int64, checkpointCount int, hash hashutil.Hash, maxTranRate int64, auditLog audit.AuditLog, logger *logger.Logger) {
+	RegisterFileServiceServer(s, &server{
+		root:            root,
+		token:           token,
+		chunkSize:       chunkSize,
+		checkpointCount: checkpointCount,
+		hash:            hash,
+		maxTranRate:     maxTranRate,
+		auditLog:        auditLog,
+		logger:          logger,
+	})
+}
+
+type server struct {
+	UnimplementedFileServiceServer
+
+	root            string
+	token           authapi.Token
+	chunkSize       int64
+	checkpointCount int
+	hash            hashutil.Hash
+	maxTranRate     int64
+	auditLog        audit.AuditLog
+	logger          *logger.Logger
+}
+
+func (s *server) Stat(ctx context.Context, in *StatRequest) (fi *monitor.FileInfo, err error) {
+	user, err := s.checkPerm(ctx, in.GetPath(), auth.ReadPerm)
+	defer func() { s.audit(ctx, user, audit.OpStat, in.GetPath(), 0, err) }()
+	if err != nil {
+		return nil, err
+	}
+	path := s.absPath(in.GetPath())
+	stat, err := os.Lstat(path)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return s.fileInfo(path, stat, in.GetNeedHash(), in.GetNeedCheckpoint()), nil
+}
+
+func (s *server) List(in *ListRequest, stream FileService_ListServer) (err error) {
+	user, err := s.checkPerm(stream.Context(), in.GetPath(), auth.ReadPerm)
+	defer func() { s.audit(stream.Context(), user, audit.OpBrowse, in.GetPath(), 0, err) }()
+	if err != nil {
+		return err
+	}
+	dir := s.absPath(in.GetPath())
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return toStatusError(err)
+	}
+	for _, entry := range entries {
+		stat, err := entry.Info()
+		if err != nil {
+			s.logger.Error(err, "[grpc file server] get file info error => %s", entry.Name())
+			continue
+		}
+		if err = stream.Send(s.fileInfo(filepath.Join(dir, entry.Name()), stat, in.GetNeedHash(), in.GetNeedCheckpoint())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *server) Read(in *ReadRequest, stream FileService_ReadServer) (err error) {
+	var size int64
+	user, err := s.checkPerm(stream.Context(), in.GetPath(), auth.ReadPerm)
+	defer func() { s.audit(stream.Context(), user, audit.OpDownload, in.GetPath(), size, err) }()
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(s.absPath(in.GetPath()))
+	if err != nil {
+		return toStatusError(err)
+	}
+	defer func() {
+		s.logger.ErrorIf(f.Close(), "[grpc file server] [read] close the file error")
+	}()
+	if _, err = f.Seek(in.GetOffset(), io.SeekStart); err != nil {
+		return toStatusError(err)
+	}
+	reader := rate.NewReader(f, s.maxTranRate, s.logger)
+	buf := make([]byte, readChunkSize)
+	for {
+		n, err := reader.Read(buf)
+		if n > 0 {
+			if sendErr := stream.Send(&ReadReply{Data: buf[:n]}); sendErr != nil {
+				return sendErr
+			}
+			size += int64(n)
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return toStatusError(err)
+		}
+	}
+}
+
+func (s *server) Write(stream FileService_WriteServer) (err error) {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if len(req.GetPath()) == 0 {
+		return status.Error(codes.InvalidArgument, "the path of the first write request can't be empty")
+	}
+	var size int64
+	// the req is replaced by the following requests, so keep the path of the first request
+	reqPath := req.GetPath()
+	user, err := s.checkPerm(stream.Context(), reqPath, auth.WritePerm)
+	defer func() { s.audit(stream.Context(), user, audit.OpWrite, reqPath, size, err) }()
+	if err != nil {
+		return err
+	}
+	path := s.absPath(req.GetPath())
+	if req.GetIsDir() {
+		if err = os.MkdirAll(path, fs.ModePerm); err != nil {
+			return toStatusError(err)
+		}
+		s.chtimes(path, req.GetATime(), req.GetMTime())
+		return stream.SendAndClose(&WriteReply{})
+	}
+	if err = os.MkdirAll(filepath.Dir(path), fs.ModePerm); err != nil {
+		return toStatusError(err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return toStatusError(err)
+	}
+	defer func() {
+		s.logger.ErrorIf(f.Close(), "[grpc file server] [write] close the file error")
+	}()
+	// truncate first before write to file
+	if err = f.Truncate(req.GetOffset()); err != nil {
+		return toStatusError(err)
+	}
+	if _, err = f.Seek(req.GetOffset(), io.SeekStart); err != nil {
+		return toStatusError(err)
+	}
+	aTime, mTime := req.GetATime(), req.GetMTime()
+	for {
+		if len(req.GetData()) > 0 {
+			n, err := f.Write(req.GetData())
+			size += int64(n)
+			if err != nil {
+				return toStatusError(err)
+			}
+		}
+		if req.GetATime() > 0 || req.GetMTime() > 0 {
+			aTime, mTime = req.GetATime(), req.GetMTime()
+		}
+		req, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	s.chtimes(path, aTime, mTime)
+	s.logger.Info("[grpc file server] [write] [success] size[%d] => [%s]", size, path)
+	return stream.SendAndClose(&WriteReply{Size: size})
+}
+
+// checkPerm check the current login user has the specified permission of the path or not, return the login user if logged in
+func (s *server) checkPerm(ctx context.Context, path string, perm auth.Perm) (*auth.User, error) {
+	user, err := s.token.IsLogin(ctx)
+	if err != nil || user == nil {
+		return nil, status.Error(codes.Unauthenticated, "login failed")
+	}
+	if !perm.CheckTo(user.Perm()) {
+		return user, status.Errorf(codes.PermissionDenied, "the user [%s] has no permission [%s]", user.UserName(), perm)
+	}
+	if !user.CheckPath(srv.SourceRoutePrefix+path, perm) {
+		return user, status.Errorf(codes.PermissionDenied, "the user [%s] has no permission [%s] => [%s]", user.UserName(), perm, path)
+	}
+	return user, nil
+}
+
+// audit record the audit log of the operation of the login user, the unauthenticated request is ignored
+func (s *server) audit(ctx context.Context, user *auth.User, op string, path string, bytes int64, err error) {
+	if user == nil {
+		return
+	}
+	r := audit.NewRecord(audit.SourceGRPC, user.UserName(), authapi.ClientIP(ctx), op, srv.SourceRoutePrefix+strings.TrimPrefix(path, "/"), bytes, int(status.Code(err)))
+	s.logger.ErrorIf(s.auditLog.Write(r), "[grpc file server] write the audit log error")
+}
+
+// absPath convert the request path to an absolute path under the root, the path can't escape the root
+func (s *server) absPath(path string) string {
+	return filepath.Join(s.root, filepath.Clean(string(filepath.Separator)+filepath.FromSlash(path)))
+}
+
+func (s *server) fileInfo(path string, stat fs.FileInfo, needHash bool, needCheckpoint bool) *monitor.FileInfo {
+	cTime, aTime, mTime, err := fsutil.GetFileTimeBySys(stat.Sys())
+	if err != nil {
+		s.logger.Error(err, "[grpc file server] get file times error => %s", path)
+		cTime = time.Now()
+		aTime = cTime
+		mTime = cTime
+	}
+	fi := &monitor.FileInfo{
+		Path:  stat.Name(),
+		IsDir: int32(contract.ParseFsDirValue(stat.IsDir())),
+		Size:  stat.Size(),
+		CTime: cTime.Unix(),
+		ATime: aTime.Unix(),
+		MTime: mTime.Unix(),
+	}
+	if stat.IsDir() {
+		fi.Size = 0
+	}
+	if fsutil.IsSymlinkMode(stat.Mode()) {
+		if linkTo, err := fsutil.Readlink(path); err == nil {
+			fi.LinkTo = linkTo
+		} else {
+			s.logger.Error(err, "[grpc file server] read link error => %s", path)
+			fi.LinkTo = stat.Name()
+		}
+		return fi
+	}
+	if stat.IsDir() || (!needHash && !needCheckpoint) {
+		return fi
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		s.logger.Error(err, "[grpc file server] open file error => %s", path)
+		return fi
+	}
+	defer f.Close()
+	var hvs hashutil.HashValues
+	if needCheckpoint {
+		hvs, _ = s.hash.CheckpointsHashFromFile(f, s.chunkSize, s.checkpointCount)
+		fi.HashValues = monitor.ToHashValueMessageList(hvs)
+	}
+	if needHash {
+		if len(hvs) > 0 {
+			fi.Hash = hvs.Last().Hash
+		} else {
+			fi.Hash, _ = s.hash.HashFromFile(f)
+		}
+	}
+	return fi
+}
+
+func (s *server) chtimes(path string, aTime, mTime int64) {
+	if aTime <= 0 && mTime <= 0 {
+		return
+	}
+	if aTime <= 0 {
+		aTime = mTime
+	}
+	if mTime <= 0 {
+		mTime = aTime
+	}
+	if err := os.Chtimes(path, time.Unix(aTime, 0), time.Unix(mTime, 0)); err != nil {
+		s.logger.Warn("[grpc file server] change file times error => %s =>[%s]", err.Error(), path)
+	}
+}
+
+// toStatusError convert the file system error to the status error
+func toStatusError(err error) error {
+	if os.IsNotExist(err) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if os.IsPermission(err) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,318 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.22.2
+// source: api/proto/file.proto
+
+package file
+
+import (
+	context "context"
+	monitor "github.com/no-src/gofs/api/monitor"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	FileService_Stat_FullMethodName  = "/file.FileService/Stat"
+	FileService_List_FullMethodName  = "/file.FileService/List"
+	FileService_Read_FullMethodName  = "/file.FileService/Read"
+	FileService_Write_FullMethodName = "/file.FileService/Write"
+)
+
+// FileServiceClient is the client API for FileService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FileServiceClient interface {
+	// Stat get the file info of the path
+	Stat(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*monitor.FileInfo, error)
+	// List list the file info of the files in the directory
+	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (FileService_ListClient, error)
+	// Read read the file content from the offset
+	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (FileService_ReadClient, error)
+	// Write write the file content from the offset, the first request must contain the path
+	Write(ctx context.Context, opts ...grpc.CallOption) (FileService_WriteClient, error)
+}
+
+type fileServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFileServiceClient(cc grpc.ClientConnInterface) FileServiceClient {
+	return &fileServiceClient{cc}
+}
+
+func (c *fileServiceClient) Stat(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*monitor.FileInfo, error) {
+	out := new(monitor.FileInfo)
+	err := c.cc.Invoke(ctx, FileService_Stat_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *fileServiceClient) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (FileService_ListClient, error) {
+	stream, err := c.cc.NewStream(ctx, &FileService_ServiceDesc.Streams[0], FileService_List_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fileServiceListClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FileService_ListClient interface {
+	Recv() (*monitor.FileInfo, error)
+	grpc.ClientStream
+}
+
+type fileServiceListClient struct {
+	grpc.ClientStream
+}
+
+func (x *fileServiceListClient) Recv() (*monitor.FileInfo, error) {
+	m := new(monitor.FileInfo)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *fileServiceClient) Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (FileService_ReadClient, error) {
+	stream, err := c.cc.NewStream(ctx, &FileService_ServiceDesc.Streams[1], FileService_Read_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fileServiceReadClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FileService_ReadClient interface {
+	Recv() (*ReadReply, error)
+	grpc.ClientStream
+}
+
+type fileServiceReadClient struct {
+	grpc.ClientStream
+}
+
+func (x *fileServiceReadClient) Recv() (*ReadReply, error) {
+	m := new(ReadReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *fileServiceClient) Write(ctx context.Context, opts ...grpc.CallOption) (FileService_WriteClient, error) {
+	stream, err := c.cc.NewStream(ctx, &FileService_ServiceDesc.Streams[2], FileService_Write_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fileServiceWriteClient{stream}
+	return x, nil
+}
+
+type FileService_WriteClient interface {
+	Send(*WriteRequest) error
+	CloseAndRecv() (*WriteReply, error)
+	grpc.ClientStream
+}
+
+type fileServiceWriteClient struct {
+	grpc.ClientStream
+}
+
+func (x *fileServiceWriteClient) Send(m *WriteRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *fileServiceWriteClient) CloseAndRecv() (*WriteReply, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(WriteReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FileServiceServer is the server API for FileService service.
+// All implementations must embed UnimplementedFileServiceServer
+// for forward compatibility
+type FileServiceServer interface {
+	// Stat get the file info of the path
+	Stat(context.Context, *StatRequest) (*monitor.FileInfo, error)
+	// List list the file info of the files in the directory
+	List(*ListRequest, FileService_ListServer) error
+	// Read read the file content from the offset
+	Read(*ReadRequest, FileService_ReadServer) error
+	// Write write the file content from the offset, the first request must contain the path
+	Write(FileService_WriteServer) error
+	mustEmbedUnimplementedFileServiceServer()
+}
+
+// UnimplementedFileServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedFileServiceServer struct {
+}
+
+func (UnimplementedFileServiceServer) Stat(context.Context, *StatRequest) (*monitor.FileInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stat not implemented")
+}
+func (UnimplementedFileServiceServer) List(*ListRequest, FileService_ListServer) error {
+	return status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (UnimplementedFileServiceServer) Read(*ReadRequest, FileService_ReadServer) error {
+	return status.Errorf(codes.Unimplemented, "method Read not implemented")
+}
+func (UnimplementedFileServiceServer) Write(FileService_WriteServer) error {
+	return status.Errorf(codes.Unimplemented, "method Write not implemented")
+}
+func (UnimplementedFileServiceServer) mustEmbedUnimplementedFileServiceServer() {}
+
+// UnsafeFileServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FileServiceServer will
+// result in compilation errors.
+type UnsafeFileServiceServer interface {
+	mustEmbedUnimplementedFileServiceServer()
+}
+
+func RegisterFileServiceServer(s grpc.ServiceRegistrar, srv FileServiceServer) {
+	s.RegisterService(&FileService_ServiceDesc, srv)
+}
+
+func _FileService_Stat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FileServiceServer).Stat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FileService_Stat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FileServiceServer).Stat(ctx, req.(*StatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FileService_List_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FileServiceServer).List(m, &fileServiceListServer{stream})
+}
+
+type FileService_ListServer interface {
+	Send(*monitor.FileInfo) error
+	grpc.ServerStream
+}
+
+type fileServiceListServer struct {
+	grpc.ServerStream
+}
+
+func (x *fileServiceListServer) Send(m *monitor.FileInfo) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _FileService_Read_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReadRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FileServiceServer).Read(m, &fileServiceReadServer{stream})
+}
+
+type FileService_ReadServer interface {
+	Send(*ReadReply) error
+	grpc.ServerStream
+}
+
+type fileServiceReadServer struct {
+	grpc.ServerStream
+}
+
+func (x *fileServiceReadServer) Send(m *ReadReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _FileService_Write_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(FileServiceServer).Write(&fileServiceWriteServer{stream})
+}
+
+type FileService_WriteServer interface {
+	SendAndClose(*WriteReply) error
+	Recv() (*WriteRequest, error)
+	grpc.ServerStream
+}
+
+type fileServiceWriteServer struct {
+	grpc.ServerStream
+}
+
+func (x *fileServiceWriteServer) SendAndClose(m *WriteReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *fileServiceWriteServer) Recv() (*WriteRequest, error) {
+	m := new(WriteRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FileService_ServiceDesc is the grpc.ServiceDesc for FileService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FileService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "file.FileService",
+	HandlerType: (*FileServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Stat",
+			Handler:    _FileService_Stat_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "List",
+			Handler:       _FileService_List_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Read",
+			Handler:       _FileService_Read_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Write",
+			Handler:       _FileService_Write_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "api/proto/file.proto",
+}
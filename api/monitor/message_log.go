@@ -0,0 +1,71 @@
+package monitor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/no-src/gofs/internal/toplist"
+)
+
+// MessageLog a bounded log of the monitor messages, every message is assigned a monotonically increasing sequence number,
+// the reconnected clients resume the monitor stream with the retained messages
+type MessageLog struct {
+	epoch    int64
+	seq      uint64
+	messages *toplist.TopList
+	mu       sync.RWMutex
+}
+
+// NewMessageLog create an instance of the MessageLog that retains the latest messages with specified capacity
+func NewMessageLog(capacity int) (*MessageLog, error) {
+	messages, err := toplist.NewOrderByAsc(capacity)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageLog{
+		epoch:    time.Now().UnixNano(),
+		messages: messages,
+	}, nil
+}
+
+// Append assign the epoch and the next sequence number to the message, then append it to the log
+func (l *MessageLog) Append(msg *MonitorMessage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.seq++
+	msg.Epoch = l.epoch
+	msg.Seq = l.seq
+	l.messages.Add(msg)
+}
+
+// Epoch return the epoch of the log, it is changed after the server is restarted
+func (l *MessageLog) Epoch() int64 {
+	return l.epoch
+}
+
+// Seq return the sequence number of the latest message
+func (l *MessageLog) Seq() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.seq
+}
+
+// Since return the messages after the specified sequence number in the current epoch and the sequence number of the latest message,
+// return false if the messages are discarded already or the epoch is not matched
+func (l *MessageLog) Since(epoch int64, fromSeq uint64) (messages []*MonitorMessage, seq uint64, ok bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	seq = l.seq
+	if epoch != l.epoch || fromSeq > l.seq {
+		return nil, seq, false
+	}
+	length := uint64(l.messages.Len())
+	// the sequence number of the oldest retained message is l.seq-length+1
+	if fromSeq+length < l.seq {
+		return nil, seq, false
+	}
+	for i := length - (l.seq - fromSeq); i < length; i++ {
+		messages = append(messages, l.messages.Get(int(i)).(*MonitorMessage))
+	}
+	return messages, seq, true
+}
@@ -0,0 +1,69 @@
+package monitor
+
+import (
+	"testing"
+)
+
+func TestMessageLog_Since(t *testing.T) {
+	capacity := 5
+	testCases := []struct {
+		name      string
+		count     int
+		fromSeq   uint64
+		diffEpoch bool
+		expectOk  bool
+		expectSeq []uint64
+	}{
+		{"empty log", 0, 0, false, true, nil},
+		{"from zero", 3, 0, false, true, []uint64{1, 2, 3}},
+		{"from middle", 3, 1, false, true, []uint64{2, 3}},
+		{"from latest", 3, 3, false, true, nil},
+		{"from future", 3, 4, false, false, nil},
+		{"full log from oldest", 10, 5, false, true, []uint64{6, 7, 8, 9, 10}},
+		{"full log from middle", 10, 8, false, true, []uint64{9, 10}},
+		{"discarded", 10, 4, false, false, nil},
+		{"discarded from zero", 10, 0, false, false, nil},
+		{"epoch not matched", 3, 1, true, false, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			l, err := NewMessageLog(capacity)
+			if err != nil {
+				t.Fatalf("create message log error => %v", err)
+			}
+			for i := 0; i < tc.count; i++ {
+				msg := &MonitorMessage{}
+				l.Append(msg)
+				if msg.Seq != uint64(i+1) || msg.Epoch != l.Epoch() {
+					t.Fatalf("append message expect seq %d epoch %d, but get seq %d epoch %d", i+1, l.Epoch(), msg.Seq, msg.Epoch)
+				}
+			}
+			epoch := l.Epoch()
+			if tc.diffEpoch {
+				epoch++
+			}
+			messages, seq, ok := l.Since(epoch, tc.fromSeq)
+			if ok != tc.expectOk {
+				t.Errorf("expect ok %v, but get %v", tc.expectOk, ok)
+			}
+			if seq != uint64(tc.count) || seq != l.Seq() {
+				t.Errorf("expect the latest seq %d, but get %d", tc.count, seq)
+			}
+			if len(messages) != len(tc.expectSeq) {
+				t.Fatalf("expect %d messages, but get %d", len(tc.expectSeq), len(messages))
+			}
+			for i, msg := range messages {
+				if msg.Seq != tc.expectSeq[i] {
+					t.Errorf("expect message seq %d, but get %d", tc.expectSeq[i], msg.Seq)
+				}
+			}
+		})
+	}
+}
+
+func TestNewMessageLog_WithInvalidCapacity(t *testing.T) {
+	if _, err := NewMessageLog(0); err == nil {
+		t.Errorf("create message log with invalid capacity expect get an error, but get nil")
+	}
+}
@@ -1,59 +1,191 @@
 package monitor
 
 import (
+	"context"
+	"strconv"
 	"sync"
+	"time"
 
+	"github.com/no-src/gofs/action"
 	authapi "github.com/no-src/gofs/api/auth"
 	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/eventlog"
 	"github.com/no-src/gofs/report"
+	srv "github.com/no-src/gofs/server"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+const (
+	// EpochHeader the header key of the server message log epoch, it is sent when the monitor stream is started
+	EpochHeader = "gofs-monitor-epoch"
+	// SeqHeader the header key of the sequence number that the monitor stream starts from
+	SeqHeader = "gofs-monitor-seq"
+)
+
 // RegisterServer register the monitor server
-func RegisterServer(s grpc.ServiceRegistrar, monitors *sync.Map, reporter report.Reporter, token authapi.Token) {
+func RegisterServer(s grpc.ServiceRegistrar, monitors *sync.Map, messageLog *MessageLog, reporter report.Reporter, token authapi.Token) {
 	RegisterMonitorServiceServer(s, &server{
-		monitors: monitors,
-		reporter: reporter,
-		token:    token,
+		monitors:   monitors,
+		messageLog: messageLog,
+		reporter:   reporter,
+		token:      token,
 	})
 }
 
 type server struct {
 	UnimplementedMonitorServiceServer
 
-	monitors *sync.Map
-	reporter report.Reporter
-	token    authapi.Token
+	monitors   *sync.Map
+	messageLog *MessageLog
+	reporter   report.Reporter
+	token      authapi.Token
 }
 
-func (s *server) Monitor(in *emptypb.Empty, m MonitorService_MonitorServer) error {
+func (s *server) Monitor(in *MonitorRequest, m MonitorService_MonitorServer) error {
 	p, ok := peer.FromContext(m.Context())
 	if !ok {
 		return status.Errorf(codes.Unknown, "the peer information is not found")
 	}
 	k := p.Addr.String()
-	var msgChan chan *MonitorMessage
-	v, ok := s.monitors.Load(k)
-	if ok {
-		msgChan = v.(chan *MonitorMessage)
-	} else {
-		msgChan = make(chan *MonitorMessage)
-		s.monitors.Store(k, msgChan)
-		user, _ := s.token.IsLogin(m.Context())
-		s.reporter.PutConnection(k, auth.MapperToSessionUser(user))
+	// the multiple streams may share the same connection, so register the message channel for every stream
+	msgChan := make(chan *MonitorMessage)
+	s.monitors.Store(m, msgChan)
+	user, _ := s.token.IsLogin(m.Context())
+	s.reporter.PutConnection(k, auth.MapperToSessionUser(user))
+
+	// the message channel is registered before loading the history messages, so no message is lost,
+	// the messages are buffered during the replay to avoid blocking the broadcaster by a slow client,
+	// and the duplicate messages are skipped by the sequence number
+	stopDrain := drain(msgChan)
+	lastSeq, err := s.replay(k, user, in, m)
+	buffered := stopDrain()
+	if err != nil {
+		s.unregister(m, k, msgChan)
+		return err
+	}
+	send := func(msg *MonitorMessage) {
+		if msg.Epoch == s.messageLog.Epoch() && msg.Seq <= lastSeq {
+			return
+		}
+		lastSeq = msg.Seq
+		if !canRead(user, msg) {
+			return
+		}
+		if m.Send(msg) == nil {
+			s.reporter.PutSent(k, msg.Seq)
+		}
+	}
+	for _, msg := range buffered {
+		send(msg)
 	}
 	for {
 		select {
 		case msg := <-msgChan:
-			m.Send(msg)
+			send(msg)
 		case <-m.Context().Done():
-			s.monitors.Delete(k)
-			s.reporter.DeleteConnection(k)
+			s.unregister(m, k, msgChan)
 			return nil
 		}
 	}
 }
+
+// drain receive the messages from the message channel into a buffer in the background,
+// call the returned function to stop receiving and get the buffered messages in order
+func drain(msgChan chan *MonitorMessage) (stop func() []*MonitorMessage) {
+	done := make(chan struct{})
+	result := make(chan []*MonitorMessage, 1)
+	go func() {
+		var buffered []*MonitorMessage
+		for {
+			select {
+			case msg := <-msgChan:
+				buffered = append(buffered, msg)
+			case <-done:
+				result <- buffered
+				return
+			}
+		}
+	}()
+	return func() []*MonitorMessage {
+		close(done)
+		return <-result
+	}
+}
+
+// unregister remove the message channel, and drain the message that is being sent by the broadcaster,
+// avoid blocking the broadcaster forever
+func (s *server) unregister(m MonitorService_MonitorServer, k string, msgChan chan *MonitorMessage) {
+	s.monitors.Delete(m)
+	s.reporter.DeleteConnection(k)
+	go func() {
+		for {
+			select {
+			case <-msgChan:
+			case <-time.After(time.Second):
+				return
+			}
+		}
+	}()
+}
+
+// replay send the messages that the client missed, if the messages are discarded already, notify the client to sync all the files.
+// a client that monitors from now on receives nothing.
+func (s *server) replay(k string, user *auth.User, in *MonitorRequest, m MonitorService_MonitorServer) (lastSeq uint64, err error) {
+	epoch := s.messageLog.Epoch()
+	resume := in.GetEpoch() != 0
+	messages, lastSeq, ok := s.messageLog.Since(in.GetEpoch(), in.GetFromSeq())
+	if !resume || !ok {
+		messages = nil
+	}
+	if err = m.SendHeader(metadata.Pairs(EpochHeader, strconv.FormatInt(epoch, 10), SeqHeader, strconv.FormatUint(lastSeq, 10))); err != nil {
+		return lastSeq, err
+	}
+	if resume && !ok {
+		if err = m.Send(&MonitorMessage{Epoch: epoch, Seq: lastSeq, FullSync: true}); err == nil {
+			s.reporter.PutSent(k, lastSeq)
+		}
+		return lastSeq, err
+	}
+	for _, msg := range messages {
+		if !canRead(user, msg) {
+			continue
+		}
+		if err = m.Send(msg); err != nil {
+			return lastSeq, err
+		}
+		s.reporter.PutSent(k, msg.Seq)
+	}
+	return lastSeq, nil
+}
+
+// canRead check the user has the read permission of the file in the monitor message or not,
+// so the client only receives the events of the paths that it can read
+func canRead(user *auth.User, msg *MonitorMessage) bool {
+	if user == nil || msg.GetFileInfo() == nil {
+		return true
+	}
+	return user.CheckPath(srv.SourceRoutePrefix+msg.GetFileInfo().GetPath(), auth.ReadPerm)
+}
+
+func (s *server) Ack(ctx context.Context, in *AckRequest) (*emptypb.Empty, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, status.Errorf(codes.Unknown, "the peer information is not found")
+	}
+	ack := report.Ack{
+		Seq:          in.GetSeq(),
+		SuccessCount: in.GetSuccessCount(),
+		FailureCount: in.GetFailureCount(),
+		Error:        in.GetError(),
+	}
+	if len(in.GetPath()) > 0 {
+		ack.Event = eventlog.NewEvent(in.GetPath(), action.Action(in.GetAction()).String())
+	}
+	s.reporter.PutAck(p.Addr.String(), ack)
+	return &emptypb.Empty{}, nil
+}
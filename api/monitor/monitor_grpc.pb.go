@@ -21,6 +21,7 @@ const _ = grpc.SupportPackageIsVersion7
 
 const (
 	MonitorService_Monitor_FullMethodName = "/monitor.MonitorService/Monitor"
+	MonitorService_Ack_FullMethodName     = "/monitor.MonitorService/Ack"
 )
 
 // MonitorServiceClient is the client API for MonitorService service.
@@ -28,7 +29,9 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type MonitorServiceClient interface {
 	// Monitor monitor the remote server
-	Monitor(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (MonitorService_MonitorClient, error)
+	Monitor(ctx context.Context, in *MonitorRequest, opts ...grpc.CallOption) (MonitorService_MonitorClient, error)
+	// Ack acknowledge the monitor messages that are applied by the client
+	Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type monitorServiceClient struct {
@@ -39,7 +42,7 @@ func NewMonitorServiceClient(cc grpc.ClientConnInterface) MonitorServiceClient {
 	return &monitorServiceClient{cc}
 }
 
-func (c *monitorServiceClient) Monitor(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (MonitorService_MonitorClient, error) {
+func (c *monitorServiceClient) Monitor(ctx context.Context, in *MonitorRequest, opts ...grpc.CallOption) (MonitorService_MonitorClient, error) {
 	stream, err := c.cc.NewStream(ctx, &MonitorService_ServiceDesc.Streams[0], MonitorService_Monitor_FullMethodName, opts...)
 	if err != nil {
 		return nil, err
@@ -71,12 +74,23 @@ func (x *monitorServiceMonitorClient) Recv() (*MonitorMessage, error) {
 	return m, nil
 }
 
+func (c *monitorServiceClient) Ack(ctx context.Context, in *AckRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, MonitorService_Ack_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MonitorServiceServer is the server API for MonitorService service.
 // All implementations must embed UnimplementedMonitorServiceServer
 // for forward compatibility
 type MonitorServiceServer interface {
 	// Monitor monitor the remote server
-	Monitor(*emptypb.Empty, MonitorService_MonitorServer) error
+	Monitor(*MonitorRequest, MonitorService_MonitorServer) error
+	// Ack acknowledge the monitor messages that are applied by the client
+	Ack(context.Context, *AckRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedMonitorServiceServer()
 }
 
@@ -84,9 +98,12 @@ type MonitorServiceServer interface {
 type UnimplementedMonitorServiceServer struct {
 }
 
-func (UnimplementedMonitorServiceServer) Monitor(*emptypb.Empty, MonitorService_MonitorServer) error {
+func (UnimplementedMonitorServiceServer) Monitor(*MonitorRequest, MonitorService_MonitorServer) error {
 	return status.Errorf(codes.Unimplemented, "method Monitor not implemented")
 }
+func (UnimplementedMonitorServiceServer) Ack(context.Context, *AckRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ack not implemented")
+}
 func (UnimplementedMonitorServiceServer) mustEmbedUnimplementedMonitorServiceServer() {}
 
 // UnsafeMonitorServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -101,7 +118,7 @@ func RegisterMonitorServiceServer(s grpc.ServiceRegistrar, srv MonitorServiceSer
 }
 
 func _MonitorService_Monitor_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(emptypb.Empty)
+	m := new(MonitorRequest)
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
@@ -121,13 +138,36 @@ func (x *monitorServiceMonitorServer) Send(m *MonitorMessage) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _MonitorService_Ack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServiceServer).Ack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MonitorService_Ack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServiceServer).Ack(ctx, req.(*AckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MonitorService_ServiceDesc is the grpc.ServiceDesc for MonitorService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var MonitorService_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "monitor.MonitorService",
 	HandlerType: (*MonitorServiceServer)(nil),
-	Methods:     []grpc.MethodDesc{},
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ack",
+			Handler:    _MonitorService_Ack_Handler,
+		},
+	},
 	Streams: []grpc.StreamDesc{
 		{
 			StreamName:    "Monitor",
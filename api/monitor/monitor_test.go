@@ -0,0 +1,67 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/no-src/gofs/auth"
+)
+
+func TestCanRead(t *testing.T) {
+	user, err := auth.NewUser(1, "alice", "alice_password", auth.FullPerm)
+	if err != nil {
+		t.Fatalf("create user error => %v", err)
+	}
+	restrictedUser, err := auth.NewUser(2, "bob", "bob_password", auth.FullPerm)
+	if err != nil {
+		t.Fatalf("create user error => %v", err)
+	}
+	userPaths, err := auth.ParseUserPaths("bob|/source/teamB|r")
+	if err != nil {
+		t.Fatalf("parse user paths error => %v", err)
+	}
+	auth.ApplyUserPaths([]*auth.User{restrictedUser}, userPaths)
+
+	testCases := []struct {
+		name   string
+		user   *auth.User
+		msg    *MonitorMessage
+		expect bool
+	}{
+		{"no path perm", user, &MonitorMessage{FileInfo: &FileInfo{Path: "teamA/hello.txt"}}, true},
+		{"readable path", restrictedUser, &MonitorMessage{FileInfo: &FileInfo{Path: "teamB/hello.txt"}}, true},
+		{"unreadable path", restrictedUser, &MonitorMessage{FileInfo: &FileInfo{Path: "teamA/hello.txt"}}, false},
+		{"full sync message", restrictedUser, &MonitorMessage{FullSync: true}, true},
+		{"nil user", nil, &MonitorMessage{FileInfo: &FileInfo{Path: "teamA/hello.txt"}}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := canRead(tc.user, tc.msg); actual != tc.expect {
+				t.Errorf("expect can read %v, but get %v", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func TestDrain(t *testing.T) {
+	msgChan := make(chan *MonitorMessage)
+	stop := drain(msgChan)
+	count := 10
+	for i := 1; i <= count; i++ {
+		select {
+		case msgChan <- &MonitorMessage{Seq: uint64(i)}:
+		case <-time.After(time.Second):
+			t.Fatalf("send message to the draining channel is blocked, seq => %d", i)
+		}
+	}
+	buffered := stop()
+	if len(buffered) != count {
+		t.Fatalf("expect %d buffered messages, but get %d", count, len(buffered))
+	}
+	for i, msg := range buffered {
+		if msg.Seq != uint64(i+1) {
+			t.Errorf("expect the buffered message seq %d, but get %d", i+1, msg.Seq)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	authapi "github.com/no-src/gofs/api/auth"
+	"github.com/no-src/gofs/api/monitor"
+	"github.com/no-src/gofs/audit"
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/contract"
+	"github.com/no-src/gofs/logger"
+	srv "github.com/no-src/gofs/server"
+	"github.com/no-src/gofs/server/handler"
+	"github.com/no-src/nsgo/hashutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RegisterServer register the push server, all the file changes are applied to the root
+func RegisterServer(s grpc.ServiceRegistrar, root string, token authapi.Token, enableLogicallyDelete bool, hash hashutil.Hash, auditLog audit.AuditLog, logger *logger.Logger) {
+	RegisterPushServiceServer(s, &server{
+		token:     token,
+		processor: handler.NewPushProcessor(logger, root, enableLogicallyDelete, hash),
+		auditLog:  auditLog,
+		logger:    logger,
+	})
+}
+
+type server struct {
+	UnimplementedPushServiceServer
+
+	token     authapi.Token
+	processor handler.PushProcessor
+	auditLog  audit.AuditLog
+	logger    *logger.Logger
+}
+
+func (s *server) Push(stream PushService_PushServer) error {
+	user, err := s.checkPerm(stream.Context(), auth.WritePerm)
+	if err != nil {
+		return err
+	}
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		reply := s.process(user, req)
+		s.audit(stream.Context(), user, req, reply)
+		if err = stream.Send(reply); err != nil {
+			return err
+		}
+	}
+}
+
+// process apply the push request and return the reply with the same id
+func (s *server) process(user *auth.User, req *PushRequest) *PushReply {
+	if !user.CheckPath(srv.SourceRoutePrefix+req.GetFileInfo().GetPath(), auth.WritePerm) {
+		return &PushReply{
+			Id:      req.GetId(),
+			Code:    int32(contract.NoPermission),
+			Message: fmt.Sprintf("the user [%s] has no permission [%s] => [%s]", user.UserName(), auth.WritePerm, req.GetFileInfo().GetPath()),
+		}
+	}
+	code, hv, err := s.processor.Process(ToPushData(req), bytes.NewReader(req.GetData()))
+	reply := &PushReply{
+		Id:      req.GetId(),
+		Code:    int32(code),
+		Message: code.String(),
+	}
+	if err != nil {
+		reply.Code = int32(contract.Fail)
+		reply.Message = err.Error()
+		s.logger.Error(err, "[grpc push server] process the push request error => [%s]", req.GetFileInfo().GetPath())
+	}
+	if hv != nil {
+		reply.HashValue = &monitor.HashValue{
+			Offset: hv.Offset,
+			Hash:   hv.Hash,
+		}
+	}
+	return reply
+}
+
+// audit record the audit log of the push request, the compare requests are ignored
+func (s *server) audit(ctx context.Context, user *auth.User, req *PushRequest, reply *PushReply) {
+	pd := ToPushData(req)
+	op := audit.PushOp(pd.Action, pd.PushAction)
+	if len(op) == 0 {
+		return
+	}
+	r := audit.NewRecord(audit.SourceGRPC, user.UserName(), authapi.ClientIP(ctx), op, srv.SourceRoutePrefix+strings.TrimPrefix(pd.FileInfo.Path, "/"), int64(len(req.GetData())), int(reply.GetCode()))
+	s.logger.ErrorIf(s.auditLog.Write(r), "[grpc push server] write the audit log error")
+}
+
+// checkPerm check the current login user has the specified permission or not, return the current login user
+func (s *server) checkPerm(ctx context.Context, perm auth.Perm) (*auth.User, error) {
+	user, err := s.token.IsLogin(ctx)
+	if err != nil || user == nil {
+		return nil, status.Error(codes.Unauthenticated, "login failed")
+	}
+	if !perm.CheckTo(user.Perm()) {
+		return nil, status.Errorf(codes.PermissionDenied, "the user [%s] has no permission [%s]", user.UserName(), perm)
+	}
+	return user, nil
+}
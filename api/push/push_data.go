@@ -0,0 +1,70 @@
+package push
+
+import (
+	"github.com/no-src/gofs/action"
+	"github.com/no-src/gofs/api/monitor"
+	"github.com/no-src/gofs/contract"
+	"github.com/no-src/gofs/contract/push"
+	"github.com/no-src/nsgo/hashutil"
+)
+
+// ToPushData convert the PushRequest to the push.PushData
+func ToPushData(req *PushRequest) push.PushData {
+	fi := req.GetFileInfo()
+	var hvs hashutil.HashValues
+	for _, hv := range fi.GetHashValues() {
+		hvs = append(hvs, &hashutil.HashValue{
+			Offset: hv.GetOffset(),
+			Hash:   hv.GetHash(),
+		})
+	}
+	return push.PushData{
+		Action:     action.Action(req.GetAction()),
+		PushAction: push.PushAction(req.GetPushAction()),
+		FileInfo: contract.FileInfo{
+			Path:       fi.GetPath(),
+			IsDir:      contract.FsDirValue(fi.GetIsDir()),
+			Size:       fi.GetSize(),
+			Hash:       fi.GetHash(),
+			HashValues: hvs,
+			CTime:      fi.GetCTime(),
+			ATime:      fi.GetATime(),
+			MTime:      fi.GetMTime(),
+			LinkTo:     fi.GetLinkTo(),
+		},
+		Chunk: contract.Chunk{
+			Offset: req.GetChunk().GetOffset(),
+			Size:   req.GetChunk().GetSize(),
+			Hash:   req.GetChunk().GetHash(),
+		},
+		ForceChecksum: req.GetForceChecksum(),
+	}
+}
+
+// ToPushRequest convert the push.PushData to the PushRequest with the request id and the file chunk content
+func ToPushRequest(id uint64, pd push.PushData, data []byte) *PushRequest {
+	fi := pd.FileInfo
+	return &PushRequest{
+		Id:         id,
+		Action:     int32(pd.Action),
+		PushAction: int32(pd.PushAction),
+		FileInfo: &monitor.FileInfo{
+			Path:       fi.Path,
+			IsDir:      int32(fi.IsDir),
+			Size:       fi.Size,
+			Hash:       fi.Hash,
+			HashValues: monitor.ToHashValueMessageList(fi.HashValues),
+			CTime:      fi.CTime,
+			ATime:      fi.ATime,
+			MTime:      fi.MTime,
+			LinkTo:     fi.LinkTo,
+		},
+		Chunk: &Chunk{
+			Offset: pd.Chunk.Offset,
+			Size:   pd.Chunk.Size,
+			Hash:   pd.Chunk.Hash,
+		},
+		ForceChecksum: pd.ForceChecksum,
+		Data:          data,
+	}
+}
@@ -0,0 +1,145 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.22.2
+// source: api/proto/push.proto
+
+package push
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	PushService_Push_FullMethodName = "/push.PushService/Push"
+)
+
+// PushServiceClient is the client API for PushService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PushServiceClient interface {
+	// Push push the file changes and the file chunks to the server, the server replies every request in order with the same id,
+	// so the client can send the next requests before receiving the replies
+	Push(ctx context.Context, opts ...grpc.CallOption) (PushService_PushClient, error)
+}
+
+type pushServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPushServiceClient(cc grpc.ClientConnInterface) PushServiceClient {
+	return &pushServiceClient{cc}
+}
+
+func (c *pushServiceClient) Push(ctx context.Context, opts ...grpc.CallOption) (PushService_PushClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PushService_ServiceDesc.Streams[0], PushService_Push_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pushServicePushClient{stream}
+	return x, nil
+}
+
+type PushService_PushClient interface {
+	Send(*PushRequest) error
+	Recv() (*PushReply, error)
+	grpc.ClientStream
+}
+
+type pushServicePushClient struct {
+	grpc.ClientStream
+}
+
+func (x *pushServicePushClient) Send(m *PushRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *pushServicePushClient) Recv() (*PushReply, error) {
+	m := new(PushReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PushServiceServer is the server API for PushService service.
+// All implementations must embed UnimplementedPushServiceServer
+// for forward compatibility
+type PushServiceServer interface {
+	// Push push the file changes and the file chunks to the server, the server replies every request in order with the same id,
+	// so the client can send the next requests before receiving the replies
+	Push(PushService_PushServer) error
+	mustEmbedUnimplementedPushServiceServer()
+}
+
+// UnimplementedPushServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPushServiceServer struct {
+}
+
+func (UnimplementedPushServiceServer) Push(PushService_PushServer) error {
+	return status.Errorf(codes.Unimplemented, "method Push not implemented")
+}
+func (UnimplementedPushServiceServer) mustEmbedUnimplementedPushServiceServer() {}
+
+// UnsafePushServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PushServiceServer will
+// result in compilation errors.
+type UnsafePushServiceServer interface {
+	mustEmbedUnimplementedPushServiceServer()
+}
+
+func RegisterPushServiceServer(s grpc.ServiceRegistrar, srv PushServiceServer) {
+	s.RegisterService(&PushService_ServiceDesc, srv)
+}
+
+func _PushService_Push_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PushServiceServer).Push(&pushServicePushServer{stream})
+}
+
+type PushService_PushServer interface {
+	Send(*PushReply) error
+	Recv() (*PushRequest, error)
+	grpc.ServerStream
+}
+
+type pushServicePushServer struct {
+	grpc.ServerStream
+}
+
+func (x *pushServicePushServer) Send(m *PushReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *pushServicePushServer) Recv() (*PushRequest, error) {
+	m := new(PushRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PushService_ServiceDesc is the grpc.ServiceDesc for PushService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PushService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "push.PushService",
+	HandlerType: (*PushServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Push",
+			Handler:       _PushService_Push_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "api/proto/push.proto",
+}
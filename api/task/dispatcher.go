@@ -63,7 +63,7 @@ func (d *dispatcher) Acquire(client *ClientInfo, ip string) (task *TaskInfo, err
 		ext := filepath.Ext(t.Conf)
 		// get default config
 		c := flag.ParseFlags([]string{os.Args[0], "-conf="})
-		// override config
+		// override config, the environment variables and the file references are not expanded by the task server or the task client
 		if err = conf.ParseContent([]byte(content), ext, &c); err != nil {
 			return nil, err
 		}
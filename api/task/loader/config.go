@@ -7,19 +7,19 @@ import (
 // TaskConfig the config of tasks
 type TaskConfig struct {
 	// Tasks the task list
-	Tasks []*TaskItem `json:"tasks" yaml:"tasks"`
+	Tasks []*TaskItem `json:"tasks" yaml:"tasks" toml:"tasks"`
 }
 
 // TaskItem a task item
 type TaskItem struct {
 	// Name a unique task name
-	Name string `json:"name" yaml:"name"`
+	Name string `json:"name" yaml:"name" toml:"name"`
 	// Conf the source of task config
-	Conf string `json:"conf" yaml:"conf"`
+	Conf string `json:"conf" yaml:"conf" toml:"conf"`
 	// Labels it can only acquire the current task if the client matches all the labels
-	Labels []string `json:"labels" yaml:"labels"`
+	Labels []string `json:"labels" yaml:"labels" toml:"labels"`
 	// AllowIP the current task only allows the specified ip to access
-	AllowIP []string `json:"allow_ip" yaml:"allow_ip"`
+	AllowIP []string `json:"allow_ip" yaml:"allow_ip" toml:"allow_ip"`
 }
 
 func (c *TaskConfig) verify() error {
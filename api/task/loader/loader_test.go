@@ -17,6 +17,7 @@ func TestLoader(t *testing.T) {
 		{"buntdb://buntdb.db"},
 		{"buntdb://:memory:"},
 		{"file://test-task-file.yaml"},
+		{"file://test-task-file.toml"},
 	}
 
 	for _, tc := range testCases {
@@ -72,6 +73,7 @@ func TestLoader(t *testing.T) {
 
 	// clear testdata
 	os.Remove("test-task-file.yaml")
+	os.Remove("test-task-file.toml")
 	os.Remove("local-disk-sync.yaml")
 	os.Remove("buntdb.db")
 }
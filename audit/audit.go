@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"strings"
+	"time"
+
+	"github.com/no-src/gofs/action"
+	"github.com/no-src/gofs/contract/push"
+)
+
+const (
+	// SourceHTTP the source of the records of the file server
+	SourceHTTP = "http"
+	// SourceGRPC the source of the records of the grpc api server
+	SourceGRPC = "grpc"
+)
+
+const (
+	// OpBrowse browse the directory
+	OpBrowse = "browse"
+	// OpDownload download the file
+	OpDownload = "download"
+	// OpStat get the file info
+	OpStat = "stat"
+	// OpWrite write the file by the grpc file api
+	OpWrite = "write"
+	// OpPushPrefix the prefix of the push operations, like push_create, push_write and push_remove
+	OpPushPrefix = "push_"
+	// OpManageConfig get the config by the manage api
+	OpManageConfig = "manage_config"
+	// OpManageReport get the report by the manage api
+	OpManageReport = "manage_report"
+	// OpManageReload reload the config by the manage api
+	OpManageReload = "manage_reload"
+	// OpManageSchema get the JSON Schema of the config by the manage api
+	OpManageSchema = "manage_schema"
+	// OpManage access the other manage api
+	OpManage = "manage"
+	// OpDecrypt download the decrypted file by the file server
+	OpDecrypt = "decrypt"
+)
+
+// Record the audit record of an authenticated file access or mutation
+type Record struct {
+	// Time the time of the operation, it is formatted in RFC 3339 with the time zone to be parsed easily
+	Time time.Time `json:"time"`
+	// Source the source of the operation, http or grpc
+	Source string `json:"source"`
+	// User the username of the login user, it is empty for the anonymous user
+	User string `json:"user"`
+	// IP the remote ip of the connection, it can't be forged by the request headers
+	IP string `json:"ip"`
+	// ForwardedIP the client ip that is forwarded by a trusted proxy, it is empty if the request does not come from a trusted proxy
+	ForwardedIP string `json:"forwarded_ip,omitempty"`
+	// Op the operation, like browse, download, push_write and manage_config
+	Op string `json:"op"`
+	// Path the path of the operation
+	Path string `json:"path"`
+	// Bytes the size of the data that is read or written
+	Bytes int64 `json:"bytes"`
+	// Code the result code, the http status code of the file server, the contract code of the push api
+	// or the status code of the grpc api
+	Code int `json:"code"`
+}
+
+// NewRecord create an instance of the Record with the current time
+func NewRecord(source, user, ip, op, path string, bytes int64, code int) Record {
+	return Record{
+		Time:   time.Now(),
+		Source: source,
+		User:   user,
+		IP:     ip,
+		Op:     op,
+		Path:   path,
+		Bytes:  bytes,
+		Code:   code,
+	}
+}
+
+// PushOp return the push operation of the action, like push_create and push_write,
+// return an empty operation for the compare requests that need not be audited
+func PushOp(act action.Action, pushAction push.PushAction) string {
+	switch pushAction {
+	case push.CompareFilePushAction, push.CompareChunkPushAction, push.CompareFileAndChunkPushAction:
+		return ""
+	}
+	return OpPushPrefix + strings.ToLower(act.String())
+}
+
+// AuditLog the audit log recorder
+type AuditLog interface {
+	// Write write the audit record to the sinks
+	Write(r Record) error
+	// Close close the sinks
+	Close() error
+}
@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/no-src/nsgo/jsonutil"
+)
+
+var errAuditLogClosed = errors.New("the audit log is closed")
+
+// Option the option of the AuditLog
+type Option struct {
+	// File the path of the audit log file, disable the file sink if it is empty
+	File string
+	// MaxSize rotate the audit log file when the size of it is greater than the max size, never rotate if it is not greater than zero
+	MaxSize int64
+	// MaxBackups the max number of the rotated audit log files that are retained
+	MaxBackups int
+	// Syslog the address of the syslog server, like udp://127.0.0.1:514, unix:///dev/log or local, disable the syslog sink if it is empty
+	Syslog string
+}
+
+type auditLog struct {
+	sinks  []io.WriteCloser
+	closed bool
+	mu     sync.Mutex
+}
+
+// NewAuditLog create an instance of the AuditLog that writes the records as JSON lines to the file and the syslog
+func NewAuditLog(opt Option) (AuditLog, error) {
+	l := &auditLog{}
+	if len(opt.File) > 0 {
+		w, err := newRotateWriter(opt.File, opt.MaxSize, opt.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		l.sinks = append(l.sinks, w)
+	}
+	if len(opt.Syslog) > 0 {
+		w, err := newSyslogWriter(opt.Syslog)
+		if err != nil {
+			l.Close()
+			return nil, err
+		}
+		l.sinks = append(l.sinks, w)
+	}
+	return l, nil
+}
+
+func (l *auditLog) Write(r Record) error {
+	data, err := jsonutil.Marshal(r)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return errAuditLogClosed
+	}
+	var errs []error
+	for _, w := range l.sinks {
+		if _, err = w.Write(data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (l *auditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	var errs []error
+	for _, w := range l.sinks {
+		errs = append(errs, w.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,150 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/no-src/gofs/action"
+	"github.com/no-src/gofs/contract/push"
+)
+
+func TestAuditLog(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "audit", "audit.log")
+	l, err := NewAuditLog(Option{File: file})
+	if err != nil {
+		t.Fatalf("create audit log error => %v", err)
+	}
+	expect := NewRecord(SourceHTTP, "gofs", "127.0.0.1", OpDownload, "/source/hello.txt", 5, 200)
+	if err = l.Write(expect); err != nil {
+		t.Fatalf("write audit record error => %v", err)
+	}
+	if err = l.Close(); err != nil {
+		t.Fatalf("close audit log error => %v", err)
+	}
+	if err = l.Write(expect); !errors.Is(err, errAuditLogClosed) {
+		t.Errorf("write to the closed audit log expect get error %v, but get %v", errAuditLogClosed, err)
+	}
+
+	records := readRecords(t, file)
+	if len(records) != 1 {
+		t.Fatalf("expect to get 1 audit record, but get %d", len(records))
+	}
+	actual := records[0]
+	if actual.Source != expect.Source || actual.User != expect.User || actual.IP != expect.IP || actual.Op != expect.Op ||
+		actual.Path != expect.Path || actual.Bytes != expect.Bytes || actual.Code != expect.Code {
+		t.Errorf("expect to get the audit record %+v, but get %+v", expect, actual)
+	}
+}
+
+func TestAuditLog_Rotate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		maxBackups    int
+		expectBackups int
+	}{
+		{"keep backups", 2, 2},
+		{"no backup", 0, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "audit.log")
+			record := NewRecord(SourceGRPC, "gofs", "127.0.0.1", OpWrite, "/source/hello.txt", 5, 0)
+			data, _ := json.Marshal(record)
+			// every file can store two records at most
+			l, err := NewAuditLog(Option{File: file, MaxSize: int64(len(data)+1) * 2, MaxBackups: tc.maxBackups})
+			if err != nil {
+				t.Fatalf("create audit log error => %v", err)
+			}
+			for i := 0; i < 9; i++ {
+				if err = l.Write(record); err != nil {
+					t.Fatalf("write audit record error => %v", err)
+				}
+			}
+			l.Close()
+
+			if count := len(readRecords(t, file)); count != 1 {
+				t.Errorf("expect to get 1 record in the current file, but get %d", count)
+			}
+			for i := 1; i <= tc.expectBackups; i++ {
+				if count := len(readRecords(t, fmt.Sprintf("%s.%d", file, i))); count != 2 {
+					t.Errorf("expect to get 2 records in the backup file %d, but get %d", i, count)
+				}
+			}
+			if _, err = os.Stat(fmt.Sprintf("%s.%d", file, tc.expectBackups+1)); !os.IsNotExist(err) {
+				t.Errorf("expect the backup file %d is removed, but get %v", tc.expectBackups+1, err)
+			}
+		})
+	}
+}
+
+func TestNewAuditLog_ReturnError(t *testing.T) {
+	testCases := []struct {
+		name string
+		opt  Option
+	}{
+		{"invalid file", Option{File: string([]byte{0})}},
+		{"invalid syslog", Option{Syslog: "hello://127.0.0.1:0"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewAuditLog(tc.opt); err == nil {
+				t.Errorf("create audit log expect get an error, but get nil")
+			}
+		})
+	}
+}
+
+func TestPushOp(t *testing.T) {
+	testCases := []struct {
+		act        action.Action
+		pushAction push.PushAction
+		expect     string
+	}{
+		{action.CreateAction, push.UnknownPushAction, "push_create"},
+		{action.WriteAction, push.WritePushAction, "push_write"},
+		{action.WriteAction, push.TruncatePushAction, "push_write"},
+		{action.RemoveAction, push.UnknownPushAction, "push_remove"},
+		{action.WriteAction, push.CompareFilePushAction, ""},
+		{action.WriteAction, push.CompareChunkPushAction, ""},
+		{action.WriteAction, push.CompareFileAndChunkPushAction, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expect, func(t *testing.T) {
+			if actual := PushOp(tc.act, tc.pushAction); actual != tc.expect {
+				t.Errorf("expect to get the push operation [%s], but get [%s]", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func TestEmptyAuditLog(t *testing.T) {
+	l := NewEmptyAuditLog()
+	if err := l.Write(Record{}); err != nil {
+		t.Errorf("write to the empty audit log error => %v", err)
+	}
+	if err := l.Close(); err != nil {
+		t.Errorf("close the empty audit log error => %v", err)
+	}
+}
+
+func readRecords(t *testing.T, file string) (records []Record) {
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatalf("open audit log file error => %v", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Record
+		if err = json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("unmarshal audit record error => %v", err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
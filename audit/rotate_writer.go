@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// rotateWriter append the data to the file, rename the file to the backup file with the sequence suffix
+// when the size of it is greater than the max size, like audit.log.1, audit.log.2, the larger suffix is the older
+type rotateWriter struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	f          *os.File
+	size       int64
+}
+
+func newRotateWriter(path string, maxSize int64, maxBackups int) (*rotateWriter, error) {
+	w := &rotateWriter{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), fs.ModePerm); err != nil {
+		return nil, err
+	}
+	return w, w.open()
+}
+
+func (w *rotateWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.f = f
+	w.size = stat.Size()
+	return nil
+}
+
+func (w *rotateWriter) Write(p []byte) (n int, err error) {
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err = w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err = w.f.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate close the current file and shift the backup files, then open a new file
+func (w *rotateWriter) rotate() error {
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	if w.maxBackups > 0 {
+		os.Remove(w.backupPath(w.maxBackups))
+		for i := w.maxBackups - 1; i > 0; i-- {
+			if err := os.Rename(w.backupPath(i), w.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(w.path, w.backupPath(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(w.path); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+func (w *rotateWriter) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", w.path, i)
+}
+
+func (w *rotateWriter) Close() error {
+	return w.f.Close()
+}
@@ -0,0 +1,52 @@
+//go:build !windows && !plan9
+
+package audit
+
+import (
+	"bytes"
+	"io"
+	"log/syslog"
+	"net/url"
+	"strings"
+)
+
+// syslogTag the tag of the audit records in the syslog
+const syslogTag = "gofs-audit"
+
+type syslogWriter struct {
+	w *syslog.Writer
+}
+
+// newSyslogWriter create a syslog writer with the address, like udp://127.0.0.1:514, unix:///dev/log,
+// or local to connect to the local syslog server
+func newSyslogWriter(addr string) (io.WriteCloser, error) {
+	var network, raddr string
+	if addr != "local" {
+		u, err := url.Parse(addr)
+		if err != nil {
+			return nil, err
+		}
+		network = strings.ToLower(u.Scheme)
+		raddr = u.Host
+		if network == "unix" || network == "unixgram" {
+			raddr = u.Path
+		}
+	}
+	w, err := syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_AUTHPRIV, syslogTag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogWriter{w: w}, nil
+}
+
+func (w *syslogWriter) Write(p []byte) (n int, err error) {
+	// the syslog message is one line always
+	if err = w.w.Info(string(bytes.TrimRight(p, "\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *syslogWriter) Close() error {
+	return w.w.Close()
+}
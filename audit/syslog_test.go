@@ -0,0 +1,38 @@
+//go:build !windows && !plan9
+
+package audit
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLog_WithSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen udp error => %v", err)
+	}
+	defer conn.Close()
+
+	l, err := NewAuditLog(Option{Syslog: "udp://" + conn.LocalAddr().String()})
+	if err != nil {
+		t.Fatalf("create audit log error => %v", err)
+	}
+	defer l.Close()
+	if err = l.Write(NewRecord(SourceHTTP, "gofs", "127.0.0.1", OpManageConfig, "/manage/config", 0, 200)); err != nil {
+		t.Fatalf("write audit record error => %v", err)
+	}
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read syslog message error => %v", err)
+	}
+	msg := string(buf[:n])
+	if !strings.Contains(msg, syslogTag) || !strings.Contains(msg, `"op":"manage_config"`) || strings.HasSuffix(msg, "\n\n") {
+		t.Errorf("get an unexpected syslog message => %s", msg)
+	}
+}
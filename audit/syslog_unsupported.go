@@ -0,0 +1,14 @@
+//go:build windows || plan9
+
+package audit
+
+import (
+	"errors"
+	"io"
+)
+
+var errSyslogUnsupported = errors.New("the syslog is not supported on the current platform")
+
+func newSyslogWriter(addr string) (io.WriteCloser, error) {
+	return nil, errSyslogUnsupported
+}
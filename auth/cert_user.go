@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"crypto/x509"
+	"fmt"
+	"strings"
+
+	"github.com/no-src/nsgo/randutil"
+)
+
+const certUserPasswordLen = 20
+
+// ParseCertUsers parse the mapping of the client certificate identity to the User List,
+// the identity is matched with the subject common name or any SAN of the client certificate,
+// the password of the cert user is random generated, so it can't log in with the password
+// For example: client1|rw,client2.gofs.io|r,spiffe://gofs.io/client3|rwx
+func ParseCertUsers(certUserStr string) (users []*User, err error) {
+	if len(certUserStr) == 0 {
+		return users, nil
+	}
+	for i, certUser := range strings.Split(certUserStr, ",") {
+		userInfo := strings.Split(certUser, "|")
+		if len(userInfo) > 2 || len(strings.TrimSpace(userInfo[0])) == 0 {
+			return nil, fmt.Errorf("invalid cert user info => [%s]", certUser)
+		}
+		perm := ""
+		if len(userInfo) > 1 {
+			perm = strings.TrimSpace(userInfo[1])
+		}
+		user, err := NewUser(i+1, strings.TrimSpace(userInfo[0]), randutil.RandomString(certUserPasswordLen), perm)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// MatchCertUser return the user that the identity of the client certificate is mapped to,
+// the subject common name is matched first, then the DNS names, email addresses, URIs and IP addresses of the SAN
+func MatchCertUser(users []*User, cert *x509.Certificate) *User {
+	if cert == nil {
+		return nil
+	}
+	identities := []string{cert.Subject.CommonName}
+	identities = append(identities, cert.DNSNames...)
+	identities = append(identities, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	for _, ip := range cert.IPAddresses {
+		identities = append(identities, ip.String())
+	}
+	for _, identity := range identities {
+		if len(identity) == 0 {
+			continue
+		}
+		for _, user := range users {
+			if user.UserName() == identity {
+				return user
+			}
+		}
+	}
+	return nil
+}
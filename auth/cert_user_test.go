@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net"
+	"net/url"
+	"testing"
+)
+
+func TestParseCertUsers(t *testing.T) {
+	testCases := []struct {
+		name        string
+		certUserStr string
+		expectCount int
+		expectPerm  string
+	}{
+		{"empty", "", 0, ""},
+		{"with perm", "client1|rw", 1, "rw"},
+		{"default perm", "client1", 1, DefaultPerm},
+		{"multiple users", "client1|r,spiffe://gofs.io/client2|rwx", 2, "r"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			users, err := ParseCertUsers(tc.certUserStr)
+			if err != nil {
+				t.Errorf("parse cert users error => %v", err)
+				return
+			}
+			if len(users) != tc.expectCount {
+				t.Errorf("parse cert users count expect:%d actual:%d", tc.expectCount, len(users))
+				return
+			}
+			if len(users) > 0 && users[0].Perm().String() != tc.expectPerm {
+				t.Errorf("parse cert users perm expect:%s actual:%s", tc.expectPerm, users[0].Perm().String())
+			}
+		})
+	}
+}
+
+func TestParseCertUsers_ReturnError(t *testing.T) {
+	testCases := []struct {
+		name        string
+		certUserStr string
+	}{
+		{"too many fields", "client1|rw|x"},
+		{"empty identity", "|rw"},
+		{"invalid perm", "client1|abc"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseCertUsers(tc.certUserStr); err == nil {
+				t.Errorf("parse cert users expect to get an error but get nil")
+			}
+		})
+	}
+}
+
+func TestMatchCertUser(t *testing.T) {
+	users, err := ParseCertUsers("client1|r,client2.gofs.io|rw,client3@gofs.io|rwx,spiffe://gofs.io/client4|r,127.0.0.1|rw")
+	if err != nil {
+		t.Fatalf("parse cert users error => %v", err)
+	}
+	uri, _ := url.Parse("spiffe://gofs.io/client4")
+	testCases := []struct {
+		name       string
+		cert       *x509.Certificate
+		expectUser string
+	}{
+		{"nil cert", nil, ""},
+		{"common name", &x509.Certificate{Subject: pkix.Name{CommonName: "client1"}}, "client1"},
+		{"dns name", &x509.Certificate{DNSNames: []string{"client2.gofs.io"}}, "client2.gofs.io"},
+		{"email address", &x509.Certificate{EmailAddresses: []string{"client3@gofs.io"}}, "client3@gofs.io"},
+		{"uri", &x509.Certificate{URIs: []*url.URL{uri}}, "spiffe://gofs.io/client4"},
+		{"ip address", &x509.Certificate{IPAddresses: []net.IP{net.ParseIP("127.0.0.1")}}, "127.0.0.1"},
+		{"common name first", &x509.Certificate{Subject: pkix.Name{CommonName: "client1"}, DNSNames: []string{"client2.gofs.io"}}, "client1"},
+		{"not matched", &x509.Certificate{Subject: pkix.Name{CommonName: "unknown"}}, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			user := MatchCertUser(users, tc.cert)
+			actual := ""
+			if user != nil {
+				actual = user.UserName()
+			}
+			if actual != tc.expectUser {
+				t.Errorf("match cert user expect:%s actual:%s", tc.expectUser, actual)
+			}
+		})
+	}
+}
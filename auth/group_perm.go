@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseGroupPerms parse the permissions of the groups, return a map that the key is the group name
+// For example: admin|rwx,dev|rw,guest|r
+func ParseGroupPerms(str string) (groupPerms map[string]Perm, err error) {
+	groupPerms = make(map[string]Perm)
+	str = strings.TrimSpace(str)
+	if len(str) == 0 {
+		return groupPerms, nil
+	}
+	for _, item := range strings.Split(str, ",") {
+		fields := strings.Split(item, "|")
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid group permission => [%s]", item)
+		}
+		group := strings.TrimSpace(fields[0])
+		if len(group) == 0 {
+			return nil, fmt.Errorf("the group name of the group permission can't be empty => [%s]", item)
+		}
+		perm := ToPerm(fields[1])
+		if !perm.IsValid() {
+			return nil, fmt.Errorf("group perm must be the composition of 'r' 'w' 'x' => [%s]", item)
+		}
+		groupPerms[group] = MergePerm(groupPerms[group], perm)
+	}
+	return groupPerms, nil
+}
+
+// MapGroupsToPerm merge the permissions of the matched groups, return the defaultPerm if no group is matched
+func MapGroupsToPerm(groups []string, groupPerms map[string]Perm, defaultPerm Perm) Perm {
+	var perms []Perm
+	for _, group := range groups {
+		if perm, ok := groupPerms[group]; ok {
+			perms = append(perms, perm)
+		}
+	}
+	if len(perms) == 0 {
+		return defaultPerm
+	}
+	return MergePerm(perms...)
+}
+
+// MergePerm merge the permissions, the invalid permissions are ignored
+func MergePerm(perms ...Perm) (p Perm) {
+	r, w, x := false, false, false
+	for _, perm := range perms {
+		if perm.IsValid() {
+			r = r || perm.R()
+			w = w || perm.W()
+			x = x || perm.X()
+		}
+	}
+	if r {
+		p += ReadPerm
+	}
+	if w {
+		p += WritePerm
+	}
+	if x {
+		p += ExecutePerm
+	}
+	return p
+}
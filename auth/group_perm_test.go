@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"testing"
+)
+
+func TestParseGroupPerms(t *testing.T) {
+	testCases := []struct {
+		name   string
+		str    string
+		expect map[string]Perm
+	}{
+		{"empty", "", map[string]Perm{}},
+		{"single group", "admin|rwx", map[string]Perm{"admin": FullPerm}},
+		{"multiple groups", "admin|rwx,dev|rw,guest|r", map[string]Perm{"admin": FullPerm, "dev": "rw", "guest": ReadPerm}},
+		{"merge the same group", "dev|r,dev|w", map[string]Perm{"dev": "rw"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := ParseGroupPerms(tc.str)
+			if err != nil {
+				t.Fatalf("parse group perms error => %v", err)
+			}
+			if len(actual) != len(tc.expect) {
+				t.Fatalf("expect %d groups, but get %d", len(tc.expect), len(actual))
+			}
+			for group, perm := range tc.expect {
+				if actual[group] != perm {
+					t.Errorf("expect the perm of the group [%s] is [%s], but get [%s]", group, perm, actual[group])
+				}
+			}
+		})
+	}
+}
+
+func TestParseGroupPerms_ReturnError(t *testing.T) {
+	testCases := []struct {
+		name string
+		str  string
+	}{
+		{"missing perm", "admin"},
+		{"empty group", "|rwx"},
+		{"invalid perm", "admin|abc"},
+		{"too many fields", "admin|rwx|x"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseGroupPerms(tc.str); err == nil {
+				t.Errorf("parse invalid group perms expect get an error, but get nil")
+			}
+		})
+	}
+}
+
+func TestMapGroupsToPerm(t *testing.T) {
+	groupPerms := map[string]Perm{"admin": FullPerm, "dev": "rw", "guest": ReadPerm, "exec": ExecutePerm}
+	testCases := []struct {
+		name        string
+		groups      []string
+		defaultPerm Perm
+		expect      Perm
+	}{
+		{"single group", []string{"dev"}, "", "rw"},
+		{"merge groups", []string{"guest", "exec"}, "", "rx"},
+		{"ignore unknown group", []string{"unknown", "guest"}, "", ReadPerm},
+		{"no matched group", []string{"unknown"}, "", ""},
+		{"default perm", nil, ReadPerm, ReadPerm},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := MapGroupsToPerm(tc.groups, groupPerms, tc.defaultPerm); actual != tc.expect {
+				t.Errorf("expect perm [%s], but get [%s]", tc.expect, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/no-src/nscache"
+	_ "github.com/no-src/nscache/memory"
+	"github.com/no-src/nsgo/timeutil"
+)
+
+const (
+	// DefaultLoginMaxAttempts the default max number of the continuous failed login attempts before lockout
+	DefaultLoginMaxAttempts = 5
+	// DefaultLoginLockout the default lockout duration of the first lockout
+	DefaultLoginLockout = time.Minute
+	// DefaultLoginMaxLockout the default max lockout duration
+	DefaultLoginMaxLockout = time.Hour
+
+	// LockoutKindIP the lockout of the client ip
+	LockoutKindIP = "ip"
+	// LockoutKindUser the lockout of the username
+	LockoutKindUser = "user"
+
+	loginLimiterKeyPrefix = "nosrc-gofs-login-failure"
+)
+
+// LoginLimiter track the failed login attempts by the client ip and the username,
+// lock out them with an exponential duration after too many continuous failed attempts
+type LoginLimiter interface {
+	// Check return the remaining lockout duration if the client ip or the username is locked out, otherwise return zero
+	Check(ip, userName string) time.Duration
+	// Fail record a failed login attempt of the client ip and the username
+	Fail(ip, userName string)
+	// Success reset the failed login attempts of the client ip and the username
+	Success(ip, userName string)
+}
+
+// LockoutEvent the event that a client ip or a username is locked out
+type LockoutEvent struct {
+	// Kind the kind of the lockout, ip or user
+	Kind string `json:"kind"`
+	// Key the client ip or the username that is locked out
+	Key string `json:"key"`
+	// Source the source of the login attempts, like http or grpc
+	Source string `json:"source"`
+	// Failures the count of the continuous failed login attempts
+	Failures int `json:"failures"`
+	// Lockouts the count of the continuous lockouts, the lockout duration is doubled every time
+	Lockouts int `json:"lockouts"`
+	// LockTime the time of the lockout
+	LockTime timeutil.Time `json:"lock_time"`
+	// UnlockTime the time of the unlock
+	UnlockTime timeutil.Time `json:"unlock_time"`
+}
+
+// LoginLimiterOption the option of the LoginLimiter
+type LoginLimiterOption struct {
+	// MaxAttempts the max number of the continuous failed login attempts before lockout, disable the lockout if it is not greater than zero
+	MaxAttempts int
+	// Lockout the lockout duration of the first lockout
+	Lockout time.Duration
+	// MaxLockout the max lockout duration
+	MaxLockout time.Duration
+	// Source the source of the login attempts, like http or grpc
+	Source string
+	// Cache store the failed login attempts, use the memory cache if it is nil
+	Cache nscache.NSCache
+	// OnLockout the callback function when a client ip or a username is locked out
+	OnLockout func(LockoutEvent)
+}
+
+// loginFailure the failed login attempts of a client ip or a username
+type loginFailure struct {
+	Failures    int   `json:"failures"`
+	Lockouts    int   `json:"lockouts"`
+	LockedUntil int64 `json:"locked_until"`
+}
+
+type loginLimiter struct {
+	opt LoginLimiterOption
+	mu  *sync.Mutex
+}
+
+var errInvalidLockout = errors.New("the lockout duration must be greater than zero")
+
+// NewLoginLimiter create an instance of the LoginLimiter, return a LoginLimiter that never locks out if the max attempts is not greater than zero
+func NewLoginLimiter(opt LoginLimiterOption) (LoginLimiter, error) {
+	if opt.MaxAttempts <= 0 {
+		return NewEmptyLoginLimiter(), nil
+	}
+	if opt.Lockout <= 0 || opt.MaxLockout <= 0 {
+		return nil, errInvalidLockout
+	}
+	if opt.MaxLockout < opt.Lockout {
+		opt.MaxLockout = opt.Lockout
+	}
+	if opt.Cache == nil {
+		c, err := nscache.NewCache("memory:")
+		if err != nil {
+			return nil, err
+		}
+		opt.Cache = c
+	}
+	return &loginLimiter{
+		opt: opt,
+		mu:  &sync.Mutex{},
+	}, nil
+}
+
+// WithSource return a LoginLimiter that shares the failed login attempts with the specified LoginLimiter, but reports the lockout events with the specified source
+func WithSource(l LoginLimiter, source string) LoginLimiter {
+	if ll, ok := l.(*loginLimiter); ok {
+		opt := ll.opt
+		opt.Source = source
+		return &loginLimiter{
+			opt: opt,
+			mu:  ll.mu,
+		}
+	}
+	return l
+}
+
+func (l *loginLimiter) Check(ip, userName string) time.Duration {
+	now := time.Now()
+	var remaining time.Duration
+	for _, key := range l.keys(ip, userName) {
+		if f := l.get(key.key); f.LockedUntil > now.UnixNano() {
+			remaining = max(remaining, time.Unix(0, f.LockedUntil).Sub(now))
+		}
+	}
+	return remaining
+}
+
+func (l *loginLimiter) Fail(ip, userName string) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range l.keys(ip, userName) {
+		f := l.get(key.key)
+		f.Failures++
+		lockout := time.Duration(0)
+		if f.Failures >= l.opt.MaxAttempts {
+			lockout = l.lockout(f.Lockouts)
+			f.Lockouts++
+			f.LockedUntil = now.Add(lockout).UnixNano()
+			if l.opt.OnLockout != nil {
+				l.opt.OnLockout(LockoutEvent{
+					Kind:       key.kind,
+					Key:        key.value,
+					Source:     l.opt.Source,
+					Failures:   f.Failures,
+					Lockouts:   f.Lockouts,
+					LockTime:   timeutil.NewTime(now),
+					UnlockTime: timeutil.NewTime(now.Add(lockout)),
+				})
+			}
+			f.Failures = 0
+		}
+		// the lockout history is kept until the key is clean for the max lockout duration
+		l.opt.Cache.Set(key.key, f, lockout+l.opt.MaxLockout)
+	}
+}
+
+func (l *loginLimiter) Success(ip, userName string) {
+	for _, key := range l.keys(ip, userName) {
+		l.opt.Cache.Remove(key.key)
+	}
+}
+
+// lockout return the lockout duration with the count of the previous lockouts, the duration is doubled every time
+func (l *loginLimiter) lockout(lockouts int) time.Duration {
+	d := l.opt.Lockout
+	for i := 0; i < lockouts && d < l.opt.MaxLockout; i++ {
+		d *= 2
+	}
+	return min(d, l.opt.MaxLockout)
+}
+
+func (l *loginLimiter) get(key string) (f loginFailure) {
+	if err := l.opt.Cache.Get(key, &f); err != nil {
+		return loginFailure{}
+	}
+	return f
+}
+
+type limiterKey struct {
+	kind  string
+	value string
+	key   string
+}
+
+func (l *loginLimiter) keys(ip, userName string) (keys []limiterKey) {
+	if len(ip) > 0 {
+		keys = append(keys, limiterKey{kind: LockoutKindIP, value: ip, key: loginLimiterKeyPrefix + ":ip:" + ip})
+	}
+	if len(userName) > 0 {
+		// the username is case-insensitive here to avoid bypassing the lockout by changing the case
+		keys = append(keys, limiterKey{kind: LockoutKindUser, value: userName, key: loginLimiterKeyPrefix + ":user:" + strings.ToLower(userName)})
+	}
+	return keys
+}
+
+type emptyLoginLimiter struct {
+}
+
+// NewEmptyLoginLimiter create an instance of the LoginLimiter that never locks out
+func NewEmptyLoginLimiter() LoginLimiter {
+	return &emptyLoginLimiter{}
+}
+
+func (l *emptyLoginLimiter) Check(ip, userName string) time.Duration {
+	return 0
+}
+
+func (l *emptyLoginLimiter) Fail(ip, userName string) {
+}
+
+func (l *emptyLoginLimiter) Success(ip, userName string) {
+}
@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoginLimiter(t *testing.T) {
+	testCases := []struct {
+		name          string
+		failures      int
+		ip            string
+		userName      string
+		checkIP       string
+		checkUserName string
+		expectLocked  bool
+	}{
+		{"not reach the max attempts", 2, "127.0.0.1", "gofs", "127.0.0.1", "gofs", false},
+		{"reach the max attempts", 3, "127.0.0.1", "gofs", "127.0.0.1", "gofs", true},
+		{"locked ip with other user", 3, "127.0.0.1", "gofs", "127.0.0.1", "root", true},
+		{"locked user from other ip", 3, "127.0.0.1", "gofs", "192.168.1.1", "gofs", true},
+		{"locked user with other case", 3, "127.0.0.1", "gofs", "192.168.1.1", "GOFS", true},
+		{"other ip and other user", 3, "127.0.0.1", "gofs", "192.168.1.1", "root", false},
+		{"empty username", 3, "127.0.0.1", "", "192.168.1.1", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var events []LockoutEvent
+			limiter, err := NewLoginLimiter(LoginLimiterOption{
+				MaxAttempts: 3,
+				Lockout:     time.Minute,
+				MaxLockout:  time.Hour,
+				Source:      "test",
+				OnLockout: func(event LockoutEvent) {
+					events = append(events, event)
+				},
+			})
+			if err != nil {
+				t.Fatalf("create login limiter error => %v", err)
+			}
+			for i := 0; i < tc.failures; i++ {
+				limiter.Fail(tc.ip, tc.userName)
+			}
+			lockout := limiter.Check(tc.checkIP, tc.checkUserName)
+			if tc.expectLocked != (lockout > 0) {
+				t.Errorf("expect locked %v, but get lockout %s", tc.expectLocked, lockout)
+			}
+			if tc.failures >= 3 && len(events) == 0 {
+				t.Errorf("expect to get the lockout events, but get nothing")
+			}
+			for _, event := range events {
+				if event.Source != "test" || event.Failures != 3 || event.Lockouts != 1 {
+					t.Errorf("get an unexpected lockout event => %+v", event)
+				}
+			}
+		})
+	}
+}
+
+func TestLoginLimiter_ExponentialLockout(t *testing.T) {
+	limiter, err := NewLoginLimiter(LoginLimiterOption{
+		MaxAttempts: 1,
+		Lockout:     time.Minute,
+		MaxLockout:  5 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("create login limiter error => %v", err)
+	}
+	expects := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for i, expect := range expects {
+		limiter.Fail("127.0.0.1", "gofs")
+		actual := limiter.Check("127.0.0.1", "gofs")
+		if actual > expect || actual < expect-time.Second {
+			t.Errorf("[%d] expect lockout %s, but get %s", i, expect, actual)
+		}
+	}
+}
+
+func TestLoginLimiter_Success(t *testing.T) {
+	limiter, err := NewLoginLimiter(LoginLimiterOption{
+		MaxAttempts: 2,
+		Lockout:     time.Minute,
+		MaxLockout:  time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("create login limiter error => %v", err)
+	}
+	limiter.Fail("127.0.0.1", "gofs")
+	limiter.Success("127.0.0.1", "gofs")
+	limiter.Fail("127.0.0.1", "gofs")
+	if lockout := limiter.Check("127.0.0.1", "gofs"); lockout > 0 {
+		t.Errorf("expect the failed attempts are reset after login success, but get lockout %s", lockout)
+	}
+}
+
+func TestLoginLimiter_WithSource(t *testing.T) {
+	var mu sync.Mutex
+	var sources []string
+	limiter, err := NewLoginLimiter(LoginLimiterOption{
+		MaxAttempts: 2,
+		Lockout:     time.Minute,
+		MaxLockout:  time.Hour,
+		OnLockout: func(event LockoutEvent) {
+			mu.Lock()
+			sources = append(sources, event.Source)
+			mu.Unlock()
+		},
+	})
+	if err != nil {
+		t.Fatalf("create login limiter error => %v", err)
+	}
+	WithSource(limiter, "http").Fail("", "gofs")
+	WithSource(limiter, "grpc").Fail("", "gofs")
+	if len(sources) != 1 || sources[0] != "grpc" {
+		t.Errorf("expect the failed attempts are shared and reported by the grpc source, but get %v", sources)
+	}
+	if l := WithSource(NewEmptyLoginLimiter(), "http"); l.Check("", "gofs") > 0 {
+		t.Errorf("expect the empty login limiter never locks out")
+	}
+}
+
+func TestNewLoginLimiter(t *testing.T) {
+	testCases := []struct {
+		name        string
+		opt         LoginLimiterOption
+		expectError bool
+		expectEmpty bool
+	}{
+		{"disabled", LoginLimiterOption{}, false, true},
+		{"invalid lockout", LoginLimiterOption{MaxAttempts: 1, MaxLockout: time.Hour}, true, false},
+		{"invalid max lockout", LoginLimiterOption{MaxAttempts: 1, Lockout: time.Minute}, true, false},
+		{"max lockout less than lockout", LoginLimiterOption{MaxAttempts: 1, Lockout: time.Hour, MaxLockout: time.Minute}, false, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter, err := NewLoginLimiter(tc.opt)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("expect error %v, but get %v", tc.expectError, err)
+			}
+			if err != nil {
+				return
+			}
+			_, isEmpty := limiter.(*emptyLoginLimiter)
+			if isEmpty != tc.expectEmpty {
+				t.Errorf("expect an empty login limiter %v, but get %v", tc.expectEmpty, isEmpty)
+			}
+		})
+	}
+}
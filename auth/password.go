@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// BcryptAlgorithm the bcrypt password hash algorithm
+	BcryptAlgorithm = "bcrypt"
+	// Argon2idAlgorithm the argon2id password hash algorithm
+	Argon2idAlgorithm = "argon2id"
+	// DefaultPasswordAlgorithm the default password hash algorithm
+	DefaultPasswordAlgorithm = BcryptAlgorithm
+)
+
+const (
+	argon2idPrefix  = "$argon2id$"
+	argon2idVersion = argon2.Version
+	argon2idMemory  = 64 * 1024
+	argon2idTime    = 1
+	argon2idThreads = 4
+	argon2idKeyLen  = 32
+	argon2idSaltLen = 16
+)
+
+var (
+	errUnsupportedPasswordAlgorithm = errors.New("unsupported password hash algorithm")
+	errInvalidPasswordHash          = errors.New("invalid password hash")
+)
+
+// HashPassword generate the password hash with the specified algorithm, current supported algorithms: bcrypt, argon2id, use the bcrypt if the algorithm is empty
+func HashPassword(password string, algorithm string) (string, error) {
+	if len(algorithm) == 0 {
+		algorithm = DefaultPasswordAlgorithm
+	}
+	switch strings.ToLower(algorithm) {
+	case BcryptAlgorithm:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		return string(hash), err
+	case Argon2idAlgorithm:
+		salt := make([]byte, argon2idSaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", err
+		}
+		key := argon2.IDKey([]byte(password), salt, argon2idTime, argon2idMemory, argon2idThreads, argon2idKeyLen)
+		return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2idVersion, argon2idMemory, argon2idTime, argon2idThreads,
+			base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+	default:
+		return "", fmt.Errorf("%w => %s", errUnsupportedPasswordAlgorithm, algorithm)
+	}
+}
+
+// VerifyPassword check the password is matched with the password hash
+func VerifyPassword(hash string, password string) bool {
+	if strings.HasPrefix(hash, argon2idPrefix) {
+		return verifyArgon2id(hash, password)
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// CheckPasswordHash check the password hash is generated by a supported algorithm
+func CheckPasswordHash(hash string) error {
+	if strings.HasPrefix(hash, argon2idPrefix) {
+		_, _, _, _, _, err := parseArgon2id(hash)
+		return err
+	}
+	if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+		return fmt.Errorf("%w => %s", errInvalidPasswordHash, err.Error())
+	}
+	return nil
+}
+
+func verifyArgon2id(hash string, password string) bool {
+	memory, iterations, threads, salt, key, err := parseArgon2id(hash)
+	if err != nil {
+		return false
+	}
+	actual := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(actual, key) == 1
+}
+
+// parseArgon2id parse the argon2id hash with the PHC string format, like $argon2id$v=19$m=65536,t=1,p=4$salt$key
+func parseArgon2id(hash string) (memory uint32, iterations uint32, threads uint8, salt []byte, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return 0, 0, 0, nil, nil, errInvalidPasswordHash
+	}
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2idVersion {
+		return 0, 0, 0, nil, nil, errInvalidPasswordHash
+	}
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return 0, 0, 0, nil, nil, errInvalidPasswordHash
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return 0, 0, 0, nil, nil, errInvalidPasswordHash
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(key) == 0 {
+		return 0, 0, 0, nil, nil, errInvalidPasswordHash
+	}
+	return memory, iterations, threads, salt, key, nil
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"testing"
+)
+
+func TestHashPassword(t *testing.T) {
+	testCases := []struct {
+		name      string
+		algorithm string
+	}{
+		{"bcrypt", BcryptAlgorithm},
+		{"argon2id", Argon2idAlgorithm},
+		{"default", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			hash, err := HashPassword("gofs_password", tc.algorithm)
+			if err != nil {
+				t.Fatalf("hash password error => %v", err)
+			}
+			if err = CheckPasswordHash(hash); err != nil {
+				t.Errorf("check password hash error => %v", err)
+			}
+			if !VerifyPassword(hash, "gofs_password") {
+				t.Errorf("verify the correct password expect true, but get false")
+			}
+			if VerifyPassword(hash, "gofs_password_x") {
+				t.Errorf("verify the wrong password expect false, but get true")
+			}
+		})
+	}
+}
+
+func TestHashPassword_ReturnError(t *testing.T) {
+	if _, err := HashPassword("gofs_password", "md5"); err == nil {
+		t.Errorf("hash password with unsupported algorithm expect get an error, but get nil")
+	}
+}
+
+func TestCheckPasswordHash_ReturnError(t *testing.T) {
+	testCases := []struct {
+		name string
+		hash string
+	}{
+		{"empty", ""},
+		{"plain text", "gofs_password"},
+		{"invalid argon2id version", "$argon2id$v=x$m=65536,t=1,p=4$c2FsdA$a2V5"},
+		{"invalid argon2id params", "$argon2id$v=19$m=x$c2FsdA$a2V5"},
+		{"invalid argon2id salt", "$argon2id$v=19$m=65536,t=1,p=4$!!!$a2V5"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := CheckPasswordHash(tc.hash); err == nil {
+				t.Errorf("check invalid password hash expect get an error, but get nil")
+			}
+			if VerifyPassword(tc.hash, "gofs_password") {
+				t.Errorf("verify password with invalid hash expect false, but get true")
+			}
+		})
+	}
+}
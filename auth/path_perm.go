@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PathPerm the permission of the path prefix, the path is the route path of the server, like /source/teamA
+type PathPerm struct {
+	Path string
+	Perm Perm
+}
+
+// NewPathPerm create a new permission of the path prefix
+func NewPathPerm(p string, perm string) (PathPerm, error) {
+	if len(strings.TrimSpace(p)) == 0 {
+		return PathPerm{}, errors.New("the path of the path permission can't be empty")
+	}
+	pp := PathPerm{
+		Path: cleanPermPath(p),
+		Perm: ToPermWithDefault(perm, DefaultPerm),
+	}
+	if !pp.Perm.IsValid() {
+		return PathPerm{}, errors.New("path perm must be the composition of 'r' 'w' 'x' or empty")
+	}
+	return pp, nil
+}
+
+// ParseUserPaths parse the path permissions of the users, return a map that the key is the username
+// For example: user1|/source/teamA|rw,user1|/dest/teamA|r,user2|/source/teamB|rw
+func ParseUserPaths(str string) (userPaths map[string][]PathPerm, err error) {
+	userPaths = make(map[string][]PathPerm)
+	str = strings.TrimSpace(str)
+	if len(str) == 0 {
+		return userPaths, nil
+	}
+	for _, item := range strings.Split(str, ",") {
+		fields := strings.Split(item, "|")
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("invalid user path permission => [%s]", item)
+		}
+		userName := strings.TrimSpace(fields[0])
+		if len(userName) == 0 {
+			return nil, fmt.Errorf("the username of the user path permission can't be empty => [%s]", item)
+		}
+		perm := ""
+		if len(fields) > 2 {
+			perm = fields[2]
+		}
+		pp, err := NewPathPerm(fields[1], perm)
+		if err != nil {
+			return nil, fmt.Errorf("%w => [%s]", err, item)
+		}
+		userPaths[userName] = append(userPaths[userName], pp)
+	}
+	return userPaths, nil
+}
+
+// ApplyUserPaths set the path permissions to the users with the same username
+func ApplyUserPaths(users []*User, userPaths map[string][]PathPerm) {
+	for _, user := range users {
+		if paths, ok := userPaths[user.UserName()]; ok {
+			user.paths = append(user.paths, paths...)
+		}
+	}
+}
+
+// CheckPathPerm check the paths grant the permission of the path or not, the longest matched path prefix is used,
+// return true if the paths are empty, return false if no path prefix is matched
+func CheckPathPerm(paths []PathPerm, p string, perm Perm) bool {
+	if len(paths) == 0 {
+		return true
+	}
+	p = cleanPermPath(p)
+	var matched *PathPerm
+	for i, pp := range paths {
+		if isPathPrefix(pp.Path, p) && (matched == nil || len(pp.Path) > len(matched.Path)) {
+			matched = &paths[i]
+		}
+	}
+	return matched != nil && perm.CheckTo(matched.Perm)
+}
+
+func isPathPrefix(prefix, p string) bool {
+	return prefix == "/" || prefix == p || strings.HasPrefix(p, prefix+"/")
+}
+
+func cleanPermPath(p string) string {
+	return path.Clean("/" + strings.ReplaceAll(strings.TrimSpace(p), "\\", "/"))
+}
@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"testing"
+)
+
+func TestParseUserPaths(t *testing.T) {
+	testCases := []struct {
+		name   string
+		str    string
+		expect map[string][]PathPerm
+	}{
+		{"empty", "", map[string][]PathPerm{}},
+		{"single path", "alice|/source/teamA|rw", map[string][]PathPerm{"alice": {{"/source/teamA", "rw"}}}},
+		{"default perm", "alice|/source/teamA", map[string][]PathPerm{"alice": {{"/source/teamA", DefaultPerm}}}},
+		{"clean path", "alice|source/teamA/../teamB/|r", map[string][]PathPerm{"alice": {{"/source/teamB", "r"}}}},
+		{"multiple users", "alice|/source/teamA|rw,alice|/dest/teamA|r,bob|/source/teamB|rwx", map[string][]PathPerm{
+			"alice": {{"/source/teamA", "rw"}, {"/dest/teamA", "r"}},
+			"bob":   {{"/source/teamB", "rwx"}},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := ParseUserPaths(tc.str)
+			if err != nil {
+				t.Fatalf("parse user paths error => %v", err)
+			}
+			if len(actual) != len(tc.expect) {
+				t.Fatalf("expect %d users, but get %d", len(tc.expect), len(actual))
+			}
+			for userName, paths := range tc.expect {
+				if len(actual[userName]) != len(paths) {
+					t.Fatalf("expect %d paths of the user [%s], but get %d", len(paths), userName, len(actual[userName]))
+				}
+				for i, pp := range paths {
+					if actual[userName][i] != pp {
+						t.Errorf("expect path perm %v, but get %v", pp, actual[userName][i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParseUserPaths_ReturnError(t *testing.T) {
+	testCases := []struct {
+		name string
+		str  string
+	}{
+		{"missing path", "alice"},
+		{"empty username", "|/source|rw"},
+		{"empty path", "alice| |rw"},
+		{"invalid perm", "alice|/source|abc"},
+		{"too many fields", "alice|/source|rw|x"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseUserPaths(tc.str); err == nil {
+				t.Errorf("parse invalid user paths expect get an error, but get nil")
+			}
+		})
+	}
+}
+
+func TestCheckPathPerm(t *testing.T) {
+	paths := []PathPerm{
+		{"/source/teamA", "rw"},
+		{"/source/teamA/readonly", "r"},
+		{"/dest", "r"},
+	}
+	testCases := []struct {
+		name   string
+		paths  []PathPerm
+		path   string
+		perm   Perm
+		expect bool
+	}{
+		{"no path perm", nil, "/source/any", WritePerm, true},
+		{"read prefix", paths, "/source/teamA", ReadPerm, true},
+		{"write sub path", paths, "/source/teamA/hello.txt", WritePerm, true},
+		{"longest prefix", paths, "/source/teamA/readonly/hello.txt", WritePerm, false},
+		{"longest prefix read", paths, "/source/teamA/readonly/hello.txt", ReadPerm, true},
+		{"not matched", paths, "/source/teamB/hello.txt", ReadPerm, false},
+		{"not path boundary", paths, "/source/teamAB/hello.txt", ReadPerm, false},
+		{"escape the prefix", paths, "/source/teamA/../teamB/hello.txt", ReadPerm, false},
+		{"parent path", paths, "/source", ReadPerm, false},
+		{"read only", paths, "/dest/hello.txt", WritePerm, false},
+		{"root prefix", []PathPerm{{"/", "r"}}, "/dest/hello.txt", ReadPerm, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := CheckPathPerm(tc.paths, tc.path, tc.perm); actual != tc.expect {
+				t.Errorf("check path perm [%s] [%s] expect %v, but get %v", tc.path, tc.perm, tc.expect, actual)
+			}
+		})
+	}
+}
@@ -8,6 +8,9 @@ type SessionUser struct {
 	UserName string
 	Password string
 	Perm     Perm
+	Paths    []PathPerm
+	// External the user is logged in by an external identity provider, such as the OpenID Connect, it is not in the user store
+	External bool
 }
 
 // MapperToSessionUser convert User to SessionUser
@@ -20,9 +23,15 @@ func MapperToSessionUser(user *User) *SessionUser {
 		UserName: user.UserName(),
 		Password: user.Password(),
 		Perm:     user.Perm(),
+		Paths:    user.Paths(),
 	}
 }
 
+// CheckPath check the session user has the permission of the path or not
+func (user *SessionUser) CheckPath(path string, perm Perm) bool {
+	return CheckPathPerm(user.Paths, path, perm)
+}
+
 func init() {
 	gob.Register(SessionUser{})
 }
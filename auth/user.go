@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"strings"
@@ -10,10 +11,12 @@ import (
 
 // User a login user info
 type User struct {
-	userId   int
-	userName string
-	password string
-	perm     Perm
+	userId       int
+	userName     string
+	password     string
+	passwordHash string
+	perm         Perm
+	paths        []PathPerm
 }
 
 // String return format user info
@@ -36,11 +39,34 @@ func (user *User) Password() string {
 	return user.password
 }
 
+// PasswordHash return the password hash of the user that is loaded from the users file
+func (user *User) PasswordHash() string {
+	return user.passwordHash
+}
+
+// VerifyPassword check the password is matched with the password or the password hash of the user
+func (user *User) VerifyPassword(password string) bool {
+	if len(user.passwordHash) > 0 {
+		return VerifyPassword(user.passwordHash, password)
+	}
+	return len(user.password) > 0 && subtle.ConstantTimeCompare([]byte(user.password), []byte(password)) == 1
+}
+
 // Perm return user permission
 func (user *User) Perm() Perm {
 	return user.perm
 }
 
+// Paths return the path permissions of the user, the user can access any path if it is empty
+func (user *User) Paths() []PathPerm {
+	return user.paths
+}
+
+// CheckPath check the user has the permission of the path or not
+func (user *User) CheckPath(path string, perm Perm) bool {
+	return CheckPathPerm(user.paths, path, perm)
+}
+
 // NewUser create a new user
 func NewUser(userId int, userName string, password string, perm string) (*User, error) {
 	if userId <= 0 {
@@ -63,6 +89,32 @@ func NewUser(userId int, userName string, password string, perm string) (*User,
 	return user, nil
 }
 
+// NewHashedUser create a new user with the password hash, the password hash is generated by HashPassword
+func NewHashedUser(userId int, userName string, passwordHash string, perm string) (*User, error) {
+	if userId <= 0 {
+		return nil, errors.New("userId must greater than zero")
+	}
+	if len(userName) == 0 {
+		return nil, errors.New("userName can't be empty")
+	}
+	if strings.ContainsAny(userName, ",|:") {
+		return nil, errors.New("userName can't contain ',' '|' or ':' ")
+	}
+	if err := CheckPasswordHash(passwordHash); err != nil {
+		return nil, err
+	}
+	p := ToPermWithDefault(perm, DefaultPerm)
+	if !p.IsValid() {
+		return nil, errors.New("user perm must be the composition of 'r' 'w' 'x' or empty")
+	}
+	return &User{
+		userId:       userId,
+		userName:     userName,
+		passwordHash: passwordHash,
+		perm:         p,
+	}, nil
+}
+
 // isValidUser check username and password is valid or not
 func isValidUser(user User) error {
 	if len(user.UserName()) == 0 {
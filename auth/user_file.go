@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/no-src/nsgo/jsonutil"
+	"github.com/no-src/nsgo/yamlutil"
+)
+
+// usersFile the content of the users file with the yaml or json format
+type usersFile struct {
+	Users []usersFileItem `json:"users" yaml:"users"`
+}
+
+// usersFileItem a user in the users file
+type usersFileItem struct {
+	UserName     string              `json:"username" yaml:"username"`
+	PasswordHash string              `json:"password_hash" yaml:"password_hash"`
+	Perm         string              `json:"perm" yaml:"perm"`
+	Paths        []usersFilePathItem `json:"paths,omitempty" yaml:"paths,omitempty"`
+}
+
+// usersFilePathItem a path permission of the user in the users file
+type usersFilePathItem struct {
+	Path string `json:"path" yaml:"path"`
+	Perm string `json:"perm" yaml:"perm"`
+}
+
+// LoadUsersFile load the users with the password hash from the users file, the format of the file is detected by the file extension,
+// the yaml(.yaml, .yml) and json(.json) format are supported, otherwise the file is parsed as the htpasswd format,
+// every line of the htpasswd format is like this, username:password_hash or username:password_hash:perm,
+// the path permissions of the users are only supported in the yaml and json format
+func LoadUsersFile(path string) (users []*User, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var items []usersFileItem
+	switch usersFileFormat(path) {
+	case ".json":
+		var f usersFile
+		err = jsonutil.Unmarshal(data, &f)
+		items = f.Users
+	case ".yaml":
+		var f usersFile
+		err = yamlutil.Unmarshal(data, &f)
+		items = f.Users
+	default:
+		items, err = parseHtpasswd(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		user, err := NewHashedUser(i+1, item.UserName, item.PasswordHash, item.Perm)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user [%s] in the users file => %w", item.UserName, err)
+		}
+		for _, path := range item.Paths {
+			pp, err := NewPathPerm(path.Path, path.Perm)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path permission of the user [%s] in the users file => %w", item.UserName, err)
+			}
+			user.paths = append(user.paths, pp)
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// SaveUsersFile save the users with the password hash to the users file, the format of the file is detected by the file extension
+func SaveUsersFile(path string, users []*User) (err error) {
+	var items []usersFileItem
+	for _, user := range users {
+		item := usersFileItem{
+			UserName:     user.UserName(),
+			PasswordHash: user.PasswordHash(),
+			Perm:         user.Perm().String(),
+		}
+		for _, pp := range user.Paths() {
+			item.Paths = append(item.Paths, usersFilePathItem{Path: pp.Path, Perm: pp.Perm.String()})
+		}
+		items = append(items, item)
+	}
+	var data []byte
+	switch usersFileFormat(path) {
+	case ".json":
+		data, err = jsonutil.Marshal(usersFile{Users: items})
+	case ".yaml":
+		data, err = yamlutil.Marshal(usersFile{Users: items})
+	default:
+		data = formatHtpasswd(items)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+func usersFileFormat(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yml" {
+		ext = ".yaml"
+	}
+	return ext
+}
+
+func parseHtpasswd(data []byte) (items []usersFileItem, err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("invalid htpasswd line => [%s]", line)
+		}
+		item := usersFileItem{
+			UserName:     fields[0],
+			PasswordHash: fields[1],
+		}
+		if len(fields) > 2 {
+			item.Perm = fields[2]
+		}
+		items = append(items, item)
+	}
+	return items, scanner.Err()
+}
+
+func formatHtpasswd(items []usersFileItem) []byte {
+	var buf bytes.Buffer
+	for _, item := range items {
+		buf.WriteString(fmt.Sprintf("%s:%s:%s\n", item.UserName, item.PasswordHash, item.Perm))
+	}
+	return buf.Bytes()
+}
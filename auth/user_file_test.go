@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveUsersFile_LoadUsersFile(t *testing.T) {
+	testCases := []struct {
+		name string
+		file string
+	}{
+		{"yaml", "users.yaml"},
+		{"yml", "users.yml"},
+		{"json", "users.json"},
+		{"htpasswd", "users.htpasswd"},
+	}
+
+	hash, err := HashPassword("gofs_password", BcryptAlgorithm)
+	if err != nil {
+		t.Fatalf("hash password error => %v", err)
+	}
+	u1, _ := NewHashedUser(1, "gofs1", hash, "rw")
+	u2, _ := NewHashedUser(2, "gofs2", hash, "")
+	pp, _ := NewPathPerm("/source/teamA", "rw")
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.file)
+			u1.paths = nil
+			if tc.name != "htpasswd" {
+				u1.paths = []PathPerm{pp}
+			}
+			if err := SaveUsersFile(path, []*User{u1, u2}); err != nil {
+				t.Fatalf("save users file error => %v", err)
+			}
+			users, err := LoadUsersFile(path)
+			if err != nil {
+				t.Fatalf("load users file error => %v", err)
+			}
+			if len(users) != 2 {
+				t.Fatalf("expect to load 2 users, but get %d", len(users))
+			}
+			for i, expect := range []*User{u1, u2} {
+				actual := users[i]
+				if actual.UserName() != expect.UserName() || actual.PasswordHash() != expect.PasswordHash() || actual.Perm() != expect.Perm() {
+					t.Errorf("expect user %s, but get %s", expect, actual)
+				}
+				if len(actual.Paths()) != len(expect.Paths()) || (len(expect.Paths()) > 0 && actual.Paths()[0] != expect.Paths()[0]) {
+					t.Errorf("expect the paths of the user %v, but get %v", expect.Paths(), actual.Paths())
+				}
+				if !actual.VerifyPassword("gofs_password") {
+					t.Errorf("verify the password of the user [%s] failed", actual.UserName())
+				}
+			}
+		})
+	}
+}
+
+func TestLoadUsersFile_Htpasswd(t *testing.T) {
+	hash, err := HashPassword("gofs_password", Argon2idAlgorithm)
+	if err != nil {
+		t.Fatalf("hash password error => %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "htpasswd")
+	content := "# gofs users\n\ngofs1:" + hash + "\ngofs2:" + hash + ":rwx\n"
+	if err = os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write users file error => %v", err)
+	}
+	users, err := LoadUsersFile(path)
+	if err != nil {
+		t.Fatalf("load users file error => %v", err)
+	}
+	if len(users) != 2 {
+		t.Fatalf("expect to load 2 users, but get %d", len(users))
+	}
+	if users[0].Perm() != ToPerm(DefaultPerm) || users[1].Perm() != FullPerm {
+		t.Errorf("expect the perm of the users are [%s] [%s], but get [%s] [%s]", DefaultPerm, FullPerm, users[0].Perm(), users[1].Perm())
+	}
+}
+
+func TestLoadUsersFile_ReturnError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"invalid htpasswd line", "htpasswd", "gofs"},
+		{"invalid password hash", "htpasswd", "gofs:gofs_password"},
+		{"invalid perm", "htpasswd", "gofs:$2a$10$Yk0hK8f5p0kFZ6Yk0hK8f5p0kFZ6Yk0hK8f5p0kFZ6Yk0hK8f5p0k:abc"},
+		{"invalid json", "users.json", "{"},
+		{"invalid yaml", "users.yaml", "users: ["},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.file)
+			if err := os.WriteFile(path, []byte(tc.content), 0600); err != nil {
+				t.Fatalf("write users file error => %v", err)
+			}
+			if _, err := LoadUsersFile(path); err == nil {
+				t.Errorf("load invalid users file expect get an error, but get nil")
+			}
+		})
+	}
+
+	if _, err := LoadUsersFile(filepath.Join(t.TempDir(), "not_exist.yaml")); err == nil {
+		t.Errorf("load not exist users file expect get an error, but get nil")
+	}
+}
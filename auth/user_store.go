@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// usersFileCheckInterval the min interval to check whether the users file is modified
+const usersFileCheckInterval = time.Second
+
+// UserStore the store of the server accounts
+type UserStore interface {
+	// Users return all the users, the users file is reloaded if it is modified
+	Users() []*User
+	// Login return the user that the username and password are matched, return nil if not matched
+	Login(userName, password string) *User
+	// SetUsers replace the specified users, the users in the users file are kept
+	SetUsers(users []*User)
+}
+
+type userStore struct {
+	users     []*User
+	usersFile string
+	fileUsers []*User
+	modTime   time.Time
+	checkTime time.Time
+	mu        sync.RWMutex
+}
+
+// NewUserStore create an instance of the UserStore that contains the specified users and the users in the users file,
+// the users file is optional, it is reloaded automatically after it is modified
+func NewUserStore(users []*User, usersFile string) (UserStore, error) {
+	s := &userStore{
+		users:     users,
+		usersFile: usersFile,
+	}
+	if len(usersFile) > 0 {
+		stat, err := os.Stat(usersFile)
+		if err != nil {
+			return nil, err
+		}
+		if err = s.load(stat.ModTime()); err != nil {
+			return nil, err
+		}
+		s.checkTime = time.Now()
+	}
+	return s, nil
+}
+
+func (s *userStore) Users() []*User {
+	s.reload()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append(append([]*User{}, s.users...), s.fileUsers...)
+}
+
+func (s *userStore) Login(userName, password string) *User {
+	for _, user := range s.Users() {
+		if user.UserName() == userName && user.VerifyPassword(password) {
+			return user
+		}
+	}
+	return nil
+}
+
+func (s *userStore) SetUsers(users []*User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// keep the user id of the file users after the specified users,
+	// the current file users may be in use by the readers, so replace them with the copies instead of modifying them
+	s.fileUsers = shiftUserId(s.fileUsers, len(users)-len(s.users))
+	s.users = users
+}
+
+// reload reload the users file if it is modified, keep the current users if reload failed
+func (s *userStore) reload() {
+	if len(s.usersFile) == 0 {
+		return
+	}
+	s.mu.Lock()
+	if time.Since(s.checkTime) < usersFileCheckInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.checkTime = time.Now()
+	modTime := s.modTime
+	s.mu.Unlock()
+
+	stat, err := os.Stat(s.usersFile)
+	if err != nil || stat.ModTime().Equal(modTime) {
+		return
+	}
+	s.load(stat.ModTime())
+}
+
+// load load the users file without the lock, then swap the file users under the lock
+func (s *userStore) load(modTime time.Time) error {
+	fileUsers, err := LoadUsersFile(s.usersFile)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the user id of the file users is after the specified users
+	s.fileUsers = shiftUserId(fileUsers, len(s.users))
+	s.modTime = modTime
+	return nil
+}
+
+// shiftUserId return the copies of the users that the user id is increased by the offset
+func shiftUserId(users []*User, offset int) []*User {
+	shifted := make([]*User, 0, len(users))
+	for _, user := range users {
+		u := *user
+		u.userId += offset
+		shifted = append(shifted, &u)
+	}
+	return shifted
+}
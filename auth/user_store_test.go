@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUserStore_Reload(t *testing.T) {
+	staticUser, err := NewUser(1, "static", "static_password", "rwx")
+	if err != nil {
+		t.Fatalf("create user error => %v", err)
+	}
+	hash, err := HashPassword("gofs_password", BcryptAlgorithm)
+	if err != nil {
+		t.Fatalf("hash password error => %v", err)
+	}
+	u1, _ := NewHashedUser(1, "gofs1", hash, "r")
+	path := filepath.Join(t.TempDir(), "users.yaml")
+	if err = SaveUsersFile(path, []*User{u1}); err != nil {
+		t.Fatalf("save users file error => %v", err)
+	}
+
+	store, err := NewUserStore([]*User{staticUser}, path)
+	if err != nil {
+		t.Fatalf("create user store error => %v", err)
+	}
+	if len(store.Users()) != 2 {
+		t.Fatalf("expect 2 users, but get %d", len(store.Users()))
+	}
+	if user := store.Login("static", "static_password"); user == nil {
+		t.Errorf("login with the static user failed")
+	}
+	user := store.Login("gofs1", "gofs_password")
+	if user == nil {
+		t.Fatalf("login with the file user failed")
+	}
+	if user.UserId() != 2 {
+		t.Errorf("expect the user id of the file user is 2, but get %d", user.UserId())
+	}
+	if store.Login("gofs1", "static_password") != nil {
+		t.Errorf("login with the wrong password expect failed, but success")
+	}
+
+	// replace the users in the file and wait for reload
+	u2, _ := NewHashedUser(1, "gofs2", hash, "rw")
+	if err = SaveUsersFile(path, []*User{u2}); err != nil {
+		t.Fatalf("save users file error => %v", err)
+	}
+	modTime := time.Now().Add(time.Second)
+	if err = os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("change the file times error => %v", err)
+	}
+	time.Sleep(usersFileCheckInterval + time.Millisecond*100)
+	if store.Login("gofs1", "gofs_password") != nil {
+		t.Errorf("login with the removed user expect failed, but success")
+	}
+	if store.Login("gofs2", "gofs_password") == nil {
+		t.Errorf("login with the reloaded user failed")
+	}
+
+	// keep the current users if the reload is failed
+	if err = os.WriteFile(path, []byte("users: ["), 0600); err != nil {
+		t.Fatalf("write users file error => %v", err)
+	}
+	modTime = modTime.Add(time.Second)
+	if err = os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("change the file times error => %v", err)
+	}
+	time.Sleep(usersFileCheckInterval + time.Millisecond*100)
+	if store.Login("gofs2", "gofs_password") == nil {
+		t.Errorf("expect to keep the current users after reload failed, but login failed")
+	}
+}
+
+func TestNewUserStore_ReturnError(t *testing.T) {
+	if _, err := NewUserStore(nil, filepath.Join(t.TempDir(), "not_exist.yaml")); err == nil {
+		t.Errorf("create user store with not exist users file expect get an error, but get nil")
+	}
+}
+
+func TestUserStore_SetUsers(t *testing.T) {
+	u1, _ := NewUser(1, "gofs1", "gofs_password1", "r")
+	u2, _ := NewUser(1, "gofs2", "gofs_password2", "rw")
+	u3, _ := NewUser(2, "gofs3", "gofs_password3", "rwx")
+	hash, err := HashPassword("file_password", BcryptAlgorithm)
+	if err != nil {
+		t.Fatalf("hash password error => %v", err)
+	}
+	fileUser, _ := NewHashedUser(1, "file_user", hash, "r")
+	path := filepath.Join(t.TempDir(), "users.yaml")
+	if err = SaveUsersFile(path, []*User{fileUser}); err != nil {
+		t.Fatalf("save users file error => %v", err)
+	}
+
+	store, err := NewUserStore([]*User{u1}, path)
+	if err != nil {
+		t.Fatalf("create user store error => %v", err)
+	}
+	store.SetUsers([]*User{u2, u3})
+
+	testCases := []struct {
+		name     string
+		userName string
+		password string
+		expectId int
+	}{
+		{"the removed user", "gofs1", "gofs_password1", 0},
+		{"the replaced user", "gofs2", "gofs_password2", 1},
+		{"the added user", "gofs3", "gofs_password3", 2},
+		{"the file user", "file_user", "file_password", 3},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			user := store.Login(tc.userName, tc.password)
+			if tc.expectId == 0 {
+				if user != nil {
+					t.Errorf("login with the removed user expect failed, but success")
+				}
+				return
+			}
+			if user == nil {
+				t.Fatalf("login with the user [%s] failed", tc.userName)
+			}
+			if user.UserId() != tc.expectId {
+				t.Errorf("expect the user id is %d, but get %d", tc.expectId, user.UserId())
+			}
+		})
+	}
+}
+
+func TestUserStore_ConcurrentReloadAndRead(t *testing.T) {
+	hash, err := HashPassword("file_password", BcryptAlgorithm)
+	if err != nil {
+		t.Fatalf("hash password error => %v", err)
+	}
+	fileUser, _ := NewHashedUser(1, "file_user", hash, "r")
+	path := filepath.Join(t.TempDir(), "users.yaml")
+	if err = SaveUsersFile(path, []*User{fileUser}); err != nil {
+		t.Fatalf("save users file error => %v", err)
+	}
+	u1, _ := NewUser(1, "gofs1", "gofs_password1", "r")
+	u2, _ := NewUser(2, "gofs2", "gofs_password2", "rw")
+	store, err := NewUserStore([]*User{u1}, path)
+	if err != nil {
+		t.Fatalf("create user store error => %v", err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				// read the users like the auth middleware resolves the session user
+				for _, u := range store.Users() {
+					_ = u.UserId()
+					_ = u.UserName()
+				}
+			}
+		}()
+	}
+
+	modTime := time.Now()
+	for i := 0; i < 100; i++ {
+		if i%2 == 0 {
+			store.SetUsers([]*User{u1, u2})
+		} else {
+			store.SetUsers([]*User{u1})
+		}
+		if i%20 == 0 {
+			// modify the users file to trigger the reload of the readers
+			modTime = modTime.Add(time.Second)
+			if err = os.Chtimes(path, modTime, modTime); err != nil {
+				t.Fatalf("change the file times error => %v", err)
+			}
+			time.Sleep(usersFileCheckInterval / 10)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	store.SetUsers([]*User{u1, u2})
+	user := store.Login("file_user", "file_password")
+	if user == nil {
+		t.Fatalf("login with the file user failed")
+	}
+	if user.UserId() != 3 {
+		t.Errorf("expect the user id of the file user is 3, but get %d", user.UserId())
+	}
+}
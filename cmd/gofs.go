@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 
+	"github.com/no-src/gofs/audit"
 	"github.com/no-src/gofs/auth"
 	"github.com/no-src/gofs/checksum"
 	"github.com/no-src/gofs/conf"
@@ -16,8 +18,10 @@ import (
 	"github.com/no-src/gofs/internal/about"
 	"github.com/no-src/gofs/internal/signal"
 	"github.com/no-src/gofs/internal/version"
+	"github.com/no-src/gofs/journal"
 	"github.com/no-src/gofs/logger"
 	"github.com/no-src/gofs/monitor"
+	"github.com/no-src/gofs/reload"
 	"github.com/no-src/gofs/report"
 	"github.com/no-src/gofs/result"
 	"github.com/no-src/gofs/retry"
@@ -35,6 +39,9 @@ func Run() result.Result {
 
 // RunWithArgs running the gofs program with specified command-line arguments, starting with the program name
 func RunWithArgs(args []string) result.Result {
+	if isUserCommand(args) {
+		return runUserCommand(args, os.Stdin)
+	}
 	return RunWithConfig(flag.ParseFlags(args))
 }
 
@@ -75,23 +82,14 @@ func runWithConfig(c conf.Config, result result.Result) {
 	}()
 
 	cp := &c
+	flagConf := c
 
 	if err = parseConfigFile(cp); err != nil {
 		result.InitDoneWithError(err)
 		return
 	}
 
-	// if current is subprocess, then reset the "-kill_ppid" and "-daemon"
-	if c.IsSubprocess {
-		c.KillPPid = false
-		c.IsDaemon = false
-	}
-
-	switchDebug := false
-	if c.DryRun && c.LogLevel != int(debugLogLevel) {
-		c.LogLevel = int(debugLogLevel)
-		switchDebug = true
-	}
+	switchDebug := prepareConfig(cp)
 
 	// init the default logger
 	var logger *logger.Logger
@@ -112,6 +110,8 @@ func runWithConfig(c conf.Config, result result.Result) {
 		return
 	}
 
+	// keep the config before initializing the default values to compare with the reloaded config
+	runningConf := c
 	if err = initDefaultValue(cp, logger); err != nil {
 		logger.Error(err, "init default value of config error")
 		result.InitDoneWithError(err)
@@ -154,6 +154,29 @@ func runWithConfig(c conf.Config, result result.Result) {
 		return
 	}
 
+	certUserList, err := auth.ParseCertUsers(c.TLSClientCertUsers)
+	if err != nil {
+		logger.Error(err, "parse cert users error => [%s]", c.TLSClientCertUsers)
+		result.InitDoneWithError(err)
+		return
+	}
+
+	userPaths, err := auth.ParseUserPaths(c.UserPaths)
+	if err != nil {
+		logger.Error(err, "parse user paths error => [%s]", c.UserPaths)
+		result.InitDoneWithError(err)
+		return
+	}
+	auth.ApplyUserPaths(userList, userPaths)
+	auth.ApplyUserPaths(certUserList, userPaths)
+
+	// init the user store that is shared by the file server and the grpc server
+	store, err := initUserStore(c, userList, logger)
+	if err != nil {
+		result.InitDoneWithError(err)
+		return
+	}
+
 	// init the web server logger
 	webLogger, err := initWebServerLogger(c)
 	if err != nil {
@@ -162,12 +185,32 @@ func runWithConfig(c conf.Config, result result.Result) {
 	}
 	defer webLogger.Close()
 
+	reloader := newConfigReloader(flagConf, runningConf, c, userList, certUserList, store, logger, webLogger)
+
 	// create retry
 	r := retry.New(c.RetryCount, c.RetryWait.Duration(), c.RetryAsync, logger)
 
 	reporter := report.NewReporter()
+
+	// init the login limiter
+	limiter, err := initLoginLimiter(c, reporter, logger)
+	if err != nil {
+		result.InitDoneWithError(err)
+		return
+	}
+
+	// init the audit log
+	auditLog, err := initAuditLog(c)
+	if err != nil {
+		result.InitDoneWithError(err)
+		return
+	}
+	defer func() {
+		logger.ErrorIf(auditLog.Close(), "close the audit log error")
+	}()
+
 	// start a file web server
-	if err = startWebServer(c, webLogger, userList, r, reporter, logger); err != nil {
+	if err = startWebServer(c, webLogger, userList, certUserList, store, r, reporter, limiter, auditLog, reloader, logger); err != nil {
 		result.InitDoneWithError(err)
 		return
 	}
@@ -180,6 +223,12 @@ func runWithConfig(c conf.Config, result result.Result) {
 	}
 	defer eventLogger.Close()
 
+	// run the named sync jobs instead of the top-level source and dest
+	if len(c.Jobs) > 0 {
+		err = runJobs(c, userList, certUserList, store, eventLogger, reporter, limiter, auditLog, reloader, result, logger)
+		return
+	}
+
 	pi, err := ignore.NewPathIgnore(c.IgnoreConf, c.IgnoreDeletedPath, logger)
 	if err != nil {
 		logger.Error(err, "init ignore config error")
@@ -187,17 +236,28 @@ func runWithConfig(c conf.Config, result result.Result) {
 		return
 	}
 
+	// init the event journal
+	j, err := initJournal(c, logger)
+	if err != nil {
+		result.InitDoneWithError(err)
+		return
+	}
+	defer func() {
+		logger.ErrorIf(j.Close(), "close the event journal error")
+	}()
+
 	// init the monitor
-	m, err := initMonitor(c, userList, eventLogger, r, pi, reporter, logger)
+	m, err := initMonitor(c, userList, certUserList, store, eventLogger, j, r, pi, reporter, limiter, auditLog, logger)
 	if err != nil {
 		result.InitDoneWithError(err)
 		return
 	}
+	reloader.addTarget("", pi, m)
 
 	// start monitor
 	logger.Info("monitor is starting...")
 	defer logger.Info("gofs exited")
-	ns, ss := signal.Notify(m.Shutdown, logger)
+	ns, ss := signal.NotifyWithReload(m.Shutdown, reloader.signalReload(), logger)
 	go func() {
 		result.RegisterNotifyHandler(ns)
 	}()
@@ -222,6 +282,21 @@ func parseConfigFile(cp *conf.Config) error {
 	return nil
 }
 
+// prepareConfig adjust the config after parsing the flags and the config file, return true if the log level is switched to debug
+func prepareConfig(cp *conf.Config) (switchDebug bool) {
+	// if current is subprocess, then reset the "-kill_ppid" and "-daemon"
+	if cp.IsSubprocess {
+		cp.KillPPid = false
+		cp.IsDaemon = false
+	}
+
+	if cp.DryRun && cp.LogLevel != int(debugLogLevel) {
+		cp.LogLevel = int(debugLogLevel)
+		switchDebug = true
+	}
+	return switchDebug
+}
+
 // executeOnce execute the work and get ready to exit
 func executeOnce(c conf.Config, logger *logger.Logger) (exit bool, err error) {
 	// print version info
@@ -236,6 +311,20 @@ func executeOnce(c conf.Config, logger *logger.Logger) (exit bool, err error) {
 		return true, nil
 	}
 
+	// print the JSON Schema of the config file
+	if c.PrintSchema {
+		schema, err := flag.JSONSchema()
+		if err == nil {
+			logger.Log("%s", schema)
+		}
+		return true, logger.ErrorIf(err, "generate the JSON Schema of the config error")
+	}
+
+	// validate the config and print all the problems
+	if c.Validate {
+		return true, validateConfig(c, logger)
+	}
+
 	// clear the deleted files
 	if c.ClearDeletedPath {
 		return true, logger.ErrorIf(fs.ClearDeletedFile(c.Dest.Path().Base(), logger), "clear the deleted files error")
@@ -251,6 +340,11 @@ func executeOnce(c conf.Config, logger *logger.Logger) (exit bool, err error) {
 		return true, logger.ErrorIf(dec.Decrypt(), "decrypt error")
 	}
 
+	// rotate the encryption key of the destination
+	if c.Rekey {
+		return true, logger.ErrorIf(rekey(c, logger), "rekey error")
+	}
+
 	// calculate checksum
 	if c.Checksum {
 		return true, checksum.PrintChecksum(c.Source.Path().Base(), c.ChunkSize.Bytes(), c.CheckpointCount, c.ChecksumAlgorithm, logger)
@@ -259,28 +353,60 @@ func executeOnce(c conf.Config, logger *logger.Logger) (exit bool, err error) {
 }
 
 // startWebServer start a file web server
-func startWebServer(c conf.Config, webLogger *logger.Logger, userList []*auth.User, r retry.Retry, reporter report.Reporter, logger *logger.Logger) error {
+func startWebServer(c conf.Config, webLogger *logger.Logger, userList []*auth.User, certUserList []*auth.User, store auth.UserStore, r retry.Retry, reporter report.Reporter, limiter auth.LoginLimiter, auditLog audit.AuditLog, reloader reload.Reloader, logger *logger.Logger) error {
 	if c.EnableFileServer {
 		waitInit := wait.NewWaitDone()
 		go func() {
-			httpfs.StartFileServer(server.NewServerOption(c, waitInit, userList, webLogger, r, reporter))
+			httpfs.StartFileServer(server.NewServerOption(c, waitInit, userList, certUserList, store, webLogger, r, reporter, limiter, auditLog, reloader))
 		}()
 		return logger.ErrorIf(waitInit.Wait(), "start the file server [%s] error", c.FileServerAddr)
 	}
 	return nil
 }
 
+// initUserStore init the user store of the servers, return nil if neither the file server nor the remote disk server is enabled
+func initUserStore(c conf.Config, userList []*auth.User, logger *logger.Logger) (auth.UserStore, error) {
+	if !c.EnableFileServer && !c.Source.Server() {
+		return nil, nil
+	}
+	store, err := auth.NewUserStore(userList, c.UsersFile)
+	return store, logger.ErrorIf(err, "init the user store error => [%s]", c.UsersFile)
+}
+
+// initLoginLimiter init the login limiter that is shared by the file server and the grpc server
+func initLoginLimiter(c conf.Config, reporter report.Reporter, logger *logger.Logger) (auth.LoginLimiter, error) {
+	opt := auth.LoginLimiterOption{
+		MaxAttempts: c.LoginMaxAttempts,
+		Lockout:     c.LoginLockout.Duration(),
+		MaxLockout:  c.LoginMaxLockout.Duration(),
+		OnLockout: func(event auth.LockoutEvent) {
+			logger.Warn("the %s [%s] is locked out until %s after %d failed login attempts from %s", event.Kind, event.Key, event.UnlockTime, event.Failures, event.Source)
+			reporter.PutLockout(event)
+		},
+	}
+	if c.LoginMaxAttempts > 0 && c.LoginLockoutSessionStore {
+		cache, err := server.NewSessionCache(c.SessionConnection)
+		if err != nil {
+			logger.Error(err, "init the login lockout store error => [%s]", c.SessionConnection)
+			return nil, err
+		}
+		opt.Cache = cache
+	}
+	limiter, err := auth.NewLoginLimiter(opt)
+	return limiter, logger.ErrorIf(err, "init the login limiter error")
+}
+
 // initMonitor init the monitor
-func initMonitor(c conf.Config, userList []*auth.User, eventWriter io.Writer, r retry.Retry, pi ignore.PathIgnore, reporter report.Reporter, logger *logger.Logger) (monitor.Monitor, error) {
+func initMonitor(c conf.Config, userList []*auth.User, certUserList []*auth.User, store auth.UserStore, eventWriter io.Writer, j journal.Journal, r retry.Retry, pi ignore.PathIgnore, reporter report.Reporter, limiter auth.LoginLimiter, auditLog audit.AuditLog, logger *logger.Logger) (monitor.Monitor, error) {
 	// create syncer
-	syncer, err := sync.NewSync(sync.NewSyncOption(c, userList, r, pi, reporter, logger))
+	syncer, err := sync.NewSync(sync.NewSyncOption(c, userList, certUserList, store, r, pi, reporter, limiter, auditLog, logger))
 	if err != nil {
 		logger.Error(err, "create the instance of Sync error")
 		return nil, err
 	}
 
 	// create monitor
-	m, err := monitor.NewMonitor(monitor.NewMonitorOption(c, syncer, r, userList, eventWriter, pi, reporter, logger), RunWithConfigContent)
+	m, err := monitor.NewMonitor(monitor.NewMonitorOption(c, syncer, r, userList, eventWriter, j, pi, reporter, logger), RunWithConfigContent)
 	if err != nil {
 		logger.Error(err, "create the instance of Monitor error")
 		return nil, err
@@ -294,6 +420,18 @@ func initMonitor(c conf.Config, userList []*auth.User, eventWriter io.Writer, r
 	return m, nil
 }
 
+// initJournal init the event journal, every pair of source and dest has its own journal file
+func initJournal(c conf.Config, logger *logger.Logger) (journal.Journal, error) {
+	if !c.EnableJournal {
+		return journal.NewEmptyJournal(), nil
+	}
+	j, err := journal.NewFileJournal(c.JournalDir, c.Source.String()+"=>"+c.Dest.String(), logger)
+	if err != nil {
+		logger.Error(err, "init the event journal error")
+	}
+	return j, err
+}
+
 // initDefaultValue init default value of config
 func initDefaultValue(cp *conf.Config, logger *logger.Logger) error {
 	initFileServer(cp)
@@ -306,6 +444,10 @@ func initDefaultValue(cp *conf.Config, logger *logger.Logger) error {
 		return err
 	}
 
+	if err := checkJobs(*cp); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -315,8 +457,9 @@ func initFileServer(cp *conf.Config) {
 		cp.FileServerAddr = server.DefaultAddrHttp
 	}
 
-	// if start a remote server monitor, auto enable file server
-	if cp.Source.Server() {
+	// if start a remote server monitor, auto enable file server,
+	// unless the file content is transferred over the gRPC api server only
+	if cp.Source.Server() && !cp.EnableGrpcTransfer {
 		cp.EnableFileServer = true
 	}
 }
@@ -345,20 +488,22 @@ func generateRandomUser(cp *conf.Config, logger *logger.Logger) error {
 // checkTLS check cert and key file of the TLS
 func checkTLS(c conf.Config) error {
 	if c.EnableTLS && (c.Source.Server() || c.EnableFileServer) {
-		exist, err := fsutil.FileExist(c.TLSCertFile)
-		if err != nil {
-			return err
-		}
-		if !exist {
-			return fmt.Errorf("cert file is not found for tls => [%s], for more information, see -tls and -tls_cert_file flags", c.TLSCertFile)
-		}
-		exist, err = fsutil.FileExist(c.TLSKeyFile)
-		if err != nil {
-			return err
-		}
-		if !exist {
-			return fmt.Errorf("key file is not found for tls => [%s], for more information, see -tls and -tls_key_file flags", c.TLSKeyFile)
-		}
+		return errors.Join(
+			checkFileExist(c.TLSCertFile, "cert file is not found for tls => [%s], for more information, see -tls and -tls_cert_file flags"),
+			checkFileExist(c.TLSKeyFile, "key file is not found for tls => [%s], for more information, see -tls and -tls_key_file flags"),
+		)
+	}
+	return nil
+}
+
+// checkFileExist return an error with the format if the file is not found
+func checkFileExist(file string, format string) error {
+	exist, err := fsutil.FileExist(file)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return fmt.Errorf(format, file)
 	}
 	return nil
 }
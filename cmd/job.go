@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	gosync "sync"
+
+	"github.com/no-src/gofs/audit"
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/conf"
+	"github.com/no-src/gofs/ignore"
+	"github.com/no-src/gofs/internal/signal"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/monitor"
+	"github.com/no-src/gofs/report"
+	"github.com/no-src/gofs/result"
+	"github.com/no-src/gofs/retry"
+	"github.com/no-src/gofs/wait"
+)
+
+var (
+	errJobNameRequired  = errors.New("the name of the sync job is required")
+	errJobNameDuplicate = errors.New("the name of the sync job is duplicate")
+	errJobPathRequired  = errors.New("the source and dest of the sync job are required")
+	errJobServerSource  = errors.New("the sync job can't use the source of the server mode, use the top-level source instead")
+	errJobsUnsupported  = errors.New("the sync jobs are not supported in the daemon mode or the task client mode")
+)
+
+// syncJob a named sync job that has its own monitor
+type syncJob struct {
+	name  string
+	c     conf.Config
+	pi    ignore.PathIgnore
+	m     monitor.Monitor
+	close func()
+}
+
+// checkJobs check the sync jobs in the config
+func checkJobs(c conf.Config) error {
+	if len(c.Jobs) == 0 {
+		return nil
+	}
+	if c.IsDaemon || c.EnableTaskClient {
+		return errJobsUnsupported
+	}
+	names := make(map[string]bool, len(c.Jobs))
+	for _, job := range c.Jobs {
+		if len(job.Name) == 0 {
+			return errJobNameRequired
+		}
+		if names[job.Name] {
+			return fmt.Errorf("%w => %s", errJobNameDuplicate, job.Name)
+		}
+		names[job.Name] = true
+		if job.Source.IsEmpty() || job.Dest.IsEmpty() {
+			return fmt.Errorf("%w => %s", errJobPathRequired, job.Name)
+		}
+		if job.Source.Server() {
+			return fmt.Errorf("%w => %s", errJobServerSource, job.Name)
+		}
+	}
+	return nil
+}
+
+// runJobs run all the sync jobs concurrently until all of them are stopped,
+// the logger, the event log and the reporter are shared by the jobs
+func runJobs(c conf.Config, userList []*auth.User, certUserList []*auth.User, store auth.UserStore, eventWriter io.Writer, reporter report.Reporter, limiter auth.LoginLimiter, auditLog audit.AuditLog, reloader *configReloader, result result.Result, logger *logger.Logger) (err error) {
+	var jobs []*syncJob
+	defer func() {
+		for _, job := range jobs {
+			job.close()
+		}
+	}()
+	for _, job := range c.Jobs {
+		sj, err := initJob(job.Name, c.JobConfig(job), userList, certUserList, store, eventWriter, reporter, limiter, auditLog, logger)
+		if err != nil {
+			result.InitDoneWithError(err)
+			return err
+		}
+		jobs = append(jobs, sj)
+		reloader.addTarget(sj.name, sj.pi, sj.m)
+	}
+
+	logger.Info("%d sync jobs are starting...", len(jobs))
+	defer logger.Info("gofs exited")
+	ns, ss := signal.NotifyWithReload(func() error {
+		var errs []error
+		for _, job := range jobs {
+			errs = append(errs, job.m.Shutdown())
+		}
+		return errors.Join(errs...)
+	}, reloader.signalReload(), logger)
+	go func() {
+		result.RegisterNotifyHandler(ns)
+	}()
+	defer ss()
+
+	// start the monitors concurrently, the init is done after all the monitors are started
+	waits := make([]wait.Wait, len(jobs))
+	errs := make([]error, len(jobs))
+	wg := gosync.WaitGroup{}
+	for i, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			waits[i], errs[i] = job.start(reporter, logger)
+		}()
+	}
+	wg.Wait()
+	err = errors.Join(errs...)
+	result.InitDoneWithError(err)
+	if err != nil {
+		// shut down the started jobs if any job is failed to start
+		for i, job := range jobs {
+			if errs[i] == nil {
+				logger.ErrorIf(job.m.Shutdown(), "shutdown the sync job error => [%s]", job.name)
+			}
+		}
+	}
+
+	for i, job := range jobs {
+		if errs[i] != nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = job.wait(waits[i], reporter, logger)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// initJob init the ignore component, the event journal, the retry component and the monitor of the sync job
+func initJob(name string, c conf.Config, userList []*auth.User, certUserList []*auth.User, store auth.UserStore, eventWriter io.Writer, reporter report.Reporter, limiter auth.LoginLimiter, auditLog audit.AuditLog, logger *logger.Logger) (*syncJob, error) {
+	r := retry.New(c.RetryCount, c.RetryWait.Duration(), c.RetryAsync, logger)
+	pi, err := ignore.NewPathIgnore(c.IgnoreConf, c.IgnoreDeletedPath, logger)
+	if err != nil {
+		logger.Error(err, "init ignore config error => [%s]", name)
+		return nil, err
+	}
+	j, err := initJournal(c, logger)
+	if err != nil {
+		return nil, err
+	}
+	m, err := initMonitor(c, userList, certUserList, store, eventWriter, j, r, pi, report.WithJob(reporter, name), limiter, auditLog, logger)
+	if err != nil {
+		logger.ErrorIf(j.Close(), "close the event journal error => [%s]", name)
+		return nil, fmt.Errorf("init the sync job [%s] error => %w", name, err)
+	}
+	return &syncJob{
+		name: name,
+		c:    c,
+		pi:   pi,
+		m:    m,
+		close: func() {
+			logger.ErrorIf(m.Close(), "close the monitor error => [%s]", name)
+			logger.ErrorIf(j.Close(), "close the event journal error => [%s]", name)
+		},
+	}, nil
+}
+
+// start start the monitor of the sync job, the status of the job is reported
+func (job *syncJob) start(reporter report.Reporter, logger *logger.Logger) (wait.Wait, error) {
+	reporter.PutJob(job.name, job.c.Source.String(), job.c.Dest.String())
+	logger.Info("sync job [%s] is starting => [%s] -> [%s]", job.name, job.c.Source.String(), job.c.Dest.String())
+	w, err := job.m.Start()
+	return w, job.failed(err, reporter, logger)
+}
+
+// wait wait for the monitor of the sync job to stop, the status of the job is reported
+func (job *syncJob) wait(w wait.Wait, reporter report.Reporter, logger *logger.Logger) error {
+	err := w.Wait()
+	if err == nil {
+		logger.Info("sync job [%s] is stopped", job.name)
+		reporter.PutJobStatus(job.name, report.JobStatusStopped, nil)
+	}
+	return job.failed(err, reporter, logger)
+}
+
+// failed report the failed status of the sync job if the error is not nil
+func (job *syncJob) failed(err error, reporter report.Reporter, logger *logger.Logger) error {
+	if err == nil {
+		return nil
+	}
+	err = fmt.Errorf("sync job [%s] failed => %w", job.name, err)
+	logger.Error(err, "sync job running failed")
+	reporter.PutJobStatus(job.name, report.JobStatusFailed, err)
+	return err
+}
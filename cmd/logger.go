@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"path/filepath"
+
+	"github.com/no-src/gofs/audit"
 	"github.com/no-src/gofs/conf"
 	"github.com/no-src/gofs/logger"
 	"github.com/no-src/log"
@@ -17,7 +20,7 @@ const (
 	debugLogLevel = level.DebugLevel
 )
 
-// initDefaultLogger init the default logger
+// initDefaultLogger init the default logger, the log level can be changed by reloading the config
 func initDefaultLogger(c conf.Config) (*logger.Logger, error) {
 	// init log formatter
 	if c.LogFormat != formatter.TextFormatter {
@@ -25,13 +28,13 @@ func initDefaultLogger(c conf.Config) (*logger.Logger, error) {
 	}
 
 	var loggers []log.Logger
-	loggers = append(loggers, log.NewConsoleLogger(level.Level(c.LogLevel)))
+	loggers = append(loggers, log.NewConsoleLogger(debugLogLevel))
 	if c.EnableFileLogger {
 		filePrefix := "gofs_"
 		if c.IsDaemon {
 			filePrefix += "daemon_"
 		}
-		flogger, err := log.NewFileLoggerWithOption(option.NewFileLoggerOption(level.Level(c.LogLevel), c.LogDir, filePrefix, c.LogFlush, c.LogFlushInterval.Duration(), c.LogSplitDate))
+		flogger, err := log.NewFileLoggerWithOption(option.NewFileLoggerOption(debugLogLevel, c.LogDir, filePrefix, c.LogFlush, c.LogFlushInterval.Duration(), c.LogSplitDate))
 		if err != nil {
 			innerLogger.Error(err, "init file logger error")
 			return nil, err
@@ -40,21 +43,21 @@ func initDefaultLogger(c conf.Config) (*logger.Logger, error) {
 	}
 
 	defaultLogger := log.NewMultiLogger(loggers...).WithFormatter(formatter.New(c.LogFormat))
-	return logger.NewLogger(defaultLogger, log.NewDefaultSampleLogger(defaultLogger, c.LogSampleRate)), nil
+	return logger.NewLevelLogger(defaultLogger, level.Level(c.LogLevel), c.LogSampleRate), nil
 }
 
-// initWebServerLogger init the web server logger
+// initWebServerLogger init the web server logger, the log level can be changed by reloading the config
 func initWebServerLogger(c conf.Config) (*logger.Logger, error) {
-	var webLogger = log.NewConsoleLogger(level.Level(c.LogLevel))
+	var webLogger = log.NewConsoleLogger(debugLogLevel)
 	if c.EnableFileLogger && c.EnableFileServer {
-		webFileLogger, err := log.NewFileLoggerWithOption(option.NewFileLoggerOption(level.Level(c.LogLevel), c.LogDir, "web_", c.LogFlush, c.LogFlushInterval.Duration(), c.LogSplitDate))
+		webFileLogger, err := log.NewFileLoggerWithOption(option.NewFileLoggerOption(debugLogLevel, c.LogDir, "web_", c.LogFlush, c.LogFlushInterval.Duration(), c.LogSplitDate))
 		if err != nil {
 			innerLogger.Error(err, "init the web server file logger error")
 			return nil, err
 		}
 		webLogger = log.NewMultiLogger(webFileLogger, webLogger).WithFormatter(formatter.New(c.LogFormat))
 	}
-	return logger.NewLogger(webLogger, log.NewDefaultSampleLogger(webLogger, c.LogSampleRate)), nil
+	return logger.NewLevelLogger(webLogger, level.Level(c.LogLevel), c.LogSampleRate), nil
 }
 
 // initEventLogger init the event logger
@@ -70,3 +73,24 @@ func initEventLogger(c conf.Config) (log.Logger, error) {
 	}
 	return eventLogger, nil
 }
+
+// initAuditLog init the audit log
+func initAuditLog(c conf.Config) (audit.AuditLog, error) {
+	if !c.EnableAuditLog {
+		return audit.NewEmptyAuditLog(), nil
+	}
+	file := c.AuditLogFile
+	if len(file) == 0 {
+		file = filepath.Join(c.LogDir, "audit.log")
+	}
+	auditLog, err := audit.NewAuditLog(audit.Option{
+		File:       file,
+		MaxSize:    c.AuditLogMaxSize.Bytes(),
+		MaxBackups: c.AuditLogMaxBackups,
+		Syslog:     c.AuditSyslog,
+	})
+	if err != nil {
+		innerLogger.Error(err, "init the audit log error")
+	}
+	return auditLog, err
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/conf"
+	"github.com/no-src/gofs/core"
+	"github.com/no-src/gofs/driver"
+	"github.com/no-src/gofs/driver/minio"
+	"github.com/no-src/gofs/driver/sftp"
+	"github.com/no-src/gofs/encrypt"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/retry"
+)
+
+var errRekeyUserIsRequired = errors.New("user is required to rekey the MinIO destination")
+
+// rekey rotate the encryption key of the files in the destination, support the local disk, SFTP and MinIO
+func rekey(c conf.Config, logger *logger.Logger) error {
+	fsys, root, err := newRekeyFS(c, logger)
+	if err != nil {
+		return err
+	}
+	rk, err := encrypt.NewRekey(encrypt.NewOption(c, logger), fsys, root, c.RekeyProgress)
+	if err != nil {
+		return err
+	}
+	return rk.Rekey()
+}
+
+// newRekeyFS create the RekeyFS of the destination and return the root path of the encrypted files in it
+func newRekeyFS(c conf.Config, logger *logger.Logger) (fsys encrypt.RekeyFS, root string, err error) {
+	dest := c.Dest
+	var d driver.Driver
+	r := retry.New(c.RetryCount, c.RetryWait.Duration(), c.RetryAsync, logger)
+	switch {
+	case dest.Is(core.SFTP):
+		d = sftp.NewSFTPDriver(dest.Addr(), dest.SSHConfig(), true, r, c.MaxTranRate.Bytes(), logger)
+		root = dest.RemotePath().Base()
+	case dest.Is(core.MinIO):
+		users, err := auth.ParseUsers(c.Users)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(users) == 0 {
+			return nil, "", errRekeyUserIsRequired
+		}
+		d = minio.NewMinIODriver(dest.Addr(), dest.RemotePath().Bucket(), dest.Secure(), users[0].UserName(), users[0].Password(), true, r, c.MaxTranRate.Bytes(), logger)
+		// the object keys of the MinIO have no leading slash
+		root = strings.TrimPrefix(dest.RemotePath().Base(), "/")
+	default:
+		return encrypt.NewLocalRekeyFS(), filepath.ToSlash(dest.Path().Base()), nil
+	}
+	return d, root, d.Connect()
+}
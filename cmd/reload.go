@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	gosync "sync"
+
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/conf"
+	"github.com/no-src/gofs/ignore"
+	"github.com/no-src/gofs/internal/rate"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/monitor"
+	"github.com/no-src/gofs/reload"
+	"github.com/no-src/log/level"
+)
+
+var errReloadWithoutConf = errors.New("the config can be reloaded only if the config file is specified by the -conf flag")
+
+// liveFields the fields of the config that can be applied live by reloading the config
+var liveFields = map[string]bool{
+	"ignore_conf":       true,
+	"ignore_deleted":    true,
+	"max_tran_rate":     true,
+	"users":             true,
+	"user_paths":        true,
+	"log_level":         true,
+	"sync_delay":        true,
+	"sync_delay_events": true,
+	"sync_delay_time":   true,
+}
+
+// configReloader re-parse the config file and apply the changes that can be applied live
+type configReloader struct {
+	flagConf    conf.Config
+	c           conf.Config
+	randomUsers string
+	fileServer  bool
+	serverUsers bool
+	users       []*auth.User
+	certUsers   []*auth.User
+	store       auth.UserStore
+	loggers     []*logger.Logger
+	targets     []reloadTarget
+	mu          gosync.Mutex
+	logger      *logger.Logger
+}
+
+// reloadTarget the ignore component and the monitor of the top-level source and dest or a sync job
+type reloadTarget struct {
+	job string
+	pi  ignore.PathIgnore
+	m   monitor.Monitor
+}
+
+// newConfigReloader create an instance of the configReloader,
+// flagConf is the config parsed from the flags, the config file is parsed on it when reloading,
+// c is the running config before initializing the default values, and fc is the final config after that
+func newConfigReloader(flagConf, c, fc conf.Config, users []*auth.User, certUsers []*auth.User, store auth.UserStore, logger *logger.Logger, loggers ...*logger.Logger) *configReloader {
+	var randomUsers string
+	if fc.Users != c.Users {
+		randomUsers = strings.TrimPrefix(strings.TrimPrefix(fc.Users, c.Users), ",")
+	}
+	return &configReloader{
+		flagConf:    flagConf,
+		c:           c,
+		randomUsers: randomUsers,
+		fileServer:  fc.EnableFileServer,
+		serverUsers: store != nil && isServerUsers(fc),
+		users:       users,
+		certUsers:   certUsers,
+		store:       store,
+		loggers:     append(loggers, logger),
+		logger:      logger,
+	}
+}
+
+// addTarget add the ignore component and the monitor of the top-level source and dest or a sync job,
+// the job is empty for the top-level source and dest
+func (r *configReloader) addTarget(job string, pi ignore.PathIgnore, m monitor.Monitor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.targets = append(r.targets, reloadTarget{job: job, pi: pi, m: m})
+}
+
+// reload reload the config and ignore the result
+func (r *configReloader) reload() error {
+	_, err := r.Reload()
+	return err
+}
+
+// signalReload return the reload function of the SIGHUP signal, return nil if the config file is not specified,
+// then the SIGHUP signal shuts down gofs like before
+func (r *configReloader) signalReload() func() error {
+	if len(r.flagConf.Conf) == 0 {
+		return nil
+	}
+	return r.reload
+}
+
+func (r *configReloader) Reload() (result reload.Result, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result = reload.Result{Applied: []string{}, RequireRestart: []string{}}
+	if len(r.flagConf.Conf) == 0 {
+		return result, errReloadWithoutConf
+	}
+	nc := r.flagConf
+	if err = conf.Parse(nc.Conf, &nc); err != nil {
+		return result, err
+	}
+	prepareConfig(&nc)
+
+	// check all the changes before applying them, nothing is applied if any of them is invalid
+	users, err := r.parseUsers(nc)
+	if err != nil {
+		return result, err
+	}
+	if err = r.checkIgnore(nc); err != nil {
+		return result, err
+	}
+
+	for _, field := range r.c.Diff(nc) {
+		if r.requireRestart(field, nc, users) {
+			result.RequireRestart = append(result.RequireRestart, field)
+		} else {
+			result.Applied = append(result.Applied, field)
+		}
+	}
+
+	r.apply(nc, users, result)
+	r.c.Update(nc, result.Applied...)
+	r.logger.Info("reload the config success, applied => %v", result.Applied)
+	if len(result.RequireRestart) > 0 {
+		r.logger.Warn("the changes of the fields require a restart to take effect => %v", result.RequireRestart)
+	}
+	return result, nil
+}
+
+// parseUsers parse the users and the path permissions of them, the random users are kept
+func (r *configReloader) parseUsers(nc conf.Config) ([]*auth.User, error) {
+	userStr := nc.Users
+	if len(r.randomUsers) > 0 {
+		if len(userStr) > 0 {
+			userStr += ","
+		}
+		userStr += r.randomUsers
+	}
+	users, err := auth.ParseUsers(userStr)
+	if err != nil {
+		return nil, err
+	}
+	userPaths, err := auth.ParseUserPaths(nc.UserPaths)
+	if err != nil {
+		return nil, err
+	}
+	auth.ApplyUserPaths(users, userPaths)
+	return users, nil
+}
+
+// checkIgnore check the ignore config files of all the targets are valid or not
+func (r *configReloader) checkIgnore(nc conf.Config) error {
+	for _, t := range r.targets {
+		if tc, ok := r.targetConfig(t, nc); ok {
+			if _, err := ignore.NewPathIgnore(tc.IgnoreConf, tc.IgnoreDeletedPath, r.logger); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// requireRestart return true if the change of the field can't be applied live
+func (r *configReloader) requireRestart(field string, nc conf.Config, users []*auth.User) bool {
+	switch field {
+	case "users", "user_paths":
+		// the users are used to log in to the remote server, or the path permissions of the client certificate users are changed
+		if !r.serverUsers || (field == "user_paths" && len(r.certUsers) > 0) {
+			return true
+		}
+		// switching between the anonymous access and the authentication requires a restart
+		return len(r.certUsers) == 0 && r.hasUsers(r.users) != r.hasUsers(users)
+	case "max_tran_rate":
+		// the file server that is started without the rate limit can't enable it live
+		return r.fileServer && r.c.MaxTranRate.Bytes() <= 0 && nc.MaxTranRate.Bytes() > 0
+	case "jobs":
+		// only the ignore rules of the jobs can be applied live
+		return !reflect.DeepEqual(jobsWithoutIgnore(r.c.Jobs), jobsWithoutIgnore(nc.Jobs))
+	}
+	return !liveFields[field]
+}
+
+// hasUsers return true if there are any users in the user store after replacing the specified users
+func (r *configReloader) hasUsers(users []*auth.User) bool {
+	return len(users) > 0 || len(r.store.Users()) > len(r.users)
+}
+
+// apply apply the live settings to the components, the ignore rules are always reloaded from the ignore config files
+func (r *configReloader) apply(nc conf.Config, users []*auth.User, result reload.Result) {
+	for _, l := range r.loggers {
+		r.logger.ErrorIf(l.SetLevel(level.Level(nc.LogLevel)), "reload the log level error")
+	}
+	// the file server keeps the old rate limit if the change requires a restart, so the sync must keep it too
+	if !slices.Contains(result.RequireRestart, "max_tran_rate") {
+		rate.Reload(nc.MaxTranRate.Bytes())
+	}
+	if r.serverUsers && !slices.Contains(result.RequireRestart, "users") && !slices.Contains(result.RequireRestart, "user_paths") {
+		r.store.SetUsers(users)
+		r.users = users
+	}
+	for _, t := range r.targets {
+		tc, ok := r.targetConfig(t, nc)
+		if !ok {
+			continue
+		}
+		r.logger.ErrorIf(t.pi.Reload(tc.IgnoreConf, tc.IgnoreDeletedPath), "reload the ignore rules error => [%s]", tc.IgnoreConf)
+		t.m.ReloadSyncDelay(tc.EnableSyncDelay, tc.SyncDelayEvents, tc.SyncDelayTime.Duration())
+	}
+}
+
+// targetConfig return the config of the target, return false if the sync job of the target is not found
+func (r *configReloader) targetConfig(t reloadTarget, nc conf.Config) (conf.Config, bool) {
+	if len(t.job) == 0 {
+		return nc, true
+	}
+	for _, job := range nc.Jobs {
+		if job.Name == t.job {
+			return nc.JobConfig(job), true
+		}
+	}
+	return nc, false
+}
+
+// isServerUsers return true if the users are only used by the servers, not used to log in to the remote servers
+func isServerUsers(c conf.Config) bool {
+	if !(c.EnableFileServer || c.Source.Server()) || c.EnableTaskClient {
+		return false
+	}
+	paths := []conf.SyncJob{{Source: c.Source, Dest: c.Dest}}
+	if len(c.Jobs) > 0 {
+		paths = c.Jobs
+	}
+	for _, p := range paths {
+		if !(p.Source.IsDisk() || p.Source.Server()) || !p.Dest.IsDisk() {
+			return false
+		}
+	}
+	return true
+}
+
+// jobsWithoutIgnore return the copy of the sync jobs without the ignore fields
+func jobsWithoutIgnore(jobs []conf.SyncJob) []conf.SyncJob {
+	var result []conf.SyncJob
+	for _, job := range jobs {
+		job.IgnoreConf = ""
+		job.IgnoreDeletedPath = false
+		result = append(result, job)
+	}
+	return result
+}
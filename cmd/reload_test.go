@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/no-src/gofs/conf"
+	"github.com/no-src/gofs/internal/rate"
+	"github.com/no-src/gofs/logger"
+)
+
+func TestConfigReloader_MaxTranRate(t *testing.T) {
+	testCases := []struct {
+		name          string
+		fileServer    bool
+		expectRestart bool
+	}{
+		{"without file server", false, false},
+		{"file server without rate limit", true, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer rate.Reload(0)
+			confFile := filepath.Join(t.TempDir(), "gofs.yaml")
+			writeTestConf(t, confFile, "max_tran_rate: 0\n")
+			flagConf := conf.Config{Conf: confFile}
+			c := flagConf
+			if err := conf.Parse(confFile, &c); err != nil {
+				t.Fatalf("parse the config error => %v", err)
+			}
+			fc := c
+			fc.EnableFileServer = tc.fileServer
+			r := newConfigReloader(flagConf, c, fc, nil, nil, nil, logger.NewTestLogger())
+
+			writeTestConf(t, confFile, "max_tran_rate: 1MiB\n")
+			result, err := r.Reload()
+			if err != nil {
+				t.Fatalf("reload the config error => %v", err)
+			}
+			if actual := slices.Contains(result.RequireRestart, "max_tran_rate"); actual != tc.expectRestart {
+				t.Errorf("expect max_tran_rate requires a restart %v, but get %v => %+v", tc.expectRestart, actual, result)
+			}
+			// the reader is not limited if the rate limit is not applied
+			origin := bytes.NewReader(nil)
+			if applied := rate.NewReader(origin, 0, logger.NewTestLogger()) != origin; applied == tc.expectRestart {
+				t.Errorf("expect the rate limit is applied %v, but get %v", !tc.expectRestart, applied)
+			}
+		})
+	}
+}
+
+func writeTestConf(t *testing.T, name string, content string) {
+	if err := os.WriteFile(name, []byte(content), 0600); err != nil {
+		t.Fatalf("write the config file error => %v", err)
+	}
+}
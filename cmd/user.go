@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/core"
+	"github.com/no-src/gofs/result"
+)
+
+const (
+	userCommand       = "user"
+	userAddAction     = "add"
+	userPasswdAction  = "passwd"
+	userRemoveAction  = "remove"
+	userCommandFormat = "usage: %s user add|passwd|remove -users_file=<path> -username=<name> [-password=<password>] [-perm=<perm>] [-algorithm=<algorithm>]"
+)
+
+var (
+	errUserExists    = errors.New("the user already exists")
+	errUserNotExists = errors.New("the user does not exist")
+)
+
+// isUserCommand whether the arguments are the user command, like gofs user add ...
+func isUserCommand(args []string) bool {
+	return len(args) > 1 && args[1] == userCommand
+}
+
+// runUserCommand manage the users in the users file, the password is read from the stdin if the -password flag is empty
+func runUserCommand(args []string, stdin io.Reader) result.Result {
+	r := result.New()
+	err := manageUser(args, stdin)
+	if err != nil {
+		innerLogger.Error(err, "execute the user command error")
+	}
+	r.InitDoneWithError(err)
+	r.DoneWithError(err)
+	return r
+}
+
+func manageUser(args []string, stdin io.Reader) (err error) {
+	if len(args) < 3 {
+		return fmt.Errorf(userCommandFormat, args[0])
+	}
+	act := args[2]
+	var (
+		usersFile string
+		userName  string
+		password  string
+		perm      string
+		algorithm string
+	)
+	cl := core.NewFlagSet(args[0]+" "+userCommand+" "+act, flag.ContinueOnError)
+	cl.StringVar(&usersFile, "users_file", "", "the path of the users file, the format is detected by the file extension, support .yaml, .yml, .json and htpasswd format")
+	cl.StringVar(&userName, "username", "", "the username of the user")
+	cl.StringVar(&password, "password", "", "the password of the user, read it from the stdin if it is empty")
+	cl.StringVar(&perm, "perm", auth.DefaultPerm, "the permission of the user, like 'rwx'")
+	cl.StringVar(&algorithm, "algorithm", auth.DefaultPasswordAlgorithm, "the password hash algorithm, current supported algorithms: bcrypt, argon2id")
+	if err = cl.Parse(args[3:]); err != nil {
+		return err
+	}
+	if len(usersFile) == 0 || len(userName) == 0 {
+		return fmt.Errorf(userCommandFormat, args[0])
+	}
+
+	users, err := auth.LoadUsersFile(usersFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	index := -1
+	for i, user := range users {
+		if user.UserName() == userName {
+			index = i
+		}
+	}
+
+	switch act {
+	case userAddAction, userPasswdAction:
+		if act == userAddAction && index >= 0 {
+			return fmt.Errorf("%w => %s", errUserExists, userName)
+		}
+		if act == userPasswdAction && index < 0 {
+			return fmt.Errorf("%w => %s", errUserNotExists, userName)
+		}
+		if len(password) == 0 {
+			if password, err = readPassword(stdin); err != nil {
+				return err
+			}
+		}
+		hash, err := auth.HashPassword(password, algorithm)
+		if err != nil {
+			return err
+		}
+		if act == userPasswdAction {
+			perm = users[index].Perm().String()
+		}
+		user, err := auth.NewHashedUser(len(users)+1, userName, hash, perm)
+		if err != nil {
+			return err
+		}
+		if act == userPasswdAction {
+			// keep the path permissions of the user
+			auth.ApplyUserPaths([]*auth.User{user}, map[string][]auth.PathPerm{userName: users[index].Paths()})
+		}
+		if index >= 0 {
+			users[index] = user
+		} else {
+			users = append(users, user)
+		}
+	case userRemoveAction:
+		if index < 0 {
+			return fmt.Errorf("%w => %s", errUserNotExists, userName)
+		}
+		users = append(users[:index], users[index+1:]...)
+	default:
+		return fmt.Errorf(userCommandFormat, args[0])
+	}
+	if err = auth.SaveUsersFile(usersFile, users); err == nil {
+		innerLogger.Info("execute the user command [%s] success => [%s] [%s]", act, userName, usersFile)
+	}
+	return err
+}
+
+// readPassword read the first line from the stdin as the password
+func readPassword(stdin io.Reader) (string, error) {
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	password := strings.TrimRight(line, "\r\n")
+	if len(password) == 0 {
+		return "", errors.New("the password can't be empty")
+	}
+	return password, nil
+}
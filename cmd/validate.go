@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	authapi "github.com/no-src/gofs/api/auth"
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/conf"
+	"github.com/no-src/gofs/encrypt"
+	"github.com/no-src/gofs/ignore"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/monitor"
+	"github.com/no-src/gofs/sync"
+)
+
+var (
+	errInvalidConfig  = errors.New("the config is invalid")
+	errSourceRequired = errors.New("the source is required")
+)
+
+// problems the problems found in the config
+type problems []error
+
+// add add the error with the prefix if it is not nil, the joined errors are added one by one
+func (p *problems) add(prefix string, err error) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range joined.Unwrap() {
+			p.add(prefix, e)
+		}
+	} else if err != nil {
+		*p = append(*p, fmt.Errorf("%s%w", prefix, err))
+	}
+}
+
+// validateConfig check the config without starting any component and print all the problems at once,
+// return an error if there are any problems
+func validateConfig(c conf.Config, logger *logger.Logger) error {
+	p := checkConfig(c, logger)
+	if len(p) == 0 {
+		logger.Log("the config is valid => [%s]", c.Conf)
+		return nil
+	}
+	logger.Log("the config is invalid, %d problem(s) found => [%s]", len(p), c.Conf)
+	for i, err := range p {
+		logger.Log("  %d. %s", i+1, err.Error())
+	}
+	return fmt.Errorf("%w, %d problem(s) found", errInvalidConfig, len(p))
+}
+
+// checkConfig check the config like the startup does, but nothing is created or started
+func checkConfig(c conf.Config, logger *logger.Logger) (p problems) {
+	initFileServer(&c)
+	p.add("", checkTLS(c))
+	p.add("", checkMutualTLS(c))
+	p.add("", checkJobs(c))
+	if c.Source.Server() {
+		p.add("", authapi.ValidateTokenOption(authapi.TokenOption{
+			Algorithm: c.TokenAlgorithm,
+			Secret:    c.TokenSecret,
+			KeyFile:   c.TokenKeyFile,
+		}))
+	}
+
+	users, err := auth.ParseUsers(c.Users)
+	p.add("users: ", err)
+	if len(c.UsersFile) > 0 && (c.EnableFileServer || c.Source.Server()) {
+		_, err = auth.LoadUsersFile(c.UsersFile)
+		p.add(fmt.Sprintf("users_file [%s]: ", c.UsersFile), err)
+	}
+	_, err = auth.ParseCertUsers(c.TLSClientCertUsers)
+	p.add("tls_client_cert_users: ", err)
+	_, err = auth.ParseUserPaths(c.UserPaths)
+	p.add("user_paths: ", err)
+
+	if len(c.Jobs) == 0 {
+		p.add("", errors.Join(checkSyncConfig(c, users, logger)...))
+	}
+	for _, job := range c.Jobs {
+		p.add(fmt.Sprintf("sync job [%s]: ", job.Name), errors.Join(checkSyncConfig(c.JobConfig(job), users, logger)...))
+	}
+	return p
+}
+
+// checkMutualTLS check the CA bundle file of the servers and the client certificate files of the mutual TLS authentication
+func checkMutualTLS(c conf.Config) error {
+	var errs []error
+	if c.EnableTLS && (c.Source.Server() || c.EnableFileServer) && len(c.TLSClientCAFile) > 0 {
+		errs = append(errs, checkFileExist(c.TLSClientCAFile, "client CA file is not found for tls => [%s], for more information, see -tls_client_ca_file flag"))
+	}
+	if len(c.TLSClientCertFile) > 0 || len(c.TLSClientKeyFile) > 0 {
+		errs = append(errs,
+			checkFileExist(c.TLSClientCertFile, "client cert file is not found for tls => [%s], for more information, see -tls_client_cert_file flag"),
+			checkFileExist(c.TLSClientKeyFile, "client key file is not found for tls => [%s], for more information, see -tls_client_key_file flag"),
+		)
+	}
+	return errors.Join(errs...)
+}
+
+// checkSyncConfig check the source, dest, sync cron, ignore config and encryption of a pair of source and dest
+func checkSyncConfig(c conf.Config, users []*auth.User, logger *logger.Logger) (p problems) {
+	source, dest := c.Source, c.Dest
+	p.add("source: ", source.Err())
+	p.add("dest: ", dest.Err())
+	if source.IsEmpty() && source.Err() == nil {
+		p.add("source: ", errSourceRequired)
+	}
+	if !source.IsEmpty() && dest.Err() == nil {
+		p.add("", sync.Validate(source, dest, c.ChunkSize.Bytes(), users))
+		p.add("", monitor.Validate(source, c.EnableTaskClient, c.SyncCron))
+	}
+	p.add("sync_cron: ", monitor.ValidateCron(c.SyncCron))
+
+	_, err := ignore.NewPathIgnore(c.IgnoreConf, c.IgnoreDeletedPath, logger)
+	p.add(fmt.Sprintf("ignore_conf [%s]: ", c.IgnoreConf), err)
+
+	if c.Encrypt {
+		sourceAbsPath, err := source.Abs()
+		if err == nil {
+			err = encrypt.NewOption(c, logger).Validate(sourceAbsPath)
+		}
+		p.add("encrypt: ", err)
+	}
+	return p
+}
@@ -12,105 +12,167 @@ import (
 // Config store all the flag info
 type Config struct {
 	// other
-	PrintVersion bool   `json:"-" yaml:"-"`
-	PrintAbout   bool   `json:"-" yaml:"-"`
-	Conf         string `json:"-" yaml:"-"`
+	PrintVersion bool   `json:"-" yaml:"-" toml:"-"`
+	PrintAbout   bool   `json:"-" yaml:"-" toml:"-"`
+	Validate     bool   `json:"-" yaml:"-" toml:"-"`
+	PrintSchema  bool   `json:"-" yaml:"-" toml:"-"`
+	Conf         string `json:"-" yaml:"-" toml:"-"`
 
 	// file sync
-	Source                core.VFS  `json:"source" yaml:"source"`
-	Dest                  core.VFS  `json:"dest" yaml:"dest"`
-	SyncOnce              bool      `json:"sync_once" yaml:"sync_once"`
-	SyncCron              string    `json:"sync_cron" yaml:"sync_cron"`
-	EnableLogicallyDelete bool      `json:"logically_delete" yaml:"logically_delete"`
-	ClearDeletedPath      bool      `json:"clear_deleted" yaml:"clear_deleted"`
-	IgnoreConf            string    `json:"ignore_conf" yaml:"ignore_conf"`
-	IgnoreDeletedPath     bool      `json:"ignore_deleted" yaml:"ignore_deleted"`
-	ChunkSize             core.Size `json:"chunk_size" yaml:"chunk_size"`
-	CheckpointCount       int       `json:"checkpoint_count" yaml:"checkpoint_count"`
-	ForceChecksum         bool      `json:"force_checksum" yaml:"force_checksum"`
-	ChecksumAlgorithm     string    `json:"checksum_algorithm" yaml:"checksum_algorithm"`
-	Progress              bool      `json:"progress" yaml:"progress"`
-	MaxTranRate           core.Size `json:"max_tran_rate" yaml:"max_tran_rate"`
-	DryRun                bool      `json:"dry_run" yaml:"dry_run"`
-	CopyLink              bool      `json:"copy_link" yaml:"copy_link"`
-	CopyUnsafeLink        bool      `json:"copy_unsafe_link" yaml:"copy_unsafe_link"`
+	Source                core.VFS  `json:"source" yaml:"source" toml:"source"`
+	Dest                  core.VFS  `json:"dest" yaml:"dest" toml:"dest"`
+	SyncOnce              bool      `json:"sync_once" yaml:"sync_once" toml:"sync_once"`
+	SyncCron              string    `json:"sync_cron" yaml:"sync_cron" toml:"sync_cron"`
+	EnableLogicallyDelete bool      `json:"logically_delete" yaml:"logically_delete" toml:"logically_delete"`
+	ClearDeletedPath      bool      `json:"clear_deleted" yaml:"clear_deleted" toml:"clear_deleted"`
+	IgnoreConf            string    `json:"ignore_conf" yaml:"ignore_conf" toml:"ignore_conf"`
+	IgnoreDeletedPath     bool      `json:"ignore_deleted" yaml:"ignore_deleted" toml:"ignore_deleted"`
+	ChunkSize             core.Size `json:"chunk_size" yaml:"chunk_size" toml:"chunk_size"`
+	CheckpointCount       int       `json:"checkpoint_count" yaml:"checkpoint_count" toml:"checkpoint_count"`
+	ForceChecksum         bool      `json:"force_checksum" yaml:"force_checksum" toml:"force_checksum"`
+	ChecksumAlgorithm     string    `json:"checksum_algorithm" yaml:"checksum_algorithm" toml:"checksum_algorithm"`
+	Progress              bool      `json:"progress" yaml:"progress" toml:"progress"`
+	MaxTranRate           core.Size `json:"max_tran_rate" yaml:"max_tran_rate" toml:"max_tran_rate"`
+	DryRun                bool      `json:"dry_run" yaml:"dry_run" toml:"dry_run"`
+	CopyLink              bool      `json:"copy_link" yaml:"copy_link" toml:"copy_link"`
+	CopyUnsafeLink        bool      `json:"copy_unsafe_link" yaml:"copy_unsafe_link" toml:"copy_unsafe_link"`
 
 	// file monitor
-	EnableSyncDelay bool          `json:"sync_delay" yaml:"sync_delay"`
-	SyncDelayEvents int           `json:"sync_delay_events" yaml:"sync_delay_events"`
-	SyncDelayTime   core.Duration `json:"sync_delay_time" yaml:"sync_delay_time"`
-	SyncWorkers     int           `json:"sync_workers" yaml:"sync_workers"`
+	EnableSyncDelay bool          `json:"sync_delay" yaml:"sync_delay" toml:"sync_delay"`
+	SyncDelayEvents int           `json:"sync_delay_events" yaml:"sync_delay_events" toml:"sync_delay_events"`
+	SyncDelayTime   core.Duration `json:"sync_delay_time" yaml:"sync_delay_time" toml:"sync_delay_time"`
+	SyncWorkers     int           `json:"sync_workers" yaml:"sync_workers" toml:"sync_workers"`
+	EnableJournal   bool          `json:"journal" yaml:"journal" toml:"journal"`
+	JournalDir      string        `json:"journal_dir" yaml:"journal_dir" toml:"journal_dir"`
+	MonitorLogSize  int           `json:"monitor_log_size" yaml:"monitor_log_size" toml:"monitor_log_size"`
 
 	// retry
-	RetryCount int           `json:"retry_count" yaml:"retry_count"`
-	RetryWait  core.Duration `json:"retry_wait" yaml:"retry_wait"`
-	RetryAsync bool          `json:"retry_async" yaml:"retry_async"`
+	RetryCount int           `json:"retry_count" yaml:"retry_count" toml:"retry_count"`
+	RetryWait  core.Duration `json:"retry_wait" yaml:"retry_wait" toml:"retry_wait"`
+	RetryAsync bool          `json:"retry_async" yaml:"retry_async" toml:"retry_async"`
 
 	// log
-	LogLevel         int           `json:"log_level" yaml:"log_level"`
-	EnableFileLogger bool          `json:"log_file" yaml:"log_file"`
-	LogDir           string        `json:"log_dir" yaml:"log_dir"`
-	LogFlush         bool          `json:"log_flush" yaml:"log_flush"`
-	LogFlushInterval core.Duration `json:"log_flush_interval" yaml:"log_flush_interval"`
-	EnableEventLog   bool          `json:"log_event" yaml:"log_event"`
-	LogSampleRate    float64       `json:"log_sample_rate" yaml:"log_sample_rate"`
-	LogFormat        string        `json:"log_format" yaml:"log_format"`
-	LogSplitDate     bool          `json:"log_split_date" yaml:"log_split_date"`
+	LogLevel         int           `json:"log_level" yaml:"log_level" toml:"log_level"`
+	EnableFileLogger bool          `json:"log_file" yaml:"log_file" toml:"log_file"`
+	LogDir           string        `json:"log_dir" yaml:"log_dir" toml:"log_dir"`
+	LogFlush         bool          `json:"log_flush" yaml:"log_flush" toml:"log_flush"`
+	LogFlushInterval core.Duration `json:"log_flush_interval" yaml:"log_flush_interval" toml:"log_flush_interval"`
+	EnableEventLog   bool          `json:"log_event" yaml:"log_event" toml:"log_event"`
+	LogSampleRate    float64       `json:"log_sample_rate" yaml:"log_sample_rate" toml:"log_sample_rate"`
+	LogFormat        string        `json:"log_format" yaml:"log_format" toml:"log_format"`
+	LogSplitDate     bool          `json:"log_split_date" yaml:"log_split_date" toml:"log_split_date"`
+
+	// audit log
+	EnableAuditLog     bool      `json:"audit_log" yaml:"audit_log" toml:"audit_log"`
+	AuditLogFile       string    `json:"audit_log_file" yaml:"audit_log_file" toml:"audit_log_file"`
+	AuditLogMaxSize    core.Size `json:"audit_log_max_size" yaml:"audit_log_max_size" toml:"audit_log_max_size"`
+	AuditLogMaxBackups int       `json:"audit_log_max_backups" yaml:"audit_log_max_backups" toml:"audit_log_max_backups"`
+	AuditSyslog        string    `json:"audit_syslog" yaml:"audit_syslog" toml:"audit_syslog"`
 
 	// daemon
-	IsDaemon           bool          `json:"daemon" yaml:"daemon"`
-	DaemonPid          bool          `json:"daemon_pid" yaml:"daemon_pid"`
-	DaemonDelay        core.Duration `json:"daemon_delay" yaml:"daemon_delay"`
-	DaemonMonitorDelay core.Duration `json:"daemon_monitor_delay" yaml:"daemon_monitor_delay"`
-	KillPPid           bool          `json:"kill_ppid" yaml:"kill_ppid"`
-	IsSubprocess       bool          `json:"sub" yaml:"sub"`
+	IsDaemon           bool          `json:"daemon" yaml:"daemon" toml:"daemon"`
+	DaemonPid          bool          `json:"daemon_pid" yaml:"daemon_pid" toml:"daemon_pid"`
+	DaemonDelay        core.Duration `json:"daemon_delay" yaml:"daemon_delay" toml:"daemon_delay"`
+	DaemonMonitorDelay core.Duration `json:"daemon_monitor_delay" yaml:"daemon_monitor_delay" toml:"daemon_monitor_delay"`
+	KillPPid           bool          `json:"kill_ppid" yaml:"kill_ppid" toml:"kill_ppid"`
+	IsSubprocess       bool          `json:"sub" yaml:"sub" toml:"sub"`
 
 	// file server
-	EnableFileServer         bool   `json:"server" yaml:"server"`
-	FileServerAddr           string `json:"server_addr" yaml:"server_addr"`
-	EnableFileServerCompress bool   `json:"server_compress" yaml:"server_compress"`
-	EnableManage             bool   `json:"manage" yaml:"manage"`
-	ManagePrivate            bool   `json:"manage_private" yaml:"manage_private"`
-	EnablePushServer         bool   `json:"push_server" yaml:"push_server"`
-	EnableReport             bool   `json:"report" yaml:"report"`
-	SessionConnection        string `json:"session_connection" yaml:"session_connection"`
+	EnableFileServer         bool   `json:"server" yaml:"server" toml:"server"`
+	FileServerAddr           string `json:"server_addr" yaml:"server_addr" toml:"server_addr"`
+	EnableFileServerCompress bool   `json:"server_compress" yaml:"server_compress" toml:"server_compress"`
+	EnableManage             bool   `json:"manage" yaml:"manage" toml:"manage"`
+	ManagePrivate            bool   `json:"manage_private" yaml:"manage_private" toml:"manage_private"`
+	EnablePushServer         bool   `json:"push_server" yaml:"push_server" toml:"push_server"`
+	EnableReport             bool   `json:"report" yaml:"report" toml:"report"`
+	ServerDecrypt            bool   `json:"server_decrypt" yaml:"server_decrypt" toml:"server_decrypt"`
+	SessionConnection        string `json:"session_connection" yaml:"session_connection" toml:"session_connection"`
+	TrustedProxies           string `json:"trusted_proxies" yaml:"trusted_proxies" toml:"trusted_proxies"`
 
 	// http protocol
-	EnableHTTP3 bool `json:"http3" yaml:"http3"`
+	EnableHTTP3 bool `json:"http3" yaml:"http3" toml:"http3"`
+
+	// grpc transfer
+	EnableGrpcTransfer bool `json:"grpc_transfer" yaml:"grpc_transfer" toml:"grpc_transfer"`
 
 	// tls transfer
-	EnableTLS             bool   `json:"tls" yaml:"tls"`
-	TLSCertFile           string `json:"tls_cert_file" yaml:"tls_cert_file"`
-	TLSKeyFile            string `json:"tls_key_file" yaml:"tls_key_file"`
-	TLSInsecureSkipVerify bool   `json:"tls_insecure_skip_verify" yaml:"tls_insecure_skip_verify"`
+	EnableTLS             bool   `json:"tls" yaml:"tls" toml:"tls"`
+	TLSCertFile           string `json:"tls_cert_file" yaml:"tls_cert_file" toml:"tls_cert_file"`
+	TLSKeyFile            string `json:"tls_key_file" yaml:"tls_key_file" toml:"tls_key_file"`
+	TLSInsecureSkipVerify bool   `json:"tls_insecure_skip_verify" yaml:"tls_insecure_skip_verify" toml:"tls_insecure_skip_verify"`
+	TLSClientCAFile       string `json:"tls_client_ca_file" yaml:"tls_client_ca_file" toml:"tls_client_ca_file"`
+	TLSClientCertUsers    string `json:"tls_client_cert_users" yaml:"tls_client_cert_users" toml:"tls_client_cert_users"`
+	TLSClientCertFile     string `json:"tls_client_cert_file" yaml:"tls_client_cert_file" toml:"tls_client_cert_file"`
+	TLSClientKeyFile      string `json:"tls_client_key_file" yaml:"tls_client_key_file" toml:"tls_client_key_file"`
 
 	// login user
-	Users             string `json:"users" yaml:"users"`
-	RandomUserCount   int    `json:"rand_user_count" yaml:"rand_user_count"`
-	RandomUserNameLen int    `json:"rand_user_len" yaml:"rand_user_len"`
-	RandomPasswordLen int    `json:"rand_pwd_len" yaml:"rand_pwd_len"`
-	RandomDefaultPerm string `json:"rand_perm" yaml:"rand_perm"`
-	TokenSecret       string `json:"token_secret" yaml:"token_secret"`
+	Users               string        `json:"users" yaml:"users" toml:"users"`
+	UsersFile           string        `json:"users_file" yaml:"users_file" toml:"users_file"`
+	UserPaths           string        `json:"user_paths" yaml:"user_paths" toml:"user_paths"`
+	RandomUserCount     int           `json:"rand_user_count" yaml:"rand_user_count" toml:"rand_user_count"`
+	RandomUserNameLen   int           `json:"rand_user_len" yaml:"rand_user_len" toml:"rand_user_len"`
+	RandomPasswordLen   int           `json:"rand_pwd_len" yaml:"rand_pwd_len" toml:"rand_pwd_len"`
+	RandomDefaultPerm   string        `json:"rand_perm" yaml:"rand_perm" toml:"rand_perm"`
+	TokenSecret         string        `json:"token_secret" yaml:"token_secret" toml:"token_secret"`
+	TokenAlgorithm      string        `json:"token_algorithm" yaml:"token_algorithm" toml:"token_algorithm"`
+	TokenKeyFile        string        `json:"token_key_file" yaml:"token_key_file" toml:"token_key_file"`
+	TokenExpires        core.Duration `json:"token_expires" yaml:"token_expires" toml:"token_expires"`
+	TokenRefreshExpires core.Duration `json:"token_refresh_expires" yaml:"token_refresh_expires" toml:"token_refresh_expires"`
+
+	// openid connect
+	OIDCIssuer       string `json:"oidc_issuer" yaml:"oidc_issuer" toml:"oidc_issuer"`
+	OIDCClientID     string `json:"oidc_client_id" yaml:"oidc_client_id" toml:"oidc_client_id"`
+	OIDCClientSecret string `json:"oidc_client_secret" yaml:"oidc_client_secret" toml:"oidc_client_secret"`
+	OIDCRedirectURL  string `json:"oidc_redirect_url" yaml:"oidc_redirect_url" toml:"oidc_redirect_url"`
+	OIDCScopes       string `json:"oidc_scopes" yaml:"oidc_scopes" toml:"oidc_scopes"`
+	OIDCUserClaim    string `json:"oidc_user_claim" yaml:"oidc_user_claim" toml:"oidc_user_claim"`
+	OIDCGroupsClaim  string `json:"oidc_groups_claim" yaml:"oidc_groups_claim" toml:"oidc_groups_claim"`
+	OIDCGroupPerms   string `json:"oidc_group_perms" yaml:"oidc_group_perms" toml:"oidc_group_perms"`
+	OIDCDefaultPerm  string `json:"oidc_default_perm" yaml:"oidc_default_perm" toml:"oidc_default_perm"`
+
+	// login lockout
+	LoginMaxAttempts         int           `json:"login_max_attempts" yaml:"login_max_attempts" toml:"login_max_attempts"`
+	LoginLockout             core.Duration `json:"login_lockout" yaml:"login_lockout" toml:"login_lockout"`
+	LoginMaxLockout          core.Duration `json:"login_max_lockout" yaml:"login_max_lockout" toml:"login_max_lockout"`
+	LoginLockoutSessionStore bool          `json:"login_lockout_session_store" yaml:"login_lockout_session_store" toml:"login_lockout_session_store"`
 
 	// checksum
-	Checksum bool `json:"checksum" yaml:"checksum"`
+	Checksum bool `json:"checksum" yaml:"checksum" toml:"checksum"`
 
 	// encrypt
-	Encrypt       bool   `json:"encrypt" yaml:"encrypt"`
-	EncryptPath   string `json:"encrypt_path" yaml:"encrypt_path"`
-	EncryptSecret string `json:"encrypt_secret" yaml:"encrypt_secret"`
+	Encrypt               bool   `json:"encrypt" yaml:"encrypt" toml:"encrypt"`
+	EncryptPath           string `json:"encrypt_path" yaml:"encrypt_path" toml:"encrypt_path"`
+	EncryptSecret         string `json:"encrypt_secret" yaml:"encrypt_secret" toml:"encrypt_secret"`
+	EncryptSecretFile     string `json:"encrypt_secret_file" yaml:"encrypt_secret_file" toml:"encrypt_secret_file"`
+	EncryptSecretEnv      string `json:"encrypt_secret_env" yaml:"encrypt_secret_env" toml:"encrypt_secret_env"`
+	EncryptFormat         string `json:"encrypt_format" yaml:"encrypt_format" toml:"encrypt_format"`
+	EncryptKDF            string `json:"encrypt_kdf" yaml:"encrypt_kdf" toml:"encrypt_kdf"`
+	EncryptName           bool   `json:"encrypt_name" yaml:"encrypt_name" toml:"encrypt_name"`
+	EncryptRecipients     string `json:"encrypt_recipients" yaml:"encrypt_recipients" toml:"encrypt_recipients"`
+	EncryptRecipientsFile string `json:"encrypt_recipients_file" yaml:"encrypt_recipients_file" toml:"encrypt_recipients_file"`
 
 	// decrypt
-	Decrypt       bool   `json:"decrypt" yaml:"decrypt"`
-	DecryptPath   string `json:"decrypt_path" yaml:"decrypt_path"`
-	DecryptSecret string `json:"decrypt_secret" yaml:"decrypt_secret"`
-	DecryptOut    string `json:"decrypt_out" yaml:"decrypt_out"`
+	Decrypt             bool   `json:"decrypt" yaml:"decrypt" toml:"decrypt"`
+	DecryptPath         string `json:"decrypt_path" yaml:"decrypt_path" toml:"decrypt_path"`
+	DecryptSecret       string `json:"decrypt_secret" yaml:"decrypt_secret" toml:"decrypt_secret"`
+	DecryptSecretFile   string `json:"decrypt_secret_file" yaml:"decrypt_secret_file" toml:"decrypt_secret_file"`
+	DecryptSecretEnv    string `json:"decrypt_secret_env" yaml:"decrypt_secret_env" toml:"decrypt_secret_env"`
+	DecryptOut          string `json:"decrypt_out" yaml:"decrypt_out" toml:"decrypt_out"`
+	DecryptIdentityFile string `json:"decrypt_identity_file" yaml:"decrypt_identity_file" toml:"decrypt_identity_file"`
+
+	// rekey
+	Rekey         bool   `json:"rekey" yaml:"rekey" toml:"rekey"`
+	RekeyProgress string `json:"rekey_progress" yaml:"rekey_progress" toml:"rekey_progress"`
+	RekeyFormat   string `json:"rekey_format" yaml:"rekey_format" toml:"rekey_format"`
+
+	// sync jobs
+	Jobs []SyncJob `json:"jobs,omitempty" yaml:"jobs,omitempty" toml:"jobs,omitempty"`
 
 	// task
-	TaskConf            string `json:"task_conf" yaml:"task_conf"`
-	EnableTaskClient    bool   `json:"task_client" yaml:"task_client"`
-	TaskClientLabels    string `json:"task_client_labels" yaml:"task_client_labels"`
-	TaskClientMaxWorker int    `json:"task_client_max_worker" yaml:"task_client_max_worker"`
+	TaskConf            string `json:"task_conf" yaml:"task_conf" toml:"task_conf"`
+	EnableTaskClient    bool   `json:"task_client" yaml:"task_client" toml:"task_client"`
+	TaskClientLabels    string `json:"task_client_labels" yaml:"task_client_labels" toml:"task_client_labels"`
+	TaskClientMaxWorker int    `json:"task_client_max_worker" yaml:"task_client_max_worker" toml:"task_client_max_worker"`
 }
 
 // ToArgs parse the Config to program arguments and the first argument is the current program name
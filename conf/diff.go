@@ -0,0 +1,39 @@
+package conf
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Diff return the json names of the fields that are different between the two configs,
+// the fields that are not serialized like the conf are ignored
+func (c Config) Diff(other Config) (fields []string) {
+	v := reflect.ValueOf(c)
+	ov := reflect.ValueOf(other)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "-" || len(name) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(v.Field(i).Interface(), ov.Field(i).Interface()) {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// Update set the specified fields with the values of the other config, the fields are the json names
+func (c *Config) Update(other Config, fields ...string) {
+	v := reflect.ValueOf(c).Elem()
+	ov := reflect.ValueOf(other)
+	t := v.Type()
+	for _, field := range fields {
+		for i := 0; i < t.NumField(); i++ {
+			if strings.Split(t.Field(i).Tag.Get("json"), ",")[0] == field {
+				v.Field(i).Set(ov.Field(i))
+				break
+			}
+		}
+	}
+}
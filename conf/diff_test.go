@@ -0,0 +1,65 @@
+package conf
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/no-src/gofs/core"
+)
+
+func TestConfig_Diff(t *testing.T) {
+	c := Config{
+		Conf:        "./gofs.yaml",
+		Source:      core.NewDiskVFS("./source"),
+		Dest:        core.NewDiskVFS("./dest"),
+		IgnoreConf:  "./ignore.conf",
+		LogLevel:    1,
+		MaxTranRate: core.NewSize(1024),
+		Jobs:        []SyncJob{{Name: "job1"}},
+	}
+
+	testCases := []struct {
+		name   string
+		modify func(c *Config)
+		expect []string
+	}{
+		{"no change", func(c *Config) {}, nil},
+		{"ignore the fields that are not serialized", func(c *Config) { c.Conf = "./other.yaml"; c.PrintVersion = true }, nil},
+		{"change the fields", func(c *Config) {
+			c.LogLevel = 0
+			c.EnableSyncDelay = true
+			c.SyncDelayTime = core.Duration(time.Second)
+		}, []string{"sync_delay", "sync_delay_time", "log_level"}},
+		{"change the vfs", func(c *Config) { c.Dest = core.NewDiskVFS("./backup") }, []string{"dest"}},
+		{"change the jobs", func(c *Config) { c.Jobs = []SyncJob{{Name: "job2"}} }, []string{"jobs"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			other := c
+			tc.modify(&other)
+			if actual := c.Diff(other); !reflect.DeepEqual(tc.expect, actual) {
+				t.Errorf("expect get the changed fields %v, but get %v", tc.expect, actual)
+			}
+		})
+	}
+}
+
+func TestConfig_Update(t *testing.T) {
+	c := Config{
+		IgnoreConf: "./ignore.conf",
+		LogLevel:   1,
+		Users:      "gofs|password|r",
+		Jobs:       []SyncJob{{Name: "job1"}},
+	}
+	other := Config{
+		IgnoreConf: "./other.conf",
+		LogLevel:   0,
+		Users:      "gofs|password|rwx",
+		Jobs:       []SyncJob{{Name: "job2"}},
+	}
+	c.Update(other, "log_level", "jobs", "not_found")
+	if diff := c.Diff(other); !reflect.DeepEqual([]string{"ignore_conf", "users"}, diff) {
+		t.Errorf("expect only update the specified fields, but get the different fields %v", diff)
+	}
+}
@@ -0,0 +1,119 @@
+package conf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/no-src/nsgo/jsonutil"
+	"github.com/no-src/nsgo/yamlutil"
+	"github.com/pelletier/go-toml/v2"
+)
+
+const (
+	// fileRefPrefix the prefix of the string value that references a file, the value is replaced with the content of the file
+	fileRefPrefix = "file:"
+	// fileUrlPrefix the prefix of the file url, such as the task_conf, it is not a file reference
+	fileUrlPrefix = "file://"
+)
+
+var (
+	errEnvNotSet = errors.New("the environment variable is not set")
+
+	// envPattern match the ${NAME} and the escaped $${NAME}
+	envPattern = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+)
+
+// expandContent expand the references in all the string values of the config content,
+// the content is returned as it is if there is nothing to expand
+func expandContent(content []byte, ext string) ([]byte, error) {
+	if !bytes.Contains(content, []byte("${")) && !bytes.Contains(content, []byte(fileRefPrefix)) {
+		return content, nil
+	}
+	var v any
+	var err error
+	if JsonFormat.MatchExt(ext) {
+		d := json.NewDecoder(bytes.NewReader(content))
+		// keep the original numbers when marshalling the config again
+		d.UseNumber()
+		err = d.Decode(&v)
+	} else if YamlFormat.MatchExt(ext) {
+		err = yamlutil.Unmarshal(content, &v)
+	} else if TomlFormat.MatchExt(ext) {
+		err = toml.Unmarshal(content, &v)
+	} else {
+		return content, nil
+	}
+	if err != nil {
+		// the syntax error is reported by parsing the original content
+		return content, nil
+	}
+	v, changed, err := expandValue(v)
+	if err != nil || !changed {
+		return content, err
+	}
+	if JsonFormat.MatchExt(ext) {
+		return jsonutil.Marshal(v)
+	} else if TomlFormat.MatchExt(ext) {
+		return toml.Marshal(v)
+	}
+	return yamlutil.Marshal(v)
+}
+
+// expandValue expand the references in all the string values of the decoded config recursively, return true if any value is changed
+func expandValue(v any) (result any, changed bool, err error) {
+	switch value := v.(type) {
+	case string:
+		s, err := expand(value)
+		return s, s != value, err
+	case []any:
+		for i, item := range value {
+			var c bool
+			if value[i], c, err = expandValue(item); err != nil {
+				return nil, false, err
+			}
+			changed = changed || c
+		}
+	case map[string]any:
+		for k, item := range value {
+			var c bool
+			if value[k], c, err = expandValue(item); err != nil {
+				return nil, false, fmt.Errorf("%s: %w", k, err)
+			}
+			changed = changed || c
+		}
+	}
+	return v, changed, nil
+}
+
+// expand replace the ${NAME} with the value of the environment variable, the $${NAME} is escaped to ${NAME},
+// then if the value starts with "file:" but not "file://", replace it with the content of the file that the trailing line breaks are trimmed
+func expand(s string) (string, error) {
+	var err error
+	s = envPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.HasPrefix(m, "$$") {
+			return m[1:]
+		}
+		name := envPattern.FindStringSubmatch(m)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok && err == nil {
+			err = fmt.Errorf("%w => %s", errEnvNotSet, name)
+		}
+		return value
+	})
+	if err != nil {
+		return s, err
+	}
+	if path, ok := strings.CutPrefix(s, fileRefPrefix); ok && !strings.HasPrefix(s, fileUrlPrefix) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return s, err
+		}
+		s = strings.TrimRight(string(data), "\r\n")
+	}
+	return s, nil
+}
@@ -0,0 +1,108 @@
+package conf
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParse_Expand(t *testing.T) {
+	t.Setenv("GOFS_TEST_SECRET", "env_secret")
+	t.Setenv("GOFS_TEST_SSH_PASS", "ssh_pass")
+	dir := t.TempDir()
+	t.Setenv("GOFS_TEST_DIR", dir)
+	usersFile := filepath.Join(dir, "users")
+	if err := os.WriteFile(usersFile, []byte("gofs|password|rw\n"), 0600); err != nil {
+		t.Fatalf("write the users file error => %v", err)
+	}
+
+	testCases := []struct {
+		name    string
+		ext     string
+		content string
+	}{
+		{"json", ".json", `{"encrypt_secret":"${GOFS_TEST_SECRET}","users":"file:${GOFS_TEST_DIR}/users","token_secret":"$${GOFS_TEST_SECRET}","dest":"sftp://127.0.0.1:22?ssh_user=root&ssh_pass=${GOFS_TEST_SSH_PASS}","chunk_size":"1MB","retry_count":123456789012,"task_conf":"file://./tasks.yaml","jobs":[{"name":"job1","encrypt_secret":"job_${GOFS_TEST_SECRET}"}]}`},
+		{"yaml", ".yaml", "encrypt_secret: ${GOFS_TEST_SECRET}\nusers: file:${GOFS_TEST_DIR}/users\ntoken_secret: $${GOFS_TEST_SECRET}\ndest: sftp://127.0.0.1:22?ssh_user=root&ssh_pass=${GOFS_TEST_SSH_PASS}\nchunk_size: 1MB\nretry_count: 123456789012\ntask_conf: file://./tasks.yaml\njobs:\n  - name: job1\n    encrypt_secret: job_${GOFS_TEST_SECRET}\n"},
+		{"toml", ".toml", "encrypt_secret = \"${GOFS_TEST_SECRET}\"\nusers = \"file:${GOFS_TEST_DIR}/users\"\ntoken_secret = \"$${GOFS_TEST_SECRET}\"\ndest = \"sftp://127.0.0.1:22?ssh_user=root&ssh_pass=${GOFS_TEST_SSH_PASS}\"\nchunk_size = \"1MB\"\nretry_count = 123456789012\ntask_conf = \"file://./tasks.yaml\"\n\n[[jobs]]\nname = \"job1\"\nencrypt_secret = \"job_${GOFS_TEST_SECRET}\"\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var c Config
+			if err := Parse(writeConfigFile(t, tc.ext, tc.content), &c); err != nil {
+				t.Fatalf("parse the config file error => %v", err)
+			}
+			if c.EncryptSecret != "env_secret" {
+				t.Errorf("expect to expand the environment variable, but get %s", c.EncryptSecret)
+			}
+			if c.Users != "gofs|password|rw" {
+				t.Errorf("expect to replace the file reference with the file content, but get %s", c.Users)
+			}
+			if c.TokenSecret != "${GOFS_TEST_SECRET}" {
+				t.Errorf("expect to keep the escaped environment variable, but get %s", c.TokenSecret)
+			}
+			if c.Dest.SSHConfig().Password != "ssh_pass" {
+				t.Errorf("expect to expand the environment variable in the dest, but get %s", c.Dest.SSHConfig().Password)
+			}
+			if c.ChunkSize.Bytes() != 1000*1000 || c.RetryCount != 123456789012 {
+				t.Errorf("expect to keep the other values, but get chunk_size=%d retry_count=%d", c.ChunkSize.Bytes(), c.RetryCount)
+			}
+			if c.TaskConf != "file://./tasks.yaml" {
+				t.Errorf("expect to keep the file url, but get %s", c.TaskConf)
+			}
+			if len(c.Jobs) != 1 || c.Jobs[0].EncryptSecret != "job_env_secret" {
+				t.Errorf("expect to expand the environment variable in the sync job, but get %+v", c.Jobs)
+			}
+		})
+	}
+}
+
+func TestParse_Expand_ReturnError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		ext     string
+		content string
+		expect  error
+	}{
+		{"json env not set", ".json", `{"encrypt_secret":"${GOFS_TEST_NOT_SET}"}`, errEnvNotSet},
+		{"yaml env not set", ".yaml", "jobs:\n  - name: ${GOFS_TEST_NOT_SET}\n", errEnvNotSet},
+		{"file not found", ".yaml", "users: file:./not-exist-file\n", os.ErrNotExist},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var c Config
+			if err := Parse(writeConfigFile(t, tc.ext, tc.content), &c); !errors.Is(err, tc.expect) {
+				t.Errorf("expect to get the error %v, but get %v", tc.expect, err)
+			}
+		})
+	}
+}
+
+func TestParseContent_NotExpand(t *testing.T) {
+	t.Setenv("GOFS_TEST_SECRET", "env_secret")
+	usersFile := filepath.Join(t.TempDir(), "users")
+	if err := os.WriteFile(usersFile, []byte("gofs|password|rw\n"), 0600); err != nil {
+		t.Fatalf("write the users file error => %v", err)
+	}
+
+	// the content like the task content from the task server must not read the local files and the environment variables
+	var c Config
+	content := `{"encrypt_secret":"${GOFS_TEST_SECRET}","users":"file:` + filepath.ToSlash(usersFile) + `"}`
+	if err := ParseContent([]byte(content), ".json", &c); err != nil {
+		t.Fatalf("parse the config content error => %v", err)
+	}
+	if c.EncryptSecret != "${GOFS_TEST_SECRET}" {
+		t.Errorf("expect to keep the environment variable, but get %s", c.EncryptSecret)
+	}
+	if c.Users != "file:"+filepath.ToSlash(usersFile) {
+		t.Errorf("expect to keep the file reference, but get %s", c.Users)
+	}
+}
+
+func writeConfigFile(t *testing.T, ext string, content string) string {
+	path := filepath.Join(t.TempDir(), "gofs"+ext)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write the config file error => %v", err)
+	}
+	return path
+}
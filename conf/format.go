@@ -21,6 +21,8 @@ var (
 	JsonFormat = NewFormat("json", ".json")
 	// YamlFormat the yaml format config
 	YamlFormat = NewFormat("yaml", ".yaml", ".yml")
+	// TomlFormat the toml format config
+	TomlFormat = NewFormat("toml", ".toml")
 )
 
 // MatchExt is the current extension matches the format
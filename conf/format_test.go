@@ -12,6 +12,7 @@ func TestFormat_Name(t *testing.T) {
 	}{
 		{JsonFormat, "json"},
 		{YamlFormat, "yaml"},
+		{TomlFormat, "toml"},
 	}
 
 	for _, tc := range testCases {
@@ -32,6 +33,7 @@ func TestFormat_MatchExt(t *testing.T) {
 	}{
 		{jsonConfigPath, JsonFormat, true},
 		{yamlConfigPath, YamlFormat, true},
+		{tomlConfigPath, TomlFormat, true},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,75 @@
+package conf
+
+import "github.com/no-src/gofs/core"
+
+// SyncJob a named pair of source and dest that runs concurrently with the other sync jobs in a single process,
+// the empty fields of the job inherit the values of the top-level config
+type SyncJob struct {
+	Name string `json:"name" yaml:"name" toml:"name"`
+
+	// file sync
+	Source            core.VFS `json:"source" yaml:"source" toml:"source"`
+	Dest              core.VFS `json:"dest" yaml:"dest" toml:"dest"`
+	IgnoreConf        string   `json:"ignore_conf" yaml:"ignore_conf" toml:"ignore_conf"`
+	IgnoreDeletedPath bool     `json:"ignore_deleted" yaml:"ignore_deleted" toml:"ignore_deleted"`
+
+	// retry
+	RetryCount int           `json:"retry_count" yaml:"retry_count" toml:"retry_count"`
+	RetryWait  core.Duration `json:"retry_wait" yaml:"retry_wait" toml:"retry_wait"`
+	RetryAsync bool          `json:"retry_async" yaml:"retry_async" toml:"retry_async"`
+
+	// encrypt
+	Encrypt               bool   `json:"encrypt" yaml:"encrypt" toml:"encrypt"`
+	EncryptPath           string `json:"encrypt_path" yaml:"encrypt_path" toml:"encrypt_path"`
+	EncryptSecret         string `json:"encrypt_secret" yaml:"encrypt_secret" toml:"encrypt_secret"`
+	EncryptSecretFile     string `json:"encrypt_secret_file" yaml:"encrypt_secret_file" toml:"encrypt_secret_file"`
+	EncryptSecretEnv      string `json:"encrypt_secret_env" yaml:"encrypt_secret_env" toml:"encrypt_secret_env"`
+	EncryptFormat         string `json:"encrypt_format" yaml:"encrypt_format" toml:"encrypt_format"`
+	EncryptKDF            string `json:"encrypt_kdf" yaml:"encrypt_kdf" toml:"encrypt_kdf"`
+	EncryptName           bool   `json:"encrypt_name" yaml:"encrypt_name" toml:"encrypt_name"`
+	EncryptRecipients     string `json:"encrypt_recipients" yaml:"encrypt_recipients" toml:"encrypt_recipients"`
+	EncryptRecipientsFile string `json:"encrypt_recipients_file" yaml:"encrypt_recipients_file" toml:"encrypt_recipients_file"`
+}
+
+// JobConfig return the config of the sync job, the source and dest are always replaced by the job,
+// the other empty fields of the job inherit the values of the top-level config, and the boolean fields are enabled if any of them is enabled,
+// the encrypt_secret, encrypt_secret_file and encrypt_secret_env are all taken from the job if the job specifies any of them
+func (c Config) JobConfig(job SyncJob) Config {
+	jc := c
+	jc.Jobs = nil
+	jc.Source = job.Source
+	jc.Dest = job.Dest
+	inherit(&jc.IgnoreConf, job.IgnoreConf)
+	jc.IgnoreDeletedPath = c.IgnoreDeletedPath || job.IgnoreDeletedPath
+
+	if job.RetryCount > 0 {
+		jc.RetryCount = job.RetryCount
+	}
+	if job.RetryWait > 0 {
+		jc.RetryWait = job.RetryWait
+	}
+	jc.RetryAsync = c.RetryAsync || job.RetryAsync
+
+	jc.Encrypt = c.Encrypt || job.Encrypt
+	inherit(&jc.EncryptPath, job.EncryptPath)
+	// exactly one secret source may be set, and setting more than one is an error, so take all of them from the job if the job
+	// specifies any of them, otherwise a top-level encrypt_secret and the encrypt_secret_file of the job would both be set
+	if len(job.EncryptSecret) > 0 || len(job.EncryptSecretFile) > 0 || len(job.EncryptSecretEnv) > 0 {
+		jc.EncryptSecret = job.EncryptSecret
+		jc.EncryptSecretFile = job.EncryptSecretFile
+		jc.EncryptSecretEnv = job.EncryptSecretEnv
+	}
+	inherit(&jc.EncryptFormat, job.EncryptFormat)
+	inherit(&jc.EncryptKDF, job.EncryptKDF)
+	jc.EncryptName = c.EncryptName || job.EncryptName
+	inherit(&jc.EncryptRecipients, job.EncryptRecipients)
+	inherit(&jc.EncryptRecipientsFile, job.EncryptRecipientsFile)
+	return jc
+}
+
+// inherit replace the value with the job value if the job value is not empty
+func inherit(v *string, jobValue string) {
+	if len(jobValue) > 0 {
+		*v = jobValue
+	}
+}
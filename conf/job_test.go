@@ -0,0 +1,59 @@
+package conf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/no-src/gofs/core"
+)
+
+func TestConfig_JobConfig(t *testing.T) {
+	c := Config{
+		Source:        core.NewDiskVFS("./source"),
+		Dest:          core.NewDiskVFS("./dest"),
+		IgnoreConf:    "./ignore.conf",
+		RetryCount:    15,
+		RetryWait:     core.Duration(time.Second * 5),
+		EncryptSecret: "top_level_secret",
+		EncryptFormat: "aes-gcm",
+		Jobs:          []SyncJob{{Name: "job1"}},
+	}
+
+	testCases := []struct {
+		name string
+		job  SyncJob
+		fn   func(jc Config) bool
+	}{
+		{"replace source and dest", SyncJob{Source: core.NewDiskVFS("./photos"), Dest: core.NewDiskVFS("./backup")}, func(jc Config) bool {
+			return jc.Source.String() == "./photos" && jc.Dest.String() == "./backup" && len(jc.Jobs) == 0
+		}},
+		{"inherit the empty fields", SyncJob{}, func(jc Config) bool {
+			return jc.IgnoreConf == c.IgnoreConf && jc.RetryCount == c.RetryCount && jc.RetryWait == c.RetryWait && !jc.Encrypt
+		}},
+		{"override the fields", SyncJob{IgnoreConf: "./job.conf", RetryCount: 3, RetryWait: core.Duration(time.Second), RetryAsync: true, IgnoreDeletedPath: true}, func(jc Config) bool {
+			return jc.IgnoreConf == "./job.conf" && jc.RetryCount == 3 && jc.RetryWait.Duration() == time.Second && jc.RetryAsync && jc.IgnoreDeletedPath
+		}},
+		{"encrypt with the inherited secret", SyncJob{Encrypt: true, EncryptPath: "./photos/private"}, func(jc Config) bool {
+			return jc.Encrypt && jc.EncryptPath == "./photos/private" && jc.EncryptSecret == c.EncryptSecret && jc.EncryptFormat == c.EncryptFormat
+		}},
+		{"encrypt with the job secret", SyncJob{Encrypt: true, EncryptSecret: "job_secret", EncryptFormat: "age", EncryptRecipients: "age1xxx", EncryptName: true}, func(jc Config) bool {
+			return jc.Encrypt && jc.EncryptSecret == "job_secret" && jc.EncryptFormat == "age" && jc.EncryptRecipients == "age1xxx" && jc.EncryptName
+		}},
+		{"encrypt with the job secret file", SyncJob{Encrypt: true, EncryptSecretFile: "./job_secret.txt"}, func(jc Config) bool {
+			return jc.Encrypt && len(jc.EncryptSecret) == 0 && jc.EncryptSecretFile == "./job_secret.txt" && len(jc.EncryptSecretEnv) == 0
+		}},
+		{"encrypt with the job secret env", SyncJob{Encrypt: true, EncryptSecretEnv: "JOB_SECRET"}, func(jc Config) bool {
+			return jc.Encrypt && len(jc.EncryptSecret) == 0 && len(jc.EncryptSecretFile) == 0 && jc.EncryptSecretEnv == "JOB_SECRET"
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if !tc.fn(c.JobConfig(tc.job)) {
+				t.Errorf("get the unexpected job config => %+v", c.JobConfig(tc.job))
+			}
+		})
+	}
+	if len(c.Jobs) != 1 || c.IgnoreConf != "./ignore.conf" {
+		t.Errorf("the top-level config should not be modified")
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/no-src/nsgo/jsonutil"
 	"github.com/no-src/nsgo/yamlutil"
+	"github.com/pelletier/go-toml/v2"
 )
 
 var (
@@ -14,7 +15,8 @@ var (
 	errUnSupportedConfigFormat = errors.New("unsupported config format")
 )
 
-// Parse read and parse the config file, support json and yaml format currently
+// Parse read and parse the config file, support json, yaml and toml format currently,
+// the ${NAME} environment variables and the "file:" references in the string values of the local config file are expanded
 func Parse[T any](path string, config *T) error {
 	if len(path) == 0 {
 		return errEmptyConfigPath
@@ -24,28 +26,37 @@ func Parse[T any](path string, config *T) error {
 		return err
 	}
 	ext := filepath.Ext(path)
+	if confBytes, err = expandContent(confBytes, ext); err != nil {
+		return err
+	}
 	return ParseContent(confBytes, ext, config)
 }
 
-// ParseContent parse the config content, support json and yaml format currently
+// ParseContent parse the config content, support json, yaml and toml format currently,
+// the environment variables and the file references are never expanded, because the content may come from the network,
+// like the task content that is sent by the task server
 func ParseContent[T any](content []byte, ext string, config *T) (err error) {
 	if JsonFormat.MatchExt(ext) {
 		err = jsonutil.Unmarshal(content, &config)
 	} else if YamlFormat.MatchExt(ext) {
 		err = yamlutil.Unmarshal(content, &config)
+	} else if TomlFormat.MatchExt(ext) {
+		err = toml.Unmarshal(content, config)
 	} else {
 		err = errUnSupportedConfigFormat
 	}
 	return err
 }
 
-// ToString convert the config object to string, support json and yaml format currently
+// ToString convert the config object to string, support json, yaml and toml format currently
 func ToString(ext string, config any) (s string, err error) {
 	var data []byte
 	if JsonFormat.MatchExt(ext) {
 		data, err = jsonutil.Marshal(config)
 	} else if YamlFormat.MatchExt(ext) {
 		data, err = yamlutil.Marshal(config)
+	} else if TomlFormat.MatchExt(ext) {
+		data, err = toml.Marshal(config)
 	} else {
 		err = errUnSupportedConfigFormat
 	}
@@ -4,13 +4,17 @@ import (
 	"errors"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/no-src/gofs/core"
 	"github.com/no-src/nsgo/hashutil"
 )
 
 const (
 	jsonConfigPath = "./example/gofs-remote-client.json"
 	yamlConfigPath = "./example/gofs-remote-server.yaml"
+	jobsConfigPath = "./example/gofs-jobs.yaml"
+	tomlConfigPath = "./example/gofs-jobs.toml"
 )
 
 func TestParse(t *testing.T) {
@@ -37,6 +41,52 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParse_Jobs(t *testing.T) {
+	for _, path := range []string{jobsConfigPath, tomlConfigPath} {
+		t.Run(path, func(t *testing.T) {
+			c := Config{}
+			if err := Parse(path, &c); err != nil {
+				t.Fatalf("parse configuration error => %s, %v", path, err)
+			}
+			if len(c.Jobs) != 2 {
+				t.Fatalf("expect to get 2 jobs, but actual get %d", len(c.Jobs))
+			}
+			job := c.Jobs[1]
+			if job.Name != "documents" || !job.Dest.Is(core.SFTP) || !job.Encrypt || job.RetryCount != 3 || job.RetryWait.Duration() != 10*time.Second {
+				t.Errorf("parse the sync job error => %+v", job)
+			}
+			if c.LogLevel != 1 || c.RetryWait.Duration() != 5*time.Second || !c.EnableTLS {
+				t.Errorf("parse the top-level config error => %+v", c)
+			}
+		})
+	}
+}
+
+func TestParse_ToString(t *testing.T) {
+	for _, ext := range []string{".json", ".yaml", ".toml"} {
+		t.Run(ext, func(t *testing.T) {
+			expect := Config{}
+			if err := Parse(jobsConfigPath, &expect); err != nil {
+				t.Fatalf("parse configuration error => %s, %v", jobsConfigPath, err)
+			}
+			// the zero size and the empty VFS can't be converted back, the flags always set the default values
+			expect.ChunkSize, expect.MaxTranRate, expect.AuditLogMaxSize = core.NewSize(1024), core.NewSize(2048), core.NewSize(4096)
+			expect.Source, expect.Dest = core.NewDiskVFS("./source"), core.NewDiskVFS("./dest")
+			s, err := ToString(ext, expect)
+			if err != nil {
+				t.Fatalf("convert configuration to string error => %v", err)
+			}
+			actual := Config{}
+			if err = ParseContent([]byte(s), ext, &actual); err != nil {
+				t.Fatalf("parse the configuration content error => %v", err)
+			}
+			if fields := expect.Diff(actual); len(fields) > 0 {
+				t.Errorf("expect to get the same config after converting, but the fields are different => %v", fields)
+			}
+		})
+	}
+}
+
 func TestParse_ReturnError(t *testing.T) {
 	testCases := []struct {
 		name   string
@@ -76,6 +126,7 @@ func TestToString(t *testing.T) {
 		{"json configuration", ".json", Config{}},
 		{"yaml configuration", ".yaml", Config{}},
 		{"yml configuration", ".yml", Config{}},
+		{"toml configuration", ".toml", Config{}},
 	}
 
 	for _, tc := range testCases {
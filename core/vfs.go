@@ -25,6 +25,7 @@ type VFS struct {
 	localSyncDisabled bool
 	secure            bool
 	sshConf           SSHConfig
+	err               error
 }
 
 const (
@@ -51,6 +52,11 @@ const (
 	minIOServerDefaultPort  = 9000
 )
 
+// String return the original vfs string
+func (vfs *VFS) String() string {
+	return vfs.original
+}
+
 // Path the local file path
 func (vfs *VFS) Path() Path {
 	return vfs.path
@@ -126,6 +132,11 @@ func (vfs *VFS) SSHConfig() SSHConfig {
 	return vfs.sshConf
 }
 
+// Err return the error of parsing the path, the VFS is empty if the error is not nil
+func (vfs *VFS) Err() error {
+	return vfs.err
+}
+
 // NewDiskVFS create an instance of VFS for the local disk file system
 func NewDiskVFS(path string) VFS {
 	vfs := VFS{
@@ -161,7 +172,8 @@ func NewVFS(path string) VFS {
 		_, vfs.host, vfs.port, vfs.path, vfs.remotePath, vfs.server, vfs.fsServer, vfs.localSyncDisabled, vfs.secure, _, err = parse(path, vfs.fsType)
 	}
 	if err != nil {
-		return NewEmptyVFS()
+		vfs = NewEmptyVFS()
+		vfs.err = err
 	}
 	return vfs
 }
@@ -141,7 +141,11 @@ func TestNewVFS_ReturnError(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.path, func(t *testing.T) {
-			compareVFS(t, tc.expect, NewVFS(tc.path))
+			actual := NewVFS(tc.path)
+			compareVFS(t, tc.expect, actual)
+			if actual.Err() == nil {
+				t.Errorf("expect to get an error of parsing the path, but get nil")
+			}
 		})
 	}
 }
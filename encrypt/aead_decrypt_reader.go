@@ -0,0 +1,120 @@
+package encrypt
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// aeadDecryptReader decrypt the data that is encrypted with the chunked AEAD format,
+// return an error if any chunk is tampered or the file is truncated
+type aeadDecryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	header  aeadHeader
+	ad      []byte
+	chunk   []byte
+	buf     []byte
+	nonce   []byte
+	counter uint64
+	last    bool
+	// block the file is encrypted with the block format, every block is stored with its nonce
+	block   bool
+	blockAD []byte
+}
+
+func (r *aeadDecryptReader) Read(p []byte) (n int, err error) {
+	for len(r.buf) == 0 {
+		if r.last {
+			return 0, io.EOF
+		}
+		if err = r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// open read and decrypt the next chunk, the chunk is the last one if there is no more data after it
+func (r *aeadDecryptReader) open() error {
+	n, err := io.ReadFull(r.r, r.chunk)
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		r.last = true
+	} else if err != nil {
+		return err
+	} else if _, err = r.r.Peek(1); errors.Is(err, io.EOF) {
+		r.last = true
+	} else if err != nil {
+		return err
+	}
+	if r.block {
+		return r.openBlock(n)
+	}
+	if n < r.aead.Overhead() {
+		return errTruncated
+	}
+	r.nonce = chunkNonce(r.nonce, r.header.nonce, r.counter, r.last)
+	r.buf, err = r.aead.Open(r.buf[:0], r.nonce, r.chunk[:n], r.ad)
+	if err != nil {
+		if r.last {
+			// the last chunk flag is not matched if the file is truncated at the chunk boundary
+			if _, nonLastErr := r.aead.Open(nil, chunkNonce(nil, r.header.nonce, r.counter, false), r.chunk[:n], r.ad); nonLastErr == nil {
+				return errTruncated
+			}
+		}
+		return fmt.Errorf("%w, chunk=%d", errAuthFailed, r.counter)
+	}
+	r.counter++
+	return nil
+}
+
+// openBlock decrypt the block with the nonce at the beginning of it
+func (r *aeadDecryptReader) openBlock(n int) (err error) {
+	nonceSize := r.aead.NonceSize()
+	if n < nonceSize+r.aead.Overhead() {
+		return errTruncated
+	}
+	nonce, sealed := r.chunk[:nonceSize], r.chunk[nonceSize:n]
+	r.blockAD = blockAdditionalData(r.blockAD, r.ad, r.counter, r.last)
+	r.buf, err = r.aead.Open(r.buf[:0], nonce, sealed, r.blockAD)
+	if err != nil {
+		if r.last {
+			// the last block flag is not matched if the file is truncated at the block boundary
+			if _, nonLastErr := r.aead.Open(nil, nonce, sealed, blockAdditionalData(nil, r.ad, r.counter, false)); nonLastErr == nil {
+				return errTruncated
+			}
+		}
+		return fmt.Errorf("%w, block=%d", errAuthFailed, r.counter)
+	}
+	r.counter++
+	return nil
+}
+
+// newAEADDecryptReader create a decryption reader of the chunked AEAD format, the magic number is already read from the reader
+func newAEADDecryptReader(r io.Reader, key []byte) (io.Reader, error) {
+	header, err := readAEADHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(header.algorithm, key)
+	if err != nil {
+		return nil, err
+	}
+	chunkSize := int(header.chunkSize) + aead.Overhead()
+	block := isBlockAlgorithm(header.algorithm)
+	if block {
+		chunkSize += aead.NonceSize()
+	}
+	return &aeadDecryptReader{
+		r:      bufio.NewReader(r),
+		aead:   aead,
+		header: header,
+		ad:     header.bytes(),
+		chunk:  make([]byte, chunkSize),
+		block:  block,
+	}, nil
+}
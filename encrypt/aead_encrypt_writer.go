@@ -0,0 +1,106 @@
+package encrypt
+
+import (
+	"crypto/cipher"
+	"errors"
+	"io"
+)
+
+var errWriterClosed = errors.New("the encrypt writer is closed")
+
+// aeadEncryptWriter encrypt the data with the chunked AEAD format, every chunk is authenticated individually,
+// and the last chunk is sealed with the last chunk flag when the writer is closed
+type aeadEncryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  aeadHeader
+	ad      []byte
+	buf     []byte
+	nonce   []byte
+	counter uint64
+	closed  bool
+
+	// blockNonce is not nil for the block formats, every block is stored with its synthetic nonce
+	blockNonce *blockNonce
+	blockAD    []byte
+}
+
+func (w *aeadEncryptWriter) Write(p []byte) (nn int, err error) {
+	if w.closed {
+		return 0, errWriterClosed
+	}
+	chunkSize := int(w.header.chunkSize)
+	for len(p) > 0 {
+		// seal the full chunk only if there are more data, so the last chunk can be marked on close
+		if len(w.buf) == chunkSize {
+			if err = w.seal(false); err != nil {
+				return nn, err
+			}
+		}
+		n := min(chunkSize-len(w.buf), len(p))
+		w.buf = append(w.buf, p[:n]...)
+		p = p[n:]
+		nn += n
+	}
+	return nn, nil
+}
+
+func (w *aeadEncryptWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.seal(true)
+}
+
+func (w *aeadEncryptWriter) seal(last bool) error {
+	var sealed []byte
+	if w.blockNonce != nil {
+		w.nonce = w.blockNonce.nonce(w.nonce, w.header.nonce, w.counter, last, w.buf)
+		w.blockAD = blockAdditionalData(w.blockAD, w.ad, w.counter, last)
+		sealed = w.aead.Seal(append([]byte(nil), w.nonce...), w.nonce, w.buf, w.blockAD)
+	} else {
+		w.nonce = chunkNonce(w.nonce, w.header.nonce, w.counter, last)
+		sealed = w.aead.Seal(nil, w.nonce, w.buf, w.ad)
+	}
+	_, err := w.w.Write(sealed)
+	w.buf = w.buf[:0]
+	w.counter++
+	return err
+}
+
+// newAEADEncryptWriter create an encryption writer with the chunked AEAD format, write the header at first,
+// the identity like the relative path is used to derive the file id of the block formats, and it is ignored by the other formats
+func newAEADEncryptWriter(w io.Writer, format string, key []byte, identity string) (io.WriteCloser, error) {
+	algorithm, err := formatAlgorithm(format)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(algorithm, key)
+	if err != nil {
+		return nil, err
+	}
+	header, err := newAEADHeader(algorithm, aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+	var bn *blockNonce
+	if isBlockAlgorithm(algorithm) {
+		if bn, err = newBlockNonce(key); err != nil {
+			return nil, err
+		}
+		header.nonce = bn.fileID(identity, aead.NonceSize())
+	}
+	ad := header.bytes()
+	if _, err = w.Write(ad); err != nil {
+		return nil, err
+	}
+	return &aeadEncryptWriter{
+		w:          w,
+		aead:       aead,
+		header:     header,
+		ad:         ad,
+		buf:        make([]byte, 0, header.chunkSize),
+		blockNonce: bn,
+	}, nil
+}
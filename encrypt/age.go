@@ -0,0 +1,75 @@
+package encrypt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+)
+
+var (
+	// ageMagic the first line of the file that is encrypted with the age format
+	ageMagic = []byte("age-encryption.org/v1")
+
+	errRecipientRequired = errors.New("at least one recipient is required for the age format")
+	errIdentityRequired  = errors.New("the identity file is required to decrypt the file that is encrypted with the age format")
+	errAgeNotSupported   = errors.New("the name encryption and the key derivation are not supported by the age format")
+)
+
+// parseRecipients parse the comma-separated age public keys and the recipients file that contains one public key per line,
+// the empty lines and the comments that start with # in the recipients file are ignored
+func parseRecipients(recipients string, file string) (result []age.Recipient, err error) {
+	for _, s := range strings.Split(recipients, ",") {
+		if s = strings.TrimSpace(s); len(s) == 0 {
+			continue
+		}
+		r, err := age.ParseX25519Recipient(s)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	if len(file) > 0 {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		rs, err := age.ParseRecipients(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w => %s", err, file)
+		}
+		result = append(result, rs...)
+	}
+	if len(result) == 0 {
+		return nil, errRecipientRequired
+	}
+	return result, nil
+}
+
+// parseIdentities parse the age identity file that contains one private key per line
+func parseIdentities(file string) ([]age.Identity, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("%w => %s", err, file)
+	}
+	return identities, nil
+}
+
+// newAgeEncryptWriter create an encryption writer with the age format, the file can be decrypted by any of the recipients
+func newAgeEncryptWriter(w io.Writer, recipients []age.Recipient) (io.WriteCloser, error) {
+	return age.Encrypt(w, recipients...)
+}
+
+// isAgeFormat check the beginning of the file is the header of the age format or not
+func isAgeFormat(prefix []byte) bool {
+	return bytes.Equal(prefix, ageMagic)
+}
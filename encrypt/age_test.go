@@ -0,0 +1,151 @@
+package encrypt
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/no-src/gofs/conf"
+	"github.com/no-src/gofs/logger"
+)
+
+func TestEncrypt_Age(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	id1 := generateAgeIdentity(t)
+	id2 := generateAgeIdentity(t)
+	dir := t.TempDir()
+	recipientsFile := filepath.Join(dir, "recipients.txt")
+	writeFile(t, recipientsFile, []byte("# the backup key\n"+id2.Recipient().String()+"\n"))
+	identityFile1 := filepath.Join(dir, "id1.txt")
+	writeFile(t, identityFile1, []byte(id1.String()+"\n"))
+	identityFile2 := filepath.Join(dir, "id2.txt")
+	writeFile(t, identityFile2, []byte(id2.String()+"\n"))
+
+	source := t.TempDir()
+	dest := t.TempDir()
+	data := randomData(t, aeadChunkSize+100)
+	sourceFile := filepath.Join(source, "hello.txt")
+	writeFile(t, sourceFile, data)
+
+	// encrypt without the secret, so the sync host can't decrypt the files
+	enc, err := NewEncrypt(NewOption(conf.Config{
+		Encrypt:               true,
+		EncryptPath:           source,
+		EncryptFormat:         FormatAge,
+		EncryptRecipients:     id1.Recipient().String(),
+		EncryptRecipientsFile: recipientsFile,
+	}, logger), source)
+	if err != nil {
+		t.Fatalf("init encrypt component error => %v", err)
+	}
+	buf := bytes.NewBuffer(nil)
+	w, err := enc.NewWriter(buf, sourceFile, "hello.txt")
+	if err != nil {
+		t.Fatalf("init encrypt writer error => %v", err)
+	}
+	w.Write(data)
+	if err = w.Close(); err != nil {
+		t.Fatalf("close encrypt writer error => %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), ageMagic) {
+		t.Errorf("expect get the file with the age format")
+	}
+	writeFile(t, filepath.Join(dest, "hello.txt"), buf.Bytes())
+
+	testCases := []struct {
+		name         string
+		identityFile string
+		secret       string
+		expect       error
+	}{
+		{"identity of the recipient", identityFile1, "", nil},
+		{"identity of the recipients file", identityFile2, "", nil},
+		{"identity with the secret", identityFile1, secret, nil},
+		{"no identity", "", secret, errIdentityRequired},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := t.TempDir()
+			dec, err := NewDecrypt(NewOption(conf.Config{
+				Decrypt:             true,
+				DecryptPath:         dest,
+				DecryptSecret:       tc.secret,
+				DecryptIdentityFile: tc.identityFile,
+				DecryptOut:          out,
+			}, logger))
+			if err != nil {
+				t.Fatalf("init decrypt component error => %v", err)
+			}
+			err = dec.Decrypt()
+			if !errors.Is(err, tc.expect) {
+				t.Fatalf("expect get error %v but get %v", tc.expect, err)
+			}
+			if tc.expect != nil {
+				return
+			}
+			actual, err := os.ReadFile(filepath.Join(out, "hello.txt"))
+			if err != nil {
+				t.Fatalf("read the decrypted file error => %v", err)
+			}
+			if !bytes.Equal(data, actual) {
+				t.Errorf("the decrypted data is not equal to the origin data")
+			}
+		})
+	}
+}
+
+func TestEncrypt_Age_ReturnError(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	recipient := generateAgeIdentity(t).Recipient().String()
+	testCases := []struct {
+		name   string
+		config conf.Config
+		expect error
+	}{
+		{"no recipient", conf.Config{}, errRecipientRequired},
+		{"name encryption", conf.Config{EncryptRecipients: recipient, EncryptName: true}, errAgeNotSupported},
+		{"key derivation", conf.Config{EncryptRecipients: recipient, EncryptKDF: KDFScrypt}, errAgeNotSupported},
+		{"recipients file not exist", conf.Config{EncryptRecipientsFile: filepath.Join(t.TempDir(), "not_exist.txt")}, os.ErrNotExist},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := t.TempDir()
+			c := tc.config
+			c.Encrypt = true
+			c.EncryptPath = source
+			c.EncryptFormat = FormatAge
+			_, err := NewEncrypt(NewOption(c, logger), source)
+			if !errors.Is(err, tc.expect) {
+				t.Errorf("expect get error %v but get %v", tc.expect, err)
+			}
+		})
+	}
+
+	t.Run("invalid recipient", func(t *testing.T) {
+		source := t.TempDir()
+		_, err := NewEncrypt(NewOption(conf.Config{
+			Encrypt:           true,
+			EncryptPath:       source,
+			EncryptFormat:     FormatAge,
+			EncryptRecipients: "age1invalid",
+		}, logger), source)
+		if err == nil {
+			t.Errorf("expect get an error with the invalid recipient but get nil")
+		}
+	})
+}
+
+func generateAgeIdentity(t *testing.T) *age.X25519Identity {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("generate the age identity error => %v", err)
+	}
+	return id
+}
@@ -0,0 +1,79 @@
+package encrypt
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+)
+
+const (
+	blockKeyInfo = "nosrc-gofs-block-nonce"
+
+	blockFileIDDomain byte = 0
+	blockNonceDomain  byte = 1
+)
+
+// blockNonce generate the synthetic nonces of the block formats, the nonce of a block is derived from the file id,
+// the block counter, the last block flag and the plaintext, so the same block is always encrypted to the same ciphertext
+type blockNonce struct {
+	mac hash.Hash
+}
+
+func newBlockNonce(key []byte) (*blockNonce, error) {
+	macKey, err := hkdf.Key(sha256.New, key, nil, blockKeyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	return &blockNonce{
+		mac: hmac.New(sha256.New, macKey),
+	}, nil
+}
+
+// fileID derive the file id from the file identity like the relative path, the file header is unchanged between the syncs
+func (bn *blockNonce) fileID(identity string, size int) []byte {
+	bn.mac.Reset()
+	bn.mac.Write([]byte{blockFileIDDomain})
+	bn.mac.Write([]byte(identity))
+	return bn.mac.Sum(nil)[:size]
+}
+
+// nonce derive the nonce of the block
+func (bn *blockNonce) nonce(dst []byte, fileID []byte, counter uint64, last bool, plaintext []byte) []byte {
+	bn.mac.Reset()
+	bn.mac.Write([]byte{blockNonceDomain})
+	bn.mac.Write(fileID)
+	bn.mac.Write(blockSuffix(counter, last))
+	bn.mac.Write(plaintext)
+	return append(dst[:0], bn.mac.Sum(nil)[:len(fileID)]...)
+}
+
+// blockAdditionalData return the additional data of the block, the header is bound with the block counter and the last block flag,
+// so the blocks can't be reordered, and the truncation is detected by the last block flag
+func blockAdditionalData(dst []byte, header []byte, counter uint64, last bool) []byte {
+	dst = append(dst[:0], header...)
+	return append(dst, blockSuffix(counter, last)...)
+}
+
+func blockSuffix(counter uint64, last bool) []byte {
+	var suffix [aeadNonceCounterSize]byte
+	binary.BigEndian.PutUint64(suffix[:8], counter)
+	if last {
+		suffix[8] = 1
+	}
+	return suffix[:]
+}
+
+// blockEncryptedSize return the size of the file that is encrypted with the block format, every block is stored with its nonce
+func blockEncryptedSize(format string, size int64) (int64, bool) {
+	algorithm, err := formatAlgorithm(format)
+	if err != nil || !isBlockAlgorithm(algorithm) {
+		return 0, false
+	}
+	nonceSize, _ := algorithmNonceSize(algorithm)
+	blocks := max((size+aeadChunkSize-1)/aeadChunkSize, 1)
+	headerSize := int64(len(aeadMagic) + 6 + nonceSize)
+	// both AES-GCM and XChaCha20-Poly1305 have a 16 bytes tag
+	return headerSize + size + blocks*int64(nonceSize+16), true
+}
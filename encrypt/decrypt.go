@@ -1,11 +1,16 @@
 package encrypt
 
 import (
+	"bufio"
+	"bytes"
 	"errors"
+	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 
+	"filippo.io/age"
 	"github.com/no-src/nsgo/fsutil"
 )
 
@@ -17,21 +22,69 @@ var (
 
 // Decrypt the decryption component
 type Decrypt struct {
-	opt Option
+	opt        Option
+	names      *nameCipher
+	identities []age.Identity
 }
 
 // NewDecrypt create a decryption component
 func NewDecrypt(opt Option) (*Decrypt, error) {
 	if opt.Decrypt {
-		if err := checkAESKey(opt.DecryptSecret); err != nil {
+		var identities []age.Identity
+		if len(opt.DecryptIdentityFile) > 0 {
+			var err error
+			if identities, err = parseIdentities(opt.DecryptIdentityFile); err != nil {
+				return nil, err
+			}
+		}
+		secret, err := resolveSecret(opt.DecryptSecret, opt.DecryptSecretFile, opt.DecryptSecretEnv)
+		if err != nil {
+			return nil, err
+		}
+		if len(secret) == 0 && len(identities) > 0 {
+			// only the files that are encrypted with the age format can be decrypted without the secret
+			return &Decrypt{
+				opt:        opt,
+				identities: identities,
+			}, nil
+		}
+		h, exist, err := loadKeyHeader(keyHeaderDir(opt.DecryptPath))
+		if err != nil {
+			return nil, err
+		}
+		if exist {
+			// the files are encrypted with the key that is derived from the passphrase
+			if secret, err = h.deriveKey(secret); err != nil {
+				return nil, err
+			}
+		} else if err = checkAESKey(secret); err != nil {
 			return nil, err
 		}
+		opt.DecryptSecret = secret
+		// the encrypted names are detected and decrypted automatically
+		names, err := newNameCipher(secret)
+		if err != nil {
+			return nil, err
+		}
+		return &Decrypt{
+			opt:        opt,
+			names:      names,
+			identities: identities,
+		}, nil
 	}
 	return &Decrypt{
 		opt: opt,
 	}, nil
 }
 
+// keyHeaderDir return the directory of the key header file, it is the parent directory if the decrypt path is a file
+func keyHeaderDir(decryptPath string) string {
+	if isDir, err := fsutil.IsDir(decryptPath); err == nil && !isDir {
+		return filepath.Dir(decryptPath)
+	}
+	return decryptPath
+}
+
 // Decrypt uses the decryption option to decrypt the files
 func (dec *Decrypt) Decrypt() error {
 	if !dec.opt.Decrypt {
@@ -54,14 +107,36 @@ func (dec *Decrypt) Decrypt() error {
 		if err != nil {
 			return err
 		}
-		if d.IsDir() {
+		if d.IsDir() || isKeyHeaderFile(path) {
 			return nil
 		}
-		r, err := newDecryptReader(path, dec.opt.DecryptSecret, dec.opt.Logger)
+		rel, err := filepath.Rel(dec.opt.DecryptPath, path)
 		if err != nil {
 			return err
 		}
-		rel, err := filepath.Rel(dec.opt.DecryptPath, path)
+		if rel == "." {
+			rel = d.Name()
+		}
+		isAEAD, err := dec.hasPrefix(path, aeadMagic)
+		if err != nil {
+			return err
+		}
+		if isAEAD {
+			return dec.decryptFile(path, filepath.Join(dec.opt.DecryptOut, dec.names.decryptRelPath(rel)), dec.newAEADReader)
+		}
+		isAge, err := dec.hasPrefix(path, ageMagic)
+		if err != nil {
+			return err
+		}
+		if isAge {
+			return dec.decryptFile(path, filepath.Join(dec.opt.DecryptOut, dec.names.decryptRelPath(rel)), dec.newAgeReader)
+		}
+		if len(dec.opt.DecryptSecret) == 0 {
+			return fmt.Errorf("%w, the file is not encrypted with the age format => %s", errEmptySecret, path)
+		}
+		rel = dec.names.decryptRelPath(rel)
+		// the file is not encrypted with the chunked AEAD format, try to decrypt it with the legacy format
+		r, err := newDecryptReader(path, dec.opt.DecryptSecret, dec.names, dec.opt.Logger)
 		if err != nil {
 			return err
 		}
@@ -70,3 +145,105 @@ func (dec *Decrypt) Decrypt() error {
 		return r.WriteTo(outPath)
 	})
 }
+
+// NewReader create a reader that decrypts the content of the encrypted file, the encryption format is detected automatically,
+// the size of the file is required by the legacy format to read the zip container
+func (dec *Decrypt) NewReader(r io.ReadSeeker, size int64) (io.Reader, error) {
+	prefix := make([]byte, len(ageMagic))
+	n, err := io.ReadFull(r, prefix)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	prefix = prefix[:n]
+	if bytes.HasPrefix(prefix, aeadMagic) {
+		return dec.newAEADReader(r)
+	}
+	if isAgeFormat(prefix) {
+		return dec.newAgeReader(r)
+	}
+	if len(dec.opt.DecryptSecret) == 0 {
+		return nil, fmt.Errorf("%w, the file is not encrypted with the age format", errEmptySecret)
+	}
+	ra, ok := r.(io.ReaderAt)
+	if !ok {
+		ra = &seekReaderAt{r: r}
+	}
+	return newLegacyReader(ra, size, dec.opt.DecryptSecret)
+}
+
+// DecryptName decrypt the encrypted file name, the name is retained if it is not encrypted
+func (dec *Decrypt) DecryptName(name string) string {
+	if dec.names == nil {
+		return name
+	}
+	name, _ = dec.names.decryptName(name)
+	return name
+}
+
+// hasPrefix check the beginning of the file is the prefix or not
+func (dec *Decrypt) hasPrefix(path string, prefix []byte) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	actual := make([]byte, len(prefix))
+	if _, err = io.ReadFull(f, actual); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return bytes.Equal(actual, prefix), nil
+}
+
+// newAEADReader create a decryption reader of the chunked AEAD format
+func (dec *Decrypt) newAEADReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if _, err := br.Discard(len(aeadMagic)); err != nil {
+		return nil, err
+	}
+	return newAEADDecryptReader(br, dec.opt.DecryptSecret)
+}
+
+// newAgeReader create a decryption reader of the age format with the identities
+func (dec *Decrypt) newAgeReader(r io.Reader) (io.Reader, error) {
+	if len(dec.identities) == 0 {
+		return nil, errIdentityRequired
+	}
+	return age.Decrypt(r, dec.identities...)
+}
+
+// decryptFile decrypt the file to the output path with the decryption reader,
+// the output file is removed if the decryption is failed
+func (dec *Decrypt) decryptFile(path string, outPath string, newReader func(io.Reader) (io.Reader, error)) (err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := newReader(f)
+	if err != nil {
+		return fmt.Errorf("%w => %s", err, path)
+	}
+	if err = os.MkdirAll(filepath.Dir(outPath), fs.ModePerm); err != nil {
+		return err
+	}
+	out, err := os.Create(outPath)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, r)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		dec.opt.Logger.ErrorIf(os.Remove(outPath), "remove the decryption file error => %s", outPath)
+		return fmt.Errorf("%w => %s", err, path)
+	}
+	dec.opt.Logger.Info("save decryption file success => %s", outPath)
+	return nil
+}
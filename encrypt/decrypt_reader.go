@@ -3,6 +3,9 @@ package encrypt
 import (
 	"archive/zip"
 	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
@@ -15,6 +18,7 @@ import (
 type decryptReader struct {
 	zrc    *zip.ReadCloser
 	secret []byte
+	names  *nameCipher
 	logger *logger.Logger
 }
 
@@ -26,7 +30,7 @@ func (r *decryptReader) WriteTo(path string) (err error) {
 			return fmt.Errorf("%w => %s", errIllegalPath, file.Name)
 		}
 
-		outPath := filepath.Join(path, file.Name)
+		outPath := filepath.Join(path, r.names.decryptRelPath(filepath.FromSlash(file.Name)))
 
 		// path is directory
 		if file.FileInfo().IsDir() {
@@ -78,7 +82,7 @@ func (r *decryptReader) WriteTo(path string) (err error) {
 }
 
 // newDecryptReader create a decryption reader
-func newDecryptReader(path string, secret []byte, logger *logger.Logger) (*decryptReader, error) {
+func newDecryptReader(path string, secret []byte, names *nameCipher, logger *logger.Logger) (*decryptReader, error) {
 	zrc, err := zip.OpenReader(path)
 	if err != nil {
 		return nil, err
@@ -86,6 +90,41 @@ func newDecryptReader(path string, secret []byte, logger *logger.Logger) (*decry
 	return &decryptReader{
 		zrc:    zrc,
 		secret: secret,
+		names:  names,
 		logger: logger,
 	}, nil
 }
+
+var errInvalidLegacyFile = errors.New("the file of the legacy format must contain only one file in the zip container")
+
+// newLegacyReader create a reader that decrypts the only file in the zip container of the legacy format
+func newLegacyReader(ra io.ReaderAt, size int64, secret []byte) (io.Reader, error) {
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	if len(zr.File) != 1 || zr.File[0].FileInfo().IsDir() {
+		return nil, errInvalidLegacyFile
+	}
+	f, err := zr.File[0].Open()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.StreamReader{S: cipher.NewCFBDecrypter(block, aesIV), R: f}, nil
+}
+
+// seekReaderAt implement the io.ReaderAt with the io.ReadSeeker, it is not safe for concurrent use
+type seekReaderAt struct {
+	r io.ReadSeeker
+}
+
+func (ra *seekReaderAt) ReadAt(p []byte, off int64) (n int, err error) {
+	if _, err = ra.r.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.ReadFull(ra.r, p)
+}
@@ -2,8 +2,10 @@ package encrypt
 
 import (
 	"archive/zip"
+	"bytes"
 	"crypto/aes"
 	"errors"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -116,3 +118,77 @@ func TestNewDecrypt_CheckKey(t *testing.T) {
 		})
 	}
 }
+
+func TestDecrypt_NewReader(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	id := generateAgeIdentity(t)
+	identityFile := filepath.Join(t.TempDir(), "id.txt")
+	writeFile(t, identityFile, []byte(id.String()+"\n"))
+	data := randomData(t, aeadChunkSize+100)
+
+	testCases := []struct {
+		name          string
+		format        string
+		encryptSecret string
+		decryptSecret string
+		expect        error
+	}{
+		{"aes-gcm", FormatAESGCM, secret, secret, nil},
+		{"xchacha20-poly1305", FormatXChaCha20Poly1305, secret, secret, nil},
+		{"aes-gcm-block", FormatAESGCMBlock, secret, secret, nil},
+		{"xchacha20-poly1305-block", FormatXChaCha20Poly1305Block, secret, secret, nil},
+		{"legacy", FormatLegacy, secret, secret, nil},
+		{"age", FormatAge, "", "", nil},
+		{"legacy without secret", FormatLegacy, secret, "", errEmptySecret},
+		{"wrong secret", FormatAESGCM, secret, "encrypt_secure_encrypt_secure_02", errAuthFailed},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			source := t.TempDir()
+			sourceFile := filepath.Join(source, "hello.txt")
+			writeFile(t, sourceFile, data)
+			enc, err := NewEncrypt(NewOption(conf.Config{
+				Encrypt:           true,
+				EncryptPath:       source,
+				EncryptSecret:     tc.encryptSecret,
+				EncryptFormat:     tc.format,
+				EncryptRecipients: id.Recipient().String(),
+			}, logger), source)
+			if err != nil {
+				t.Fatalf("init encrypt component error => %v", err)
+			}
+			buf := bytes.NewBuffer(nil)
+			w, err := enc.NewWriter(buf, sourceFile, "hello.txt")
+			if err != nil {
+				t.Fatalf("init encrypt writer error => %v", err)
+			}
+			w.Write(data)
+			if err = w.Close(); err != nil {
+				t.Fatalf("close encrypt writer error => %v", err)
+			}
+
+			dec, err := NewDecrypt(NewOption(conf.Config{
+				Decrypt:             true,
+				DecryptPath:         t.TempDir(),
+				DecryptSecret:       tc.decryptSecret,
+				DecryptIdentityFile: identityFile,
+			}, logger))
+			if err != nil {
+				t.Fatalf("init decrypt component error => %v", err)
+			}
+			var actual []byte
+			r, err := dec.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			if err == nil {
+				actual, err = io.ReadAll(r)
+			}
+			if !errors.Is(err, tc.expect) {
+				t.Fatalf("expect get error %v but get %v", tc.expect, err)
+			}
+			if tc.expect == nil && !bytes.Equal(data, actual) {
+				t.Errorf("the decrypted data is not equal to the origin data")
+			}
+		})
+	}
+}
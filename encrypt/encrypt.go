@@ -5,16 +5,23 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
+	"filippo.io/age"
 	"github.com/no-src/gofs/logger"
 	"github.com/no-src/nsgo/fsutil"
 )
 
 // Encrypt the encryption component
 type Encrypt struct {
-	opt        Option
-	parentPath string
-	logger     *logger.Logger
+	opt           Option
+	parentPath    string
+	keyHeaderPath string
+	names         *nameCipher
+	encryptRel    string
+	recipients    []age.Recipient
+	logger        *logger.Logger
 }
 
 // NewEncrypt create an encryption component
@@ -32,24 +39,142 @@ func NewEncrypt(opt Option, parentPath string) (*Encrypt, error) {
 		if !isSub {
 			return nil, fmt.Errorf("%w, source=%s encrypt=%s", errNotSubDir, parentPath, opt.EncryptPath)
 		}
-		if err = checkAESKey(opt.EncryptSecret); err != nil {
+		if opt.EncryptFormat == FormatAge {
+			return enc, enc.initRecipients()
+		}
+		if enc.opt.EncryptSecret, err = resolveSecret(opt.EncryptSecret, opt.EncryptSecretFile, opt.EncryptSecretEnv); err != nil {
+			return nil, err
+		}
+		if len(opt.EncryptKDF) > 0 {
+			if err = enc.deriveKey(); err != nil {
+				return nil, err
+			}
+		}
+		if err = checkFormat(enc.opt.EncryptFormat, enc.opt.EncryptSecret); err != nil {
 			return nil, err
 		}
+		if opt.EncryptName {
+			if err = enc.initNameCipher(); err != nil {
+				return nil, err
+			}
+		}
 	}
 	return enc, nil
 }
 
+// initRecipients init the recipients of the age format, the secret is not required
+func (e *Encrypt) initRecipients() (err error) {
+	if e.opt.EncryptName || len(e.opt.EncryptKDF) > 0 {
+		return errAgeNotSupported
+	}
+	e.recipients, err = parseRecipients(e.opt.EncryptRecipients, e.opt.EncryptRecipientsFile)
+	return err
+}
+
+// initNameCipher init the name cipher and the encrypt path that is relative to the parent path
+func (e *Encrypt) initNameCipher() (err error) {
+	if e.names, err = newNameCipher(e.opt.EncryptSecret); err != nil {
+		return err
+	}
+	parentAbs, err := filepath.Abs(e.parentPath)
+	if err != nil {
+		return err
+	}
+	encryptAbs, err := filepath.Abs(e.opt.EncryptPath)
+	if err != nil {
+		return err
+	}
+	e.encryptRel, err = filepath.Rel(parentAbs, encryptAbs)
+	return err
+}
+
+// deriveKey derive the encryption key from the passphrase with the key header in the encrypt path, create the key header if it does not exist
+func (e *Encrypt) deriveKey() error {
+	h, err := loadOrCreateKeyHeader(e.opt.EncryptPath, e.opt.EncryptKDF)
+	if err != nil {
+		return err
+	}
+	if e.opt.EncryptSecret, err = h.deriveKey(e.opt.EncryptSecret); err != nil {
+		return err
+	}
+	e.keyHeaderPath, err = filepath.Abs(filepath.Join(e.opt.EncryptPath, KeyHeaderFile))
+	return err
+}
+
 // NewWriter create an encryption writer
 func (e *Encrypt) NewWriter(w io.Writer, source string, name string) (io.WriteCloser, error) {
 	if e.NeedEncrypt(source) {
-		return newEncryptWriter(w, name, e.opt.EncryptSecret, aesIV)
+		switch e.opt.EncryptFormat {
+		case FormatLegacy:
+			return newEncryptWriter(w, name, e.opt.EncryptSecret, aesIV)
+		case FormatAge:
+			return newAgeEncryptWriter(w, e.recipients)
+		}
+		return newAEADEncryptWriter(w, e.opt.EncryptFormat, e.opt.EncryptSecret, e.identity(source))
 	}
 	return newBufferWriter(w), nil
 }
 
+// identity return the slash-separated path of the source file that is relative to the encrypt path,
+// it is the same between the syncs, so the file id of the block formats is unchanged
+func (e *Encrypt) identity(source string) string {
+	sourceAbs, err := filepath.Abs(source)
+	if err != nil {
+		return source
+	}
+	encryptAbs, err := filepath.Abs(e.opt.EncryptPath)
+	if err != nil {
+		return source
+	}
+	rel, err := filepath.Rel(encryptAbs, sourceAbs)
+	if err != nil {
+		return source
+	}
+	return filepath.ToSlash(rel)
+}
+
+// IsBlockFormat the encryption is enabled and the encryption format is the block format,
+// the unchanged blocks of the file are encrypted to the same ciphertext, so the encrypted file can be compared by chunks
+func (e *Encrypt) IsBlockFormat() bool {
+	return e.opt.Encrypt && isBlockFormat(e.opt.EncryptFormat)
+}
+
+// EncryptedSize return the size of the encrypted file with the size of the source file, return false if the size can't be calculated
+func (e *Encrypt) EncryptedSize(size int64) (int64, bool) {
+	return blockEncryptedSize(e.opt.EncryptFormat, size)
+}
+
+// EncryptRelPath encrypt the names in the path that is relative to the parent path if the name encryption is enabled,
+// only the names under the encrypt path are encrypted, and the key header file is retained,
+// return an error if any encrypted name exceeds the max length of the file name
+func (e *Encrypt) EncryptRelPath(rel string) (string, error) {
+	if e.names == nil {
+		return rel, nil
+	}
+	var encrypted []string
+	if e.encryptRel != "." {
+		if rel != e.encryptRel && !strings.HasPrefix(rel, e.encryptRel+string(filepath.Separator)) {
+			return rel, nil
+		}
+		encrypted = append(encrypted, e.encryptRel)
+		rel = strings.TrimPrefix(strings.TrimPrefix(rel, e.encryptRel), string(filepath.Separator))
+	}
+	if len(rel) == 0 || rel == "." || e.isKeyHeader(filepath.Join(e.parentPath, e.encryptRel, rel)) {
+		return filepath.Join(append(encrypted, rel)...), nil
+	}
+	for _, name := range strings.Split(rel, string(filepath.Separator)) {
+		encryptedName, err := e.names.encryptName(name)
+		if err != nil {
+			return "", err
+		}
+		encrypted = append(encrypted, encryptedName)
+	}
+	return filepath.Join(encrypted...), nil
+}
+
 // NeedEncrypt encryption is enabled and path is matched
 func (e *Encrypt) NeedEncrypt(path string) bool {
-	if e.opt.Encrypt {
+	if e.opt.Encrypt && !e.isKeyHeader(path) {
 		isSub, err := fsutil.IsSub(e.opt.EncryptPath, path)
 		if err == nil && isSub {
 			return true
@@ -58,6 +183,15 @@ func (e *Encrypt) NeedEncrypt(path string) bool {
 	return false
 }
 
+// isKeyHeader the key header file is synced to the destination without encryption, so the destination files can be decrypted with the passphrase
+func (e *Encrypt) isKeyHeader(path string) bool {
+	if len(e.keyHeaderPath) == 0 || !isKeyHeaderFile(path) {
+		return false
+	}
+	absPath, err := filepath.Abs(path)
+	return err == nil && absPath == e.keyHeaderPath
+}
+
 // CreateEncryptTemp create an encryption temporary file if enable encrypt and the path is matched
 func (e *Encrypt) CreateEncryptTemp(path string) (tempPath string, removeTemp func() error, err error) {
 	removeTemp = func() error {
@@ -79,31 +213,49 @@ func (e *Encrypt) CreateEncryptTemp(path string) (tempPath string, removeTemp fu
 	}
 
 	fileName := sourceStat.Name()
+	entryName := fileName
+	if e.names != nil {
+		// the name of the zip entry is the name of the destination file
+		if entryName, err = e.names.encryptName(fileName); err != nil {
+			return tempPath, removeTemp, err
+		}
+	}
 	reader := bufio.NewReader(sourceFile)
 
 	tempFile, err := os.CreateTemp("", fileName)
 	if err != nil {
 		return tempPath, removeTemp, err
 	}
+	tempPath = tempFile.Name()
 
-	defer func() {
-		e.logger.ErrorIf(tempFile.Close(), "[encrypt temp] close the temporary file error")
-	}()
+	if err = e.writeEncryptTemp(tempFile, reader, path, entryName); err != nil {
+		// the caller can't remove the incomplete temporary file when an error is returned, so remove it here
+		e.logger.ErrorIf(os.Remove(tempPath), "[encrypt temp] remove the temporary file error")
+		return "", removeTemp, err
+	}
 
 	removeTemp = func() error {
-		return e.logger.ErrorIf(os.Remove(tempFile.Name()), "[encrypt temp] remove the temporary file error")
+		return e.logger.ErrorIf(os.Remove(tempPath), "[encrypt temp] remove the temporary file error")
 	}
+	return tempPath, removeTemp, nil
+}
 
-	w, err := e.NewWriter(tempFile, path, fileName)
-	if err != nil {
-		return tempPath, removeTemp, err
-	}
+// writeEncryptTemp write the encrypted content to the temporary file, the writer and the temporary file are always closed,
+// the close errors are returned because the encrypted content is incomplete until the writer is closed
+func (e *Encrypt) writeEncryptTemp(tempFile *os.File, reader io.Reader, path string, entryName string) (err error) {
 	defer func() {
-		e.logger.ErrorIf(w.Close(), "[encrypt temp] close the encrypt writer error")
+		if closeErr := tempFile.Close(); err == nil {
+			err = closeErr
+		}
 	}()
-	_, err = reader.WriteTo(w)
+
+	w, err := e.NewWriter(tempFile, path, entryName)
 	if err != nil {
-		return tempPath, removeTemp, err
+		return err
+	}
+	if _, err = io.Copy(w, reader); err != nil {
+		e.logger.ErrorIf(w.Close(), "[encrypt temp] close the encrypt writer error")
+		return err
 	}
-	return tempFile.Name(), removeTemp, nil
+	return w.Close()
 }
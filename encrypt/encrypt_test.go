@@ -48,6 +48,51 @@ func TestEncrypt(t *testing.T) {
 	}
 }
 
+func TestEncrypt_Format(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	for _, format := range []string{FormatAESGCM, FormatXChaCha20Poly1305, FormatAESGCMBlock, FormatXChaCha20Poly1305Block, FormatLegacy} {
+		t.Run(format, func(t *testing.T) {
+			encryptOpt := NewOption(conf.Config{
+				Encrypt:       true,
+				EncryptPath:   encryptPath,
+				EncryptSecret: secret,
+				EncryptFormat: format,
+			}, logger)
+
+			decryptOpt := NewOption(conf.Config{
+				Decrypt:       true,
+				DecryptPath:   decryptPath,
+				DecryptSecret: secret,
+				DecryptOut:    decryptOut,
+			}, logger)
+
+			err := testEncrypt(encryptOpt, decryptOpt, sourcePath, originPath, encryptFilePath)
+			if err != nil {
+				t.Errorf("test encrypt and decrypt error err=%v", err)
+			}
+		})
+	}
+}
+
+func TestEncrypt_UnsupportedFormat(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	encryptOpt := NewOption(conf.Config{
+		Encrypt:       true,
+		EncryptPath:   encryptPath,
+		EncryptSecret: secret,
+		EncryptFormat: "aes-cbc",
+	}, logger)
+
+	_, err := NewEncrypt(encryptOpt, sourcePath)
+	if !errors.Is(err, errUnsupportedFormat) {
+		t.Errorf("expect get error => [%v] but get [%v]", errUnsupportedFormat, err)
+	}
+}
+
 func TestEncrypt_Disabled(t *testing.T) {
 	logger := logger.NewTestLogger()
 	defer logger.Close()
@@ -152,6 +197,44 @@ func TestNewEncrypt_CheckKey(t *testing.T) {
 }
 
 //gocyclo:ignore
+func TestCreateEncryptTemp_RemoveTempOnError(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	tempDir := t.TempDir()
+	t.Setenv("TMPDIR", tempDir)
+	source := t.TempDir()
+	// reading a directory returns an error after the temporary file is created
+	dir := filepath.Join(source, "dir")
+	if err := os.Mkdir(dir, 0700); err != nil {
+		t.Fatalf("create the directory error => %v", err)
+	}
+
+	enc, err := NewEncrypt(NewOption(conf.Config{
+		Encrypt:       true,
+		EncryptPath:   source,
+		EncryptSecret: secret,
+	}, logger), source)
+	if err != nil {
+		t.Fatalf("create encrypt error => %v", err)
+	}
+	// the callers don't remove the temporary file when an error is returned
+	tempPath, _, err := enc.CreateEncryptTemp(dir)
+	if err == nil {
+		t.Fatalf("expect to get an error, but get nil")
+	}
+	if len(tempPath) > 0 {
+		t.Errorf("expect to get an empty temporary path, but get %s", tempPath)
+	}
+	entries, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("read the temporary directory error => %v", err)
+	}
+	if len(entries) > 0 {
+		t.Errorf("expect the temporary file is removed, but get %d files", len(entries))
+	}
+}
+
 func testEncrypt(encryptOpt Option, decryptOpt Option, sourcePath string, originPath string, encryptFilePath string) error {
 	// encrypt
 	enc, err := NewEncrypt(encryptOpt, sourcePath)
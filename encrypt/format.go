@@ -0,0 +1,214 @@
+package encrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// FormatAESGCM the chunked AES-GCM encryption format
+	FormatAESGCM = "aes-gcm"
+	// FormatXChaCha20Poly1305 the chunked XChaCha20-Poly1305 encryption format
+	FormatXChaCha20Poly1305 = "xchacha20-poly1305"
+	// FormatAESGCMBlock the block AES-GCM encryption format, the unchanged blocks are encrypted to the same ciphertext,
+	// so the chunk compare can skip them
+	FormatAESGCMBlock = "aes-gcm-block"
+	// FormatXChaCha20Poly1305Block the block XChaCha20-Poly1305 encryption format, the unchanged blocks are encrypted to the same ciphertext,
+	// so the chunk compare can skip them
+	FormatXChaCha20Poly1305Block = "xchacha20-poly1305-block"
+	// FormatAge the age format that encrypts the files to one or more X25519 public keys,
+	// so the files can be encrypted without the ability to decrypt them
+	FormatAge = "age"
+	// FormatLegacy the legacy AES-CFB encryption format with the fixed IV in a zip container, it is unauthenticated and only for compatibility
+	FormatLegacy = "legacy"
+
+	// DefaultFormat the default encryption format
+	DefaultFormat = FormatAESGCM
+
+	// aeadVersion the current version of the chunked AEAD format
+	aeadVersion byte = 1
+	// aeadChunkSize the max size of the plaintext in a chunk
+	aeadChunkSize = 64 * 1024
+	// aeadNonceCounterSize the last bytes of the nonce are the chunk counter and the last chunk flag
+	aeadNonceCounterSize = 9
+)
+
+const (
+	algorithmAESGCM byte = iota + 1
+	algorithmXChaCha20Poly1305
+	algorithmAESGCMBlock
+	algorithmXChaCha20Poly1305Block
+)
+
+var (
+	// aeadMagic the magic number at the beginning of the file that is encrypted with the chunked AEAD format
+	aeadMagic = []byte("GOFSENC")
+
+	errUnsupportedFormat = errors.New("unsupported encryption format")
+	errUnsupportedHeader = errors.New("unsupported encryption header")
+	errAuthFailed        = errors.New("message authentication failed, the file is corrupted or tampered, or the secret is wrong")
+	errTruncated         = errors.New("the encrypted file is truncated")
+)
+
+// aeadHeader the header of the file that is encrypted with the chunked AEAD format, the layout is
+// magic(7) | version(1) | algorithm(1) | chunk size(4) | nonce(aead nonce size),
+// the nonce is random for the chunked formats, and it is the file id for the block formats
+type aeadHeader struct {
+	version   byte
+	algorithm byte
+	chunkSize uint32
+	nonce     []byte
+}
+
+func (h aeadHeader) bytes() []byte {
+	buf := bytes.NewBuffer(nil)
+	buf.Write(aeadMagic)
+	buf.WriteByte(h.version)
+	buf.WriteByte(h.algorithm)
+	binary.Write(buf, binary.BigEndian, h.chunkSize)
+	buf.Write(h.nonce)
+	return buf.Bytes()
+}
+
+// newAEADHeader create a header with a random nonce for the new file
+func newAEADHeader(algorithm byte, nonceSize int) (h aeadHeader, err error) {
+	h = aeadHeader{
+		version:   aeadVersion,
+		algorithm: algorithm,
+		chunkSize: aeadChunkSize,
+		nonce:     make([]byte, nonceSize),
+	}
+	_, err = rand.Read(h.nonce)
+	return h, err
+}
+
+// readAEADHeader read the header after the magic number
+func readAEADHeader(r io.Reader) (h aeadHeader, err error) {
+	fixed := make([]byte, 6)
+	if _, err = io.ReadFull(r, fixed); err != nil {
+		return h, fmt.Errorf("%w => %w", errTruncated, err)
+	}
+	h.version = fixed[0]
+	h.algorithm = fixed[1]
+	h.chunkSize = binary.BigEndian.Uint32(fixed[2:])
+	if h.version != aeadVersion {
+		return h, fmt.Errorf("%w, version=%d", errUnsupportedHeader, h.version)
+	}
+	if h.chunkSize == 0 || h.chunkSize > 16*aeadChunkSize {
+		return h, fmt.Errorf("%w, chunk size=%d", errUnsupportedHeader, h.chunkSize)
+	}
+	nonceSize, err := algorithmNonceSize(h.algorithm)
+	if err != nil {
+		return h, err
+	}
+	h.nonce = make([]byte, nonceSize)
+	if _, err = io.ReadFull(r, h.nonce); err != nil {
+		return h, fmt.Errorf("%w => %w", errTruncated, err)
+	}
+	return h, nil
+}
+
+// isAEADFormat check the beginning of the file is the magic number of the chunked AEAD format or not
+func isAEADFormat(prefix []byte) bool {
+	return bytes.Equal(prefix, aeadMagic)
+}
+
+// isBlockFormat check the encryption format is the block format or not
+func isBlockFormat(format string) bool {
+	return format == FormatAESGCMBlock || format == FormatXChaCha20Poly1305Block
+}
+
+func isBlockAlgorithm(algorithm byte) bool {
+	return algorithm == algorithmAESGCMBlock || algorithm == algorithmXChaCha20Poly1305Block
+}
+
+// checkFormat check the encryption format is supported and the secret is valid for it
+func checkFormat(format string, key []byte) error {
+	switch format {
+	case FormatAESGCM, FormatAESGCMBlock, FormatLegacy:
+		return checkAESKey(key)
+	case FormatXChaCha20Poly1305, FormatXChaCha20Poly1305Block:
+		if len(key) != chacha20poly1305.KeySize {
+			return fmt.Errorf("%w, the secret length of %s must be %d, but get %d", errUnsupportedFormat, format, chacha20poly1305.KeySize, len(key))
+		}
+		return nil
+	}
+	return fmt.Errorf("%w => %s", errUnsupportedFormat, format)
+}
+
+func formatAlgorithm(format string) (byte, error) {
+	switch format {
+	case FormatAESGCM:
+		return algorithmAESGCM, nil
+	case FormatXChaCha20Poly1305:
+		return algorithmXChaCha20Poly1305, nil
+	case FormatAESGCMBlock:
+		return algorithmAESGCMBlock, nil
+	case FormatXChaCha20Poly1305Block:
+		return algorithmXChaCha20Poly1305Block, nil
+	}
+	return 0, fmt.Errorf("%w => %s", errUnsupportedFormat, format)
+}
+
+// algorithmFormat return the encryption format of the algorithm in the header
+func algorithmFormat(algorithm byte) (string, error) {
+	switch algorithm {
+	case algorithmAESGCM:
+		return FormatAESGCM, nil
+	case algorithmXChaCha20Poly1305:
+		return FormatXChaCha20Poly1305, nil
+	case algorithmAESGCMBlock:
+		return FormatAESGCMBlock, nil
+	case algorithmXChaCha20Poly1305Block:
+		return FormatXChaCha20Poly1305Block, nil
+	}
+	return "", fmt.Errorf("%w, algorithm=%d", errUnsupportedHeader, algorithm)
+}
+
+func algorithmNonceSize(algorithm byte) (int, error) {
+	switch algorithm {
+	case algorithmAESGCM, algorithmAESGCMBlock:
+		return 12, nil
+	case algorithmXChaCha20Poly1305, algorithmXChaCha20Poly1305Block:
+		return chacha20poly1305.NonceSizeX, nil
+	}
+	return 0, fmt.Errorf("%w, algorithm=%d", errUnsupportedHeader, algorithm)
+}
+
+func newAEAD(algorithm byte, key []byte) (cipher.AEAD, error) {
+	switch algorithm {
+	case algorithmAESGCM, algorithmAESGCMBlock:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case algorithmXChaCha20Poly1305, algorithmXChaCha20Poly1305Block:
+		return chacha20poly1305.NewX(key)
+	}
+	return nil, fmt.Errorf("%w, algorithm=%d", errUnsupportedHeader, algorithm)
+}
+
+// chunkNonce return the nonce of the chunk, the random nonce of the file is XORed with the chunk counter and the last chunk flag,
+// so the chunks can't be reordered, and the truncation is detected by the last chunk flag
+func chunkNonce(dst []byte, nonce []byte, counter uint64, last bool) []byte {
+	dst = append(dst[:0], nonce...)
+	var suffix [aeadNonceCounterSize]byte
+	binary.BigEndian.PutUint64(suffix[:8], counter)
+	if last {
+		suffix[8] = 1
+	}
+	offset := len(dst) - aeadNonceCounterSize
+	for i, b := range suffix {
+		dst[offset+i] ^= b
+	}
+	return dst
+}
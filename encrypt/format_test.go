@@ -0,0 +1,204 @@
+package encrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestAEADFormat(t *testing.T) {
+	testCases := []struct {
+		format string
+		size   int
+	}{
+		{FormatAESGCM, 0},
+		{FormatAESGCM, 1},
+		{FormatAESGCM, aeadChunkSize - 1},
+		{FormatAESGCM, aeadChunkSize},
+		{FormatAESGCM, aeadChunkSize + 1},
+		{FormatAESGCM, aeadChunkSize*3 + 100},
+		{FormatXChaCha20Poly1305, 0},
+		{FormatXChaCha20Poly1305, aeadChunkSize},
+		{FormatXChaCha20Poly1305, aeadChunkSize*2 + 1},
+		{FormatAESGCMBlock, 0},
+		{FormatAESGCMBlock, aeadChunkSize},
+		{FormatAESGCMBlock, aeadChunkSize*3 + 100},
+		{FormatXChaCha20Poly1305Block, 1},
+		{FormatXChaCha20Poly1305Block, aeadChunkSize*2 + 1},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s_%d", tc.format, tc.size), func(t *testing.T) {
+			data := randomData(t, tc.size)
+			encrypted := aeadEncrypt(t, tc.format, []byte(secret), data)
+			if expectSize, ok := blockEncryptedSize(tc.format, int64(tc.size)); ok && expectSize != int64(len(encrypted)) {
+				t.Errorf("expect get the encrypted size %d but get %d", expectSize, len(encrypted))
+			}
+			actual, err := aeadDecrypt(encrypted, []byte(secret))
+			if err != nil {
+				t.Errorf("decrypt error => %v", err)
+				return
+			}
+			if !bytes.Equal(data, actual) {
+				t.Errorf("the decrypted data is not equal to the origin data")
+			}
+		})
+	}
+}
+
+func TestAEADFormat_RandomNonce(t *testing.T) {
+	data := []byte("hello gofs")
+	c1 := aeadEncrypt(t, FormatAESGCM, []byte(secret), data)
+	c2 := aeadEncrypt(t, FormatAESGCM, []byte(secret), data)
+	if bytes.Equal(c1, c2) {
+		t.Errorf("expect to get the different ciphertext of the same data, but get the same")
+	}
+}
+
+func TestBlockFormat_Deterministic(t *testing.T) {
+	data := randomData(t, aeadChunkSize*4)
+	encrypted := aeadEncrypt(t, FormatAESGCMBlock, []byte(secret), data)
+	if !bytes.Equal(encrypted, aeadEncrypt(t, FormatAESGCMBlock, []byte(secret), data)) {
+		t.Fatalf("expect to get the same ciphertext of the same data with the block format, but get the different")
+	}
+
+	// modify the second block, only the second block of the ciphertext is changed
+	modified := bytes.Clone(data)
+	modified[aeadChunkSize+10] ^= 0xff
+	modifiedEncrypted := aeadEncrypt(t, FormatAESGCMBlock, []byte(secret), modified)
+	headerSize := len(aeadMagic) + 6 + 12
+	encryptedBlockSize := 12 + aeadChunkSize + 16
+	for i := 0; i < 4; i++ {
+		start := headerSize + i*encryptedBlockSize
+		equal := bytes.Equal(encrypted[start:start+encryptedBlockSize], modifiedEncrypted[start:start+encryptedBlockSize])
+		if equal != (i != 1) {
+			t.Errorf("expect the block %d is changed %v, but get %v", i, i == 1, !equal)
+		}
+	}
+
+	// the file id is derived from the identity, so the same data of the different files is encrypted to the different ciphertext
+	buf := bytes.NewBuffer(nil)
+	w, err := newAEADEncryptWriter(buf, FormatAESGCMBlock, []byte(secret), "other.txt")
+	if err != nil {
+		t.Fatalf("init aead encrypt writer error => %v", err)
+	}
+	w.Write(data)
+	w.Close()
+	if bytes.Equal(encrypted[headerSize:], buf.Bytes()[headerSize:]) {
+		t.Errorf("expect to get the different ciphertext of the different files, but get the same")
+	}
+}
+
+func TestAEADFormat_ReturnError(t *testing.T) {
+	testAEADFormatReturnError(t, FormatAESGCM, aeadChunkSize+16)
+	testAEADFormatReturnError(t, FormatAESGCMBlock, 12+aeadChunkSize+16)
+}
+
+func testAEADFormatReturnError(t *testing.T, format string, encryptedChunkSize int) {
+	data := randomData(t, aeadChunkSize*2+10)
+	encrypted := aeadEncrypt(t, format, []byte(secret), data)
+	headerSize := len(aeadMagic) + 6 + 12
+
+	tamper := func(offset int) []byte {
+		c := bytes.Clone(encrypted)
+		c[offset] ^= 0xff
+		return c
+	}
+	reorder := func() []byte {
+		c := bytes.Clone(encrypted)
+		first := bytes.Clone(c[headerSize : headerSize+encryptedChunkSize])
+		copy(c[headerSize:], c[headerSize+encryptedChunkSize:headerSize+encryptedChunkSize*2])
+		copy(c[headerSize+encryptedChunkSize:], first)
+		return c
+	}
+
+	testCases := []struct {
+		name   string
+		data   []byte
+		secret string
+		expect error
+	}{
+		{"tamper the header", tamper(len(aeadMagic) + 7), secret, errAuthFailed},
+		{"tamper the first chunk", tamper(headerSize + 10), secret, errAuthFailed},
+		{"tamper the last chunk", tamper(len(encrypted) - 1), secret, errAuthFailed},
+		{"reorder the chunks", reorder(), secret, errAuthFailed},
+		{"truncate at the chunk boundary", encrypted[:headerSize+encryptedChunkSize], secret, errTruncated},
+		{"truncate in the chunk", encrypted[:headerSize+encryptedChunkSize+100], secret, errAuthFailed},
+		{"truncate in the chunk tag", encrypted[:headerSize+encryptedChunkSize+10], secret, errTruncated},
+		{"truncate all the chunks", encrypted[:headerSize], secret, errTruncated},
+		{"truncate the header", encrypted[:headerSize-1], secret, errTruncated},
+		{"wrong secret", encrypted, "encrypt_secure_encrypt_secure_02", errAuthFailed},
+	}
+	for _, tc := range testCases {
+		t.Run(format+"_"+tc.name, func(t *testing.T) {
+			_, err := aeadDecrypt(tc.data, []byte(tc.secret))
+			if !errors.Is(err, tc.expect) {
+				t.Errorf("expect get error => [%v] but get [%v]", tc.expect, err)
+			}
+		})
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	testCases := []struct {
+		format string
+		key    string
+		valid  bool
+	}{
+		{FormatAESGCM, "1234567890123456", true},
+		{FormatAESGCM, secret, true},
+		{FormatAESGCM, "123456789012345", false},
+		{FormatLegacy, "1234567890123456", true},
+		{FormatXChaCha20Poly1305, secret, true},
+		{FormatXChaCha20Poly1305, "1234567890123456", false},
+		{FormatAESGCMBlock, "1234567890123456", true},
+		{FormatXChaCha20Poly1305Block, secret, true},
+		{FormatXChaCha20Poly1305Block, "1234567890123456", false},
+		{"", secret, false},
+		{"aes-cbc", secret, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.format+"_"+tc.key, func(t *testing.T) {
+			err := checkFormat(tc.format, []byte(tc.key))
+			if tc.valid != (err == nil) {
+				t.Errorf("check format expect valid is %v, but get error %v", tc.valid, err)
+			}
+		})
+	}
+}
+
+func randomData(t *testing.T, size int) []byte {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatalf("generate random data error => %v", err)
+	}
+	return data
+}
+
+func aeadEncrypt(t *testing.T, format string, key []byte, data []byte) []byte {
+	buf := bytes.NewBuffer(nil)
+	w, err := newAEADEncryptWriter(buf, format, key, "hello.txt")
+	if err != nil {
+		t.Fatalf("init aead encrypt writer error => %v", err)
+	}
+	if _, err = w.Write(data); err != nil {
+		t.Fatalf("write data error => %v", err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatalf("close aead encrypt writer error => %v", err)
+	}
+	return buf.Bytes()
+}
+
+func aeadDecrypt(data []byte, key []byte) ([]byte, error) {
+	if !isAEADFormat(data[:len(aeadMagic)]) {
+		return nil, errUnsupportedHeader
+	}
+	r, err := newAEADDecryptReader(bytes.NewReader(data[len(aeadMagic):]), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
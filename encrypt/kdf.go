@@ -0,0 +1,147 @@
+package encrypt
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KDFScrypt derive the encryption key from the passphrase with scrypt
+	KDFScrypt = "scrypt"
+	// KDFArgon2id derive the encryption key from the passphrase with argon2id
+	KDFArgon2id = "argon2id"
+
+	// KeyHeaderFile the name of the key header file that stores the salt and the parameters of the key derivation,
+	// it is created in the root of the encrypt path and synced to the destination without encryption
+	KeyHeaderFile = ".gofs-key.json"
+
+	keyHeaderVersion = 1
+	kdfKeyLen        = 32
+	kdfSaltLen       = 16
+
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+
+	argon2idKDFTime    = 1
+	argon2idKDFMemory  = 64 * 1024
+	argon2idKDFThreads = 4
+)
+
+var (
+	errUnsupportedKDF = errors.New("unsupported key derivation function")
+	errKDFMismatch    = errors.New("the key derivation function is not matched with the existing key header")
+	errInvalidKDF     = errors.New("invalid key derivation parameters")
+	errEmptySecret    = errors.New("the secret can't be empty")
+)
+
+// keyHeader the salt and the parameters of the key derivation, it is not secret
+type keyHeader struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	Salt    []byte `json:"salt"`
+	// scrypt parameters
+	N int `json:"n,omitempty"`
+	R int `json:"r,omitempty"`
+	P int `json:"p,omitempty"`
+	// argon2id parameters
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+}
+
+// newKeyHeader create a key header with a random salt and the default parameters of the key derivation function
+func newKeyHeader(kdf string) (h keyHeader, err error) {
+	h = keyHeader{
+		Version: keyHeaderVersion,
+		KDF:     kdf,
+		Salt:    make([]byte, kdfSaltLen),
+	}
+	switch kdf {
+	case KDFScrypt:
+		h.N, h.R, h.P = scryptN, scryptR, scryptP
+	case KDFArgon2id:
+		h.Time, h.Memory, h.Threads = argon2idKDFTime, argon2idKDFMemory, argon2idKDFThreads
+	default:
+		return h, fmt.Errorf("%w => %s", errUnsupportedKDF, kdf)
+	}
+	_, err = rand.Read(h.Salt)
+	return h, err
+}
+
+// deriveKey derive the encryption key from the passphrase
+func (h keyHeader) deriveKey(passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, errEmptySecret
+	}
+	if h.Version != keyHeaderVersion || len(h.Salt) < kdfSaltLen {
+		return nil, fmt.Errorf("%w, version=%d salt length=%d", errInvalidKDF, h.Version, len(h.Salt))
+	}
+	switch h.KDF {
+	case KDFScrypt:
+		return scrypt.Key(passphrase, h.Salt, h.N, h.R, h.P, kdfKeyLen)
+	case KDFArgon2id:
+		if h.Time == 0 || h.Memory == 0 || h.Threads == 0 {
+			return nil, fmt.Errorf("%w, time=%d memory=%d threads=%d", errInvalidKDF, h.Time, h.Memory, h.Threads)
+		}
+		return argon2.IDKey(passphrase, h.Salt, h.Time, h.Memory, h.Threads, kdfKeyLen), nil
+	}
+	return nil, fmt.Errorf("%w => %s", errUnsupportedKDF, h.KDF)
+}
+
+// loadKeyHeader load the key header file in the directory, return false if the key header file does not exist
+func loadKeyHeader(dir string) (h keyHeader, exist bool, err error) {
+	data, err := os.ReadFile(filepath.Join(dir, KeyHeaderFile))
+	if os.IsNotExist(err) {
+		return h, false, nil
+	}
+	if err != nil {
+		return h, false, err
+	}
+	err = json.Unmarshal(data, &h)
+	return h, err == nil, err
+}
+
+// loadOrCreateKeyHeader load the key header file in the directory, create it if it does not exist
+func loadOrCreateKeyHeader(dir string, kdf string) (h keyHeader, err error) {
+	h, exist, err := loadKeyHeader(dir)
+	if err != nil {
+		return h, err
+	}
+	if exist {
+		return h, h.checkKDF(kdf, dir)
+	}
+	if h, err = newKeyHeader(kdf); err != nil {
+		return h, err
+	}
+	return h, writeKeyHeader(dir, h)
+}
+
+// checkKDF check the key derivation function of the key header in the directory is matched with the specified one
+func (h keyHeader) checkKDF(kdf string, dir string) error {
+	if h.KDF != kdf {
+		return fmt.Errorf("%w, expect %s but get %s => %s", errKDFMismatch, kdf, h.KDF, filepath.Join(dir, KeyHeaderFile))
+	}
+	return nil
+}
+
+// writeKeyHeader write the key header file to the directory, the existing key header file is replaced
+func writeKeyHeader(dir string, h keyHeader) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, KeyHeaderFile), data, 0644)
+}
+
+// isKeyHeaderFile check the path is the key header file or not
+func isKeyHeaderFile(path string) bool {
+	return filepath.Base(path) == KeyHeaderFile
+}
@@ -0,0 +1,178 @@
+package encrypt
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/no-src/gofs/conf"
+	"github.com/no-src/gofs/logger"
+)
+
+func TestEncrypt_KDF(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	passphrase := "my passphrase"
+	for _, kdf := range []string{KDFScrypt, KDFArgon2id} {
+		t.Run(kdf, func(t *testing.T) {
+			source := t.TempDir()
+			dest := t.TempDir()
+			out := t.TempDir()
+			data := []byte("hello gofs")
+
+			encryptOpt := NewOption(conf.Config{
+				Encrypt:       true,
+				EncryptPath:   source,
+				EncryptSecret: passphrase,
+				EncryptKDF:    kdf,
+			}, logger)
+			enc, err := NewEncrypt(encryptOpt, source)
+			if err != nil {
+				t.Fatalf("init encrypt component error => %v", err)
+			}
+			headerPath := filepath.Join(source, KeyHeaderFile)
+			if enc.NeedEncrypt(headerPath) {
+				t.Errorf("the key header file should not be encrypted")
+			}
+			if !enc.NeedEncrypt(filepath.Join(source, "hello.txt")) {
+				t.Errorf("the file in the encrypt path should be encrypted")
+			}
+
+			// the key header is reused by the next encryption component
+			enc2, err := NewEncrypt(encryptOpt, source)
+			if err != nil {
+				t.Fatalf("init encrypt component again error => %v", err)
+			}
+			if !bytes.Equal(enc.opt.EncryptSecret, enc2.opt.EncryptSecret) {
+				t.Errorf("expect to derive the same key with the existing key header")
+			}
+
+			// simulate the sync, the key header is copied to the destination without encryption
+			header, err := os.ReadFile(headerPath)
+			if err != nil {
+				t.Fatalf("read the key header error => %v", err)
+			}
+			if err = os.WriteFile(filepath.Join(dest, KeyHeaderFile), header, 0644); err != nil {
+				t.Fatalf("write the key header error => %v", err)
+			}
+			buf := bytes.NewBuffer(nil)
+			w, err := enc.NewWriter(buf, filepath.Join(source, "hello.txt"), "hello.txt")
+			if err != nil {
+				t.Fatalf("init encrypt writer error => %v", err)
+			}
+			io.Copy(w, bytes.NewReader(data))
+			w.Close()
+			if err = os.WriteFile(filepath.Join(dest, "hello.txt"), buf.Bytes(), 0644); err != nil {
+				t.Fatalf("write the encrypted file error => %v", err)
+			}
+
+			decrypt := func(secret string) error {
+				dec, err := NewDecrypt(NewOption(conf.Config{
+					Decrypt:       true,
+					DecryptPath:   dest,
+					DecryptSecret: secret,
+					DecryptOut:    out,
+				}, logger))
+				if err != nil {
+					return err
+				}
+				return dec.Decrypt()
+			}
+
+			if err = decrypt("wrong passphrase"); !errors.Is(err, errAuthFailed) {
+				t.Errorf("expect get error => [%v] but get [%v]", errAuthFailed, err)
+			}
+			if err = decrypt(passphrase); err != nil {
+				t.Fatalf("decrypt error => %v", err)
+			}
+			actual, err := os.ReadFile(filepath.Join(out, "hello.txt"))
+			if err != nil {
+				t.Fatalf("read the decrypted file error => %v", err)
+			}
+			if !bytes.Equal(data, actual) {
+				t.Errorf("the decrypted data is not equal to the origin data")
+			}
+			if _, err = os.Stat(filepath.Join(out, KeyHeaderFile)); !os.IsNotExist(err) {
+				t.Errorf("the key header file should not be decrypted, err=%v", err)
+			}
+		})
+	}
+}
+
+func TestEncrypt_KDF_ReturnError(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	source := t.TempDir()
+	if _, err := loadOrCreateKeyHeader(source, KDFScrypt); err != nil {
+		t.Fatalf("create the key header error => %v", err)
+	}
+
+	testCases := []struct {
+		name   string
+		kdf    string
+		secret string
+		expect error
+	}{
+		{"kdf mismatch", KDFArgon2id, secret, errKDFMismatch},
+		{"unsupported kdf", "pbkdf2", secret, errUnsupportedKDF},
+		{"empty secret", KDFScrypt, "", errEmptySecret},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := source
+			if tc.expect == errUnsupportedKDF {
+				dir = t.TempDir()
+			}
+			_, err := NewEncrypt(NewOption(conf.Config{
+				Encrypt:       true,
+				EncryptPath:   dir,
+				EncryptSecret: tc.secret,
+				EncryptKDF:    tc.kdf,
+			}, logger), dir)
+			if !errors.Is(err, tc.expect) {
+				t.Errorf("expect get error => [%v] but get [%v]", tc.expect, err)
+			}
+		})
+	}
+}
+
+func TestResolveSecret(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(secretFile, []byte("secret from file\r\n"), 0600); err != nil {
+		t.Fatalf("write the secret file error => %v", err)
+	}
+	t.Setenv("GOFS_TEST_SECRET", "secret from env")
+
+	testCases := []struct {
+		name   string
+		secret string
+		file   string
+		env    string
+		expect string
+		err    error
+	}{
+		{"secret", "secret", "", "", "secret", nil},
+		{"file", "", secretFile, "", "secret from file", nil},
+		{"env", "", "", "GOFS_TEST_SECRET", "secret from env", nil},
+		{"empty env", "", "", "GOFS_TEST_SECRET_NOT_EXIST", "", errEmptySecret},
+		{"ambiguous", "secret", secretFile, "", "", errAmbiguousSecret},
+		{"file not exist", "", secretFile + ".not_exist", "", "", os.ErrNotExist},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := resolveSecret([]byte(tc.secret), tc.file, tc.env)
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expect get error => [%v] but get [%v]", tc.err, err)
+				return
+			}
+			if string(actual) != tc.expect {
+				t.Errorf("expect get secret [%s] but get [%s]", tc.expect, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package encrypt
+
+import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	nameKeyInfo = "nosrc-gofs-name-encryption"
+	// maxEncryptedNameLength the max length of the file name on the most file systems
+	maxEncryptedNameLength = 255
+)
+
+// nameEncoding the encrypted names only contain the uppercase letters and digits, so they are valid on all the file systems
+var nameEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// maxPlainNameLength the max length of the name that can be encrypted, the encrypted name is about 1.6 times the length
+// of the name plus 26 bytes, so the name must not be longer than 143 bytes, otherwise the encrypted name exceeds the limit
+var maxPlainNameLength = nameEncoding.DecodedLen(maxEncryptedNameLength) - sivBlockSize
+
+var errNameTooLong = errors.New("the name is too long to encrypt")
+
+// nameCipher encrypt the file and directory names deterministically with AES-SIV and encode them with base32,
+// so the same name is always encrypted to the same name and the encrypted path can be found without any index
+type nameCipher struct {
+	siv *aesSIV
+}
+
+// newNameCipher create a nameCipher with the key that is derived from the encryption key
+func newNameCipher(key []byte) (*nameCipher, error) {
+	nameKey, err := hkdf.Key(sha256.New, key, nil, nameKeyInfo, 64)
+	if err != nil {
+		return nil, err
+	}
+	siv, err := newAESSIV(nameKey)
+	if err != nil {
+		return nil, err
+	}
+	return &nameCipher{siv: siv}, nil
+}
+
+// encryptName encrypt the name, return an error if the encrypted name exceeds the max length of the file name
+func (c *nameCipher) encryptName(name string) (string, error) {
+	if len(name) > maxPlainNameLength {
+		return "", fmt.Errorf("%w, the name is %d bytes, but the max length is %d bytes when the name encryption is enabled => %s", errNameTooLong, len(name), maxPlainNameLength, name)
+	}
+	return nameEncoding.EncodeToString(c.siv.Seal([]byte(name))), nil
+}
+
+// decryptName decrypt the encrypted name, return false if the name is not encrypted with the key
+func (c *nameCipher) decryptName(name string) (string, bool) {
+	data, err := nameEncoding.DecodeString(name)
+	if err != nil {
+		return name, false
+	}
+	plain, err := c.siv.Open(data)
+	if err != nil || !isValidName(string(plain)) {
+		return name, false
+	}
+	return string(plain), true
+}
+
+// decryptRelPath decrypt all the encrypted names in the relative path, the names that are not encrypted are retained
+func (c *nameCipher) decryptRelPath(rel string) string {
+	if c == nil {
+		return rel
+	}
+	names := strings.Split(rel, string(filepath.Separator))
+	for i, name := range names {
+		names[i], _ = c.decryptName(name)
+	}
+	return filepath.Join(names...)
+}
+
+// isValidName the decrypted name can't escape the output directory
+func isValidName(name string) bool {
+	return len(name) > 0 && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
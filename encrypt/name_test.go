@@ -0,0 +1,242 @@
+package encrypt
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/no-src/gofs/conf"
+	"github.com/no-src/gofs/logger"
+)
+
+func TestEncrypt_EncryptRelPath(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	source := t.TempDir()
+	encryptDir := filepath.Join(source, "encrypt")
+	if err := os.MkdirAll(encryptDir, 0755); err != nil {
+		t.Fatalf("create the encrypt path error => %v", err)
+	}
+	enc, err := NewEncrypt(NewOption(conf.Config{
+		Encrypt:       true,
+		EncryptPath:   encryptDir,
+		EncryptSecret: "my passphrase",
+		EncryptKDF:    KDFScrypt,
+		EncryptName:   true,
+	}, logger), source)
+	if err != nil {
+		t.Fatalf("init encrypt component error => %v", err)
+	}
+	dec, err := NewDecrypt(NewOption(conf.Config{
+		Decrypt:       true,
+		DecryptPath:   encryptDir,
+		DecryptSecret: "my passphrase",
+	}, logger))
+	if err != nil {
+		t.Fatalf("init decrypt component error => %v", err)
+	}
+
+	testCases := []struct {
+		rel         string
+		expectPlain bool
+	}{
+		{"encrypt", true},
+		{filepath.Join("plain", "hello.txt"), true},
+		{"encrypt_not_sub.txt", true},
+		{filepath.Join("encrypt", KeyHeaderFile), true},
+		{filepath.Join("encrypt", "hello.txt"), false},
+		{filepath.Join("encrypt", "a", "b", "hello.txt"), false},
+		{filepath.Join("encrypt", "a", KeyHeaderFile), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.rel, func(t *testing.T) {
+			actual := encryptTestRelPath(t, enc, tc.rel)
+			if actual != encryptTestRelPath(t, enc, tc.rel) {
+				t.Errorf("the name encryption should be deterministic")
+			}
+			if tc.expectPlain {
+				if actual != tc.rel {
+					t.Errorf("expect get the plain path [%s] but get [%s]", tc.rel, actual)
+				}
+				return
+			}
+			names := strings.Split(actual, string(filepath.Separator))
+			if names[0] != "encrypt" {
+				t.Errorf("the name of the encrypt path should not be encrypted => %s", actual)
+			}
+			for _, name := range names[1:] {
+				if strings.Contains(tc.rel, name) {
+					t.Errorf("the name should be encrypted => %s", name)
+				}
+			}
+			if decrypted := dec.names.decryptRelPath(actual); decrypted != tc.rel {
+				t.Errorf("expect get the decrypted path [%s] but get [%s]", tc.rel, decrypted)
+			}
+		})
+	}
+}
+
+func TestDecrypt_EncryptName(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	for _, format := range []string{FormatAESGCM, FormatLegacy} {
+		t.Run(format, func(t *testing.T) {
+			source := t.TempDir()
+			dest := t.TempDir()
+			out := t.TempDir()
+			data := []byte("hello gofs")
+
+			enc, err := NewEncrypt(NewOption(conf.Config{
+				Encrypt:       true,
+				EncryptPath:   source,
+				EncryptSecret: secret,
+				EncryptFormat: format,
+				EncryptName:   true,
+			}, logger), source)
+			if err != nil {
+				t.Fatalf("init encrypt component error => %v", err)
+			}
+
+			rel := filepath.Join("a", "hello.txt")
+			destPath := filepath.Join(dest, encryptTestRelPath(t, enc, rel))
+			if err = os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+				t.Fatalf("create the dest directory error => %v", err)
+			}
+			buf := bytes.NewBuffer(nil)
+			w, err := enc.NewWriter(buf, filepath.Join(source, rel), filepath.Base(destPath))
+			if err != nil {
+				t.Fatalf("init encrypt writer error => %v", err)
+			}
+			w.Write(data)
+			w.Close()
+			if bytes.Contains(buf.Bytes(), []byte("hello.txt")) {
+				t.Errorf("the encrypted file should not contain the plain name")
+			}
+			if err = os.WriteFile(destPath, buf.Bytes(), 0644); err != nil {
+				t.Fatalf("write the encrypted file error => %v", err)
+			}
+
+			dec, err := NewDecrypt(NewOption(conf.Config{
+				Decrypt:       true,
+				DecryptPath:   dest,
+				DecryptSecret: secret,
+				DecryptOut:    out,
+			}, logger))
+			if err != nil {
+				t.Fatalf("init decrypt component error => %v", err)
+			}
+			if err = dec.Decrypt(); err != nil {
+				t.Fatalf("decrypt error => %v", err)
+			}
+			actual, err := os.ReadFile(filepath.Join(out, rel))
+			if err != nil {
+				t.Fatalf("read the decrypted file error => %v", err)
+			}
+			if !bytes.Equal(data, actual) {
+				t.Errorf("the decrypted data is not equal to the origin data")
+			}
+		})
+	}
+}
+
+func TestNameCipher_DecryptName(t *testing.T) {
+	c, err := newNameCipher([]byte(secret))
+	if err != nil {
+		t.Fatalf("init name cipher error => %v", err)
+	}
+	other, err := newNameCipher([]byte("encrypt_secure_encrypt_secure_02"))
+	if err != nil {
+		t.Fatalf("init name cipher error => %v", err)
+	}
+	testCases := []struct {
+		name   string
+		expect string
+		ok     bool
+	}{
+		{encryptTestName(t, c, "hello.txt"), "hello.txt", true},
+		{encryptTestName(t, other, "hello.txt"), "", false},
+		{"hello.txt", "hello.txt", false},
+		{"MZXW6YTBOI", "MZXW6YTBOI", false},
+		{encryptTestName(t, c, ".."), "", false},
+		{encryptTestName(t, c, "a/b"), "", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, ok := c.decryptName(tc.name)
+			if ok != tc.ok {
+				t.Errorf("expect decrypt result %v but get %v", tc.ok, ok)
+				return
+			}
+			if ok && actual != tc.expect {
+				t.Errorf("expect get name [%s] but get [%s]", tc.expect, actual)
+			}
+			if !ok && actual != tc.name {
+				t.Errorf("expect retain the name [%s] but get [%s]", tc.name, actual)
+			}
+		})
+	}
+}
+
+func TestNameCipher_EncryptName_TooLong(t *testing.T) {
+	c, err := newNameCipher([]byte(secret))
+	if err != nil {
+		t.Fatalf("init name cipher error => %v", err)
+	}
+	name := strings.Repeat("a", maxPlainNameLength)
+	if encrypted := encryptTestName(t, c, name); len(encrypted) > maxEncryptedNameLength {
+		t.Errorf("expect the encrypted name is not longer than %d bytes, but get %d bytes", maxEncryptedNameLength, len(encrypted))
+	}
+	if _, err = c.encryptName(name + "a"); !errors.Is(err, errNameTooLong) {
+		t.Errorf("expect get error => [%v] but get [%v]", errNameTooLong, err)
+	}
+}
+
+func TestEncrypt_EncryptRelPath_NameTooLong(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	source := t.TempDir()
+	enc, err := NewEncrypt(NewOption(conf.Config{
+		Encrypt:       true,
+		EncryptPath:   source,
+		EncryptSecret: secret,
+		EncryptName:   true,
+	}, logger), source)
+	if err != nil {
+		t.Fatalf("init encrypt component error => %v", err)
+	}
+	longName := strings.Repeat("a", maxPlainNameLength+1)
+	if _, err = enc.EncryptRelPath(filepath.Join("a", longName, "hello.txt")); !errors.Is(err, errNameTooLong) {
+		t.Errorf("expect get error => [%v] but get [%v]", errNameTooLong, err)
+	}
+
+	// return the error before creating the temporary file
+	path := filepath.Join(source, longName)
+	if err = os.WriteFile(path, []byte("hello gofs"), 0600); err != nil {
+		t.Fatalf("write the file error => %v", err)
+	}
+	if _, _, err = enc.CreateEncryptTemp(path); !errors.Is(err, errNameTooLong) {
+		t.Errorf("expect get error => [%v] but get [%v]", errNameTooLong, err)
+	}
+}
+
+func encryptTestName(t *testing.T, c *nameCipher, name string) string {
+	encrypted, err := c.encryptName(name)
+	if err != nil {
+		t.Fatalf("encrypt the name error => %v", err)
+	}
+	return encrypted
+}
+
+func encryptTestRelPath(t *testing.T, enc *Encrypt, rel string) string {
+	encrypted, err := enc.EncryptRelPath(rel)
+	if err != nil {
+		t.Fatalf("encrypt the relative path error => %v", err)
+	}
+	return encrypted
+}
@@ -7,32 +7,59 @@ import (
 
 // Option the encryption option
 type Option struct {
-	Encrypt       bool
-	EncryptPath   string
-	EncryptSecret []byte
+	Encrypt               bool
+	EncryptPath           string
+	EncryptSecret         []byte
+	EncryptSecretFile     string
+	EncryptSecretEnv      string
+	EncryptFormat         string
+	EncryptKDF            string
+	EncryptName           bool
+	EncryptRecipients     string
+	EncryptRecipientsFile string
 
-	Decrypt       bool
-	DecryptPath   string
-	DecryptSecret []byte
-	DecryptOut    string
+	Decrypt             bool
+	DecryptPath         string
+	DecryptSecret       []byte
+	DecryptSecretFile   string
+	DecryptSecretEnv    string
+	DecryptOut          string
+	DecryptIdentityFile string
+
+	RekeyFormat string
 
 	Logger *logger.Logger
 }
 
 // NewOption create an encryption option
 func NewOption(config conf.Config, logger *logger.Logger) Option {
-	if !config.Encrypt && !config.Decrypt {
+	if !config.Encrypt && !config.Decrypt && !config.Rekey && !config.ServerDecrypt {
 		return EmptyOption()
 	}
+	format := config.EncryptFormat
+	if len(format) == 0 {
+		format = DefaultFormat
+	}
 	return Option{
-		Encrypt:       config.Encrypt,
-		EncryptPath:   config.EncryptPath,
-		EncryptSecret: []byte(config.EncryptSecret),
-		Decrypt:       config.Decrypt,
-		DecryptPath:   config.DecryptPath,
-		DecryptSecret: []byte(config.DecryptSecret),
-		DecryptOut:    config.DecryptOut,
-		Logger:        logger,
+		Encrypt:               config.Encrypt,
+		EncryptPath:           config.EncryptPath,
+		EncryptSecret:         []byte(config.EncryptSecret),
+		EncryptSecretFile:     config.EncryptSecretFile,
+		EncryptSecretEnv:      config.EncryptSecretEnv,
+		EncryptFormat:         format,
+		EncryptKDF:            config.EncryptKDF,
+		EncryptName:           config.EncryptName,
+		EncryptRecipients:     config.EncryptRecipients,
+		EncryptRecipientsFile: config.EncryptRecipientsFile,
+		Decrypt:               config.Decrypt,
+		DecryptPath:           config.DecryptPath,
+		DecryptSecret:         []byte(config.DecryptSecret),
+		DecryptSecretFile:     config.DecryptSecretFile,
+		DecryptSecretEnv:      config.DecryptSecretEnv,
+		DecryptOut:            config.DecryptOut,
+		DecryptIdentityFile:   config.DecryptIdentityFile,
+		RekeyFormat:           config.RekeyFormat,
+		Logger:                logger,
 	}
 }
 
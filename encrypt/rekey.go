@@ -0,0 +1,529 @@
+package encrypt
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/no-src/gofs/logger"
+)
+
+const (
+	// rekeyTempSuffix the suffix of the temporary file that is uploaded before replacing the encrypted file
+	rekeyTempSuffix = ".gofs-rekey"
+)
+
+var (
+	errRekeyUnsupportedFormat = errors.New("the legacy and age formats are not supported by the key rotation")
+	errRekeyFailed            = errors.New("some files are failed to rekey, run it again to resume")
+)
+
+// Rekey decrypt the encrypted files in the destination with the old key and re-encrypt them with the new key,
+// the progress is recorded in the progress file, so it can be interrupted and resumed
+type Rekey struct {
+	fsys         RekeyFS
+	root         string
+	progressFile string
+	// format convert the files to the format, the format of every file is kept if it is empty
+	format      string
+	encryptPath string
+
+	oldSecret []byte
+	newSecret []byte
+	kdf       string
+	oldKey    []byte
+	newKey    []byte
+	oldNames  *nameCipher
+	newNames  *nameCipher
+	// newHeader the new key header that is uploaded after all the files are rekeyed
+	newHeader *keyHeader
+	done      map[string]bool
+	logger    *logger.Logger
+}
+
+// rekeyRecord a line of the progress file
+type rekeyRecord struct {
+	// Path the rekeyed file that is relative to the root
+	Path string `json:"path,omitempty"`
+	// KeyHeader the new key header that is not uploaded yet
+	KeyHeader *keyHeader `json:"key_header,omitempty"`
+}
+
+// NewRekey create a key rotation component, the old secret is the decrypt secret and the new secret is the encrypt secret of the option,
+// the root is the slash-separated path of the encrypted destination in the fsys
+func NewRekey(opt Option, fsys RekeyFS, root string, progressFile string) (*Rekey, error) {
+	if opt.EncryptFormat == FormatLegacy || opt.EncryptFormat == FormatAge || opt.RekeyFormat == FormatLegacy || opt.RekeyFormat == FormatAge {
+		return nil, errRekeyUnsupportedFormat
+	}
+	oldSecret, err := resolveSecret(opt.DecryptSecret, opt.DecryptSecretFile, opt.DecryptSecretEnv)
+	if err != nil {
+		return nil, err
+	}
+	newSecret, err := resolveSecret(opt.EncryptSecret, opt.EncryptSecretFile, opt.EncryptSecretEnv)
+	if err != nil {
+		return nil, err
+	}
+	if len(oldSecret) == 0 || len(newSecret) == 0 {
+		return nil, errEmptySecret
+	}
+	if len(opt.EncryptKDF) > 0 {
+		if _, err = newKeyHeader(opt.EncryptKDF); err != nil {
+			return nil, err
+		}
+	}
+	rk := &Rekey{
+		fsys:         fsys,
+		root:         path.Clean(root),
+		progressFile: progressFile,
+		format:       opt.RekeyFormat,
+		encryptPath:  opt.EncryptPath,
+		oldSecret:    oldSecret,
+		newSecret:    newSecret,
+		kdf:          opt.EncryptKDF,
+		done:         make(map[string]bool),
+		logger:       opt.Logger,
+	}
+	return rk, rk.loadProgress()
+}
+
+// initKeys init the old key and the new key, the key header of the destination is retained if the key derivation function is not changed
+func (rk *Rekey) initKeys(headerExist bool) (err error) {
+	var h keyHeader
+	if headerExist {
+		if h, err = rk.readKeyHeader(); err != nil {
+			return err
+		}
+		rk.oldKey, err = h.deriveKey(rk.oldSecret)
+	} else {
+		rk.oldKey, err = rk.oldSecret, checkAESKey(rk.oldSecret)
+	}
+	if err != nil {
+		return err
+	}
+	if err = rk.initNewKey(h, headerExist); err != nil {
+		return err
+	}
+	if len(rk.format) > 0 {
+		if err = checkFormat(rk.format, rk.newKey); err != nil {
+			return err
+		}
+	}
+	if rk.oldNames, err = newNameCipher(rk.oldKey); err != nil {
+		return err
+	}
+	rk.newNames, err = newNameCipher(rk.newKey)
+	return err
+}
+
+func (rk *Rekey) initNewKey(h keyHeader, headerExist bool) (err error) {
+	kdf := rk.kdf
+
+	switch {
+	case rk.newHeader != nil:
+		// resume with the new key header in the progress file
+		h = *rk.newHeader
+	case headerExist && (len(kdf) == 0 || kdf == h.KDF):
+	case len(kdf) > 0:
+		if h, err = newKeyHeader(kdf); err != nil {
+			return err
+		}
+		rk.newHeader = &h
+		if err = rk.appendProgress(rekeyRecord{KeyHeader: &h}); err != nil {
+			return err
+		}
+	default:
+		rk.newKey = rk.newSecret
+		return nil
+	}
+	rk.newKey, err = h.deriveKey(rk.newSecret)
+	return err
+}
+
+// Rekey rekey all the encrypted files in the destination, the files that are not encrypted with the chunked AEAD format are ignored
+func (rk *Rekey) Rekey() error {
+	var files, dirs, temps []string
+	headerPath := rk.join(KeyHeaderFile)
+	headerExist := false
+	err := rk.fsys.WalkDir(rk.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch {
+		case p == headerPath:
+			headerExist = true
+		case p == rk.root:
+		case d.IsDir():
+			dirs = append(dirs, p)
+		case strings.HasSuffix(p, rekeyTempSuffix):
+			temps = append(temps, p)
+		default:
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err = rk.initKeys(headerExist); err != nil {
+		return err
+	}
+	sort.Strings(files)
+	rk.recoverTemps(temps, files)
+
+	// the old directories that are renamed, and the directories that must be kept because some files in them are not moved
+	oldDirs := make(map[string]bool)
+	keepDirs := make(map[string]bool)
+	for _, dir := range dirs {
+		newRel, err := rk.rekeyRelPath(rk.rel(dir))
+		if err != nil {
+			return err
+		}
+		if newDir := rk.join(newRel); newDir != dir {
+			if err = rk.fsys.MkdirAll(newDir); err != nil {
+				return err
+			}
+			oldDirs[dir] = true
+		}
+	}
+
+	failed := 0
+	for _, p := range files {
+		rel := rk.rel(p)
+		if rk.done[rel] {
+			continue
+		}
+		moved, err := rk.rekeyFile(p)
+		if err != nil {
+			failed++
+			rk.logger.Error(err, "[rekey] rekey the file error => %s", p)
+		}
+		if !moved {
+			for dir := path.Dir(p); dir != rk.root && dir != "." && dir != "/"; dir = path.Dir(dir) {
+				keepDirs[dir] = true
+			}
+		}
+		if err == nil {
+			// record the new path, so the file is skipped even if it is moved, the new path is valid because the file is moved to it
+			newRel, _ := rk.rekeyRelPath(rel)
+			rk.logger.ErrorIf(rk.appendProgress(rekeyRecord{Path: newRel}), "[rekey] record the progress error")
+		}
+	}
+	rk.removeOldDirs(oldDirs, keepDirs)
+
+	if failed > 0 {
+		return fmt.Errorf("%w, failed=%d", errRekeyFailed, failed)
+	}
+	if err = rk.uploadKeyHeader(); err != nil {
+		return err
+	}
+	rk.logger.Info("[rekey] rekey all the files success => %s", rk.root)
+	return os.Remove(rk.progressFile)
+}
+
+// rekeyFile rekey the file and move it to the path with the rekeyed names, return true if the file is moved
+func (rk *Rekey) rekeyFile(p string) (moved bool, err error) {
+	newRel, err := rk.rekeyRelPath(rk.rel(p))
+	if err != nil {
+		return false, err
+	}
+	newPath := rk.join(newRel)
+	isAEAD, err := rk.isAEADFile(p)
+	if err != nil || !isAEAD {
+		if err == nil {
+			rk.logger.Info("[rekey] [ignored] the file is not encrypted with the chunked AEAD format => %s", p)
+		}
+		return false, err
+	}
+	temp, err := rk.reencrypt(p, rk.plainRelPath(rk.rel(p)))
+	if temp != nil {
+		defer func() {
+			rk.logger.ErrorIf(os.Remove(temp.Name()), "[rekey] remove the temporary file error")
+		}()
+	}
+	if errors.Is(err, errAuthFailed) && rk.canDecrypt(p, rk.newKey) {
+		rk.logger.Info("[rekey] [ignored] the file is already rekeyed => %s", p)
+		return newPath == p, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err = rk.fsys.MkdirAll(path.Dir(newPath)); err != nil {
+		return false, err
+	}
+	destTemp := newPath + rekeyTempSuffix
+	if err = rk.fsys.Write(temp.Name(), destTemp); err != nil {
+		return false, err
+	}
+	if err = rk.replace(destTemp, newPath); err != nil {
+		return false, err
+	}
+	if newPath != p {
+		if err = rk.fsys.Remove(p); err != nil {
+			return true, err
+		}
+	}
+	rk.logger.Info("[rekey] [success] => %s", newPath)
+	return true, nil
+}
+
+// reencrypt decrypt the file with the old key and encrypt it with the new key to a local temporary file,
+// the format in the file header is kept unless the format is converted explicitly,
+// the identity is the plain relative path that derives the file id of the block formats
+func (rk *Rekey) reencrypt(p string, identity string) (temp *os.File, err error) {
+	f, err := rk.fsys.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	if _, err = br.Discard(len(aeadMagic)); err != nil {
+		return nil, err
+	}
+	format := rk.format
+	if len(format) == 0 {
+		if format, err = peekFormat(br); err != nil {
+			return nil, err
+		}
+		if err = checkFormat(format, rk.newKey); err != nil {
+			return nil, err
+		}
+	}
+	r, err := newAEADDecryptReader(br, rk.oldKey)
+	if err != nil {
+		return nil, err
+	}
+	if temp, err = os.CreateTemp("", "gofs-rekey-*"); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := temp.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	bw := bufio.NewWriter(temp)
+	w, err := newAEADEncryptWriter(bw, format, rk.newKey, identity)
+	if err != nil {
+		return temp, err
+	}
+	if _, err = io.Copy(w, r); err != nil {
+		return temp, err
+	}
+	if err = w.Close(); err != nil {
+		return temp, err
+	}
+	return temp, bw.Flush()
+}
+
+// peekFormat return the encryption format in the file header after the magic number without consuming it
+func peekFormat(br *bufio.Reader) (string, error) {
+	// the header starts with the version and the algorithm
+	fixed, err := br.Peek(2)
+	if err != nil {
+		return "", fmt.Errorf("%w => %w", errTruncated, err)
+	}
+	return algorithmFormat(fixed[1])
+}
+
+// replace replace the dest file with the temporary file, some servers like SFTP can't rename to an existing file
+func (rk *Rekey) replace(temp, dest string) error {
+	if err := rk.fsys.Rename(temp, dest); err == nil {
+		return nil
+	}
+	if err := rk.fsys.Remove(dest); err != nil {
+		return err
+	}
+	return rk.fsys.Rename(temp, dest)
+}
+
+// recoverTemps recover the temporary files that are left by the interrupted key rotation,
+// the temporary file is complete if the dest file is removed, otherwise it is removed
+func (rk *Rekey) recoverTemps(temps []string, files []string) {
+	exists := make(map[string]bool)
+	for _, f := range files {
+		exists[f] = true
+	}
+	for _, temp := range temps {
+		dest := strings.TrimSuffix(temp, rekeyTempSuffix)
+		if exists[dest] {
+			rk.logger.ErrorIf(rk.fsys.Remove(temp), "[rekey] remove the incomplete temporary file error => %s", temp)
+		} else {
+			rk.logger.ErrorIf(rk.fsys.Rename(temp, dest), "[rekey] recover the temporary file error => %s", temp)
+		}
+	}
+}
+
+// removeOldDirs remove the old directories that are renamed from the deepest one
+func (rk *Rekey) removeOldDirs(oldDirs map[string]bool, keepDirs map[string]bool) {
+	var dirs []string
+	for dir := range oldDirs {
+		if !keepDirs[dir] {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		return strings.Count(dirs[i], "/") > strings.Count(dirs[j], "/")
+	})
+	for _, dir := range dirs {
+		rk.logger.ErrorIf(rk.fsys.Remove(dir), "[rekey] remove the old directory error => %s", dir)
+	}
+}
+
+// rekeyRelPath re-encrypt the encrypted names in the relative path with the new key
+func (rk *Rekey) rekeyRelPath(rel string) (string, error) {
+	names := strings.Split(rel, "/")
+	for i, name := range names {
+		if plain, ok := rk.oldNames.decryptName(name); ok {
+			encrypted, err := rk.newNames.encryptName(plain)
+			if err != nil {
+				return "", err
+			}
+			names[i] = encrypted
+		}
+	}
+	return strings.Join(names, "/"), nil
+}
+
+// plainRelPath decrypt the encrypted names in the relative path with the old key
+func (rk *Rekey) plainRelPath(rel string) string {
+	names := strings.Split(rel, "/")
+	for i, name := range names {
+		if plain, ok := rk.oldNames.decryptName(name); ok {
+			names[i] = plain
+		}
+	}
+	return strings.Join(names, "/")
+}
+
+func (rk *Rekey) isAEADFile(p string) (bool, error) {
+	f, err := rk.fsys.Open(p)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	prefix := make([]byte, len(aeadMagic))
+	if _, err = io.ReadFull(f, prefix); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return false, nil
+		}
+		return false, err
+	}
+	return isAEADFormat(prefix), nil
+}
+
+// canDecrypt check the first chunk of the file can be decrypted with the key or not
+func (rk *Rekey) canDecrypt(p string, key []byte) bool {
+	f, err := rk.fsys.Open(p)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	br := bufio.NewReader(f)
+	if _, err = br.Discard(len(aeadMagic)); err != nil {
+		return false
+	}
+	r, err := newAEADDecryptReader(br, key)
+	if err != nil {
+		return false
+	}
+	_, err = r.Read(make([]byte, 1))
+	return err == nil || errors.Is(err, io.EOF)
+}
+
+func (rk *Rekey) readKeyHeader() (h keyHeader, err error) {
+	f, err := rk.fsys.Open(rk.join(KeyHeaderFile))
+	if err != nil {
+		return h, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err == nil {
+		err = json.Unmarshal(data, &h)
+	}
+	return h, err
+}
+
+// uploadKeyHeader upload the new key header to the destination and the encrypt path after all the files are rekeyed
+func (rk *Rekey) uploadKeyHeader() error {
+	if rk.newHeader == nil {
+		return nil
+	}
+	dir, err := os.MkdirTemp("", "gofs-rekey-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err = writeKeyHeader(dir, *rk.newHeader); err != nil {
+		return err
+	}
+	if err = rk.fsys.Write(path.Join(dir, KeyHeaderFile), rk.join(KeyHeaderFile)); err != nil {
+		return err
+	}
+	if len(rk.encryptPath) > 0 {
+		return writeKeyHeader(rk.encryptPath, *rk.newHeader)
+	}
+	rk.logger.Warn("[rekey] the key header is changed, copy the %s in the destination to the encrypt path before the next sync", KeyHeaderFile)
+	return nil
+}
+
+func (rk *Rekey) loadProgress() error {
+	f, err := os.Open(rk.progressFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record rekeyRecord
+		// ignore the incomplete line that is written when interrupted
+		if json.Unmarshal(scanner.Bytes(), &record) != nil {
+			continue
+		}
+		if len(record.Path) > 0 {
+			rk.done[record.Path] = true
+		}
+		if record.KeyHeader != nil {
+			rk.newHeader = record.KeyHeader
+		}
+	}
+	return scanner.Err()
+}
+
+func (rk *Rekey) appendProgress(record rekeyRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(rk.progressFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(data, '\n')); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// rel return the slash-separated path that is relative to the root
+func (rk *Rekey) rel(p string) string {
+	if rk.root == "." {
+		return p
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(p, rk.root), "/")
+}
+
+func (rk *Rekey) join(rel string) string {
+	return path.Join(rk.root, rel)
+}
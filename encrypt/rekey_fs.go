@@ -0,0 +1,74 @@
+package encrypt
+
+import (
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// RekeyFS the file system of the encrypted destination, all the paths are slash-separated,
+// the driver.Driver of the SFTP and MinIO implements it
+type RekeyFS interface {
+	// WalkDir walks the file tree rooted at root, calling fn for each file or directory in the tree, including root
+	WalkDir(root string, fn fs.WalkDirFunc) error
+	// Open opens the named file for reading
+	Open(path string) (http.File, error)
+	// Write write the local src file to the dest file
+	Write(src string, dest string) error
+	// Rename renames a file
+	Rename(oldPath, newPath string) error
+	// Remove removes the specified file or directory
+	Remove(path string) error
+	// MkdirAll creates a directory named path
+	MkdirAll(path string) error
+}
+
+type localRekeyFS struct {
+}
+
+// NewLocalRekeyFS create a RekeyFS of the local disk
+func NewLocalRekeyFS() RekeyFS {
+	return &localRekeyFS{}
+}
+
+func (lfs *localRekeyFS) WalkDir(root string, fn fs.WalkDirFunc) error {
+	return filepath.WalkDir(filepath.FromSlash(root), func(path string, d fs.DirEntry, err error) error {
+		return fn(filepath.ToSlash(path), d, err)
+	})
+}
+
+func (lfs *localRekeyFS) Open(path string) (http.File, error) {
+	return os.Open(filepath.FromSlash(path))
+}
+
+func (lfs *localRekeyFS) Write(src string, dest string) (err error) {
+	srcFile, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+	destFile, err := os.Create(filepath.FromSlash(dest))
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(destFile, srcFile)
+	if closeErr := destFile.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (lfs *localRekeyFS) Rename(oldPath, newPath string) error {
+	return os.Rename(filepath.FromSlash(oldPath), filepath.FromSlash(newPath))
+}
+
+// Remove removes the file or the empty directory
+func (lfs *localRekeyFS) Remove(path string) error {
+	return os.Remove(filepath.FromSlash(path))
+}
+
+func (lfs *localRekeyFS) MkdirAll(path string) error {
+	return os.MkdirAll(filepath.FromSlash(path), fs.ModePerm)
+}
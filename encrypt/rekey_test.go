@@ -0,0 +1,322 @@
+package encrypt
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/no-src/gofs/conf"
+	"github.com/no-src/gofs/logger"
+)
+
+const newSecret = "rekey_secure_rekey_secure_rekey0"
+
+func TestRekey(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	testCases := []struct {
+		name        string
+		oldKDF      string
+		newKDF      string
+		encryptName bool
+		format      string
+	}{
+		{"raw key", "", "", false, ""},
+		{"raw key to aes-gcm", "", "", false, FormatAESGCM},
+		{"raw key to xchacha20-poly1305", "", "", false, FormatXChaCha20Poly1305},
+		{"raw key to aes-gcm-block", "", "", true, FormatAESGCMBlock},
+		{"raw key with name", "", "", true, FormatAESGCM},
+		{"raw key to scrypt", "", KDFScrypt, true, FormatAESGCM},
+		{"keep scrypt", KDFScrypt, "", true, FormatAESGCM},
+		{"scrypt to argon2id", KDFScrypt, KDFArgon2id, false, FormatAESGCM},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dest := t.TempDir()
+			out := t.TempDir()
+			progress := filepath.Join(t.TempDir(), "rekey.progress")
+			oldSecret := secret
+			if len(tc.oldKDF) > 0 {
+				oldSecret = "my old passphrase"
+			}
+			files := map[string][]byte{
+				"hello.txt":     []byte("hello gofs"),
+				"a/b/hello.txt": randomData(t, aeadChunkSize*2+100),
+				"a/empty.txt":   {},
+			}
+			writeRekeyTestFiles(t, dest, oldSecret, tc.oldKDF, tc.encryptName, files)
+			plainPath := filepath.Join(dest, "plain.txt")
+			if err := os.WriteFile(plainPath, []byte("plain"), 0644); err != nil {
+				t.Fatalf("write the plain file error => %v", err)
+			}
+
+			rk, err := NewRekey(NewOption(conf.Config{
+				Rekey:         true,
+				DecryptSecret: oldSecret,
+				EncryptSecret: newSecret,
+				EncryptKDF:    tc.newKDF,
+				RekeyFormat:   tc.format,
+			}, logger), NewLocalRekeyFS(), filepath.ToSlash(dest), progress)
+			if err != nil {
+				t.Fatalf("init rekey component error => %v", err)
+			}
+			if err = rk.Rekey(); err != nil {
+				t.Fatalf("rekey error => %v", err)
+			}
+			if _, err = os.Stat(progress); !os.IsNotExist(err) {
+				t.Errorf("the progress file should be removed after rekey success => %v", err)
+			}
+			if data, err := os.ReadFile(plainPath); err != nil || string(data) != "plain" {
+				t.Errorf("the plain file should be kept => %s %v", data, err)
+			}
+			if err = os.Remove(plainPath); err != nil {
+				t.Fatalf("remove the plain file error => %v", err)
+			}
+			assertRekeyTestFiles(t, dest, out, newSecret, files)
+		})
+	}
+}
+
+func TestRekey_KeepFormat(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	testCases := []struct {
+		format string
+	}{
+		{FormatAESGCM},
+		{FormatXChaCha20Poly1305},
+		{FormatAESGCMBlock},
+		{FormatXChaCha20Poly1305Block},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			dest := t.TempDir()
+			data := randomData(t, aeadChunkSize*2+100)
+			writeFile(t, filepath.Join(dest, "hello.txt"), aeadEncrypt(t, tc.format, []byte(secret), data))
+
+			// the encrypt format is the default format, it must not convert the files
+			rk, err := NewRekey(NewOption(conf.Config{
+				Rekey:         true,
+				DecryptSecret: secret,
+				EncryptSecret: newSecret,
+			}, logger), NewLocalRekeyFS(), filepath.ToSlash(dest), filepath.Join(t.TempDir(), "rekey.progress"))
+			if err != nil {
+				t.Fatalf("init rekey component error => %v", err)
+			}
+			if err = rk.Rekey(); err != nil {
+				t.Fatalf("rekey error => %v", err)
+			}
+
+			rekeyed, err := os.ReadFile(filepath.Join(dest, "hello.txt"))
+			if err != nil {
+				t.Fatalf("read the rekeyed file error => %v", err)
+			}
+			expectAlgorithm, _ := formatAlgorithm(tc.format)
+			if len(rekeyed) <= len(aeadMagic)+1 || rekeyed[len(aeadMagic)+1] != expectAlgorithm {
+				t.Errorf("expect the format of the rekeyed file is kept as %s", tc.format)
+			}
+			actual, err := aeadDecrypt(rekeyed, []byte(newSecret))
+			if err != nil || string(actual) != string(data) {
+				t.Errorf("decrypt the rekeyed file with the new secret error => %v", err)
+			}
+		})
+	}
+}
+
+func TestRekey_Resume(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	dest := t.TempDir()
+	out := t.TempDir()
+	progress := filepath.Join(t.TempDir(), "rekey.progress")
+	files := map[string][]byte{
+		"done.txt":      []byte("rekeyed before interrupted"),
+		"temp.txt":      []byte("the dest file is removed before interrupted"),
+		"a/pending.txt": []byte("not rekeyed yet"),
+	}
+	writeRekeyTestFiles(t, dest, secret, "", true, files)
+
+	opt := NewOption(conf.Config{
+		Rekey:         true,
+		DecryptSecret: secret,
+		EncryptSecret: newSecret,
+	}, logger)
+	oldNames, err := newNameCipher([]byte(secret))
+	if err != nil {
+		t.Fatalf("init name cipher error => %v", err)
+	}
+	newNames, err := newNameCipher([]byte(newSecret))
+	if err != nil {
+		t.Fatalf("init name cipher error => %v", err)
+	}
+
+	// simulate the interrupted key rotation, the done.txt is rekeyed and recorded,
+	// the temp.txt is uploaded to the temporary file and the old file is removed
+	rk, err := NewRekey(opt, NewLocalRekeyFS(), filepath.ToSlash(dest), progress)
+	if err != nil {
+		t.Fatalf("init rekey component error => %v", err)
+	}
+	done := encryptTestName(t, newNames, "done.txt")
+	writeFile(t, filepath.Join(dest, done), aeadEncrypt(t, FormatAESGCM, []byte(newSecret), files["done.txt"]))
+	if err = os.Remove(filepath.Join(dest, encryptTestName(t, oldNames, "done.txt"))); err != nil {
+		t.Fatalf("remove the old file error => %v", err)
+	}
+	if err = rk.appendProgress(rekeyRecord{Path: done}); err != nil {
+		t.Fatalf("append the progress error => %v", err)
+	}
+	temp := filepath.Join(dest, encryptTestName(t, newNames, "temp.txt")+rekeyTempSuffix)
+	writeFile(t, temp, aeadEncrypt(t, FormatAESGCM, []byte(newSecret), files["temp.txt"]))
+	if err = os.Remove(filepath.Join(dest, encryptTestName(t, oldNames, "temp.txt"))); err != nil {
+		t.Fatalf("remove the old file error => %v", err)
+	}
+
+	rk, err = NewRekey(opt, NewLocalRekeyFS(), filepath.ToSlash(dest), progress)
+	if err != nil {
+		t.Fatalf("init rekey component error => %v", err)
+	}
+	if !rk.done[done] {
+		t.Errorf("the rekeyed file should be loaded from the progress file => %s", done)
+	}
+	if err = rk.Rekey(); err != nil {
+		t.Fatalf("rekey error => %v", err)
+	}
+	if _, err = os.Stat(temp); !os.IsNotExist(err) {
+		t.Errorf("the temporary file should be recovered => %v", err)
+	}
+	assertRekeyTestFiles(t, dest, out, newSecret, files)
+
+	// run again without the progress file, the rekeyed files are detected and skipped
+	rk, err = NewRekey(opt, NewLocalRekeyFS(), filepath.ToSlash(dest), progress)
+	if err != nil {
+		t.Fatalf("init rekey component error => %v", err)
+	}
+	if err = rk.Rekey(); err != nil {
+		t.Fatalf("rekey the rekeyed files error => %v", err)
+	}
+	assertRekeyTestFiles(t, dest, t.TempDir(), newSecret, files)
+}
+
+func TestRekey_ReturnError(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	testCases := []struct {
+		name   string
+		config conf.Config
+		expect error
+	}{
+		{"legacy format", conf.Config{Rekey: true, DecryptSecret: secret, EncryptSecret: newSecret, EncryptFormat: FormatLegacy}, errRekeyUnsupportedFormat},
+		{"convert to age format", conf.Config{Rekey: true, DecryptSecret: secret, EncryptSecret: newSecret, RekeyFormat: FormatAge}, errRekeyUnsupportedFormat},
+		{"empty old secret", conf.Config{Rekey: true, EncryptSecret: newSecret}, errEmptySecret},
+		{"empty new secret", conf.Config{Rekey: true, DecryptSecret: secret}, errEmptySecret},
+		{"unsupported kdf", conf.Config{Rekey: true, DecryptSecret: secret, EncryptSecret: newSecret, EncryptKDF: "unsupported"}, errUnsupportedKDF},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewRekey(NewOption(tc.config, logger), NewLocalRekeyFS(), t.TempDir(), filepath.Join(t.TempDir(), "rekey.progress"))
+			if !errors.Is(err, tc.expect) {
+				t.Errorf("expect get error %v but get %v", tc.expect, err)
+			}
+		})
+	}
+
+	t.Run("wrong old secret", func(t *testing.T) {
+		dest := t.TempDir()
+		progress := filepath.Join(t.TempDir(), "rekey.progress")
+		writeRekeyTestFiles(t, dest, secret, "", false, map[string][]byte{"hello.txt": []byte("hello gofs")})
+		rk, err := NewRekey(NewOption(conf.Config{
+			Rekey:         true,
+			DecryptSecret: "wrong_secret_wrong_secret_wrong0",
+			EncryptSecret: newSecret,
+		}, logger), NewLocalRekeyFS(), filepath.ToSlash(dest), progress)
+		if err != nil {
+			t.Fatalf("init rekey component error => %v", err)
+		}
+		if err = rk.Rekey(); !errors.Is(err, errRekeyFailed) {
+			t.Errorf("expect get error %v but get %v", errRekeyFailed, err)
+		}
+		if _, err = os.Stat(filepath.Join(dest, "hello.txt")); err != nil {
+			t.Errorf("the file should be kept after rekey failed => %v", err)
+		}
+	})
+}
+
+// writeRekeyTestFiles write the files that are encrypted with the secret to the dest directory, the file paths are slash-separated
+func writeRekeyTestFiles(t *testing.T, dest string, secret string, kdf string, encryptName bool, files map[string][]byte) {
+	key := []byte(secret)
+	if len(kdf) > 0 {
+		h, err := loadOrCreateKeyHeader(dest, kdf)
+		if err != nil {
+			t.Fatalf("create the key header error => %v", err)
+		}
+		if key, err = h.deriveKey(key); err != nil {
+			t.Fatalf("derive the key error => %v", err)
+		}
+	}
+	names, err := newNameCipher(key)
+	if err != nil {
+		t.Fatalf("init name cipher error => %v", err)
+	}
+	for rel, data := range files {
+		if encryptName {
+			components := strings.Split(rel, "/")
+			for i, name := range components {
+				components[i] = encryptTestName(t, names, name)
+			}
+			rel = strings.Join(components, "/")
+		}
+		writeFile(t, filepath.Join(dest, filepath.FromSlash(rel)), aeadEncrypt(t, FormatAESGCM, key, data))
+	}
+}
+
+// assertRekeyTestFiles decrypt the files in the dest directory with the secret and compare them with the expected files
+func assertRekeyTestFiles(t *testing.T, dest string, out string, secret string, files map[string][]byte) {
+	dec, err := NewDecrypt(NewOption(conf.Config{
+		Decrypt:       true,
+		DecryptPath:   dest,
+		DecryptSecret: secret,
+		DecryptOut:    out,
+	}, logger.NewTestLogger()))
+	if err != nil {
+		t.Fatalf("init decrypt component error => %v", err)
+	}
+	if err = dec.Decrypt(); err != nil {
+		t.Fatalf("decrypt the rekeyed files error => %v", err)
+	}
+	count := 0
+	err = filepath.WalkDir(out, func(p string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			count++
+		}
+		return err
+	})
+	if err != nil || count != len(files) {
+		t.Errorf("expect get %d files but get %d => %v", len(files), count, err)
+	}
+	for rel, expect := range files {
+		actual, err := os.ReadFile(filepath.Join(out, filepath.FromSlash(rel)))
+		if err != nil {
+			t.Errorf("read the decrypted file error => %v", err)
+			continue
+		}
+		if string(actual) != string(expect) {
+			t.Errorf("the decrypted file is not matched => %s", rel)
+		}
+	}
+}
+
+func writeFile(t *testing.T, name string, data []byte) {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatalf("create the directory error => %v", err)
+	}
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		t.Fatalf("write the file error => %v", err)
+	}
+}
@@ -0,0 +1,39 @@
+package encrypt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var errAmbiguousSecret = errors.New("only one of the secret, the secret file and the secret environment variable can be specified")
+
+// resolveSecret return the secret from the command line, the secret file or the environment variable,
+// the trailing line breaks of the secret file are trimmed
+func resolveSecret(secret []byte, file string, env string) ([]byte, error) {
+	count := 0
+	for _, s := range []string{string(secret), file, env} {
+		if len(s) > 0 {
+			count++
+		}
+	}
+	if count > 1 {
+		return nil, errAmbiguousSecret
+	}
+	if len(file) > 0 {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strings.TrimRight(string(data), "\r\n")), nil
+	}
+	if len(env) > 0 {
+		value, ok := os.LookupEnv(env)
+		if !ok || len(value) == 0 {
+			return nil, fmt.Errorf("%w, the environment variable [%s] is not set", errEmptySecret, env)
+		}
+		return []byte(value), nil
+	}
+	return secret, nil
+}
@@ -0,0 +1,153 @@
+package encrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+const sivBlockSize = aes.BlockSize
+
+var (
+	errInvalidSIVKey = errors.New("the AES-SIV key length must be 32, 48 or 64 bytes")
+	errSIVOpen       = errors.New("AES-SIV authentication failed")
+)
+
+// aesSIV the deterministic authenticated encryption AES-SIV, see RFC 5297
+type aesSIV struct {
+	mac cipher.Block
+	ctr cipher.Block
+}
+
+// newAESSIV create an AES-SIV instance, the first half of the key is used for S2V and the second half is used for CTR
+func newAESSIV(key []byte) (*aesSIV, error) {
+	if l := len(key); l != 32 && l != 48 && l != 64 {
+		return nil, fmt.Errorf("%w, get %d bytes", errInvalidSIVKey, l)
+	}
+	half := len(key) / 2
+	mac, err := aes.NewCipher(key[:half])
+	if err != nil {
+		return nil, err
+	}
+	ctr, err := aes.NewCipher(key[half:])
+	if err != nil {
+		return nil, err
+	}
+	return &aesSIV{mac: mac, ctr: ctr}, nil
+}
+
+// Seal encrypt the plaintext with the associated data, return the synthetic IV followed by the ciphertext
+func (s *aesSIV) Seal(plaintext []byte, ad ...[]byte) []byte {
+	v := s.s2v(plaintext, ad...)
+	out := make([]byte, sivBlockSize+len(plaintext))
+	copy(out, v[:])
+	s.xorKeyStream(out[sivBlockSize:], plaintext, v)
+	return out
+}
+
+// Open decrypt and authenticate the ciphertext that is returned by Seal
+func (s *aesSIV) Open(ciphertext []byte, ad ...[]byte) ([]byte, error) {
+	if len(ciphertext) < sivBlockSize {
+		return nil, errSIVOpen
+	}
+	var v [sivBlockSize]byte
+	copy(v[:], ciphertext)
+	plaintext := make([]byte, len(ciphertext)-sivBlockSize)
+	s.xorKeyStream(plaintext, ciphertext[sivBlockSize:], v)
+	t := s.s2v(plaintext, ad...)
+	if subtle.ConstantTimeCompare(t[:], v[:]) != 1 {
+		return nil, errSIVOpen
+	}
+	return plaintext, nil
+}
+
+func (s *aesSIV) xorKeyStream(dst, src []byte, v [sivBlockSize]byte) {
+	// clear the 31st and 63rd rightmost bits of the counter, see RFC 5297 section 2.6
+	v[8] &= 0x7f
+	v[12] &= 0x7f
+	cipher.NewCTR(s.ctr, v[:]).XORKeyStream(dst, src)
+}
+
+// s2v the S2V operation of the AES-SIV, see RFC 5297 section 2.4
+func (s *aesSIV) s2v(plaintext []byte, ad ...[]byte) [sivBlockSize]byte {
+	var zero [sivBlockSize]byte
+	d := cmac(s.mac, zero[:])
+	for _, a := range ad {
+		d = dbl(d)
+		xorBlock(&d, cmac(s.mac, a))
+	}
+	var t []byte
+	if len(plaintext) >= sivBlockSize {
+		t = make([]byte, len(plaintext))
+		copy(t, plaintext)
+		offset := len(t) - sivBlockSize
+		for i := range d {
+			t[offset+i] ^= d[i]
+		}
+	} else {
+		d = dbl(d)
+		padded := pad(plaintext)
+		xorBlock(&d, padded)
+		t = d[:]
+	}
+	return cmac(s.mac, t)
+}
+
+// cmac the AES-CMAC, see RFC 4493
+func cmac(block cipher.Block, msg []byte) [sivBlockSize]byte {
+	var l [sivBlockSize]byte
+	block.Encrypt(l[:], l[:])
+	k1 := dbl(l)
+	k2 := dbl(k1)
+
+	n := (len(msg) + sivBlockSize - 1) / sivBlockSize
+	complete := n > 0 && len(msg)%sivBlockSize == 0
+	if n == 0 {
+		n = 1
+	}
+	var last [sivBlockSize]byte
+	if complete {
+		copy(last[:], msg[(n-1)*sivBlockSize:])
+		xorBlock(&last, k1)
+	} else {
+		last = pad(msg[(n-1)*sivBlockSize:])
+		xorBlock(&last, k2)
+	}
+
+	var x [sivBlockSize]byte
+	for i := 0; i < n-1; i++ {
+		for j := range x {
+			x[j] ^= msg[i*sivBlockSize+j]
+		}
+		block.Encrypt(x[:], x[:])
+	}
+	xorBlock(&x, last)
+	block.Encrypt(x[:], x[:])
+	return x
+}
+
+// dbl multiply the block by x in GF(2^128)
+func dbl(b [sivBlockSize]byte) (r [sivBlockSize]byte) {
+	carry := b[0] >> 7
+	for i := 0; i < sivBlockSize-1; i++ {
+		r[i] = b[i]<<1 | b[i+1]>>7
+	}
+	r[sivBlockSize-1] = b[sivBlockSize-1] << 1
+	r[sivBlockSize-1] ^= 0x87 * carry
+	return r
+}
+
+// pad append a single 1 bit and the 0 bits to the incomplete block
+func pad(b []byte) (r [sivBlockSize]byte) {
+	n := copy(r[:], b)
+	r[n] = 0x80
+	return r
+}
+
+func xorBlock(dst *[sivBlockSize]byte, src [sivBlockSize]byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
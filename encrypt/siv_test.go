@@ -0,0 +1,109 @@
+package encrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func TestAESSIV(t *testing.T) {
+	// the test vectors from RFC 5297 appendix A
+	testCases := []struct {
+		name       string
+		key        string
+		ad         []string
+		plaintext  string
+		ciphertext string
+	}{
+		{
+			name:       "deterministic authenticated encryption",
+			key:        "fffefdfcfbfaf9f8f7f6f5f4f3f2f1f0f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+			ad:         []string{"101112131415161718191a1b1c1d1e1f2021222324252627"},
+			plaintext:  "112233445566778899aabbccddee",
+			ciphertext: "85632d07c6e8f37f950acd320a2ecc9340c02b9690c4dc04daef7f6afe5c",
+		},
+		{
+			name: "nonce-based authenticated encryption",
+			key:  "7f7e7d7c7b7a79787776757473727170404142434445464748494a4b4c4d4e4f",
+			ad: []string{
+				"00112233445566778899aabbccddeeffdeaddadadeaddadaffeeddccbbaa99887766554433221100",
+				"102030405060708090a0",
+				"09f911029d74e35bd84156c5635688c0",
+			},
+			plaintext:  "7468697320697320736f6d6520706c61696e7465787420746f20656e6372797074207573696e67205349562d414553",
+			ciphertext: "7bdb6e3b432667eb06f4d14bff2fbd0fcb900f2fddbe404326601965c889bf17dba77ceb094fa663b7a3f748ba8af829ea64ad544a272e9c485b62a3fd5c0d",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := newAESSIV(mustDecodeHex(t, tc.key))
+			if err != nil {
+				t.Fatalf("init AES-SIV error => %v", err)
+			}
+			var ad [][]byte
+			for _, a := range tc.ad {
+				ad = append(ad, mustDecodeHex(t, a))
+			}
+			plaintext := mustDecodeHex(t, tc.plaintext)
+			expect := mustDecodeHex(t, tc.ciphertext)
+			actual := s.Seal(plaintext, ad...)
+			if !bytes.Equal(expect, actual) {
+				t.Errorf("expect get ciphertext %x but get %x", expect, actual)
+			}
+			opened, err := s.Open(actual, ad...)
+			if err != nil {
+				t.Errorf("open error => %v", err)
+				return
+			}
+			if !bytes.Equal(plaintext, opened) {
+				t.Errorf("expect get plaintext %x but get %x", plaintext, opened)
+			}
+			actual[len(actual)-1] ^= 1
+			if _, err = s.Open(actual, ad...); !errors.Is(err, errSIVOpen) {
+				t.Errorf("expect get error => [%v] but get [%v]", errSIVOpen, err)
+			}
+		})
+	}
+}
+
+func TestCMAC(t *testing.T) {
+	// the test vectors from RFC 4493 section 4
+	block, err := aes.NewCipher(mustDecodeHex(t, "2b7e151628aed2a6abf7158809cf4f3c"))
+	if err != nil {
+		t.Fatalf("init AES error => %v", err)
+	}
+	msg := mustDecodeHex(t, "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e5130c81c46a35ce411e5fbc1191a0a52eff69f2445df4f9b17ad2b417be66c3710")
+	testCases := []struct {
+		length int
+		expect string
+	}{
+		{0, "bb1d6929e95937287fa37d129b756746"},
+		{16, "070a16b46b4d4144f79bdd9dd04a287c"},
+		{40, "dfa66747de9ae63030ca32611497c827"},
+		{64, "51f0bebf7e3b9d92fc49741779363cfe"},
+	}
+	for _, tc := range testCases {
+		actual := cmac(block, msg[:tc.length])
+		if hex.EncodeToString(actual[:]) != tc.expect {
+			t.Errorf("[%d] expect get cmac %s but get %x", tc.length, tc.expect, actual)
+		}
+	}
+}
+
+func TestNewAESSIV_ReturnError(t *testing.T) {
+	for _, length := range []int{0, 16, 24, 33, 63} {
+		if _, err := newAESSIV(make([]byte, length)); !errors.Is(err, errInvalidSIVKey) {
+			t.Errorf("[%d] expect get error => [%v] but get [%v]", length, errInvalidSIVKey, err)
+		}
+	}
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("decode hex error => %v", err)
+	}
+	return b
+}
@@ -0,0 +1,59 @@
+package encrypt
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/no-src/nsgo/fsutil"
+)
+
+// Validate check the encryption option like NewEncrypt without any side effect, the key header file is never created,
+// all the problems are returned
+func (opt Option) Validate(parentPath string) error {
+	if !opt.Encrypt {
+		return nil
+	}
+	var errs []error
+	isSub, err := fsutil.IsSub(parentPath, opt.EncryptPath)
+	if err != nil {
+		errs = append(errs, err)
+	} else if !isSub {
+		errs = append(errs, fmt.Errorf("%w, source=%s encrypt=%s", errNotSubDir, parentPath, opt.EncryptPath))
+	}
+
+	if opt.EncryptFormat == FormatAge {
+		if opt.EncryptName || len(opt.EncryptKDF) > 0 {
+			errs = append(errs, errAgeNotSupported)
+		}
+		_, err = parseRecipients(opt.EncryptRecipients, opt.EncryptRecipientsFile)
+		return errors.Join(append(errs, err)...)
+	}
+
+	secret, err := resolveSecret(opt.EncryptSecret, opt.EncryptSecretFile, opt.EncryptSecretEnv)
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	if len(opt.EncryptKDF) > 0 {
+		errs = append(errs, validateKDF(opt.EncryptPath, opt.EncryptKDF, secret))
+		// the length of the derived key is always valid, so only the format is checked
+		secret = make([]byte, kdfKeyLen)
+	}
+	errs = append(errs, checkFormat(opt.EncryptFormat, secret))
+	return errors.Join(errs...)
+}
+
+// validateKDF check the passphrase is not empty and the key derivation function is supported and matched with the existing key header
+func validateKDF(dir string, kdf string, passphrase []byte) error {
+	if len(passphrase) == 0 {
+		return errEmptySecret
+	}
+	h, exist, err := loadKeyHeader(dir)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return h.checkKDF(kdf, dir)
+	}
+	_, err = newKeyHeader(kdf)
+	return err
+}
@@ -0,0 +1,68 @@
+package encrypt
+
+import (
+	"crypto/aes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/no-src/gofs/conf"
+	"github.com/no-src/gofs/logger"
+)
+
+func TestOption_Validate(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	source := t.TempDir()
+	mismatchDir := t.TempDir()
+	h, err := newKeyHeader(KDFScrypt)
+	if err != nil {
+		t.Fatalf("create the key header error => %v", err)
+	}
+	if err = writeKeyHeader(mismatchDir, h); err != nil {
+		t.Fatalf("write the key header error => %v", err)
+	}
+	recipient := generateAgeIdentity(t).Recipient().String()
+
+	testCases := []struct {
+		name   string
+		c      conf.Config
+		parent string
+		expect []error
+	}{
+		{"disabled", conf.Config{}, source, nil},
+		{"valid secret", conf.Config{Encrypt: true, EncryptPath: source, EncryptSecret: secret}, source, nil},
+		{"valid kdf", conf.Config{Encrypt: true, EncryptPath: source, EncryptSecret: "short", EncryptKDF: KDFArgon2id}, source, nil},
+		{"valid age", conf.Config{Encrypt: true, EncryptPath: source, EncryptFormat: FormatAge, EncryptRecipients: recipient}, source, nil},
+		{"invalid secret length", conf.Config{Encrypt: true, EncryptPath: source, EncryptSecret: "short"}, source, []error{aes.KeySizeError(5)}},
+		{"invalid format", conf.Config{Encrypt: true, EncryptPath: source, EncryptSecret: secret, EncryptFormat: "unknown"}, source, []error{errUnsupportedFormat}},
+		{"ambiguous secret", conf.Config{Encrypt: true, EncryptPath: source, EncryptSecret: secret, EncryptSecretEnv: "GOFS_SECRET"}, source, []error{errAmbiguousSecret}},
+		{"not sub dir and invalid secret", conf.Config{Encrypt: true, EncryptPath: source, EncryptSecret: "short"}, t.TempDir(), []error{errNotSubDir, aes.KeySizeError(5)}},
+		{"unsupported kdf", conf.Config{Encrypt: true, EncryptPath: source, EncryptSecret: secret, EncryptKDF: "md5"}, source, []error{errUnsupportedKDF}},
+		{"empty passphrase", conf.Config{Encrypt: true, EncryptPath: source, EncryptKDF: KDFScrypt}, source, []error{errEmptySecret}},
+		{"kdf mismatch", conf.Config{Encrypt: true, EncryptPath: mismatchDir, EncryptSecret: secret, EncryptKDF: KDFArgon2id}, mismatchDir, []error{errKDFMismatch}},
+		{"age with name and no recipient", conf.Config{Encrypt: true, EncryptPath: source, EncryptFormat: FormatAge, EncryptName: true}, source, []error{errAgeNotSupported, errRecipientRequired}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewOption(tc.c, logger).Validate(tc.parent)
+			if len(tc.expect) == 0 && err != nil {
+				t.Errorf("expect to get no error, but get %v", err)
+			}
+			if len(tc.expect) > 0 && err == nil {
+				t.Errorf("expect to get the errors %v, but get nil", tc.expect)
+			}
+			for _, expect := range tc.expect {
+				if !errors.Is(err, expect) {
+					t.Errorf("expect to get the error %v, but get %v", expect, err)
+				}
+			}
+		})
+	}
+
+	if _, err = os.Stat(filepath.Join(source, KeyHeaderFile)); !os.IsNotExist(err) {
+		t.Errorf("the key header file should not be created by the validation, err => %v", err)
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"time"
 
+	authapi "github.com/no-src/gofs/api/auth"
+	"github.com/no-src/gofs/auth"
 	"github.com/no-src/gofs/conf"
 	"github.com/no-src/gofs/core"
 	"github.com/no-src/gofs/daemon"
+	"github.com/no-src/gofs/encrypt"
 	"github.com/no-src/gofs/logger"
 	"github.com/no-src/gofs/server"
 	"github.com/no-src/nsgo/hashutil"
@@ -24,11 +27,20 @@ func ParseFlags(args []string) (config conf.Config) {
 		args = append(args, "-h")
 	}
 
-	var cl = core.NewFlagSet(args[0], flag.ExitOnError)
+	cl := newFlagSet(args[0], &config)
+	cl.Parse(args[1:])
+	return config
+}
+
+// newFlagSet create a flag set that defines all the flags of the config
+func newFlagSet(name string, config *conf.Config) *core.FlagSet {
+	cl := core.NewFlagSet(name, flag.ExitOnError)
 
 	// other
 	cl.BoolVar(&config.PrintVersion, "v", false, "print the version info")
 	cl.BoolVar(&config.PrintAbout, "about", false, "print the about info")
+	cl.BoolVar(&config.Validate, "validate", false, "validate the config without starting the sync, print all the problems and exit")
+	cl.BoolVar(&config.PrintSchema, "print_schema", false, "print the JSON Schema of the config file")
 	cl.StringVar(&config.Conf, "conf", "", "the path of config file")
 
 	// file sync
@@ -55,6 +67,9 @@ func ParseFlags(args []string) (config conf.Config) {
 	cl.IntVar(&config.SyncDelayEvents, "sync_delay_events", 10, "the maximum event count of sync delay")
 	cl.DurationVar(&config.SyncDelayTime, "sync_delay_time", time.Second*30, "the maximum delay interval time after the last sync")
 	cl.IntVar(&config.SyncWorkers, "sync_workers", 1, "the number of file sync workers")
+	cl.BoolVar(&config.EnableJournal, "journal", false, "enable the durable event journal, the pending events are recorded to disk and replayed on startup, so no change is lost across crashes")
+	cl.StringVar(&config.JournalDir, "journal_dir", "./journal/", "set the directory of the event journal file")
+	cl.IntVar(&config.MonitorLogSize, "monitor_log_size", 10000, "the max number of the monitor messages retained by the remote disk server, the reconnected remote disk clients replay the missed messages from them")
 
 	// retry
 	cl.IntVar(&config.RetryCount, "retry_count", 15, "if execute failed, then retry to work -retry_count times")
@@ -72,6 +87,13 @@ func ParseFlags(args []string) (config conf.Config) {
 	cl.StringVar(&config.LogFormat, "log_format", logger.DefaultFormatter, "set the log output format, current support text and json")
 	cl.BoolVar(&config.LogSplitDate, "log_split_date", false, "split log file by date")
 
+	// audit log
+	cl.BoolVar(&config.EnableAuditLog, "audit_log", false, "enable the audit log that records every authenticated file access and mutation of the file server and the grpc api server as JSON lines")
+	cl.StringVar(&config.AuditLogFile, "audit_log_file", "", "the path of the audit log file, it is the audit.log in the -log_dir directory if it is empty")
+	cl.SizeVar(&config.AuditLogMaxSize, "audit_log_max_size", "100MiB", "rotate the audit log file when the size of it reaches the max size, never rotate if it is 0")
+	cl.IntVar(&config.AuditLogMaxBackups, "audit_log_max_backups", 10, "the max number of the rotated audit log files that are retained")
+	cl.StringVar(&config.AuditSyslog, "audit_syslog", "", "send the audit records to the syslog server too, like udp://127.0.0.1:514, tcp://127.0.0.1:514, unix:///dev/log, or local to connect to the local syslog server")
+
 	// daemon
 	cl.BoolVar(&config.IsDaemon, "daemon", false, "enable daemon to create and monitor a subprocess to work, you can use [go build -ldflags=\"-H windowsgui\"] to build on Windows")
 	cl.BoolVar(&config.DaemonPid, "daemon_pid", false, "record parent process pid, daemon process pid and worker process pid to pid file")
@@ -88,24 +110,56 @@ func ParseFlags(args []string) (config conf.Config) {
 	cl.BoolVar(&config.ManagePrivate, "manage_private", true, "allow to access manage api route by private address and loopback address only")
 	cl.BoolVar(&config.EnablePushServer, "push_server", false, "whether to enable the push server")
 	cl.BoolVar(&config.EnableReport, "report", false, "enable the report api route and start to collect the report data, need to enable -manage flag first")
+	cl.BoolVar(&config.ServerDecrypt, "server_decrypt", false, "enable the decrypt route of the file server to download the decrypted files in the dest path, the secret is specified by the -decrypt_secret or -decrypt_identity_file flag, only the users with the execute permission can access it")
 	cl.StringVar(&config.SessionConnection, "session_connection", "memory:", "the session connection string, an example for redis session: redis://127.0.0.1:6379?password=redis_password&db=10&max_idle=10&secret=redis_secret")
+	cl.StringVar(&config.TrustedProxies, "trusted_proxies", "", "the ip addresses or CIDRs of the trusted reverse proxies separated by commas, the client ip is read from the X-Forwarded-For header only if the request comes from them, no proxy is trusted if it is empty")
 
 	// http protocol
 	cl.BoolVar(&config.EnableHTTP3, "http3", false, "enable the HTTP3 protocol, pay attention to what you enable the TLS first")
 
+	// grpc transfer
+	cl.BoolVar(&config.EnableGrpcTransfer, "grpc_transfer", false, "transfer the file content over the gRPC api server instead of the file server in the remote disk client mode and the remote push client mode, and do not start the file server automatically in the remote disk server mode")
+
 	// tls transfer
 	cl.BoolVar(&config.EnableTLS, "tls", true, fmt.Sprintf("enable the tls connections, if disable it, server_addr is \"%s\" default", server.DefaultAddrHttp))
 	cl.StringVar(&config.TLSCertFile, "tls_cert_file", "gofs.pem", "cert file for tls connections")
 	cl.StringVar(&config.TLSKeyFile, "tls_key_file", "gofs.key", "key file for tls connections")
 	cl.BoolVar(&config.TLSInsecureSkipVerify, "tls_insecure_skip_verify", true, "controls whether a client skip verifies the server's certificate chain and host name")
+	cl.StringVar(&config.TLSClientCAFile, "tls_client_ca_file", "", "the CA bundle file to verify the client certificates, enable the mutual TLS authentication of the file server and the remote disk server if it is not empty")
+	cl.StringVar(&config.TLSClientCertUsers, "tls_client_cert_users", "", "map the subject common name or SAN of the client certificates to the server accounts, format like this, client1|rw,spiffe://gofs.io/client2|r")
+	cl.StringVar(&config.TLSClientCertFile, "tls_client_cert_file", "", "the client certificate file for the mutual TLS authentication")
+	cl.StringVar(&config.TLSClientKeyFile, "tls_client_key_file", "", "the client key file for the mutual TLS authentication")
 
 	// login user
 	cl.StringVar(&config.Users, "users", "", "the server accounts, the server allows anonymous access if there is no effective account, format like this, user1|password1|rwx,user2|password2|rwx")
+	cl.StringVar(&config.UsersFile, "users_file", "", "the users file that contains the server accounts with the hashed passwords, support the yaml, json and htpasswd format, it is reloaded automatically after it is modified")
+	cl.StringVar(&config.UserPaths, "user_paths", "", "restrict the server accounts of -users and -tls_client_cert_users to the path prefixes with the specified permission, the longest matched path prefix is used, format like this, user1|/source/teamA|rw,user1|/dest/teamA|r")
 	cl.IntVar(&config.RandomUserCount, "rand_user_count", 0, "the number of random server accounts, if it is greater than zero, random generate some accounts for -users")
 	cl.IntVar(&config.RandomUserNameLen, "rand_user_len", 6, "the length of the random user's username")
 	cl.IntVar(&config.RandomPasswordLen, "rand_pwd_len", 10, "the length of the random user's password")
 	cl.StringVar(&config.RandomDefaultPerm, "rand_perm", "r", "the default permission of every random user, like 'rwx'")
-	cl.StringVar(&config.TokenSecret, "token_secret", "", "a secret string for token")
+	cl.StringVar(&config.TokenSecret, "token_secret", "", "a secret string to sign the token with the HS256 algorithm, at least 16 bytes")
+	cl.StringVar(&config.TokenAlgorithm, "token_algorithm", authapi.DefaultTokenAlgorithm, "the algorithm to sign the JWT token, current supported algorithms: HS256, EdDSA")
+	cl.StringVar(&config.TokenKeyFile, "token_key_file", "", "the PEM file of the Ed25519 private key to sign the token with the EdDSA algorithm, generate a random key if it is empty")
+	cl.DurationVar(&config.TokenExpires, "token_expires", authapi.DefaultTokenExpires, "the lifetime of the access token")
+	cl.DurationVar(&config.TokenRefreshExpires, "token_refresh_expires", authapi.DefaultRefreshTokenExpires, "the lifetime of the refresh token, the clients refresh the access token transparently before it expires")
+
+	// openid connect
+	cl.StringVar(&config.OIDCIssuer, "oidc_issuer", "", "the issuer url of the OpenID Connect provider, enable the OpenID Connect login of the file server if it is not empty")
+	cl.StringVar(&config.OIDCClientID, "oidc_client_id", "", "the client id that is registered in the OpenID Connect provider")
+	cl.StringVar(&config.OIDCClientSecret, "oidc_client_secret", "", "the client secret that is registered in the OpenID Connect provider")
+	cl.StringVar(&config.OIDCRedirectURL, "oidc_redirect_url", "", "the callback url of the OpenID Connect login, like https://127.0.0.1/login/oidc/callback, generate it by the request if it is empty")
+	cl.StringVar(&config.OIDCScopes, "oidc_scopes", "profile,email", "the extra scopes of the OpenID Connect login, separated by commas, the openid scope is always requested")
+	cl.StringVar(&config.OIDCUserClaim, "oidc_user_claim", "preferred_username", "the claim of the ID token that is used as the username, use the subject if the claim is not found")
+	cl.StringVar(&config.OIDCGroupsClaim, "oidc_groups_claim", "groups", "the claim of the ID token that contains the groups of the user")
+	cl.StringVar(&config.OIDCGroupPerms, "oidc_group_perms", "", "map the groups of the OpenID Connect users to the permissions, format like this, admin|rwx,dev|rw,guest|r")
+	cl.StringVar(&config.OIDCDefaultPerm, "oidc_default_perm", "", "the permission of the OpenID Connect users that have no matched group, the users can't log in if it is empty")
+
+	// login lockout
+	cl.IntVar(&config.LoginMaxAttempts, "login_max_attempts", auth.DefaultLoginMaxAttempts, "the max number of the continuous failed login attempts of a client ip or a username before it is locked out, disable the lockout if it is not greater than zero")
+	cl.DurationVar(&config.LoginLockout, "login_lockout", auth.DefaultLoginLockout, "the lockout duration of the first lockout, the duration is doubled for every continuous lockout")
+	cl.DurationVar(&config.LoginMaxLockout, "login_max_lockout", auth.DefaultLoginMaxLockout, "the max lockout duration")
+	cl.BoolVar(&config.LoginLockoutSessionStore, "login_lockout_session_store", false, "store the failed login attempts in the backend of the -session_connection flag, so the lockout can be shared by multiple instances with redis, store them in memory default")
 
 	// checksum
 	cl.BoolVar(&config.Checksum, "checksum", false, "calculate and print the checksum for source file")
@@ -114,19 +168,32 @@ func ParseFlags(args []string) (config conf.Config) {
 	cl.BoolVar(&config.Encrypt, "encrypt", false, "enable the encrypt path")
 	cl.StringVar(&config.EncryptPath, "encrypt_path", "", "the files in the encrypt path will be encrypted before sync to destination")
 	cl.StringVar(&config.EncryptSecret, "encrypt_secret", "", "a secret string for encryption")
+	cl.StringVar(&config.EncryptSecretFile, "encrypt_secret_file", "", "read the secret for encryption from the file instead of the -encrypt_secret flag")
+	cl.StringVar(&config.EncryptSecretEnv, "encrypt_secret_env", "", "read the secret for encryption from the environment variable with the name instead of the -encrypt_secret flag")
+	cl.StringVar(&config.EncryptKDF, "encrypt_kdf", "", "derive the encryption key from the secret as a passphrase, support scrypt and argon2id, the salt is stored in the .gofs-key.json in the encrypt path, use the secret as the raw key if it is empty")
+	cl.BoolVar(&config.EncryptName, "encrypt_name", false, "encrypt the names of the files and directories in the encrypt path deterministically, the -decrypt flag decrypts them automatically")
+	cl.StringVar(&config.EncryptFormat, "encrypt_format", encrypt.DefaultFormat, "the encryption format, support aes-gcm, xchacha20-poly1305, aes-gcm-block, xchacha20-poly1305-block, age and legacy, the block formats support the chunk compare of the local disk, the age format encrypts the files to the public keys, the legacy format is unauthenticated and only for compatibility")
+	cl.StringVar(&config.EncryptRecipients, "encrypt_recipients", "", "the comma-separated age public keys for the age format, the files can be decrypted by any of them")
+	cl.StringVar(&config.EncryptRecipientsFile, "encrypt_recipients_file", "", "the age recipients file that contains one public key per line for the age format")
 
 	// decrypt
 	cl.BoolVar(&config.Decrypt, "decrypt", false, "decrypt the files from decrypt path to decrypt output path")
 	cl.StringVar(&config.DecryptPath, "decrypt_path", "", "a directory or file to decrypt")
-	cl.StringVar(&config.DecryptSecret, "decrypt_secret", "", "a secret string for decryption")
+	cl.StringVar(&config.DecryptSecret, "decrypt_secret", "", "a secret string for decryption, it is used as the passphrase if the .gofs-key.json exists in the decrypt path")
+	cl.StringVar(&config.DecryptSecretFile, "decrypt_secret_file", "", "read the secret for decryption from the file instead of the -decrypt_secret flag")
+	cl.StringVar(&config.DecryptSecretEnv, "decrypt_secret_env", "", "read the secret for decryption from the environment variable with the name instead of the -decrypt_secret flag")
 	cl.StringVar(&config.DecryptOut, "decrypt_out", "", "the decrypt files output directory path")
+	cl.StringVar(&config.DecryptIdentityFile, "decrypt_identity_file", "", "the age identity file to decrypt the files that are encrypted with the age format")
+
+	// rekey
+	cl.BoolVar(&config.Rekey, "rekey", false, "decrypt the files in the dest path with the -decrypt_secret and re-encrypt them with the -encrypt_secret, support the local disk, SFTP and MinIO")
+	cl.StringVar(&config.RekeyProgress, "rekey_progress", "rekey.progress", "the progress file of the -rekey flag, the rekeyed files are recorded in it and skipped when run again, it is removed after all the files are rekeyed")
+	cl.StringVar(&config.RekeyFormat, "rekey_format", "", "convert the files to the encryption format during the key rotation of the -rekey flag, like aes-gcm-block, the format of every file is kept if it is empty")
 
 	// task
 	cl.StringVar(&config.TaskConf, "task_conf", "", "the task conf address")
 	cl.BoolVar(&config.EnableTaskClient, "task_client", false, "start a task client")
 	cl.StringVar(&config.TaskClientLabels, "task_client_labels", "", "the labels of the task client")
 	cl.IntVar(&config.TaskClientMaxWorker, "task_client_max_worker", 1, "limit the max concurrent workers in the task client side")
-
-	cl.Parse(args[1:])
-	return config
+	return cl
 }
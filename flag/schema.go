@@ -0,0 +1,146 @@
+package flag
+
+import (
+	"encoding/json"
+	"flag"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/no-src/gofs/conf"
+	"github.com/no-src/gofs/core"
+)
+
+const (
+	// schemaDialect the JSON Schema dialect of the generated schema
+	schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+	// sizePattern the pattern of the core.Size, like 1024, 1KB, 1.5MiB
+	sizePattern = `^\s*[0-9]+(\.[0-9]+)?\s*[a-zA-Z]*\s*$`
+	// durationPattern the pattern of the core.Duration that is acceptable to time.ParseDuration, like 300ms, 1h30m
+	durationPattern = `^[-+]?(0|([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+)$`
+)
+
+// Schema a subset of the JSON Schema that describes the config file
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+}
+
+// JSONSchema generate the JSON Schema of the config file from the struct tags of the conf.Config and the flag definitions,
+// the description and the default value of every field are the same as the flag
+func JSONSchema() ([]byte, error) {
+	return json.MarshalIndent(NewSchema(), "", "  ")
+}
+
+// NewSchema create the JSON Schema of the config file
+func NewSchema() *Schema {
+	var c conf.Config
+	cl := newFlagSet("gofs", &c)
+	flags := make(map[uintptr]*flag.Flag)
+	cl.VisitAll(func(f *flag.Flag) {
+		// the value of the flag points to the field of the config
+		flags[reflect.ValueOf(f.Value).Pointer()] = f
+	})
+
+	s := objectSchema(reflect.ValueOf(&c).Elem(), func(field reflect.Value, name string) *flag.Flag {
+		return flags[field.Addr().Pointer()]
+	})
+	s.Schema = schemaDialect
+	s.Title = "gofs"
+	s.Description = "the config file of gofs, all the fields are the same as the flags"
+
+	// the empty fields of the sync job inherit the values of the top-level config, so the defaults are not set
+	var job conf.SyncJob
+	js := objectSchema(reflect.ValueOf(&job).Elem(), func(field reflect.Value, name string) *flag.Flag {
+		return cl.Lookup(name)
+	})
+	for _, p := range js.Properties {
+		p.Default = nil
+	}
+	js.Properties["name"].Description = "the unique name of the sync job"
+	js.Required = []string{"name", "source", "dest"}
+	s.Properties["jobs"] = &Schema{
+		Type:        "array",
+		Description: "the named sync jobs that run concurrently in a single process, replace the top-level source and dest",
+		Items:       js,
+	}
+	return s
+}
+
+// objectSchema create the schema of the struct, the fields without the json name are skipped
+func objectSchema(v reflect.Value, lookup func(field reflect.Value, name string) *flag.Flag) *Schema {
+	s := &Schema{
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: new(bool),
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if len(name) == 0 || name == "-" {
+			continue
+		}
+		field := v.Field(i)
+		p := fieldSchema(field)
+		if p == nil {
+			continue
+		}
+		if f := lookup(field, name); f != nil {
+			p.Description = f.Usage
+			// the default value of the VFS flag is not the original path
+			if _, ok := field.Interface().(core.VFS); !ok {
+				p.Default = defaultValue(p, f.DefValue)
+			}
+		}
+		s.Properties[name] = p
+	}
+	return s
+}
+
+// fieldSchema create the schema of the field according to the type, return nil if the type is not supported
+func fieldSchema(v reflect.Value) *Schema {
+	switch v.Interface().(type) {
+	case core.VFS:
+		return &Schema{Type: "string"}
+	case core.Size:
+		return &Schema{Type: "string", Pattern: sizePattern}
+	case core.Duration:
+		return &Schema{Type: "string", Pattern: durationPattern}
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	}
+	return nil
+}
+
+// defaultValue convert the default value of the flag to the type of the schema, return nil if it is the zero value
+func defaultValue(s *Schema, value string) any {
+	var v any = value
+	switch s.Type {
+	case "boolean":
+		v, _ = strconv.ParseBool(value)
+	case "integer":
+		v, _ = strconv.ParseInt(value, 10, 64)
+	case "number":
+		v, _ = strconv.ParseFloat(value, 64)
+	}
+	if reflect.ValueOf(v).IsZero() {
+		return nil
+	}
+	return v
+}
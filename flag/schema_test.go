@@ -0,0 +1,111 @@
+package flag
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/no-src/gofs/conf"
+	"github.com/no-src/nsgo/yamlutil"
+)
+
+func TestJSONSchema_AllFlags(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("generate the JSON Schema error => %v", err)
+	}
+	var s Schema
+	if err = json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("parse the JSON Schema error => %v", err)
+	}
+
+	// find the json name of the config field that every flag is bound to
+	var c conf.Config
+	v := reflect.ValueOf(&c).Elem()
+	names := make(map[uintptr]string)
+	for i := 0; i < v.NumField(); i++ {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		names[v.Field(i).Addr().Pointer()] = name
+	}
+	newFlagSet("gofs", &c).VisitAll(func(f *flag.Flag) {
+		name, ok := names[reflect.ValueOf(f.Value).Pointer()]
+		if !ok {
+			t.Errorf("the flag is not bound to any field of the config => %s", f.Name)
+			return
+		}
+		if name == "-" {
+			// the flag is only used in the command line, like -v and -conf
+			return
+		}
+		if name != f.Name {
+			t.Errorf("expect the flag name is the same as the json name of the field, flag=%s json=%s", f.Name, name)
+		}
+		p := s.Properties[name]
+		if p == nil {
+			t.Errorf("the flag is not represented in the JSON Schema => %s", f.Name)
+			return
+		}
+		if p.Description != f.Usage || len(p.Type) == 0 {
+			t.Errorf("get the unexpected schema of the flag [%s] => %+v", f.Name, p)
+		}
+	})
+}
+
+func TestJSONSchema_Types(t *testing.T) {
+	s := NewSchema()
+	testCases := []struct {
+		name          string
+		expectType    string
+		expectPattern bool
+		expectDefault any
+	}{
+		{"source", "string", false, nil},
+		{"sync_once", "boolean", false, nil},
+		{"ignore_deleted", "boolean", false, true},
+		{"retry_count", "integer", false, int64(15)},
+		{"log_sample_rate", "number", false, float64(1)},
+		{"chunk_size", "string", true, "1MiB"},
+		{"retry_wait", "string", true, "5s"},
+		{"jobs", "array", false, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := s.Properties[tc.name]
+			if p == nil {
+				t.Fatalf("the field is not found in the JSON Schema => %s", tc.name)
+			}
+			if p.Type != tc.expectType || (len(p.Pattern) > 0) != tc.expectPattern || p.Default != tc.expectDefault {
+				t.Errorf("get the unexpected schema => %+v", p)
+			}
+		})
+	}
+
+	job := s.Properties["jobs"].Items
+	if job == nil || job.Properties["name"] == nil || job.Properties["encrypt_secret"] == nil || job.Properties["retry_wait"].Default != nil {
+		t.Errorf("get the unexpected schema of the sync job => %+v", job)
+	}
+}
+
+func TestJSONSchema_Example(t *testing.T) {
+	s := NewSchema()
+	for _, path := range []string{"../conf/example/gofs-remote-client.json", "../conf/example/gofs-remote-server.yaml", "../conf/example/gofs-jobs.yaml"} {
+		t.Run(path, func(t *testing.T) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read the example config error => %v", err)
+			}
+			var m map[string]any
+			if err = yamlutil.Unmarshal(data, &m); err != nil {
+				t.Fatalf("parse the example config error => %v", err)
+			}
+			for k := range m {
+				if s.Properties[k] == nil {
+					t.Errorf("the field of the example config is not found in the JSON Schema => %s", k)
+				}
+			}
+		})
+	}
+}
@@ -219,3 +219,30 @@ func testMatchPath(t *testing.T, pi PathIgnore, expect bool, path string) {
 		t.Errorf("[%s] => expect: %v, but actual: %v", path, expect, actual)
 	}
 }
+
+func TestPathIgnore_Reload(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	pi, err := NewPathIgnore("", false, logger)
+	if err != nil {
+		t.Errorf("init default ignore component error => %v", err)
+		return
+	}
+	testMatchPath(t, pi, false, "/source/bin/")
+	testMatchPath(t, pi, false, "/hello.txt.1643351810.deleted")
+
+	if err = pi.Reload(testIgnoreFile, true); err != nil {
+		t.Errorf("reload the ignore component error => %v", err)
+		return
+	}
+	testMatchPath(t, pi, true, "/source/bin/")
+	testMatchPath(t, pi, true, "/hello.txt.1643351810.deleted")
+
+	// keep the current rules if reload failed
+	if err = pi.Reload("./testdata/notfound.ignore", false); err == nil {
+		t.Errorf("reload the ignore component with a not found config should be return error")
+	}
+	testMatchPath(t, pi, true, "/source/bin/")
+	testMatchPath(t, pi, true, "/hello.txt.1643351810.deleted")
+}
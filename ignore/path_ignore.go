@@ -1,6 +1,8 @@
 package ignore
 
 import (
+	"sync"
+
 	"github.com/no-src/gofs/fs"
 	"github.com/no-src/gofs/logger"
 	"github.com/no-src/nsgo/stringutil"
@@ -10,12 +12,15 @@ import (
 type PathIgnore interface {
 	// MatchPath the current string matches the rule or not, if enable the matchIgnoreDeletedPath, check the deleted file rule is matched or not first
 	MatchPath(path, caller, desc string) bool
+	// Reload reload the ignore rules from the config file, keep the current rules if reload failed
+	Reload(ignoreConf string, ignoreDeletedPath bool) error
 }
 
 type pathIgnore struct {
 	ig                Ignore
 	ignoreDeletedPath bool
 	logger            *logger.Logger
+	mu                sync.RWMutex
 }
 
 // NewPathIgnore create an instance of the PathIgnore component
@@ -25,28 +30,46 @@ func NewPathIgnore(ignoreConf string, ignoreDeletedPath bool, logger *logger.Log
 	pi := &pathIgnore{
 		logger: logger,
 	}
+	if err := pi.Reload(ignoreConf, ignoreDeletedPath); err != nil {
+		return nil, err
+	}
+	return pi, nil
+}
+
+func (pi *pathIgnore) Reload(ignoreConf string, ignoreDeletedPath bool) error {
+	var ig Ignore
 	if !stringutil.IsEmpty(ignoreConf) {
-		ig, err := New(ignoreConf, logger)
+		var err error
+		ig, err = New(ignoreConf, pi.logger)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		pi.ig = ig
 	}
+	pi.mu.Lock()
+	pi.ig = ig
 	pi.ignoreDeletedPath = ignoreDeletedPath
-	return pi, nil
+	pi.mu.Unlock()
+	return nil
 }
 
 // match the current string matches the rule or not
 func (pi *pathIgnore) match(s string) bool {
-	if pi.ig != nil {
-		return pi.ig.Match(s)
+	pi.mu.RLock()
+	ig := pi.ig
+	pi.mu.RUnlock()
+	if ig != nil {
+		return ig.Match(s)
 	}
 	return false
 }
 
 func (pi *pathIgnore) MatchPath(path, caller, desc string) bool {
+	pi.mu.RLock()
+	ignoreDeletedPath := pi.ignoreDeletedPath
+	pi.mu.RUnlock()
+
 	var matched bool
-	if pi.ignoreDeletedPath {
+	if ignoreDeletedPath {
 		matched = fs.IsDeleted(path)
 		if matched {
 			pi.logger.Debug("[ignored] [%s] a deleted path is matched [%s] => [%s]", caller, desc, path)
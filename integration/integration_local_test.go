@@ -18,6 +18,7 @@ func TestIntegration_LocalDisk(t *testing.T) {
 		{"gofs local disk with sync once", "run-gofs-local-disk-sync-once.yaml", "test-gofs-local-disk-sync-once.yaml"},
 		{"gofs local disk with copy link", "run-gofs-local-disk-copy-link.yaml", "test-gofs-local-disk-copy-link.yaml"},
 		{"gofs local disk with copy unsafe link", "run-gofs-local-disk-copy-unsafe-link.yaml", "test-gofs-local-disk-copy-unsafe-link.yaml"},
+		{"gofs local disk with sync jobs", "run-gofs-local-disk-jobs.yaml", "test-gofs-local-disk-jobs.yaml"},
 	}
 
 	for _, tc := range testCases {
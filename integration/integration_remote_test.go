@@ -3,7 +3,11 @@
 package integration
 
 import (
+	"net"
 	"testing"
+	"time"
+
+	"github.com/no-src/gofs/server"
 )
 
 func TestIntegration_RemoteDisk(t *testing.T) {
@@ -17,6 +21,7 @@ func TestIntegration_RemoteDisk(t *testing.T) {
 		{"gofs remote disk with HTTP3", "run-gofs-remote-disk-server-with-http3.yaml", "run-gofs-remote-disk-client-with-http3.yaml", "test-gofs-remote-disk.yaml"},
 		{"gofs remote disk with insecure", "run-gofs-remote-disk-server-with-insecure.yaml", "run-gofs-remote-disk-client-with-insecure.yaml", "test-gofs-remote-disk.yaml"},
 		{"gofs remote push", "run-gofs-remote-push-server.yaml", "run-gofs-remote-push-client.yaml", "test-gofs-remote-push.yaml"},
+		{"gofs remote push with gRPC transfer", "run-gofs-remote-push-server-with-grpc-transfer.yaml", "run-gofs-remote-push-client-with-grpc-transfer.yaml", "test-gofs-remote-push.yaml"},
 		{"gofs remote push with HTTP3", "run-gofs-remote-push-server-with-http3.yaml", "run-gofs-remote-push-client-with-http3.yaml", "test-gofs-remote-push.yaml"}}
 
 	for _, tc := range testCases {
@@ -25,3 +30,17 @@ func TestIntegration_RemoteDisk(t *testing.T) {
 		})
 	}
 }
+
+func TestIntegration_RemoteDiskWithGrpcTransfer(t *testing.T) {
+	// the remote disk server does not start the file server, so the sync only depends on the gRPC api server
+	testIntegrationClientServer(t, "run-gofs-remote-disk-server-with-grpc-transfer.yaml", "run-gofs-remote-disk-client-with-grpc-transfer.yaml", "test-gofs-remote-disk.yaml", assertFileServerNotStarted)
+}
+
+// assertFileServerNotStarted check the default address of the file server is not listening
+func assertFileServerNotStarted(t *testing.T) {
+	conn, err := net.DialTimeout("tcp", "127.0.0.1"+server.DefaultAddrHttps, time.Second)
+	if err == nil {
+		conn.Close()
+		t.Errorf("expect the file server is not started, but the address %s is listening", server.DefaultAddrHttps)
+	}
+}
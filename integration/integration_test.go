@@ -22,7 +22,9 @@ func runWithConfigFile(path string) result.Result {
 	return cmd.RunWithConfigFile(path)
 }
 
-func testIntegrationClientServer(t *testing.T, runServerConf string, runClientConf string, testConf string) {
+// testIntegrationClientServer run the server and the client, then execute the test actions,
+// the serverChecks are executed after the server and the client are initialized
+func testIntegrationClientServer(t *testing.T, runServerConf string, runClientConf string, testConf string, serverChecks ...func(t *testing.T)) {
 	if len(runServerConf) > 0 {
 		runServerConf = getRunConf(runServerConf)
 	}
@@ -63,6 +65,10 @@ func testIntegrationClientServer(t *testing.T, runServerConf string, runClientCo
 
 	time.Sleep(time.Second)
 
+	for _, check := range serverChecks {
+		check(t)
+	}
+
 	if err = commands.ExecActions(); err != nil {
 		t.Errorf("execute actions commands error, err=%v", err)
 	}
@@ -0,0 +1,189 @@
+package httpclient
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/no-src/gofs/internal/tlsutil"
+	"github.com/no-src/nsgo/httputil"
+	"github.com/quic-go/quic-go/http3"
+)
+
+var errEmptyUrl = errors.New("url is empty")
+
+type httpClient struct {
+	defaultClient    *http.Client
+	noRedirectClient *http.Client
+}
+
+// NewHttpClient create a http client that is the same as the httputil.NewHttpClient,
+// and present the client certificate to the server if the clientCertFile is not empty
+func NewHttpClient(insecureSkipVerify bool, certFile, clientCertFile, clientKeyFile string, enableHTTP3 bool) (httputil.HttpClient, error) {
+	tlsConfig, err := tlsutil.NewHttpClientTLSConfig(insecureSkipVerify, certFile, clientCertFile, clientKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var rt http.RoundTripper
+	if enableHTTP3 {
+		rt = &http3.Transport{
+			TLSClientConfig: tlsConfig,
+		}
+	} else {
+		rt = &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+			TLSClientConfig:       tlsConfig,
+		}
+	}
+
+	c := &httpClient{
+		defaultClient: &http.Client{
+			Transport: rt,
+		},
+		noRedirectClient: &http.Client{
+			Transport: rt,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+	return c, nil
+}
+
+func (c *httpClient) HttpGet(url string) (resp *http.Response, err error) {
+	return c.defaultClient.Get(url)
+}
+
+func (c *httpClient) HttpGetWithCookie(url string, header http.Header, cookies ...*http.Cookie) (resp *http.Response, err error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	addCookies(req, cookies)
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Set(k, v)
+		}
+	}
+	return c.defaultClient.Do(req)
+}
+
+func (c *httpClient) HttpPost(url string, data url.Values) (resp *http.Response, err error) {
+	return c.defaultClient.PostForm(url, data)
+}
+
+func (c *httpClient) HttpPostWithCookie(url string, data url.Values, cookies ...*http.Cookie) (resp *http.Response, err error) {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	addCookies(req, cookies)
+	req.Header.Set(httputil.HeaderContentType, "application/x-www-form-urlencoded")
+	return c.defaultClient.Do(req)
+}
+
+func (c *httpClient) HttpPostFileChunkWithCookie(url string, fieldName string, fileName string, data url.Values, chunk []byte, cookies ...*http.Cookie) (resp *http.Response, err error) {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	for k, v := range data {
+		for _, item := range v {
+			if err = w.WriteField(k, item); err != nil {
+				return nil, err
+			}
+		}
+	}
+	fw, err := w.CreateFormFile(fieldName, filepath.Base(fileName))
+	if err != nil {
+		return nil, err
+	}
+	if len(chunk) > 0 {
+		if _, err = fw.Write(chunk); err != nil {
+			return nil, err
+		}
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	addCookies(req, cookies)
+	req.Header.Set(httputil.HeaderContentType, w.FormDataContentType())
+	return c.defaultClient.Do(req)
+}
+
+func (c *httpClient) HttpPostWithoutRedirect(url string, data url.Values) (resp *http.Response, err error) {
+	return c.noRedirectClient.PostForm(url, data)
+}
+
+func (c *httpClient) Download(path, url string, alwaysDownload bool) error {
+	if len(url) == 0 {
+		return errEmptyUrl
+	}
+	if !alwaysDownload {
+		_, err := os.Stat(path)
+		if err == nil || !os.IsNotExist(err) {
+			return err
+		}
+	}
+	resp, err := c.HttpGet(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, fs.ModePerm)
+}
+
+func (c *httpClient) HttpPostData(url string, data []byte) (resp *http.Response, err error) {
+	return c.sendData(http.MethodPost, url, data)
+}
+
+func (c *httpClient) HttpPut(url string, data []byte) (resp *http.Response, err error) {
+	return c.sendData(http.MethodPut, url, data)
+}
+
+func (c *httpClient) HttpDelete(url string, data []byte) (resp *http.Response, err error) {
+	return c.sendData(http.MethodDelete, url, data)
+}
+
+func (c *httpClient) sendData(method string, url string, data []byte) (resp *http.Response, err error) {
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(httputil.HeaderContentType, "application/json")
+	return c.defaultClient.Do(req)
+}
+
+func addCookies(req *http.Request, cookies []*http.Cookie) {
+	for _, cookie := range cookies {
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package httpclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHttpClient_WithClientCert(t *testing.T) {
+	certFile, keyFile := generateClientCert(t)
+	pool := x509.NewCertPool()
+	pemCert, err := os.ReadFile(certFile)
+	if err != nil {
+		t.Fatalf("read the client cert error => %v", err)
+	}
+	pool.AppendCertsFromPEM(pemCert)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.TLS = &tls.Config{
+		ClientCAs:  pool,
+		ClientAuth: tls.RequireAndVerifyClientCert,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	testCases := []struct {
+		name           string
+		clientCertFile string
+		clientKeyFile  string
+		expectError    bool
+	}{
+		{"with client cert", certFile, keyFile, false},
+		{"without client cert", "", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := NewHttpClient(true, "", tc.clientCertFile, tc.clientKeyFile, false)
+			if err != nil {
+				t.Fatalf("create http client error => %v", err)
+			}
+			resp, err := c.HttpGet(srv.URL)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if tc.expectError && err == nil {
+				t.Errorf("expect to get an error without the client cert, but get nil")
+			}
+			if !tc.expectError && (err != nil || resp.StatusCode != http.StatusOK) {
+				t.Errorf("request with the client cert error => %v", err)
+			}
+		})
+	}
+}
+
+func TestNewHttpClient_ReturnError(t *testing.T) {
+	testCases := []struct {
+		name               string
+		insecureSkipVerify bool
+		certFile           string
+		clientCertFile     string
+	}{
+		{"cert file not found", false, "not_found_cert.pem", ""},
+		{"client cert file not found", true, "", "not_found_client_cert.pem"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := NewHttpClient(tc.insecureSkipVerify, tc.certFile, tc.clientCertFile, "not_found_client_key.pem", false); err == nil {
+				t.Errorf("expect to get an error, but get nil")
+			}
+		})
+	}
+}
+
+// generateClientCert generate a self-signed client certificate that can verify itself
+func generateClientCert(t *testing.T) (certFile string, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate the client key error => %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gofs-client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create the client cert error => %v", err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal the client key error => %v", err)
+	}
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "client-cert.pem")
+	keyFile = filepath.Join(dir, "client-key.pem")
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("write the client cert error => %v", err)
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600); err != nil {
+		t.Fatalf("write the client key error => %v", err)
+	}
+	return certFile, keyFile
+}
@@ -19,6 +19,7 @@ func NewHTTPDir(path string, bytesPerSecond int64, logger *logger.Logger) http.F
 
 // NewDir create a limit http.FileSystem that wrap the real http.FileSystem.
 func NewDir(fs http.FileSystem, bytesPerSecond int64, logger *logger.Logger) http.FileSystem {
+	bytesPerSecond = limit(bytesPerSecond)
 	if bytesPerSecond <= 0 {
 		return fs
 	}
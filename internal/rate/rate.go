@@ -4,11 +4,29 @@ import (
 	"context"
 	"io"
 	"sync"
+	"sync/atomic"
 
 	"github.com/no-src/gofs/logger"
 	"golang.org/x/time/rate"
 )
 
+// reloadedRate the bytesPerSecond that is reloaded at runtime, it overrides the specified bytesPerSecond
+var reloadedRate atomic.Pointer[int64]
+
+// Reload replace the bytesPerSecond of the limit readers that are created after reloading,
+// the rate limit is disabled if the bytesPerSecond is zero or negative
+func Reload(bytesPerSecond int64) {
+	reloadedRate.Store(&bytesPerSecond)
+}
+
+// limit return the reloaded bytesPerSecond if the rate is reloaded, otherwise return the specified bytesPerSecond
+func limit(bytesPerSecond int64) int64 {
+	if r := reloadedRate.Load(); r != nil {
+		return *r
+	}
+	return bytesPerSecond
+}
+
 type rateReader struct {
 	r              io.Reader
 	ra             io.ReaderAt
@@ -13,6 +13,7 @@ type reader struct {
 // NewReader create a limit io.Reader that wrap the real io.Reader.
 // The bytesPerSecond must be greater than defaultBufSize of io.Reader.
 func NewReader(r io.Reader, bytesPerSecond int64, logger *logger.Logger) io.Reader {
+	bytesPerSecond = limit(bytesPerSecond)
 	if bytesPerSecond <= 0 {
 		return r
 	}
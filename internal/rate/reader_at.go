@@ -13,6 +13,7 @@ type readerAt struct {
 // NewReaderAt create a limit io.ReaderAt that wrap the real io.ReaderAt.
 // The bytesPerSecond must be greater than defaultBufSize of io.ReaderAt.
 func NewReaderAt(ra io.ReaderAt, bytesPerSecond int64, logger *logger.Logger) io.ReaderAt {
+	bytesPerSecond = limit(bytesPerSecond)
 	if bytesPerSecond <= 0 {
 		return ra
 	}
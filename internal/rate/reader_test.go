@@ -90,3 +90,35 @@ func TestNewReader_DisableOrEnableRate(t *testing.T) {
 		})
 	}
 }
+
+func TestReload(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+	defer reloadedRate.Store(nil)
+
+	testCases := []struct {
+		name           string
+		bytesPerSecond int64
+		reloadRate     int64
+		expectLimit    bool
+	}{
+		{"enable rate by reloading", 0, KB, true},
+		{"disable rate by reloading zero rate", KB, 0, false},
+		{"disable rate by reloading negative rate", KB, -1, false},
+		{"change rate by reloading", KB, M, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			Reload(tc.reloadRate)
+			r := NewReader(bytes.NewBuffer(nil), tc.bytesPerSecond, logger)
+			rr, ok := r.(*reader)
+			if ok != tc.expectLimit {
+				t.Errorf("expect to get the limit reader %v, actual get %T", tc.expectLimit, r)
+				return
+			}
+			if ok && rr.rate.bytesPerSecond != tc.reloadRate {
+				t.Errorf("expect to get the reloaded rate %d, actual get %d", tc.reloadRate, rr.rate.bytesPerSecond)
+			}
+		})
+	}
+}
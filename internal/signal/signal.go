@@ -24,11 +24,22 @@ type StopSignal func()
 
 // Notify receive signal and try to shut down
 func Notify(shutdown func() error, logger *logger.Logger) (NotifySignal, StopSignal) {
+	return NotifyWithReload(shutdown, nil, logger)
+}
+
+// NotifyWithReload receive signal and try to shut down, or reload the config if receive the SIGHUP signal,
+// the SIGHUP signal shuts down like the others if the reload is nil
+func NotifyWithReload(shutdown func() error, reload func() error, logger *logger.Logger) (NotifySignal, StopSignal) {
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGABRT, syscall.SIGTERM)
 	go func() {
 		for {
 			s := <-c
+			if s == syscall.SIGHUP && reload != nil {
+				logger.Info("received a signal [%s], reloading the config", s.String())
+				logger.ErrorIf(reload(), "reload the config error")
+				continue
+			}
 			switch s {
 			case syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGABRT, syscall.SIGTERM:
 				logger.Debug("received a signal [%s], waiting to exit", s.String())
@@ -89,3 +89,48 @@ func TestNotify_IgnoreSignal(t *testing.T) {
 		})
 	}
 }
+
+func TestNotifyWithReload(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	reloaded := make(chan error, 2)
+	shutdown := make(chan struct{}, 1)
+	reloadCount := 0
+	ns, ss := NotifyWithReload(func() error {
+		shutdown <- struct{}{}
+		return nil
+	}, func() error {
+		var err error
+		if reloadCount++; reloadCount == 1 {
+			err = errors.New("reload error mock")
+		}
+		reloaded <- err
+		return err
+	}, logger)
+	defer ss()
+
+	// keep running after reloading whether the reload is failed or not
+	for i := 0; i < 2; i++ {
+		if err := ns(syscall.SIGHUP, time.Second); err != nil {
+			t.Fatalf("send the SIGHUP signal error => %v", err)
+		}
+		select {
+		case <-reloaded:
+		case <-time.After(time.Second):
+			t.Fatalf("expect to reload the config after received the SIGHUP signal")
+		}
+	}
+	if len(shutdown) > 0 {
+		t.Errorf("expect not to shut down after received the SIGHUP signal")
+	}
+
+	if err := ns(syscall.SIGTERM, time.Second); err != nil {
+		t.Fatalf("send the SIGTERM signal error => %v", err)
+	}
+	select {
+	case <-shutdown:
+	case <-time.After(time.Second):
+		t.Errorf("expect to shut down after received the SIGTERM signal")
+	}
+}
@@ -0,0 +1,95 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var errAppendCertsFromPemFailed = errors.New("append certs from pem failed")
+
+// NewServerTLSConfig create a tls config for the server with the cert file and key file,
+// if the clientCAFile is not empty, verify the client certificates that are given by the CA bundle
+func NewServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+	if len(clientCAFile) > 0 {
+		if config.ClientCAs, err = newCertPool(clientCAFile); err != nil {
+			return nil, err
+		}
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return config, nil
+}
+
+// NewClientTLSConfig create a tls config for the client that verifies the server certificate with the cert file,
+// if the clientCertFile is not empty, present the client certificate to the server
+func NewClientTLSConfig(certFile, serverName, clientCertFile, clientKeyFile string) (*tls.Config, error) {
+	roots, err := newCertPool(certFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		RootCAs:    roots,
+		ServerName: serverName,
+	}
+	if err = loadClientCert(config, clientCertFile, clientKeyFile); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// NewHttpClientTLSConfig create a tls config for the http client, it does not verify the server certificate if the insecureSkipVerify is true,
+// otherwise it is the same as NewClientTLSConfig, the server name is resolved from the request url
+func NewHttpClientTLSConfig(insecureSkipVerify bool, certFile, clientCertFile, clientKeyFile string) (*tls.Config, error) {
+	if !insecureSkipVerify {
+		return NewClientTLSConfig(certFile, "", clientCertFile, clientKeyFile)
+	}
+	config := &tls.Config{
+		InsecureSkipVerify: true,
+	}
+	if err := loadClientCert(config, clientCertFile, clientKeyFile); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// loadClientCert load the client certificate into the tls config if the clientCertFile is not empty
+func loadClientCert(config *tls.Config, clientCertFile, clientKeyFile string) error {
+	if len(clientCertFile) == 0 {
+		return nil
+	}
+	cert, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+	if err != nil {
+		return err
+	}
+	config.Certificates = []tls.Certificate{cert}
+	return nil
+}
+
+// VerifiedClientCert return the client certificate that is verified by the server, return nil if not exist
+func VerifiedClientCert(state *tls.ConnectionState) *x509.Certificate {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return state.VerifiedChains[0][0]
+}
+
+func newCertPool(certFile string) (*x509.CertPool, error) {
+	pemCerts, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemCerts) {
+		return nil, fmt.Errorf("%w => %s", errAppendCertsFromPemFailed, certFile)
+	}
+	return pool, nil
+}
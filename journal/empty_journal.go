@@ -0,0 +1,29 @@
+package journal
+
+type emptyJournal struct {
+}
+
+// NewEmptyJournal create an instance of the Journal that does nothing
+func NewEmptyJournal() Journal {
+	return &emptyJournal{}
+}
+
+func (j *emptyJournal) Append(entry Entry) (seq uint64, err error) {
+	return 0, nil
+}
+
+func (j *emptyJournal) Done(key string, seq uint64) error {
+	return nil
+}
+
+func (j *emptyJournal) Pending() []Entry {
+	return nil
+}
+
+func (j *emptyJournal) Compact() error {
+	return nil
+}
+
+func (j *emptyJournal) Close() error {
+	return nil
+}
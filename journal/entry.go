@@ -0,0 +1,50 @@
+package journal
+
+import "github.com/no-src/gofs/action"
+
+// Op the operation of the journal record
+type Op string
+
+const (
+	// AppendOp a pending event is appended
+	AppendOp Op = "append"
+	// DoneOp a pending event is synced
+	DoneOp Op = "done"
+)
+
+// Entry the pending event that is recorded in the journal
+type Entry struct {
+	// Key the unique identity of the pending event
+	Key string `json:"key"`
+	// Name the file path of the pending event
+	Name string `json:"name"`
+	// Size the file size of the pending event
+	Size int64 `json:"size"`
+	// Action the action of the pending event
+	Action action.Action `json:"action,omitempty"`
+	// Seq the sequence number of the pending event, it is assigned by the journal when the entry is appended
+	Seq uint64 `json:"seq,omitempty"`
+}
+
+// NewEntry create an instance of the Entry with the WriteAction
+func NewEntry(key string, name string, size int64) Entry {
+	return NewActionEntry(key, action.WriteAction, name, size)
+}
+
+// NewActionEntry create an instance of the Entry with the specified action
+func NewActionEntry(key string, act action.Action, name string, size int64) Entry {
+	return Entry{
+		Key:    key,
+		Name:   name,
+		Size:   size,
+		Action: act,
+	}
+}
+
+// record a line of the journal file
+type record struct {
+	Op    Op    `json:"op"`
+	Entry Entry `json:"entry"`
+	// Time the record time, unix sec
+	Time int64 `json:"time"`
+}
@@ -0,0 +1,284 @@
+package journal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/no-src/gofs/action"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/nsgo/jsonutil"
+)
+
+var (
+	errJournalClosed = errors.New("the journal is closed")
+)
+
+const (
+	// defaultCompactThreshold compact the journal file when the count of records is greater than it
+	defaultCompactThreshold = 1000
+)
+
+type fileJournal struct {
+	path             string
+	f                *os.File
+	w                *bufio.Writer
+	pending          map[string]int
+	entries          []*Entry
+	seq              uint64
+	records          int
+	compactThreshold int
+	closed           bool
+	mu               sync.Mutex
+	logger           *logger.Logger
+}
+
+// NewFileJournal create an instance of the Journal that is stored in the specified directory,
+// the journal file name is generated by the name, replay the pending events from the existing journal file
+func NewFileJournal(dir string, name string, logger *logger.Logger) (Journal, error) {
+	if err := os.MkdirAll(dir, fs.ModePerm); err != nil {
+		return nil, err
+	}
+	j := &fileJournal{
+		path:             filepath.Join(dir, FileName(name)),
+		pending:          make(map[string]int),
+		compactThreshold: defaultCompactThreshold,
+		logger:           logger,
+	}
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+	// remove the done records before appending the new records
+	if err := j.compact(); err != nil {
+		return nil, err
+	}
+	if len(j.entries) > 0 {
+		logger.Info("load %d pending events from the journal => %s", len(j.entries), j.path)
+	}
+	return j, nil
+}
+
+// FileName generate the journal file name by the name, the same name returns the same file name always
+func FileName(name string) string {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return fmt.Sprintf("journal_%x.log", h.Sum64())
+}
+
+// load read all the records from the journal file, ignore the broken record that is caused by crash
+func (j *fileJournal) load() error {
+	f, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		j.logger.ErrorIf(f.Close(), "[journal] close the journal file error")
+	}()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var r record
+		if err = jsonutil.Unmarshal(line, &r); err != nil {
+			j.logger.Warn("[journal] ignore the broken record => %s", string(line))
+			continue
+		}
+		j.apply(r)
+	}
+	return scanner.Err()
+}
+
+// apply update the pending events with the record
+func (j *fileJournal) apply(r record) {
+	e := r.Entry
+	// the record that is written by the old version has no action, it is always a write event
+	if e.Action == action.UnknownAction {
+		e.Action = action.WriteAction
+	}
+	j.seq = max(j.seq, e.Seq)
+	switch r.Op {
+	case AppendOp:
+		if i, ok := j.pending[e.Key]; ok {
+			j.entries[i] = &e
+		} else {
+			j.pending[e.Key] = len(j.entries)
+			j.entries = append(j.entries, &e)
+		}
+	case DoneOp:
+		// the done record of an older entry must not remove the newer entry with the same key
+		if i, ok := j.pending[e.Key]; ok && j.entries[i].Seq == e.Seq {
+			j.entries[i] = nil
+			delete(j.pending, e.Key)
+		}
+	}
+}
+
+func (j *fileJournal) Append(entry Entry) (seq uint64, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed {
+		return 0, errJournalClosed
+	}
+	// every appended entry gets a new sequence number even if it is the same as the pending entry,
+	// because the pending entry may be done by a sync that started before this event
+	j.seq++
+	entry.Seq = j.seq
+	j.apply(record{Op: AppendOp, Entry: entry})
+	// the pending event must be persisted, otherwise it may be lost after crash
+	return entry.Seq, j.write(record{Op: AppendOp, Entry: entry, Time: time.Now().Unix()}, true)
+}
+
+func (j *fileJournal) Done(key string, seq uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if i, ok := j.pending[key]; !ok || j.entries[i].Seq != seq {
+		return nil
+	}
+	j.apply(record{Op: DoneOp, Entry: Entry{Key: key, Seq: seq}})
+	if len(j.pending) == 0 || j.records >= j.compactThreshold {
+		return j.compact()
+	}
+	// losing a done record only causes the event to be synced again, so there is no need to flush to disk immediately
+	return j.write(record{Op: DoneOp, Entry: Entry{Key: key, Seq: seq}, Time: time.Now().Unix()}, false)
+}
+
+func (j *fileJournal) write(r record, sync bool) error {
+	if j.closed {
+		return errJournalClosed
+	}
+	// the journal file may be closed by a failed compaction, try to reopen it
+	if j.f == nil {
+		if err := j.open(); err != nil {
+			return err
+		}
+	}
+	data, err := jsonutil.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err = j.w.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	j.records++
+	if !sync {
+		return nil
+	}
+	if err = j.w.Flush(); err != nil {
+		return err
+	}
+	return j.f.Sync()
+}
+
+func (j *fileJournal) Pending() (entries []Entry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, e := range j.entries {
+		if e != nil {
+			entries = append(entries, *e)
+		}
+	}
+	return entries
+}
+
+func (j *fileJournal) Compact() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.compact()
+}
+
+// compact write the pending events to a temporary file, then replace the journal file with it
+func (j *fileJournal) compact() (err error) {
+	if j.closed {
+		return errJournalClosed
+	}
+	if j.f != nil {
+		if err = j.w.Flush(); err != nil {
+			return err
+		}
+		if err = j.f.Close(); err != nil {
+			return err
+		}
+		j.f = nil
+	}
+
+	tempPath := j.path + ".tmp"
+	tf, err := os.Create(tempPath)
+	if err != nil {
+		return err
+	}
+	var entries []*Entry
+	pending := make(map[string]int, len(j.pending))
+	tw := bufio.NewWriter(tf)
+	now := time.Now().Unix()
+	for _, e := range j.entries {
+		if e == nil {
+			continue
+		}
+		var data []byte
+		if data, err = jsonutil.Marshal(record{Op: AppendOp, Entry: *e, Time: now}); err == nil {
+			_, err = tw.Write(append(data, '\n'))
+		}
+		if err != nil {
+			tf.Close()
+			return err
+		}
+		pending[e.Key] = len(entries)
+		entries = append(entries, e)
+	}
+	if err = tw.Flush(); err == nil {
+		err = tf.Sync()
+	}
+	if closeErr := tf.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempPath, j.path)
+	}
+	if err != nil {
+		return err
+	}
+	j.entries = entries
+	j.pending = pending
+	j.records = len(entries)
+	return j.open()
+}
+
+// open open the journal file in append mode
+func (j *fileJournal) open() error {
+	f, err := os.OpenFile(j.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	j.f = f
+	j.w = bufio.NewWriter(f)
+	return nil
+}
+
+func (j *fileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.closed {
+		return nil
+	}
+	j.closed = true
+	if j.f == nil {
+		return nil
+	}
+	err := j.w.Flush()
+	if closeErr := j.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
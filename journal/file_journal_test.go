@@ -0,0 +1,242 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/no-src/gofs/action"
+	"github.com/no-src/gofs/logger"
+)
+
+func TestFileJournal_Replay(t *testing.T) {
+	testCases := []struct {
+		name   string
+		append []Entry
+		done   []string
+		expect []Entry
+	}{
+		{"empty", nil, nil, nil},
+		{"all pending", []Entry{NewEntry("a", "/a", 1), NewEntry("b", "/b", 2)}, nil, []Entry{NewEntry("a", "/a", 1), NewEntry("b", "/b", 2)}},
+		{"partial done", []Entry{NewEntry("a", "/a", 1), NewEntry("b", "/b", 2), NewEntry("c", "/c", 3)}, []string{"b"}, []Entry{NewEntry("a", "/a", 1), NewEntry("c", "/c", 3)}},
+		{"all done", []Entry{NewEntry("a", "/a", 1), NewEntry("b", "/b", 2)}, []string{"a", "b"}, nil},
+		{"append again", []Entry{NewEntry("a", "/a", 1), NewEntry("a", "/a", 10)}, nil, []Entry{NewEntry("a", "/a", 10)}},
+		{"append after done", []Entry{NewEntry("a", "/a", 1)}, []string{"a", "not_exist"}, nil},
+		{"latest action", []Entry{NewActionEntry("a", action.CreateAction, "/a", 0), NewEntry("b", "/b", 2), NewActionEntry("a", action.RemoveAction, "/a", 0)}, nil, []Entry{NewActionEntry("a", action.RemoveAction, "/a", 0), NewEntry("b", "/b", 2)}},
+		{"action done", []Entry{NewActionEntry("a", action.RenameAction, "/a", 0), NewEntry("b", "/b", 2)}, []string{"a"}, []Entry{NewEntry("b", "/b", 2)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			j, err := NewFileJournal(dir, tc.name, logger.NewTestLogger())
+			if err != nil {
+				t.Fatalf("create file journal error => %v", err)
+			}
+			seqs := make(map[string]uint64)
+			for _, e := range tc.append {
+				if seqs[e.Key], err = j.Append(e); err != nil {
+					t.Fatalf("append entry error => %v", err)
+				}
+			}
+			for _, key := range tc.done {
+				if err = j.Done(key, seqs[key]); err != nil {
+					t.Fatalf("done entry error => %v", err)
+				}
+			}
+			if err = j.Close(); err != nil {
+				t.Fatalf("close file journal error => %v", err)
+			}
+			reload, err := NewFileJournal(dir, tc.name, logger.NewTestLogger())
+			if err != nil {
+				t.Fatalf("reload file journal error => %v", err)
+			}
+			defer reload.Close()
+			assertEntries(t, tc.expect, reload.Pending())
+		})
+	}
+}
+
+func TestFileJournal_Crash(t *testing.T) {
+	dir := t.TempDir()
+	name := "crash"
+	j, err := NewFileJournal(dir, name, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("create file journal error => %v", err)
+	}
+	defer j.Close()
+	seqs := make(map[string]uint64)
+	for _, e := range []Entry{NewEntry("a", "/a", 1), NewEntry("b", "/b", 2)} {
+		if seqs[e.Key], err = j.Append(e); err != nil {
+			t.Fatalf("append entry error => %v", err)
+		}
+	}
+	if err = j.Done("a", seqs["a"]); err != nil {
+		t.Fatalf("done entry error => %v", err)
+	}
+	// simulate crash, do not close the journal, the appended events must not be lost,
+	// the done record may be lost, it only causes the event to be synced again
+	reload, err := NewFileJournal(dir, name, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("reload file journal error => %v", err)
+	}
+	defer reload.Close()
+	pending := reload.Pending()
+	found := false
+	for _, e := range pending {
+		if e.Seq = 0; e == NewEntry("b", "/b", 2) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expect the pending entry to be replayed after crash, but get %v", pending)
+	}
+}
+
+func TestFileJournal_DoneAfterAppendAgain(t *testing.T) {
+	dir := t.TempDir()
+	name := "done_after_append_again"
+	j, err := NewFileJournal(dir, name, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("create file journal error => %v", err)
+	}
+	defer j.Close()
+	// the write of the first event is in flight when the second event of the same key is appended
+	inFlightSeq, err := j.Append(NewEntry("a", "/a", 1))
+	if err != nil {
+		t.Fatalf("append entry error => %v", err)
+	}
+	newerSeq, err := j.Append(NewEntry("a", "/a", 1))
+	if err != nil {
+		t.Fatalf("append entry again error => %v", err)
+	}
+	if newerSeq == inFlightSeq {
+		t.Fatalf("expect a new sequence number for the newer entry, but get the same one %d", newerSeq)
+	}
+	// the in-flight write is finished, it must not remove the newer entry
+	if err = j.Done("a", inFlightSeq); err != nil {
+		t.Fatalf("done entry error => %v", err)
+	}
+	assertEntries(t, []Entry{NewEntry("a", "/a", 1)}, j.Pending())
+
+	// simulate crash, the newer entry must be replayed
+	reload, err := NewFileJournal(dir, name, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("reload file journal error => %v", err)
+	}
+	defer reload.Close()
+	pending := reload.Pending()
+	assertEntries(t, []Entry{NewEntry("a", "/a", 1)}, pending)
+	if len(pending) > 0 && pending[0].Seq != newerSeq {
+		t.Errorf("expect the sequence number %d of the replayed entry, but get %d", newerSeq, pending[0].Seq)
+	}
+	if err = reload.Done("a", newerSeq); err != nil {
+		t.Fatalf("done the newer entry error => %v", err)
+	}
+	assertEntries(t, nil, reload.Pending())
+}
+
+func TestFileJournal_IgnoreBrokenRecord(t *testing.T) {
+	dir := t.TempDir()
+	name := "broken"
+	content := `{"op":"append","entry":{"key":"a","name":"/a","size":1},"time":1}
+{"op":"append","entry":{"key":"b","name":"/b","size":2},"time":1}
+{"op":"done","entry":{"key":"a"},"time":1}
+{"op":"append","entry":{"key":"c","na`
+	if err := os.WriteFile(filepath.Join(dir, FileName(name)), []byte(content), 0600); err != nil {
+		t.Fatalf("write journal file error => %v", err)
+	}
+	j, err := NewFileJournal(dir, name, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("create file journal error => %v", err)
+	}
+	defer j.Close()
+	assertEntries(t, []Entry{NewEntry("b", "/b", 2)}, j.Pending())
+}
+
+func TestFileJournal_Compact(t *testing.T) {
+	dir := t.TempDir()
+	name := "compact"
+	j, err := NewFileJournal(dir, name, logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("create file journal error => %v", err)
+	}
+	defer j.Close()
+	fj := j.(*fileJournal)
+	fj.compactThreshold = 10
+	keepSeq, err := j.Append(NewEntry("keep", "/keep", 1))
+	if err != nil {
+		t.Fatalf("append entry error => %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		seq, err := j.Append(NewEntry("tmp", "/tmp", int64(i)))
+		if err != nil {
+			t.Fatalf("append entry error => %v", err)
+		}
+		if err = j.Done("tmp", seq); err != nil {
+			t.Fatalf("done entry error => %v", err)
+		}
+	}
+	if fj.records > fj.compactThreshold {
+		t.Errorf("expect the journal to be compacted, but get %d records", fj.records)
+	}
+	if err = j.Done("keep", keepSeq); err != nil {
+		t.Fatalf("done entry error => %v", err)
+	}
+	stat, err := os.Stat(filepath.Join(dir, FileName(name)))
+	if err != nil {
+		t.Fatalf("stat journal file error => %v", err)
+	}
+	if stat.Size() != 0 {
+		t.Errorf("expect the journal file to be empty after all the events done, but get size %d", stat.Size())
+	}
+}
+
+func TestFileJournal_Closed(t *testing.T) {
+	j, err := NewFileJournal(t.TempDir(), "closed", logger.NewTestLogger())
+	if err != nil {
+		t.Fatalf("create file journal error => %v", err)
+	}
+	if err = j.Close(); err != nil {
+		t.Fatalf("close file journal error => %v", err)
+	}
+	if err = j.Close(); err != nil {
+		t.Errorf("close file journal twice expect no error, but get %v", err)
+	}
+	if _, err = j.Append(NewEntry("a", "/a", 1)); err != errJournalClosed {
+		t.Errorf("append to the closed journal expect error %v, but get %v", errJournalClosed, err)
+	}
+}
+
+func TestEmptyJournal(t *testing.T) {
+	j := NewEmptyJournal()
+	if _, err := j.Append(NewEntry("a", "/a", 1)); err != nil {
+		t.Errorf("append error => %v", err)
+	}
+	if len(j.Pending()) != 0 {
+		t.Errorf("expect no pending entry in the empty journal")
+	}
+	if err := j.Done("a", 0); err != nil {
+		t.Errorf("done error => %v", err)
+	}
+	if err := j.Compact(); err != nil {
+		t.Errorf("compact error => %v", err)
+	}
+	if err := j.Close(); err != nil {
+		t.Errorf("close error => %v", err)
+	}
+}
+
+func assertEntries(t *testing.T, expect []Entry, actual []Entry) {
+	if len(expect) != len(actual) {
+		t.Errorf("expect %d pending entries, but get %d => %v", len(expect), len(actual), actual)
+		return
+	}
+	for i := range expect {
+		// the sequence number is assigned by the journal, compare the other fields only
+		a := actual[i]
+		if a.Seq = 0; expect[i] != a {
+			t.Errorf("expect entry %v, but get %v", expect[i], a)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package journal
+
+// Journal the append-only on-disk journal of the pending events
+type Journal interface {
+	// Append record a pending event, the newer entry will replace the older entry with the same key,
+	// return the sequence number of the appended entry
+	Append(entry Entry) (seq uint64, err error)
+	// Done mark the pending event is synced successfully, do nothing if the pending event of the key is replaced by
+	// a newer entry that has a different sequence number
+	Done(key string, seq uint64) error
+	// Pending return all the pending events in the order of appending
+	Pending() []Entry
+	// Compact rewrite the journal file with the pending events only
+	Compact() error
+	// Close close the journal file
+	Close() error
+}
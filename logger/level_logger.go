@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/no-src/log"
+	"github.com/no-src/log/formatter"
+	"github.com/no-src/log/level"
+)
+
+var errLevelUnsupported = errors.New("the logger does not support changing the log level")
+
+// levelLogger filter the logs by the log level that can be changed at runtime
+type levelLogger struct {
+	log.Logger
+
+	lvl *atomic.Int32
+}
+
+// NewLevelLogger create an instance of Logger with the specified log level, the log level can be changed at runtime by the SetLevel,
+// the logger should enable all the log levels, the logs are filtered by the Logger instead
+func NewLevelLogger(logger log.Logger, lvl level.Level, sampleRate float64) *Logger {
+	l := &levelLogger{
+		Logger: logger,
+		lvl:    &atomic.Int32{},
+	}
+	l.lvl.Store(int32(lvl))
+	return &Logger{
+		Logger: l,
+		Sample: log.NewDefaultSampleLogger(l, sampleRate),
+		lvl:    l.lvl,
+	}
+}
+
+// SetLevel change the log level of the Logger that is created by the NewLevelLogger
+func (l *Logger) SetLevel(lvl level.Level) error {
+	if l.lvl == nil {
+		return errLevelUnsupported
+	}
+	l.lvl.Store(int32(lvl))
+	return nil
+}
+
+func (l *levelLogger) Debug(format string, args ...any) {
+	if l.enabled(level.DebugLevel) {
+		l.Logger.Debug(format, args...)
+	}
+}
+
+func (l *levelLogger) Info(format string, args ...any) {
+	if l.enabled(level.InfoLevel) {
+		l.Logger.Info(format, args...)
+	}
+}
+
+func (l *levelLogger) Warn(format string, args ...any) {
+	if l.enabled(level.WarnLevel) {
+		l.Logger.Warn(format, args...)
+	}
+}
+
+func (l *levelLogger) Error(err error, format string, args ...any) {
+	if l.enabled(level.ErrorLevel) {
+		l.Logger.Error(err, format, args...)
+	}
+}
+
+func (l *levelLogger) ErrorIf(err error, format string, args ...any) error {
+	if err != nil {
+		l.Error(err, format, args...)
+	}
+	return err
+}
+
+func (l *levelLogger) WithFormatter(f formatter.Formatter) log.Logger {
+	l.Logger.WithFormatter(f)
+	return l
+}
+
+func (l *levelLogger) WithTimeFormat(f string) log.Logger {
+	l.Logger.WithTimeFormat(f)
+	return l
+}
+
+func (l *levelLogger) enabled(lvl level.Level) bool {
+	return lvl >= level.Level(l.lvl.Load())
+}
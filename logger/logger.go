@@ -1,6 +1,8 @@
 package logger
 
 import (
+	"sync/atomic"
+
 	"github.com/no-src/log"
 	"github.com/no-src/log/formatter"
 	"github.com/no-src/log/level"
@@ -24,6 +26,8 @@ type Logger struct {
 
 	// Sample the sample logger
 	Sample log.Logger
+
+	lvl *atomic.Int32
 }
 
 // NewLogger create an instance of Logger
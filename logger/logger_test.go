@@ -6,6 +6,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/no-src/log"
 	"github.com/no-src/log/level"
 )
 
@@ -48,3 +49,54 @@ func testLogger(name string, logger *Logger) {
 	sample.Warn("%s sample: hello", name)
 	sample.Error(errors.New("test error mock"), "%s sample: hello", name)
 }
+
+func TestLevelLogger(t *testing.T) {
+	testLogger("level", NewLevelLogger(log.NewConsoleLogger(level.DebugLevel), level.DebugLevel, 1.0))
+}
+
+func TestLogger_SetLevel(t *testing.T) {
+	c := &countLogger{Logger: log.NewEmptyLogger()}
+	logger := NewLevelLogger(c, level.WarnLevel, 1.0)
+
+	testCases := []struct {
+		name   string
+		lvl    level.Level
+		expect int
+	}{
+		{"debug", level.DebugLevel, 8},
+		{"info", level.InfoLevel, 6},
+		{"warn", level.WarnLevel, 4},
+		{"error", level.ErrorLevel, 2},
+		{"none", level.NoneLevel, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := logger.SetLevel(tc.lvl); err != nil {
+				t.Fatalf("set the log level error => %v", err)
+			}
+			c.count = 0
+			testLogger(tc.name, logger)
+			if c.count != tc.expect {
+				t.Errorf("expect write %d logs, but get %d", tc.expect, c.count)
+			}
+		})
+	}
+
+	if err := NewTestLogger().SetLevel(level.InfoLevel); !errors.Is(err, errLevelUnsupported) {
+		t.Errorf("expect get the error %v, but get %v", errLevelUnsupported, err)
+	}
+}
+
+type countLogger struct {
+	log.Logger
+
+	count int
+}
+
+func (l *countLogger) Debug(format string, args ...any) { l.count++ }
+
+func (l *countLogger) Info(format string, args ...any) { l.count++ }
+
+func (l *countLogger) Warn(format string, args ...any) { l.count++ }
+
+func (l *countLogger) Error(err error, format string, args ...any) { l.count++ }
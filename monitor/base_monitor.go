@@ -11,7 +11,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/no-src/gofs/action"
 	"github.com/no-src/gofs/eventlog"
+	"github.com/no-src/gofs/journal"
 	"github.com/no-src/gofs/logger"
 	"github.com/no-src/gofs/retry"
 	nssync "github.com/no-src/gofs/sync"
@@ -31,9 +33,11 @@ type baseMonitor struct {
 	shutdown        chan struct{}
 	syncOnce        bool
 	el              eventlog.EventLog
+	journal         journal.Journal
 	enableSyncDelay bool
 	syncDelayEvents int
 	syncDelayTime   time.Duration
+	syncDelayMu     sync.RWMutex
 	lastSyncTime    time.Time
 	syncing         bool
 	multiWorkers    bool
@@ -53,7 +57,11 @@ func newBaseMonitor(opt Option) baseMonitor {
 	syncDelayTime := opt.SyncDelayTime
 	syncWorkers := opt.SyncWorkers
 	logger := opt.Logger
+	j := opt.Journal
 
+	if j == nil {
+		j = journal.NewEmptyJournal()
+	}
 	multiWorkers := false
 	if syncWorkers > 1 {
 		multiWorkers = true
@@ -68,6 +76,7 @@ func newBaseMonitor(opt Option) baseMonitor {
 		shutdown:        make(chan struct{}, 1),
 		syncOnce:        syncOnce,
 		el:              eventlog.New(eventWriter),
+		journal:         j,
 		enableSyncDelay: enableSyncDelay,
 		syncDelayEvents: syncDelayEvents,
 		syncDelayTime:   syncDelayTime,
@@ -111,6 +120,9 @@ func (m *baseMonitor) addWrite(name string, size int64) {
 	wm := m.writeMap[m.key(name)]
 	if wm == nil {
 		wm = newDefaultWriteMessage(name, size)
+		// append to the journal before the write message is dispatched, then the sync of it marks the right entry done,
+		// the following events are covered by the same entry until the write message is dispatched
+		wm.seq = m.appendJournal(journal.NewEntry(m.key(name), name, size))
 		m.writeMap[m.key(name)] = wm
 		m.writeList = append(m.writeList, wm)
 	} else {
@@ -134,9 +146,80 @@ func (m *baseMonitor) removeWrite(name string) {
 		m.logger.Debug("removeWrite => [%s]", name)
 	}
 	m.mu.Unlock()
+	// the write message that is dispatched already marks its entry done after the sync
+	if wm != nil {
+		m.done(name, wm.seq)
+	}
 	m.writeNotify <- struct{}{}
 }
 
+// done mark the write event is finished in the journal, the newer write event of the same path is kept pending
+func (m *baseMonitor) done(name string, seq uint64) {
+	m.logger.ErrorIf(m.journal.Done(m.key(name), seq), "mark the write event done in journal error => [%s]", name)
+}
+
+// appendJournal record the pending event in the journal, return the sequence number of it
+func (m *baseMonitor) appendJournal(e journal.Entry) uint64 {
+	seq, err := m.journal.Append(e)
+	m.logger.ErrorIf(err, "append the %s event to journal error => [%s]", e.Action.String(), e.Name)
+	return seq
+}
+
+// eventKey return the journal key of the create, remove and rename events,
+// these events share the same key of a path, so only the latest one of them is pending
+func (m *baseMonitor) eventKey(name string) string {
+	return "event:" + m.key(name)
+}
+
+// syncEvent record the create, remove or rename event in the journal before executing it,
+// the event is kept pending if it fails, then it will be replayed on the next startup or be covered by the next full sync
+func (m *baseMonitor) syncEvent(act action.Action, name string, f func(path string) error) error {
+	key := m.eventKey(name)
+	seq := m.appendJournal(journal.NewActionEntry(key, act, name, 0))
+	err := f(name)
+	// if file or directory is not exist, ignore it
+	if err == nil || os.IsNotExist(err) {
+		m.logger.ErrorIf(m.journal.Done(key, seq), "mark the %s event done in journal error => [%s]", act.String(), name)
+	}
+	return err
+}
+
+// replayJournal resend the pending events that are recorded in the journal,
+// it should be called after the write notification loop is started
+func (m *baseMonitor) replayJournal() {
+	entries := m.journal.Pending()
+	if len(entries) > 0 {
+		m.logger.Info("replay %d pending events from the journal", len(entries))
+	}
+	for _, e := range entries {
+		var err error
+		switch e.Action {
+		case action.CreateAction:
+			err = m.syncEvent(e.Action, e.Name, m.syncer.Create)
+		case action.RemoveAction:
+			m.removeWrite(e.Name)
+			err = m.syncEvent(e.Action, e.Name, m.syncer.Remove)
+		case action.RenameAction:
+			err = m.syncEvent(e.Action, e.Name, m.syncer.Rename)
+		default:
+			m.addWrite(e.Name, e.Size)
+		}
+		m.logger.ErrorIf(err, "replay the %s event error => [%s]", e.Action.String(), e.Name)
+	}
+}
+
+// fullSync execute the full sync, then mark the pending events that are recorded in the journal before the sync done
+func (m *baseMonitor) fullSync(f func() error) error {
+	entries := m.journal.Pending()
+	if err := f(); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		m.logger.ErrorIf(m.journal.Done(e.Key, e.Seq), "mark the write event done in journal error => [%s]", e.Name)
+	}
+	return nil
+}
+
 // startReceiveWriteNotify start loop to receive write notification, and delay process
 func (m *baseMonitor) startReceiveWriteNotify() {
 	for {
@@ -177,14 +260,15 @@ func (m *baseMonitor) startSyncWrite() {
 			continue
 		}
 		name := wm.name
+		seq := wm.seq
 		if !m.multiWorkers {
-			m.write(name)
+			m.write(name, seq)
 			continue
 		}
 
 		// a small file that file size less than smallFileSize
 		if wm.size > 0 && wm.size < m.smallFileSize {
-			m.write(name)
+			m.write(name, seq)
 			continue
 		}
 
@@ -200,28 +284,33 @@ func (m *baseMonitor) startSyncWrite() {
 			}
 
 			m.workerMap.Store(name, struct{}{})
-			m.write(name)
+			m.write(name, seq)
 			m.workerMap.Delete(name)
 			m.workerChan <- struct{}{}
 		}()
 	}
 }
 
-func (m *baseMonitor) write(name string) {
+func (m *baseMonitor) write(name string, seq uint64) {
 	if m.retry != nil {
 		m.retry.Do(func() error {
 			err := m.syncer.Write(name)
 			// if file or directory is not exist, ignore it
 			if os.IsNotExist(err) {
-				return nil
+				err = nil
 			}
 			if err != nil {
 				m.logger.Error(err, "write file error => [%s]", name)
+			} else {
+				m.done(name, seq)
 			}
 			return err
 		}, fmt.Sprintf("write file => %s", name))
 	} else {
-		m.logger.ErrorIf(m.syncer.Write(name), "write file error => [%s]", name)
+		err := m.logger.ErrorIf(m.syncer.Write(name), "write file error => [%s]", name)
+		if err == nil || os.IsNotExist(err) {
+			m.done(name, seq)
+		}
 	}
 }
 
@@ -264,6 +353,19 @@ func (m *baseMonitor) startCron(f func() error) error {
 }
 
 func (m *baseMonitor) SyncCron(spec string) error {
+	spec = strings.TrimSpace(spec)
+	if len(spec) == 0 {
+		return nil
+	}
+	err := ValidateCron(spec)
+	if err == nil {
+		m.syncSpec = spec
+	}
+	return err
+}
+
+// ValidateCron check the cron spec with seconds is valid or not, the empty spec is valid
+func ValidateCron(spec string) error {
 	spec = strings.TrimSpace(spec)
 	if len(spec) == 0 {
 		return nil
@@ -272,9 +374,6 @@ func (m *baseMonitor) SyncCron(spec string) error {
 		cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
 	)
 	_, err := parser.Parse(spec)
-	if err == nil {
-		m.syncSpec = spec
-	}
 	return err
 }
 
@@ -288,17 +387,33 @@ func (m *baseMonitor) Shutdown() (err error) {
 	return err
 }
 
+func (m *baseMonitor) ReloadSyncDelay(enable bool, events int, delay time.Duration) {
+	m.syncDelayMu.Lock()
+	defer m.syncDelayMu.Unlock()
+	m.enableSyncDelay = enable
+	m.syncDelayEvents = events
+	m.syncDelayTime = delay
+}
+
+// syncDelay return the current sync delay settings
+func (m *baseMonitor) syncDelay() (enable bool, events int, delay time.Duration) {
+	m.syncDelayMu.RLock()
+	defer m.syncDelayMu.RUnlock()
+	return m.enableSyncDelay, m.syncDelayEvents, m.syncDelayTime
+}
+
 func (m *baseMonitor) waitSyncDelay(eventLenFunc func() int) {
 	for {
-		if m.enableSyncDelay && !m.syncing {
+		enableSyncDelay, syncDelayEvents, syncDelayTime := m.syncDelay()
+		if enableSyncDelay && !m.syncing {
 			currentEvents := eventLenFunc()
 			if currentEvents > 0 {
-				if currentEvents < m.syncDelayEvents && time.Now().Before(m.lastSyncTime.Add(m.syncDelayTime)) {
-					m.logger.Sample.Debug("[sync delay] [waiting] sync delay time => %s, sync delay events => %d, last sync time => %s, current event count => %d ", m.syncDelayTime, m.syncDelayEvents, m.lastSyncTime, currentEvents)
+				if currentEvents < syncDelayEvents && time.Now().Before(m.lastSyncTime.Add(syncDelayTime)) {
+					m.logger.Sample.Debug("[sync delay] [waiting] sync delay time => %s, sync delay events => %d, last sync time => %s, current event count => %d ", syncDelayTime, syncDelayEvents, m.lastSyncTime, currentEvents)
 					<-time.After(time.Second)
 					continue
 				}
-				m.logger.Debug("[sync delay] [starting] sync delay time => %s, sync delay events => %d, last sync time => %s, current event count => %d ", m.syncDelayTime, m.syncDelayEvents, m.lastSyncTime, currentEvents)
+				m.logger.Debug("[sync delay] [starting] sync delay time => %s, sync delay events => %d, last sync time => %s, current event count => %d ", syncDelayTime, syncDelayEvents, m.lastSyncTime, currentEvents)
 				m.syncing = true
 			}
 		}
@@ -308,11 +423,12 @@ func (m *baseMonitor) waitSyncDelay(eventLenFunc func() int) {
 
 func (m *baseMonitor) resetSyncDelay() {
 	m.lastSyncTime = time.Now()
-	if m.enableSyncDelay {
+	enableSyncDelay, syncDelayEvents, syncDelayTime := m.syncDelay()
+	if enableSyncDelay {
 		syncing := m.syncing
 		m.syncing = false
 		if syncing {
-			m.logger.Debug("[sync delay] [reset] sync delay time => %s, sync delay events => %d, last sync time => %s ", m.syncDelayTime, m.syncDelayEvents, m.lastSyncTime)
+			m.logger.Debug("[sync delay] [reset] sync delay time => %s, sync delay events => %d, last sync time => %s ", syncDelayTime, syncDelayEvents, m.lastSyncTime)
 		}
 	} else {
 		m.syncing = true
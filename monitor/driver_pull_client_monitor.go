@@ -52,7 +52,9 @@ func (m *driverPullClientMonitor) waitShutdown(st *atomic.Bool, wd wait.Done) {
 func (m *driverPullClientMonitor) sync() (err error) {
 	source := m.syncer.Source()
 	path := source.RemotePath().Base()
-	return m.syncer.SyncOnce(path)
+	return m.fullSync(func() error {
+		return m.syncer.SyncOnce(path)
+	})
 }
 
 func (m *driverPullClientMonitor) Close() error {
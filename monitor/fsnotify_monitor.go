@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/no-src/gofs/action"
 	"github.com/no-src/gofs/core"
 	"github.com/no-src/gofs/eventlog"
 	"github.com/no-src/gofs/ignore"
@@ -86,12 +87,16 @@ func (m *fsNotifyMonitor) Start() (wait.Wait, error) {
 	// execute -sync_once flag
 	if m.syncOnce {
 		wd.Done()
-		return wd, m.syncer.SyncOnce(source.Path().Base())
+		return wd, m.fullSync(func() error {
+			return m.syncer.SyncOnce(source.Path().Base())
+		})
 	}
 
 	// execute -sync_cron flag
 	if err := m.startCron(func() error {
-		return m.syncer.SyncOnce(source.Path().Base())
+		return m.fullSync(func() error {
+			return m.syncer.SyncOnce(source.Path().Base())
+		})
 	}); err != nil {
 		return nil, err
 	}
@@ -107,6 +112,9 @@ func (m *fsNotifyMonitor) Start() (wait.Wait, error) {
 	go m.startSyncWrite()
 	go m.startProcessEvents()
 	go m.startReceiveEvents(wd)
+
+	// resend the events that are not finished before the last exit
+	m.replayJournal()
 	return wd, nil
 }
 
@@ -201,7 +209,7 @@ func (m *fsNotifyMonitor) write(event fsnotify.Event) {
 }
 
 func (m *fsNotifyMonitor) create(event fsnotify.Event) {
-	err := m.syncer.Create(event.Name)
+	err := m.syncEvent(action.CreateAction, event.Name, m.syncer.Create)
 	if err == nil {
 		// if create a new dir, then monitor it
 		isDir, err := m.syncer.IsDir(event.Name)
@@ -248,11 +256,11 @@ func (m *fsNotifyMonitor) symlinkOrCreate(event fsnotify.Event) {
 
 func (m *fsNotifyMonitor) remove(event fsnotify.Event) {
 	m.removeWrite(event.Name)
-	m.logger.ErrorIf(m.syncer.Remove(event.Name), "[remove] event execute error => [%s]", event.Name)
+	m.logger.ErrorIf(m.syncEvent(action.RemoveAction, event.Name, m.syncer.Remove), "[remove] event execute error => [%s]", event.Name)
 }
 
 func (m *fsNotifyMonitor) rename(event fsnotify.Event) {
-	m.logger.ErrorIf(m.syncer.Rename(event.Name), "[rename] event execute error => [%s]", event.Name)
+	m.logger.ErrorIf(m.syncEvent(action.RenameAction, event.Name, m.syncer.Rename), "[rename] event execute error => [%s]", event.Name)
 }
 
 func (m *fsNotifyMonitor) chmod(event fsnotify.Event) {
@@ -2,6 +2,8 @@ package monitor
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/no-src/gofs/core"
 	"github.com/no-src/gofs/result"
@@ -18,25 +20,60 @@ type Monitor interface {
 	SyncCron(spec string) error
 	// Shutdown exit the Start
 	Shutdown() error
+	// ReloadSyncDelay change the sync delay settings at runtime
+	ReloadSyncDelay(enable bool, events int, delay time.Duration)
 }
 
 type runFn func(content string, ext string) result.Result
 
 // NewMonitor create a monitor instance
 func NewMonitor(opt Option, run runFn) (Monitor, error) {
-	source := opt.Syncer.Source()
+	fn, err := newMonitorFunc(opt.Syncer.Source(), opt.EnableTaskClient)
+	if err != nil {
+		return nil, err
+	}
+	return fn(opt, run)
+}
+
+// Validate check the source is supported by the monitor or not, and check the sync cron is allowed or not
+func Validate(source core.VFS, enableTaskClient bool, syncCron string) error {
+	if _, err := newMonitorFunc(source, enableTaskClient); err != nil {
+		return err
+	}
+	if len(strings.TrimSpace(syncCron)) > 0 && isTaskClient(source, enableTaskClient) {
+		return errTaskClientSyncCron
+	}
+	return nil
+}
+
+// newMonitorFunc return the function to create the monitor according to the source
+func newMonitorFunc(source core.VFS, enableTaskClient bool) (newMonitorFn, error) {
 	if source.IsDisk() {
-		return NewFsNotifyMonitor(opt)
+		return withoutRun(NewFsNotifyMonitor), nil
 	} else if source.Is(core.RemoteDisk) && source.Server() {
-		return NewRemoteServerMonitor(opt)
-	} else if source.Is(core.RemoteDisk) && !source.Server() && opt.EnableTaskClient {
-		return NewTaskClientMonitor(opt, run)
+		return withoutRun(NewRemoteServerMonitor), nil
+	} else if isTaskClient(source, enableTaskClient) {
+		return NewTaskClientMonitor, nil
 	} else if source.Is(core.RemoteDisk) && !source.Server() {
-		return NewRemoteClientMonitor(opt)
+		return withoutRun(NewRemoteClientMonitor), nil
 	} else if source.Is(core.SFTP) {
-		return NewSftpPullClientMonitor(opt)
+		return withoutRun(NewSftpPullClientMonitor), nil
 	} else if source.Is(core.MinIO) {
-		return NewMinIOPullClientMonitor(opt)
+		return withoutRun(NewMinIOPullClientMonitor), nil
 	}
 	return nil, fmt.Errorf("file system unsupported ! source=>%s", source.Type().String())
 }
+
+// isTaskClient return true if the source is a remote server and the task client is enabled
+func isTaskClient(source core.VFS, enableTaskClient bool) bool {
+	return source.Is(core.RemoteDisk) && !source.Server() && enableTaskClient
+}
+
+type newMonitorFn func(opt Option, run runFn) (Monitor, error)
+
+// withoutRun convert the function that creates the monitor without the run function to newMonitorFn
+func withoutRun(fn func(opt Option) (Monitor, error)) newMonitorFn {
+	return func(opt Option, _ runFn) (Monitor, error) {
+		return fn(opt)
+	}
+}
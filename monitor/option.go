@@ -8,6 +8,7 @@ import (
 	"github.com/no-src/gofs/auth"
 	"github.com/no-src/gofs/conf"
 	"github.com/no-src/gofs/ignore"
+	"github.com/no-src/gofs/journal"
 	"github.com/no-src/gofs/logger"
 	"github.com/no-src/gofs/report"
 	"github.com/no-src/gofs/retry"
@@ -19,12 +20,15 @@ type Option struct {
 	SyncOnce            bool
 	EnableTLS           bool
 	TLSCertFile         string
+	TLSClientCertFile   string
+	TLSClientKeyFile    string
 	EnableSyncDelay     bool
 	SyncDelayEvents     int
 	SyncDelayTime       time.Duration
 	SyncWorkers         int
 	Users               []*auth.User
 	EventWriter         io.Writer
+	Journal             journal.Journal
 	Syncer              sync.Sync
 	Retry               retry.Retry
 	PathIgnore          ignore.PathIgnore
@@ -36,11 +40,13 @@ type Option struct {
 }
 
 // NewMonitorOption create an instance of the Option, store all the monitor component options
-func NewMonitorOption(config conf.Config, syncer sync.Sync, retry retry.Retry, users []*auth.User, eventWriter io.Writer, pi ignore.PathIgnore, reporter report.Reporter, logger *logger.Logger) Option {
+func NewMonitorOption(config conf.Config, syncer sync.Sync, retry retry.Retry, users []*auth.User, eventWriter io.Writer, j journal.Journal, pi ignore.PathIgnore, reporter report.Reporter, logger *logger.Logger) Option {
 	opt := Option{
 		SyncOnce:            config.SyncOnce,
 		EnableTLS:           config.EnableTLS,
 		TLSCertFile:         config.TLSCertFile,
+		TLSClientCertFile:   config.TLSClientCertFile,
+		TLSClientKeyFile:    config.TLSClientKeyFile,
 		EnableSyncDelay:     config.EnableSyncDelay,
 		SyncDelayEvents:     config.SyncDelayEvents,
 		SyncDelayTime:       config.SyncDelayTime.Duration(),
@@ -49,6 +55,7 @@ func NewMonitorOption(config conf.Config, syncer sync.Sync, retry retry.Retry, u
 		Retry:               retry,
 		Users:               users,
 		EventWriter:         eventWriter,
+		Journal:             j,
 		PathIgnore:          pi,
 		Reporter:            reporter,
 		EnableTaskClient:    config.EnableTaskClient,
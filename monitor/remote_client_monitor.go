@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -28,6 +30,13 @@ type remoteClientMonitor struct {
 	closed   atomic.Bool
 	messages *clist.CList
 	pi       ignore.PathIgnore
+	// epoch the epoch of the server message log that the last received message belongs to
+	epoch atomic.Int64
+	// seq the sequence number of the last received message
+	seq atomic.Uint64
+	// ack the pending acknowledgement of the applied messages
+	ack   *monitor.AckRequest
+	ackMu sync.Mutex
 }
 
 // NewRemoteClientMonitor create an instance of remoteClientMonitor to monitor the remote file change
@@ -38,6 +47,8 @@ func NewRemoteClientMonitor(opt Option) (Monitor, error) {
 	port := source.Port()
 	enableTLS := opt.EnableTLS
 	certFile := opt.TLSCertFile
+	clientCertFile := opt.TLSClientCertFile
+	clientKeyFile := opt.TLSClientKeyFile
 	users := opt.Users
 	pi := opt.PathIgnore
 
@@ -51,7 +62,7 @@ func NewRemoteClientMonitor(opt Option) (Monitor, error) {
 		user = users[0]
 	}
 	m := &remoteClientMonitor{
-		client:      apiclient.New(host, port, enableTLS, certFile, user),
+		client:      apiclient.New(host, port, enableTLS, certFile, clientCertFile, clientKeyFile, user),
 		messages:    clist.New(),
 		baseMonitor: newBaseMonitor(opt),
 		pi:          pi,
@@ -83,6 +94,10 @@ func (m *remoteClientMonitor) Start() (wait.Wait, error) {
 	go m.startReceiveWriteNotify()
 	go m.startSyncWrite()
 	go m.startProcessMessage()
+	go m.startAck()
+
+	// resend the events that are not finished before the last exit
+	m.replayJournal()
 
 	return w, nil
 }
@@ -93,7 +108,9 @@ func (m *remoteClientMonitor) sync() (err error) {
 	if err != nil {
 		return err
 	}
-	return m.syncer.SyncOnce(info.ServerAddr + info.SourcePath)
+	return m.fullSync(func() error {
+		return m.syncer.SyncOnce(info.ServerAddr + info.SourcePath)
+	})
 }
 
 // syncAndShutdown execute sync and then try to shut down, the caller should wait for shutdown by wait.Wait()
@@ -129,7 +146,7 @@ func (m *remoteClientMonitor) waitShutdown(st *atomic.Bool, wd wait.Done) {
 // readMessage loop read the messages, if receive a message, parse the message then send to consumers according to the api type.
 // if receive a shutdown notify, then stop reading the message.
 func (m *remoteClientMonitor) readMessage(st *atomic.Bool, wd wait.Done) {
-	mc, err := m.client.Monitor()
+	mc, err := m.monitor()
 	if err != nil {
 		return
 	}
@@ -149,7 +166,7 @@ func (m *remoteClientMonitor) readMessage(st *atomic.Bool, wd wait.Done) {
 			m.logger.Error(err, "receive monitor message error")
 			if m.client.IsClosed(err) {
 				m.retry.Do(func() error {
-					nmc, err := m.client.Monitor()
+					nmc, err := m.monitor()
 					if err == nil {
 						mc = nmc
 						m.logger.Info("monitor the remote server success")
@@ -159,7 +176,7 @@ func (m *remoteClientMonitor) readMessage(st *atomic.Bool, wd wait.Done) {
 			} else if m.client.IsUnauthenticated(err) {
 				if m.logger.ErrorIf(m.client.Login(), "re-login to remote server error") == nil {
 					m.logger.Info("re-login to remote server success")
-					if nmc, err := m.client.Monitor(); err == nil {
+					if nmc, err := m.monitor(); err == nil {
 						mc = nmc
 						m.logger.Info("monitor the remote server success")
 					}
@@ -171,11 +188,47 @@ func (m *remoteClientMonitor) readMessage(st *atomic.Bool, wd wait.Done) {
 			}
 		} else {
 			errCount = 0
+			m.receivedSeq(msg.GetEpoch(), msg.GetSeq())
 			m.messages.PushBack(msg)
 		}
 	}
 }
 
+// monitor start the monitor stream, resume it from the last received message,
+// the server replays the missed messages or notifies to sync all the files if the missed messages are discarded
+func (m *remoteClientMonitor) monitor() (monitor.MonitorService_MonitorClient, error) {
+	mc, err := m.client.Monitor(&monitor.MonitorRequest{
+		Epoch:   m.epoch.Load(),
+		FromSeq: m.seq.Load(),
+	})
+	if err != nil || m.epoch.Load() != 0 {
+		return mc, err
+	}
+	// no message is received before, record the start position of the stream
+	header, err := mc.Header()
+	if err != nil {
+		return nil, err
+	}
+	epochs, seqs := header.Get(monitor.EpochHeader), header.Get(monitor.SeqHeader)
+	if len(epochs) > 0 && len(seqs) > 0 {
+		epoch, epochErr := strconv.ParseInt(epochs[0], 10, 64)
+		seq, seqErr := strconv.ParseUint(seqs[0], 10, 64)
+		if epochErr == nil && seqErr == nil {
+			m.receivedSeq(epoch, seq)
+		}
+	}
+	return mc, nil
+}
+
+// receivedSeq record the position of the last received message
+func (m *remoteClientMonitor) receivedSeq(epoch int64, seq uint64) {
+	if epoch == 0 {
+		return
+	}
+	m.epoch.Store(epoch)
+	m.seq.Store(seq)
+}
+
 // startProcessMessage start loop to process the file change messages
 func (m *remoteClientMonitor) startProcessMessage() {
 	for {
@@ -192,15 +245,73 @@ func (m *remoteClientMonitor) startProcessMessage() {
 		}
 		msg := element.Value.(*monitor.MonitorMessage)
 		m.logger.Info("client read request => %s", msg.String())
-		if m.pi.MatchPath(msg.FileInfo.Path, "remote client monitor", action.Action(msg.Action).String()) {
+		var err error
+		if msg.FullSync {
+			// the missed messages are discarded by the server, sync all the files instead
+			m.logger.Warn("the missed monitor messages are discarded by the server, start to sync all the files")
+			err = m.logger.ErrorIf(m.sync(), "sync all the files error")
+		} else if m.pi.MatchPath(msg.FileInfo.Path, "remote client monitor", action.Action(msg.Action).String()) {
 			// ignore match
 		} else {
-			m.execSync(msg)
+			err = m.execSync(msg)
 		}
+		m.applied(msg, err)
 		m.messages.Remove(element)
 	}
 }
 
+// applied record the message is applied, the acknowledgement will be sent to the server later
+func (m *remoteClientMonitor) applied(msg *monitor.MonitorMessage, err error) {
+	m.ackMu.Lock()
+	defer m.ackMu.Unlock()
+	if m.ack == nil {
+		m.ack = &monitor.AckRequest{}
+	}
+	m.ack.Epoch = msg.GetEpoch()
+	m.ack.Seq = msg.GetSeq()
+	m.ack.Path = msg.GetFileInfo().GetPath()
+	m.ack.Action = msg.GetAction()
+	if err != nil {
+		m.ack.FailureCount++
+		m.ack.Error = err.Error()
+	} else {
+		m.ack.SuccessCount++
+	}
+}
+
+// startAck start loop to send the acknowledgements of the applied messages to the server periodically
+func (m *remoteClientMonitor) startAck() {
+	for !m.closed.Load() {
+		<-time.After(time.Second)
+		m.ackMu.Lock()
+		req := m.ack
+		m.ack = nil
+		m.ackMu.Unlock()
+		if req == nil {
+			continue
+		}
+		if err := m.client.Ack(req); err != nil {
+			m.logger.Error(err, "send the acknowledgement to the server error")
+			m.restoreAck(req)
+		}
+	}
+}
+
+// restoreAck merge the acknowledgement that is failed to send into the pending acknowledgement
+func (m *remoteClientMonitor) restoreAck(req *monitor.AckRequest) {
+	m.ackMu.Lock()
+	defer m.ackMu.Unlock()
+	if m.ack == nil {
+		m.ack = req
+		return
+	}
+	m.ack.SuccessCount += req.SuccessCount
+	m.ack.FailureCount += req.FailureCount
+	if len(m.ack.Error) == 0 {
+		m.ack.Error = req.Error
+	}
+}
+
 // execSync execute the file change message to sync
 func (m *remoteClientMonitor) execSync(msg *monitor.MonitorMessage) (err error) {
 	fi := msg.FileInfo
@@ -218,7 +329,7 @@ func (m *remoteClientMonitor) execSync(msg *monitor.MonitorMessage) (err error)
 
 	switch action.Action(msg.Action) {
 	case action.CreateAction:
-		err = m.syncer.Create(path)
+		err = m.syncEvent(action.CreateAction, path, m.syncer.Create)
 	case action.SymlinkAction:
 		err = m.syncer.Symlink(fi.LinkTo, path)
 	case action.WriteAction:
@@ -230,9 +341,9 @@ func (m *remoteClientMonitor) execSync(msg *monitor.MonitorMessage) (err error)
 		m.addWrite(path, fi.Size)
 	case action.RemoveAction:
 		m.removeWrite(path)
-		err = m.syncer.Remove(path)
+		err = m.syncEvent(action.RemoveAction, path, m.syncer.Remove)
 	case action.RenameAction:
-		err = m.syncer.Rename(path)
+		err = m.syncEvent(action.RenameAction, path, m.syncer.Rename)
 	case action.ChmodAction:
 		err = m.syncer.Chmod(path)
 	}
@@ -19,6 +19,8 @@ import (
 	"github.com/no-src/nsgo/randutil"
 )
 
+var errTaskClientSyncCron = errors.New("the usage of the -sync_cron flag is incompatible with enabling the -task_client flag")
+
 type taskClientMonitor struct {
 	shutdown chan struct{}
 	retry    retry.Retry
@@ -40,6 +42,8 @@ func NewTaskClientMonitor(opt Option, run runFn) (Monitor, error) {
 	port := source.Port()
 	enableTLS := opt.EnableTLS
 	certFile := opt.TLSCertFile
+	clientCertFile := opt.TLSClientCertFile
+	clientKeyFile := opt.TLSClientKeyFile
 	users := opt.Users
 	labels := opt.TaskClientLabels
 	retry := opt.Retry
@@ -53,7 +57,7 @@ func NewTaskClientMonitor(opt Option, run runFn) (Monitor, error) {
 	m := &taskClientMonitor{
 		shutdown: make(chan struct{}, 1),
 		retry:    retry,
-		client:   apiclient.New(host, port, enableTLS, certFile, user),
+		client:   apiclient.New(host, port, enableTLS, certFile, clientCertFile, clientKeyFile, user),
 		runFn:    run,
 		clientId: randutil.RandomString(10),
 		labels:   labels,
@@ -148,7 +152,11 @@ func (m *taskClientMonitor) SyncCron(spec string) error {
 	if len(spec) == 0 {
 		return nil
 	}
-	return errors.New("the usage of the -sync_cron flag is incompatible with enabling the -task_client flag")
+	return errTaskClientSyncCron
+}
+
+// ReloadSyncDelay the task client monitor does not sync the files, so nothing to do
+func (m *taskClientMonitor) ReloadSyncDelay(enable bool, events int, delay time.Duration) {
 }
 
 func (m *taskClientMonitor) Shutdown() (err error) {
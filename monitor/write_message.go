@@ -13,6 +13,8 @@ type writeMessage struct {
 	last int64
 	// cancel the current writeMessage is canceled or not
 	cancel bool
+	// seq the sequence number of the write event in the journal
+	seq uint64
 }
 
 func newWriteMessage(name string, size int64, count int, last int64) *writeMessage {
@@ -0,0 +1,15 @@
+package reload
+
+// Reloader re-parse the config file and apply the changes that can be applied live
+type Reloader interface {
+	// Reload re-parse the config file and apply the changes, return the changed fields of the config
+	Reload() (Result, error)
+}
+
+// Result the changed fields of the reloaded config, the field names are the same as the config file
+type Result struct {
+	// Applied the changed fields that are applied live
+	Applied []string `json:"applied"`
+	// RequireRestart the changed fields that require a restart to take effect
+	RequireRestart []string `json:"require_restart"`
+}
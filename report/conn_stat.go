@@ -19,4 +19,6 @@ type ConnStat struct {
 	DisconnectTime timeutil.Time `json:"disconnect_time"`
 	// LifeTime the lifetime of a client, it is 0s always that if the client is online
 	LifeTime core.Duration `json:"life_time"`
+	// Delivery the delivery status of the monitor messages
+	Delivery DeliveryStat `json:"delivery"`
 }
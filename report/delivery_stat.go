@@ -0,0 +1,74 @@
+package report
+
+import (
+	"github.com/no-src/gofs/eventlog"
+	"github.com/no-src/nsgo/timeutil"
+)
+
+// DeliveryStat the delivery status of the monitor messages that are sent to the client
+type DeliveryStat struct {
+	// SentCount the count of the messages that are sent to the client
+	SentCount uint64 `json:"sent_count"`
+	// SentSeq the sequence number of the last message that is sent to the client
+	SentSeq uint64 `json:"sent_seq"`
+	// AckSeq the sequence number of the last message that is applied by the client
+	AckSeq uint64 `json:"ack_seq"`
+	// Lag the count of the messages that are sent but not applied by the client yet
+	Lag uint64 `json:"lag"`
+	// SuccessCount the count of the messages that are applied successfully
+	SuccessCount uint64 `json:"success_count"`
+	// FailureCount the count of the messages that are failed to apply
+	FailureCount uint64 `json:"failure_count"`
+	// LastEvent the last applied event
+	LastEvent *eventlog.Event `json:"last_event"`
+	// LastError the error of the last failed message
+	LastError string `json:"last_error"`
+	// LastAckTime the time of the last acknowledgement
+	LastAckTime timeutil.Time `json:"last_ack_time"`
+}
+
+// Ack the acknowledgement of the monitor messages that are applied by the client since the last acknowledgement
+type Ack struct {
+	// Seq the sequence number of the last applied message
+	Seq uint64
+	// Event the last applied event
+	Event eventlog.Event
+	// SuccessCount the count of the messages that are applied successfully
+	SuccessCount uint64
+	// FailureCount the count of the messages that are failed to apply
+	FailureCount uint64
+	// Error the error of the last failed message
+	Error string
+}
+
+// sent update the delivery status after a message is sent to the client
+func (ds *DeliveryStat) sent(seq uint64) {
+	ds.SentCount++
+	ds.SentSeq = max(ds.SentSeq, seq)
+	ds.updateLag()
+}
+
+// ack update the delivery status with the acknowledgement of the client
+func (ds *DeliveryStat) ack(ack Ack) {
+	ds.AckSeq = max(ds.AckSeq, ack.Seq)
+	ds.SuccessCount += ack.SuccessCount
+	ds.FailureCount += ack.FailureCount
+	if len(ack.Event.Name) > 0 {
+		event := ack.Event
+		ds.LastEvent = &event
+	}
+	if len(ack.Error) > 0 {
+		ds.LastError = ack.Error
+	}
+	ds.LastAckTime = timeutil.Now()
+	ds.updateLag()
+}
+
+func (ds *DeliveryStat) updateLag() {
+	applied := ds.SuccessCount + ds.FailureCount
+	if ds.SentCount > applied {
+		ds.Lag = ds.SentCount - applied
+	} else {
+		ds.Lag = 0
+	}
+}
@@ -2,3 +2,6 @@ package report
 
 // EventStat the statistical data of file change events
 type EventStat map[string]uint64
+
+// LockoutStat the statistical data of the lockout events, the key is the kind of the lockout
+type LockoutStat map[string]uint64
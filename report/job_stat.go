@@ -0,0 +1,57 @@
+package report
+
+import (
+	"github.com/no-src/gofs/eventlog"
+	"github.com/no-src/nsgo/timeutil"
+)
+
+const (
+	// JobStatusRunning the sync job is running
+	JobStatusRunning = "running"
+	// JobStatusStopped the sync job is stopped normally
+	JobStatusStopped = "stopped"
+	// JobStatusFailed the sync job is stopped with an error
+	JobStatusFailed = "failed"
+)
+
+// JobStat the status of a named sync job
+type JobStat struct {
+	// Name the name of the sync job
+	Name string `json:"name"`
+	// Source the source of the sync job
+	Source string `json:"source"`
+	// Dest the dest of the sync job
+	Dest string `json:"dest"`
+	// Status the current status of the sync job, like running, stopped and failed
+	Status string `json:"status"`
+	// Error the error message if the sync job is failed
+	Error string `json:"error"`
+	// StartTime the start time of the sync job
+	StartTime timeutil.Time `json:"start_time"`
+	// StopTime the stop time of the sync job
+	StopTime timeutil.Time `json:"stop_time"`
+	// EventStat the statistical data of file change events of the sync job
+	EventStat EventStat `json:"event_stat"`
+}
+
+// WithJob return a Reporter that shares the report data with the specified Reporter,
+// and collects the file change events to the status of the named sync job too
+func WithJob(r Reporter, name string) Reporter {
+	if rr, ok := r.(*reporter); ok {
+		return &jobReporter{
+			reporter: rr,
+			name:     name,
+		}
+	}
+	return r
+}
+
+type jobReporter struct {
+	*reporter
+
+	name string
+}
+
+func (r *jobReporter) PutEvent(event eventlog.Event) {
+	go r.putJobEvent(r.name, event)
+}
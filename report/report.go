@@ -40,4 +40,10 @@ type Report struct {
 	EventStat EventStat `json:"event_stat"`
 	// ApiStat returns the statistical data of api access info
 	ApiStat ApiStat `json:"api_stat"`
+	// Lockouts returns some latest lockout events of the login endpoints
+	Lockouts *toplist.TopList `json:"lockouts"`
+	// LockoutStat returns the statistical data of the lockout events
+	LockoutStat LockoutStat `json:"lockout_stat"`
+	// Jobs returns the status of the named sync jobs
+	Jobs map[string]*JobStat `json:"jobs"`
 }
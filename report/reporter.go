@@ -22,10 +22,20 @@ type Reporter interface {
 	PutConnection(addr string, user *auth.SessionUser)
 	// DeleteConnection delete a closed connection
 	DeleteConnection(addr string)
+	// PutSent put a monitor message that is sent to the client connection
+	PutSent(addr string, seq uint64)
+	// PutAck put an acknowledgement of the monitor messages that are applied by the client connection
+	PutAck(addr string, ack Ack)
 	// PutEvent put a file change event
 	PutEvent(event eventlog.Event)
 	// PutApiStat put an access log of api
 	PutApiStat(ip string)
+	// PutLockout put a lockout event of the login endpoints
+	PutLockout(event auth.LockoutEvent)
+	// PutJob put a named sync job that is running
+	PutJob(name string, source string, dest string)
+	// PutJobStatus update the status of the named sync job, the error is recorded if it is not nil
+	PutJobStatus(name string, status string, err error)
 	// Enable enable or disable the Reporter
 	Enable(enabled bool)
 }
@@ -52,8 +62,11 @@ func NewReporter() Reporter {
 		ApiStat: ApiStat{
 			VisitorStat: make(map[string]uint64),
 		},
+		LockoutStat: make(map[string]uint64),
+		Jobs:        make(map[string]*JobStat),
 	}
 	report.Events, _ = toplist.New(100)
+	report.Lockouts, _ = toplist.New(100)
 	report.Hostname, _ = os.Hostname()
 	return &reporter{
 		report: report,
@@ -65,7 +78,23 @@ func (r *reporter) GetReport() Report {
 	defer r.mu.Unlock()
 	r.report.CurrentTime = timeutil.Now()
 	r.report.UpTime = core.Duration(r.report.CurrentTime.Sub(r.report.StartTime))
-	return r.report
+	report := r.report
+	// the online connection stats are updated in place, so return a copy of them
+	report.Online = make(map[string]*ConnStat, len(r.report.Online))
+	for addr, stat := range r.report.Online {
+		s := *stat
+		report.Online[addr] = &s
+	}
+	report.Jobs = make(map[string]*JobStat, len(r.report.Jobs))
+	for name, stat := range r.report.Jobs {
+		s := *stat
+		s.EventStat = make(EventStat, len(stat.EventStat))
+		for op, count := range stat.EventStat {
+			s.EventStat[op] = count
+		}
+		report.Jobs[name] = &s
+	}
+	return report
 }
 
 func (r *reporter) PutConnection(addr string, user *auth.SessionUser) {
@@ -116,6 +145,38 @@ func (r *reporter) deleteConnection(addr string) {
 	}
 }
 
+func (r *reporter) PutSent(addr string, seq uint64) {
+	go r.putSent(addr, seq)
+}
+
+func (r *reporter) putSent(addr string, seq uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.enabled {
+		return
+	}
+	stat := r.report.Online[r.connAddr(addr)]
+	if stat != nil {
+		stat.Delivery.sent(seq)
+	}
+}
+
+func (r *reporter) PutAck(addr string, ack Ack) {
+	go r.putAck(addr, ack)
+}
+
+func (r *reporter) putAck(addr string, ack Ack) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.enabled {
+		return
+	}
+	stat := r.report.Online[r.connAddr(addr)]
+	if stat != nil {
+		stat.Delivery.ack(ack)
+	}
+}
+
 func (r *reporter) PutEvent(event eventlog.Event) {
 	go r.putEvent(event)
 }
@@ -144,6 +205,69 @@ func (r *reporter) putApiStat(ip string) {
 	r.report.ApiStat.VisitorStat[ip]++
 }
 
+func (r *reporter) PutLockout(event auth.LockoutEvent) {
+	go r.putLockout(event)
+}
+
+func (r *reporter) putLockout(event auth.LockoutEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.enabled {
+		return
+	}
+	r.report.Lockouts.Add(event)
+	r.report.LockoutStat[event.Kind]++
+}
+
+// PutJob put the job synchronously, so the status of the job is always updated after it
+func (r *reporter) PutJob(name string, source string, dest string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.enabled {
+		return
+	}
+	r.report.Jobs[name] = &JobStat{
+		Name:      name,
+		Source:    source,
+		Dest:      dest,
+		Status:    JobStatusRunning,
+		StartTime: timeutil.Now(),
+		EventStat: make(EventStat),
+	}
+}
+
+func (r *reporter) PutJobStatus(name string, status string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.enabled {
+		return
+	}
+	stat := r.report.Jobs[name]
+	if stat == nil {
+		return
+	}
+	stat.Status = status
+	if err != nil {
+		stat.Error = err.Error()
+	}
+	if status != JobStatusRunning {
+		stat.StopTime = timeutil.Now()
+	}
+}
+
+func (r *reporter) putJobEvent(name string, event eventlog.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.enabled {
+		return
+	}
+	r.report.Events.Add(event)
+	r.report.EventStat[event.Op]++
+	if stat := r.report.Jobs[name]; stat != nil {
+		stat.EventStat[event.Op]++
+	}
+}
+
 func (r *reporter) Enable(enabled bool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -1,6 +1,7 @@
 package report
 
 import (
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -148,3 +149,111 @@ func testGetReporterWithEnable(t *testing.T, reporter Reporter, addrOnline, addr
 		t.Errorf("[enabled] test PutApiStat error, expect to get %d access count, actual:%d", expectAccessCount, actualAccessCount)
 	}
 }
+
+func TestReporter_Delivery(t *testing.T) {
+	reporter := NewReporter()
+	reporter.Enable(true)
+	addr := "127.0.0.1:12345"
+	reporter.PutConnection(addr, nil)
+	time.Sleep(time.Millisecond * 100)
+	for seq := uint64(1); seq <= 5; seq++ {
+		reporter.PutSent(addr, seq)
+	}
+	reporter.PutAck(addr, Ack{
+		Seq:          3,
+		Event:        eventlog.NewEvent("/source/hello.txt", "WRITE"),
+		SuccessCount: 2,
+		FailureCount: 1,
+		Error:        "permission denied",
+	})
+	reporter.PutSent("127.0.0.1:54321", 6)
+	time.Sleep(time.Millisecond * 100)
+
+	stat := reporter.GetReport().Online[addr]
+	if stat == nil {
+		t.Fatalf("expect to get the online connection %s, but get nil", addr)
+	}
+	d := stat.Delivery
+	if d.SentCount != 5 || d.SentSeq != 5 {
+		t.Errorf("expect sent count 5 and sent seq 5, but get sent count %d and sent seq %d", d.SentCount, d.SentSeq)
+	}
+	if d.AckSeq != 3 || d.SuccessCount != 2 || d.FailureCount != 1 {
+		t.Errorf("expect ack seq 3, success count 2, failure count 1, but get %d %d %d", d.AckSeq, d.SuccessCount, d.FailureCount)
+	}
+	if d.Lag != 2 {
+		t.Errorf("expect lag 2, but get %d", d.Lag)
+	}
+	if d.LastEvent == nil || d.LastEvent.Name != "/source/hello.txt" {
+		t.Errorf("expect the last event is /source/hello.txt, but get %v", d.LastEvent)
+	}
+	if d.LastError != "permission denied" {
+		t.Errorf("expect the last error is %s, but get %s", "permission denied", d.LastError)
+	}
+}
+
+func TestReporter_Lockout(t *testing.T) {
+	testCases := []struct {
+		name        string
+		enabled     bool
+		expectCount int
+	}{
+		{"enabled", true, 2},
+		{"disabled", false, 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reporter := NewReporter()
+			reporter.Enable(tc.enabled)
+			reporter.PutLockout(auth.LockoutEvent{Kind: auth.LockoutKindIP, Key: "127.0.0.1", Source: "http"})
+			reporter.PutLockout(auth.LockoutEvent{Kind: auth.LockoutKindUser, Key: "gofs", Source: "grpc"})
+			time.Sleep(time.Millisecond * 100)
+
+			r := reporter.GetReport()
+			if r.Lockouts.Len() != tc.expectCount {
+				t.Errorf("expect to get %d lockout events, but get %d", tc.expectCount, r.Lockouts.Len())
+			}
+			actualCount := int(r.LockoutStat[auth.LockoutKindIP] + r.LockoutStat[auth.LockoutKindUser])
+			if actualCount != tc.expectCount {
+				t.Errorf("expect to get %d lockout stat, but get %d", tc.expectCount, actualCount)
+			}
+		})
+	}
+}
+
+func TestReporter_Job(t *testing.T) {
+	reporter := NewReporter()
+	reporter.Enable(true)
+	reporter.PutJob("photos", "./photos", "./backup/photos")
+	reporter.PutJob("docs", "./docs", "./backup/docs")
+	photos := WithJob(reporter, "photos")
+	photos.PutEvent(eventlog.NewEvent("./photos/hello.jpg", "CREATE"))
+	photos.PutEvent(eventlog.NewEvent("./photos/hello.jpg", "WRITE"))
+	reporter.PutEvent(eventlog.NewEvent("./source/hello.txt", "WRITE"))
+	time.Sleep(time.Millisecond * 100)
+	reporter.PutJobStatus("docs", JobStatusFailed, errors.New("permission denied"))
+	reporter.PutJobStatus("unknown", JobStatusStopped, nil)
+
+	r := reporter.GetReport()
+	if len(r.Jobs) != 2 {
+		t.Fatalf("expect to get 2 jobs, but get %d", len(r.Jobs))
+	}
+	stat := r.Jobs["photos"]
+	if stat == nil || stat.Status != JobStatusRunning || stat.Source != "./photos" || stat.Dest != "./backup/photos" {
+		t.Errorf("expect to get the running job photos, but get %v", stat)
+	} else if stat.EventStat["CREATE"] != 1 || stat.EventStat["WRITE"] != 1 {
+		t.Errorf("expect to get the event stat of the job photos, but get %v", stat.EventStat)
+	}
+	if r.Events.Len() != 3 || r.EventStat["WRITE"] != 2 {
+		t.Errorf("expect to get all the events, but get %d events and %v", r.Events.Len(), r.EventStat)
+	}
+	stat = r.Jobs["docs"]
+	if stat == nil || stat.Status != JobStatusFailed || stat.Error != "permission denied" || stat.StopTime.Time().IsZero() {
+		t.Errorf("expect to get the failed job docs, but get %v", stat)
+	}
+
+	// the returned report is a copy of the current report
+	r.Jobs["photos"].EventStat["CREATE"] = 10
+	if reporter.GetReport().Jobs["photos"].EventStat["CREATE"] != 1 {
+		t.Errorf("expect the job stat is not modified by the caller")
+	}
+}
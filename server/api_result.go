@@ -4,9 +4,9 @@ import "github.com/no-src/gofs/contract"
 
 // ApiResult the common result of api response
 type ApiResult struct {
-	Code    contract.Code `json:"code"`
-	Message string        `json:"message"`
-	Data    any           `json:"data"`
+	Code    contract.Code `json:"code" toml:"code"`
+	Message string        `json:"message" toml:"message"`
+	Data    any           `json:"data" toml:"data"`
 }
 
 // NewApiResult create an instance of the ApiResult
@@ -0,0 +1,14 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/no-src/gofs/server"
+)
+
+// jsonWithAuditCode response the api result as json and record the code of it as the audit result code
+func jsonWithAuditCode(c *gin.Context, r server.ApiResult) {
+	c.Set(server.ContextAuditCode, int(r.Code))
+	c.JSON(http.StatusOK, r)
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/contract"
+	"github.com/no-src/gofs/encrypt"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/server"
+)
+
+type decryptHandler struct {
+	logger     *logger.Logger
+	root       http.FileSystem
+	encryptRel string
+	dec        *encrypt.Decrypt
+}
+
+// NewDecryptHandlerFunc returns a gin.HandlerFunc that downloads the decrypted file in the dest path,
+// only the files in the encryptRel can be downloaded, the encryptRel is a slash path that is relative to the root, empty means all the files
+func NewDecryptHandlerFunc(logger *logger.Logger, root http.FileSystem, encryptRel string, dec *encrypt.Decrypt) gin.HandlerFunc {
+	return (&decryptHandler{
+		logger:     logger,
+		root:       root,
+		encryptRel: strings.Trim(encryptRel, "/"),
+		dec:        dec,
+	}).Handle
+}
+
+func (h *decryptHandler) Handle(c *gin.Context) {
+	name := path.Clean("/" + c.Param("path"))
+	if !checkPathPerm(c, server.DestRoutePrefix+strings.TrimPrefix(name, "/"), auth.ReadPerm) {
+		c.JSON(http.StatusOK, server.NewApiResult(contract.NoPermission, contract.NoPermissionDesc, nil))
+		return
+	}
+	if !h.isEncryptPath(name) {
+		c.JSON(http.StatusOK, server.NewErrorApiResult(-501, "the path is not in the encrypt path"))
+		return
+	}
+	f, err := h.root.Open(name)
+	if os.IsNotExist(err) {
+		c.JSON(http.StatusOK, server.NewApiResult(contract.NotFound, contract.NotFoundDesc, nil))
+		return
+	}
+	if err != nil {
+		h.logger.Error(err, "[decrypt] open the file error => %s", name)
+		c.JSON(http.StatusOK, server.NewServerErrorResult())
+		return
+	}
+	defer func() {
+		h.logger.ErrorIf(f.Close(), "[decrypt] close the file error => %s", name)
+	}()
+	stat, err := f.Stat()
+	if err != nil {
+		h.logger.Error(err, "[decrypt] get the file info error => %s", name)
+		c.JSON(http.StatusOK, server.NewServerErrorResult())
+		return
+	}
+	if stat.IsDir() {
+		c.JSON(http.StatusOK, server.NewErrorApiResult(-502, "the directory can't be decrypted"))
+		return
+	}
+	r, err := h.dec.NewReader(f, stat.Size())
+	if err != nil {
+		h.logger.Error(err, "[decrypt] create the decrypt reader error => %s", name)
+		c.JSON(http.StatusOK, server.NewErrorApiResult(-503, "decrypt the file failed"))
+		return
+	}
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": h.dec.DecryptName(stat.Name())}))
+	c.Status(http.StatusOK)
+	// the response is already started, so the decryption error can only be logged here
+	_, err = io.Copy(c.Writer, r)
+	h.logger.ErrorIf(err, "[decrypt] write the decrypted file error => %s", name)
+}
+
+// isEncryptPath check the path is in the encrypt path or not
+func (h *decryptHandler) isEncryptPath(name string) bool {
+	if len(h.encryptRel) == 0 {
+		return true
+	}
+	name = strings.TrimPrefix(name, "/")
+	return name == h.encryptRel || strings.HasPrefix(name, h.encryptRel+"/")
+}
@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/conf"
+	"github.com/no-src/gofs/contract"
+	"github.com/no-src/gofs/encrypt"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/server"
+)
+
+const testDecryptSecret = "encrypt_secure_encrypt_secure_01"
+
+func TestDecryptHandler(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	data := []byte("hello gofs")
+	source := t.TempDir()
+	dest := t.TempDir()
+	writeTestFile(t, filepath.Join(source, "secret", "hello.txt"), data)
+	writeTestFile(t, filepath.Join(dest, "plain.txt"), data)
+	writeTestFile(t, filepath.Join(dest, "secret", "hello.txt"), encryptTestData(t, logger, filepath.Join(source, "secret"), data))
+
+	dec, err := encrypt.NewDecrypt(encrypt.NewOption(conf.Config{
+		Decrypt:       true,
+		DecryptPath:   filepath.Join(dest, "secret"),
+		DecryptSecret: testDecryptSecret,
+	}, logger))
+	if err != nil {
+		t.Fatalf("init decrypt component error => %v", err)
+	}
+
+	testCases := []struct {
+		name       string
+		path       string
+		paths      []auth.PathPerm
+		expectCode contract.Code
+	}{
+		{"decrypt the file", "/secret/hello.txt", nil, contract.Success},
+		{"decrypt the file with path permission", "/secret/hello.txt", []auth.PathPerm{{Path: "/dest/secret", Perm: auth.ReadPerm}}, contract.Success},
+		{"no path permission", "/secret/hello.txt", []auth.PathPerm{{Path: "/dest/other", Perm: auth.ReadPerm}}, contract.NoPermission},
+		{"not in the encrypt path", "/plain.txt", nil, -501},
+		{"escape the encrypt path", "/secret/../plain.txt", nil, -501},
+		{"directory", "/secret", nil, -502},
+		{"not found", "/secret/not_found.txt", nil, contract.NotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.Use(func(c *gin.Context) {
+				c.Set(server.ContextUser, &auth.SessionUser{UserName: "gofs", Perm: auth.FullPerm, Paths: tc.paths})
+			})
+			engine.GET(server.DecryptGroupRoute+server.DecryptRoute, NewDecryptHandlerFunc(logger, http.Dir(dest), "secret", dec))
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, server.DecryptGroupRoute+tc.path, nil))
+
+			if tc.expectCode == contract.Success {
+				if !bytes.Equal(data, w.Body.Bytes()) {
+					t.Errorf("expect get the decrypted data %q but get %q", data, w.Body.String())
+				}
+				return
+			}
+			var result server.ApiResult
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Fatalf("parse the api result error => %v", err)
+			}
+			if result.Code != tc.expectCode {
+				t.Errorf("expect get code %d but get %d", tc.expectCode, result.Code)
+			}
+		})
+	}
+}
+
+func encryptTestData(t *testing.T, logger *logger.Logger, encryptPath string, data []byte) []byte {
+	enc, err := encrypt.NewEncrypt(encrypt.NewOption(conf.Config{
+		Encrypt:       true,
+		EncryptPath:   encryptPath,
+		EncryptSecret: testDecryptSecret,
+	}, logger), encryptPath)
+	if err != nil {
+		t.Fatalf("init encrypt component error => %v", err)
+	}
+	buf := bytes.NewBuffer(nil)
+	w, err := enc.NewWriter(buf, filepath.Join(encryptPath, "hello.txt"), "hello.txt")
+	if err != nil {
+		t.Fatalf("init encrypt writer error => %v", err)
+	}
+	w.Write(data)
+	if err = w.Close(); err != nil {
+		t.Fatalf("close encrypt writer error => %v", err)
+	}
+	return buf.Bytes()
+}
+
+func writeTestFile(t *testing.T, name string, data []byte) {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		t.Fatalf("create the directory error => %v", err)
+	}
+	if err := os.WriteFile(name, data, 0644); err != nil {
+		t.Fatalf("write the file error => %v", err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/no-src/gofs/auth"
 	"github.com/no-src/gofs/contract"
 	"github.com/no-src/gofs/logger"
 	"github.com/no-src/gofs/server"
@@ -62,6 +63,11 @@ func (h *fileApiHandler) Handle(c *gin.Context) {
 		return
 	}
 
+	if !checkPathPerm(c, path, auth.ReadPerm) {
+		c.JSON(http.StatusOK, server.NewApiResult(contract.NoPermission, contract.NoPermissionDesc, nil))
+		return
+	}
+
 	path = strings.TrimLeft(path, sourcePrefix)
 
 	f, err := h.root.Open(path)
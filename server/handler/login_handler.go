@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
@@ -14,15 +15,17 @@ import (
 )
 
 type loginHandler struct {
-	users  []*auth.User
-	logger *logger.Logger
+	store   auth.UserStore
+	limiter auth.LoginLimiter
+	logger  *logger.Logger
 }
 
-// NewLoginHandlerFunc returns a gin.HandlerFunc that providers a login api
-func NewLoginHandlerFunc(users []*auth.User, logger *logger.Logger) gin.HandlerFunc {
+// NewLoginHandlerFunc returns a gin.HandlerFunc that providers a login api, the failed login attempts are limited by the LoginLimiter
+func NewLoginHandlerFunc(store auth.UserStore, limiter auth.LoginLimiter, logger *logger.Logger) gin.HandlerFunc {
 	return (&loginHandler{
-		users:  users,
-		logger: logger,
+		store:   store,
+		limiter: limiter,
+		logger:  logger,
 	}).Handle
 }
 
@@ -46,11 +49,16 @@ func (h *loginHandler) Handle(c *gin.Context) {
 		}
 	}
 
+	ip := c.ClientIP()
+	if lockout := h.limiter.Check(ip, userName); lockout > 0 {
+		h.logger.Warn("login is locked out, username=%s remote=%s", userName, c.Request.RemoteAddr)
+		c.String(http.StatusTooManyRequests, "too many failed login attempts, retry after %s", lockout.Round(time.Second))
+		return
+	}
+
 	var loginUser *auth.SessionUser
-	for _, user := range h.users {
-		if user.UserName() == userName && user.Password() == password {
-			loginUser = auth.MapperToSessionUser(user)
-		}
+	if user := h.store.Login(userName, password); user != nil {
+		loginUser = auth.MapperToSessionUser(user)
 	}
 	if loginUser != nil {
 		session := sessions.Default(c)
@@ -66,9 +74,11 @@ func (h *loginHandler) Handle(c *gin.Context) {
 			c.String(http.StatusInternalServerError, "save session error")
 			return
 		}
+		h.limiter.Success(ip, userName)
 		h.logger.Info("login success, userid=%d username=%s remote=%s", loginUser.UserId, loginUser.UserName, c.Request.RemoteAddr)
 		c.Redirect(http.StatusFound, returnUrl)
 	} else {
+		h.limiter.Fail(ip, userName)
 		h.logger.Info("login failed, username=%s remote=%s", userName, c.Request.RemoteAddr)
 		c.Redirect(http.StatusFound, server.LoginIndexFullRoute)
 	}
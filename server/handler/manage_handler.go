@@ -40,9 +40,22 @@ func (h *manageHandler) Handle(c *gin.Context) {
 	if len(config.DecryptSecret) > 0 {
 		config.DecryptSecret = mask
 	}
+	if len(config.Jobs) > 0 {
+		// the jobs are shared with the original config, so copy them before masking
+		jobs := make([]conf.SyncJob, len(config.Jobs))
+		for i, job := range config.Jobs {
+			if len(job.EncryptSecret) > 0 {
+				job.EncryptSecret = mask
+			}
+			jobs[i] = job
+		}
+		config.Jobs = jobs
+	}
 	result := server.NewApiResult(contract.Success, contract.SuccessDesc, config)
 	if format == conf.YamlFormat.Name() {
 		c.YAML(http.StatusOK, result)
+	} else if format == conf.TomlFormat.Name() {
+		c.TOML(http.StatusOK, result)
 	} else {
 		c.PureJSON(http.StatusOK, result)
 	}
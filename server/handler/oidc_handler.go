@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/server"
+	"golang.org/x/oauth2"
+)
+
+// OIDCOption the option of the OpenID Connect login
+type OIDCOption struct {
+	// Issuer the issuer url of the OpenID Connect provider
+	Issuer string
+	// ClientID the client id that is registered in the OpenID Connect provider
+	ClientID string
+	// ClientSecret the client secret that is registered in the OpenID Connect provider
+	ClientSecret string
+	// RedirectURL the callback url of the file server, generate it by the request if it is empty
+	RedirectURL string
+	// Scopes the requested scopes, the openid scope is always requested
+	Scopes []string
+	// UserClaim the claim of the ID token that is used as the username, use the subject if the claim is not found
+	UserClaim string
+	// GroupsClaim the claim of the ID token that contains the groups of the user
+	GroupsClaim string
+	// GroupPerms the permissions of the groups
+	GroupPerms map[string]auth.Perm
+	// DefaultPerm the permission of the user that has no matched group, the user can't log in if it is empty
+	DefaultPerm auth.Perm
+}
+
+type oidcHandler struct {
+	opt      OIDCOption
+	config   oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	logger   *logger.Logger
+}
+
+// NewOIDCHandlerFuncs returns the gin.HandlerFunc of the OpenID Connect login api and callback api,
+// the login api redirects to the provider with the authorization code flow,
+// and the callback api maps the claims of the ID token to the session user
+func NewOIDCHandlerFuncs(ctx context.Context, opt OIDCOption, logger *logger.Logger) (login gin.HandlerFunc, callback gin.HandlerFunc, err error) {
+	provider, err := oidc.NewProvider(ctx, opt.Issuer)
+	if err != nil {
+		return nil, nil, err
+	}
+	scopes := []string{oidc.ScopeOpenID}
+	for _, scope := range opt.Scopes {
+		if scope != oidc.ScopeOpenID && len(scope) > 0 {
+			scopes = append(scopes, scope)
+		}
+	}
+	h := &oidcHandler{
+		opt: opt,
+		config: oauth2.Config{
+			ClientID:     opt.ClientID,
+			ClientSecret: opt.ClientSecret,
+			Endpoint:     provider.Endpoint(),
+			RedirectURL:  opt.RedirectURL,
+			Scopes:       scopes,
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: opt.ClientID}),
+		logger:   logger,
+	}
+	return h.Login, h.Callback, nil
+}
+
+// Login redirect to the OpenID Connect provider
+func (h *oidcHandler) Login(c *gin.Context) {
+	session := sessions.Default(c)
+	state, err := randomString()
+	if err != nil {
+		h.fail(c, err, "generate the oidc state error")
+		return
+	}
+	nonce, err := randomString()
+	if err != nil {
+		h.fail(c, err, "generate the oidc nonce error")
+		return
+	}
+	returnUrl := safeReturnUrl(c.Query(server.ParamReturnUrl))
+	session.Set(server.SessionOIDCState, state)
+	session.Set(server.SessionOIDCNonce, nonce)
+	session.Set(server.SessionOIDCReturnUrl, returnUrl)
+	if err = session.Save(); err != nil {
+		h.fail(c, err, "save session error")
+		return
+	}
+	c.Redirect(http.StatusFound, h.oauth2Config(c).AuthCodeURL(state, oidc.Nonce(nonce)))
+}
+
+// Callback verify the ID token that is exchanged by the authorization code, and save the session user
+func (h *oidcHandler) Callback(c *gin.Context) {
+	session := sessions.Default(c)
+	state, _ := session.Get(server.SessionOIDCState).(string)
+	nonce, _ := session.Get(server.SessionOIDCNonce).(string)
+	returnUrl, _ := session.Get(server.SessionOIDCReturnUrl).(string)
+	session.Delete(server.SessionOIDCState)
+	session.Delete(server.SessionOIDCNonce)
+	session.Delete(server.SessionOIDCReturnUrl)
+
+	if len(state) == 0 || c.Query("state") != state {
+		h.fail(c, errors.New("the oidc state is not matched"), "oidc login failed")
+		return
+	}
+	if errMsg := c.Query("error"); len(errMsg) > 0 {
+		h.fail(c, fmt.Errorf("%s => %s", errMsg, c.Query("error_description")), "oidc login failed")
+		return
+	}
+	ctx := c.Request.Context()
+	token, err := h.oauth2Config(c).Exchange(ctx, c.Query("code"))
+	if err != nil {
+		h.fail(c, err, "exchange the oidc authorization code error")
+		return
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		h.fail(c, errors.New("the id_token is not found"), "oidc login failed")
+		return
+	}
+	idToken, err := h.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		h.fail(c, err, "verify the oidc id token error")
+		return
+	}
+	if idToken.Nonce != nonce {
+		h.fail(c, errors.New("the oidc nonce is not matched"), "oidc login failed")
+		return
+	}
+	var claims map[string]any
+	if err = idToken.Claims(&claims); err != nil {
+		h.fail(c, err, "parse the oidc claims error")
+		return
+	}
+	userName, _ := claims[h.opt.UserClaim].(string)
+	if len(userName) == 0 {
+		userName = idToken.Subject
+	}
+	perm := auth.MapGroupsToPerm(toGroups(claims[h.opt.GroupsClaim]), h.opt.GroupPerms, h.opt.DefaultPerm)
+	if !perm.IsValid() {
+		h.fail(c, fmt.Errorf("the oidc user [%s] has no permission", userName), "oidc login failed")
+		return
+	}
+
+	session.Set(server.SessionUser, auth.SessionUser{
+		UserName: userName,
+		Perm:     perm,
+		External: true,
+	})
+	if err = session.Save(); err != nil {
+		h.fail(c, err, "save session error")
+		return
+	}
+	h.logger.Info("oidc login success, username=%s perm=%s remote=%s", userName, perm, c.Request.RemoteAddr)
+	if len(returnUrl) == 0 {
+		returnUrl = "/"
+	}
+	c.Redirect(http.StatusFound, returnUrl)
+}
+
+// oauth2Config return the oauth2.Config with the redirect url that is generated by the request if the RedirectURL is empty
+func (h *oidcHandler) oauth2Config(c *gin.Context) *oauth2.Config {
+	config := h.config
+	if len(config.RedirectURL) == 0 {
+		scheme := server.SchemeHttp
+		if c.Request.TLS != nil {
+			scheme = server.SchemeHttps
+		}
+		config.RedirectURL = fmt.Sprintf("%s://%s%s", scheme, c.Request.Host, server.LoginOIDCCallbackFullRoute)
+	}
+	return &config
+}
+
+func (h *oidcHandler) fail(c *gin.Context, err error, msg string) {
+	h.logger.Error(err, "%s, remote=%s", msg, c.Request.RemoteAddr)
+	if saveErr := sessions.Default(c).Save(); saveErr != nil {
+		h.logger.Error(saveErr, "save session error, remote=%s", c.Request.RemoteAddr)
+	}
+	c.Redirect(http.StatusFound, server.LoginIndexFullRoute)
+}
+
+// safeReturnUrl return the return url if it is a relative path of the file server, otherwise return the root path,
+// avoid redirecting to other sites after login
+func safeReturnUrl(returnUrl string) string {
+	if !strings.HasPrefix(returnUrl, "/") || strings.HasPrefix(returnUrl, "//") || strings.HasPrefix(returnUrl, "/\\") {
+		return "/"
+	}
+	u, err := url.Parse(returnUrl)
+	if err != nil || len(u.Scheme) > 0 || len(u.Host) > 0 {
+		return "/"
+	}
+	return returnUrl
+}
+
+// toGroups convert the groups claim to a string slice, the claim is a string array or a string
+func toGroups(claim any) (groups []string) {
+	switch v := claim.(type) {
+	case string:
+		groups = append(groups, v)
+	case []any:
+		for _, g := range v {
+			if s, ok := g.(string); ok {
+				groups = append(groups, s)
+			}
+		}
+	}
+	return groups
+}
+
+func randomString() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
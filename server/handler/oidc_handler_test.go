@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/server"
+	"github.com/no-src/gofs/server/middleware"
+)
+
+const (
+	testOIDCClientID     = "gofs-client"
+	testOIDCClientSecret = "gofs-secret"
+	testOIDCKeyId        = "gofs-key"
+)
+
+func TestOIDCHandler(t *testing.T) {
+	testCases := []struct {
+		name        string
+		groups      []string
+		defaultPerm auth.Perm
+		badState    bool
+		expectLogin bool
+	}{
+		{"mapped group", []string{"dev"}, "", false, true},
+		{"default perm", []string{"unknown"}, auth.ReadPerm, false, true},
+		{"no permission", []string{"unknown"}, "", false, false},
+		{"state not matched", []string{"dev"}, "", true, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider := newMockOIDCProvider(t, "alice", tc.groups)
+			defer provider.Close()
+
+			login, callback, err := NewOIDCHandlerFuncs(context.Background(), OIDCOption{
+				Issuer:       provider.URL,
+				ClientID:     testOIDCClientID,
+				ClientSecret: testOIDCClientSecret,
+				Scopes:       []string{"profile"},
+				UserClaim:    "preferred_username",
+				GroupsClaim:  "groups",
+				GroupPerms:   map[string]auth.Perm{"dev": "rw"},
+				DefaultPerm:  tc.defaultPerm,
+			}, logger.NewTestLogger())
+			if err != nil {
+				t.Fatalf("create oidc handler error => %v", err)
+			}
+
+			store, err := server.NewSessionStore("memory:")
+			if err != nil {
+				t.Fatalf("create session store error => %v", err)
+			}
+			engine := gin.New()
+			engine.Use(sessions.Sessions(server.SessionName, store))
+			loginGroup := engine.Group(server.LoginGroupRoute)
+			loginGroup.GET(server.LoginIndexRoute, func(c *gin.Context) {
+				c.String(http.StatusOK, "login page")
+			})
+			loginGroup.GET(server.LoginOIDCRoute, login)
+			loginGroup.GET(server.LoginOIDCCallbackRoute, callback)
+			rootGroup := engine.Group(server.RootGroupRoute)
+			rootGroup.Use(middleware.NewAuthHandlerFunc(logger.NewTestLogger(), auth.ReadPerm, nil, nil))
+			rootGroup.GET(server.SourceRoutePrefix+"hello", func(c *gin.Context) {
+				c.String(http.StatusOK, "hello")
+			})
+			fileServer := httptest.NewServer(engine)
+			defer fileServer.Close()
+			provider.badState = tc.badState
+
+			jar, _ := cookiejar.New(nil)
+			client := &http.Client{Jar: jar}
+			resp, err := client.Get(fileServer.URL + server.LoginOIDCFullRoute + "?" + server.ParamReturnUrl + "=" + url.QueryEscape(server.SourceRoutePrefix+"hello"))
+			if err != nil {
+				t.Fatalf("oidc login error => %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			expect := "login page"
+			if tc.expectLogin {
+				expect = "hello"
+			}
+			if string(body) != expect {
+				t.Errorf("expect get the response [%s], but get [%s]", expect, string(body))
+			}
+		})
+	}
+}
+
+func TestNewOIDCHandlerFuncs_ReturnError(t *testing.T) {
+	_, _, err := NewOIDCHandlerFuncs(context.Background(), OIDCOption{Issuer: "http://127.0.0.1:0"}, logger.NewTestLogger())
+	if err == nil {
+		t.Errorf("create oidc handler with an invalid issuer expect get an error, but get nil")
+	}
+}
+
+func TestToGroups(t *testing.T) {
+	testCases := []struct {
+		name   string
+		claim  any
+		expect []string
+	}{
+		{"nil", nil, nil},
+		{"string", "dev", []string{"dev"}},
+		{"array", []any{"dev", 1, "admin"}, []string{"dev", "admin"}},
+		{"unsupported", 1, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := toGroups(tc.claim)
+			if strings.Join(actual, ",") != strings.Join(tc.expect, ",") {
+				t.Errorf("expect groups %v, but get %v", tc.expect, actual)
+			}
+		})
+	}
+}
+
+// mockOIDCProvider a local OpenID Connect provider that issues the ID token of the specified user
+type mockOIDCProvider struct {
+	*httptest.Server
+	t        *testing.T
+	key      *rsa.PrivateKey
+	userName string
+	groups   []string
+	nonce    string
+	badState bool
+}
+
+func newMockOIDCProvider(t *testing.T, userName string, groups []string) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key error => %v", err)
+	}
+	p := &mockOIDCProvider{
+		t:        t,
+		key:      key,
+		userName: userName,
+		groups:   groups,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/keys", p.keys)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	p.Server = httptest.NewServer(mux)
+	return p
+}
+
+func (p *mockOIDCProvider) discovery(w http.ResponseWriter, _ *http.Request) {
+	p.writeJSON(w, map[string]any{
+		"issuer":                                p.URL,
+		"authorization_endpoint":                p.URL + "/authorize",
+		"token_endpoint":                        p.URL + "/token",
+		"jwks_uri":                              p.URL + "/keys",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (p *mockOIDCProvider) keys(w http.ResponseWriter, _ *http.Request) {
+	p.writeJSON(w, map[string]any{
+		"keys": []map[string]any{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": testOIDCKeyId,
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *mockOIDCProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != testOIDCClientID || !strings.Contains(q.Get("scope"), "openid") {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+	p.nonce = q.Get("nonce")
+	state := q.Get("state")
+	if p.badState {
+		state += "x"
+	}
+	redirect := q.Get("redirect_uri") + "?code=gofs-code&state=" + url.QueryEscape(state)
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+func (p *mockOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if clientID != testOIDCClientID || clientSecret != testOIDCClientSecret || r.PostFormValue("code") != "gofs-code" {
+		http.Error(w, "invalid client", http.StatusUnauthorized)
+		return
+	}
+	now := time.Now()
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                p.URL,
+		"aud":                testOIDCClientID,
+		"sub":                "alice-id",
+		"iat":                now.Unix(),
+		"exp":                now.Add(time.Minute).Unix(),
+		"nonce":              p.nonce,
+		"preferred_username": p.userName,
+		"groups":             p.groups,
+	})
+	idToken.Header["kid"] = testOIDCKeyId
+	rawIDToken, err := idToken.SignedString(p.key)
+	if err != nil {
+		p.t.Errorf("sign the id token error => %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	p.writeJSON(w, map[string]any{
+		"access_token": "gofs-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     rawIDToken,
+	})
+}
+
+func (p *mockOIDCProvider) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		p.t.Errorf("write json error => %v", err)
+	}
+}
+
+func TestSafeReturnUrl(t *testing.T) {
+	testCases := []struct {
+		name      string
+		returnUrl string
+		expect    string
+	}{
+		{"empty", "", "/"},
+		{"root", "/", "/"},
+		{"relative path", "/source/hello?mode=1", "/source/hello?mode=1"},
+		{"no leading slash", "source/hello", "/"},
+		{"absolute url", "https://example.com/source", "/"},
+		{"scheme relative url", "//example.com/source", "/"},
+		{"backslash", "/\\example.com/source", "/"},
+		{"javascript", "javascript:alert(1)", "/"},
+		{"invalid url", "/%zz", "/"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := safeReturnUrl(tc.returnUrl); actual != tc.expect {
+				t.Errorf("expect the return url [%s], but get [%s]", tc.expect, actual)
+			}
+		})
+	}
+}
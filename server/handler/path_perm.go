@@ -0,0 +1,18 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/server"
+)
+
+// checkPathPerm check the login user in the context has the permission of the path or not,
+// return true if there is no login user in the context, it means the server allows anonymous access
+func checkPathPerm(c *gin.Context, path string, perm auth.Perm) bool {
+	v, ok := c.Get(server.ContextUser)
+	if !ok {
+		return true
+	}
+	user, ok := v.(*auth.SessionUser)
+	return !ok || user.CheckPath(path, perm)
+}
@@ -5,14 +5,15 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
-	"mime/multipart"
-	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/no-src/gofs/action"
+	"github.com/no-src/gofs/audit"
+	"github.com/no-src/gofs/auth"
 	"github.com/no-src/gofs/contract"
 	"github.com/no-src/gofs/contract/push"
 	"github.com/no-src/gofs/core"
@@ -31,21 +32,36 @@ type pushHandler struct {
 	hash                  hashutil.Hash
 }
 
+// PushProcessor apply the push data to the storage path
+type PushProcessor interface {
+	// Process apply the push data to the storage path, the data is the content of the file chunk for the write action
+	Process(pushData push.PushData, data io.Reader) (code contract.Code, hv *hashutil.HashValue, err error)
+}
+
 // NewPushHandlerFunc returns a gin.HandlerFunc that to manage the files
 func NewPushHandlerFunc(logger *logger.Logger, source core.VFS, enableLogicallyDelete bool, hash hashutil.Hash) gin.HandlerFunc {
-	return (&pushHandler{
+	return newPushHandler(logger, source.Path().Base(), enableLogicallyDelete, hash).Handle
+}
+
+// NewPushProcessor returns a PushProcessor that apply the push data to the storage path
+func NewPushProcessor(logger *logger.Logger, storagePath string, enableLogicallyDelete bool, hash hashutil.Hash) PushProcessor {
+	return newPushHandler(logger, storagePath, enableLogicallyDelete, hash)
+}
+
+func newPushHandler(logger *logger.Logger, storagePath string, enableLogicallyDelete bool, hash hashutil.Hash) *pushHandler {
+	return &pushHandler{
 		logger:                logger,
-		storagePath:           source.Path().Base(),
+		storagePath:           storagePath,
 		enableLogicallyDelete: enableLogicallyDelete,
 		hash:                  hash,
-	}).Handle
+	}
 }
 
 func (h *pushHandler) Handle(c *gin.Context) {
 	defer func() {
 		e := recover()
 		if e != nil {
-			c.JSON(http.StatusOK, server.NewServerErrorResult())
+			jsonWithAuditCode(c, server.NewServerErrorResult())
 		}
 	}()
 
@@ -54,15 +70,22 @@ func (h *pushHandler) Handle(c *gin.Context) {
 	err := jsonutil.Unmarshal([]byte(pushDataStr), &pushData)
 	if err != nil {
 		msg := "unmarshal push data error"
-		c.JSON(http.StatusOK, server.NewErrorApiResult(-501, msg))
+		jsonWithAuditCode(c, server.NewErrorApiResult(-501, msg))
 		h.logger.Error(err, "%s => %s", msg, pushDataStr)
 		return
 	}
 
 	h.logger.Debug("receive action %s => %s", pushData.Action.String(), pushDataStr)
+	c.Set(server.ContextAuditOp, audit.PushOp(pushData.Action, pushData.PushAction))
+	c.Set(server.ContextAuditPath, server.SourceRoutePrefix+strings.TrimPrefix(pushData.FileInfo.Path, "/"))
+	c.Set(server.ContextAuditBytes, pushData.Chunk.Size)
 
 	if pushData.Action.Valid() == action.UnknownAction {
-		c.JSON(http.StatusOK, server.NewErrorApiResult(-502, fmt.Sprintf("unknown action => %d", pushData.Action.Int())))
+		jsonWithAuditCode(c, server.NewErrorApiResult(-502, fmt.Sprintf("unknown action => %d", pushData.Action.Int())))
+		return
+	}
+	if !checkPathPerm(c, server.SourceRoutePrefix+pushData.FileInfo.Path, auth.WritePerm) {
+		jsonWithAuditCode(c, server.NewApiResult(contract.NoPermission, contract.NoPermissionDesc, nil))
 		return
 	}
 	fi := pushData.FileInfo
@@ -79,17 +102,46 @@ func (h *pushHandler) Handle(c *gin.Context) {
 		err = h.chmod(fi)
 	case action.WriteAction:
 		r, _ := h.write(pushData, c)
-		c.JSON(http.StatusOK, r)
+		jsonWithAuditCode(c, r)
 		return
 	default:
 		err = fmt.Errorf("unsupported action => [%d:%s]", pushData.Action.Int(), pushData.Action.String())
 	}
 	if err != nil {
 		h.logger.Error(err, "process action error %s => %s", pushData.Action.String(), fi.Path)
-		c.JSON(http.StatusOK, server.NewErrorApiResult(-503, fmt.Sprintf("process action error => %s", err.Error())))
+		jsonWithAuditCode(c, server.NewErrorApiResult(-503, fmt.Sprintf("process action error => %s", err.Error())))
 	} else {
-		c.JSON(http.StatusOK, server.NewApiResult(contract.Success, contract.SuccessDesc, nil))
+		jsonWithAuditCode(c, server.NewApiResult(contract.Success, contract.SuccessDesc, nil))
+	}
+}
+
+func (h *pushHandler) Process(pushData push.PushData, data io.Reader) (code contract.Code, hv *hashutil.HashValue, err error) {
+	h.logger.Debug("process action %s => %s", pushData.Action.String(), pushData.FileInfo.Path)
+	if pushData.Action.Valid() == action.UnknownAction {
+		return contract.Unknown, nil, fmt.Errorf("unknown action => %d", pushData.Action.Int())
+	}
+	fi := pushData.FileInfo
+	switch pushData.Action {
+	case action.CreateAction:
+		err = h.create(fi)
+	case action.SymlinkAction:
+		err = h.symlink(fi)
+	case action.RemoveAction:
+		err = h.remove(fi)
+	case action.RenameAction:
+		err = h.rename(fi)
+	case action.ChmodAction:
+		err = h.chmod(fi)
+	case action.WriteAction:
+		return h.writeData(pushData, data)
+	default:
+		err = fmt.Errorf("unsupported action => [%d:%s]", pushData.Action.Int(), pushData.Action.String())
+	}
+	if err != nil {
+		h.logger.Error(err, "process action error %s => %s", pushData.Action.String(), fi.Path)
+		return contract.Unknown, nil, err
 	}
+	return contract.Success, nil, nil
 }
 
 func (h *pushHandler) buildAbsPath(path string) string {
@@ -190,8 +242,15 @@ func (h *pushHandler) write(pushData push.PushData, c *gin.Context) (server.ApiR
 		h.logger.Error(err, msg)
 		return server.NewErrorApiResult(-505, msg), err
 	}
+	src, err := fh.Open()
+	if err != nil {
+		msg := "open upload file error"
+		h.logger.Error(err, msg)
+		return server.NewErrorApiResult(-505, msg), err
+	}
+	defer src.Close()
 
-	code, hv, err := h.Save(fh, path, pushData)
+	code, hv, err := h.Save(src, path, pushData)
 	if err != nil {
 		h.logger.Error(err, fmt.Sprintf("save upload file error => [%s]", path))
 		return server.NewErrorApiResult(-506, fmt.Sprintf("save upload file error => [%s]", fi.Path)), err
@@ -209,21 +268,38 @@ func (h *pushHandler) write(pushData push.PushData, c *gin.Context) (server.ApiR
 	return server.NewApiResult(contract.Success, contract.SuccessDesc, nil), nil
 }
 
+// writeData write the data to the file or compare the file, it is the same as the write function without the gin.Context
+func (h *pushHandler) writeData(pushData push.PushData, data io.Reader) (contract.Code, *hashutil.HashValue, error) {
+	fi := pushData.FileInfo
+	if fi.IsDir.Bool() {
+		return contract.Unknown, nil, errors.New("can't write a directory")
+	}
+	path := h.buildAbsPath(fi.Path)
+	code, hv, err := h.Save(data, path, pushData)
+	if err != nil {
+		h.logger.Error(err, fmt.Sprintf("save push file error => [%s]", path))
+		return code, nil, err
+	} else if code != contract.Unknown {
+		h.logger.Debug("push a file that is %s => %s", code.String(), fi.Path)
+		return code, hv, nil
+	}
+	if err = h.chtimes(path, fi); err != nil {
+		h.logger.Error(err, "change file times error after write file => [%s]", path)
+		return code, nil, err
+	}
+	return contract.Success, nil, nil
+}
+
 func (h *pushHandler) chtimes(absPath string, fi contract.FileInfo) error {
 	return os.Chtimes(absPath, time.Unix(fi.ATime, 0), time.Unix(fi.MTime, 0))
 }
 
-func (h *pushHandler) Save(file *multipart.FileHeader, dst string, pushData push.PushData) (code contract.Code, hv *hashutil.HashValue, err error) {
+func (h *pushHandler) Save(src io.Reader, dst string, pushData push.PushData) (code contract.Code, hv *hashutil.HashValue, err error) {
 	offset := pushData.Chunk.Offset
 	if pushData.PushAction < push.WritePushAction {
 		code, hv = h.compare(dst, pushData)
 		return code, hv, nil
 	}
-	src, err := file.Open()
-	if err != nil {
-		return code, nil, err
-	}
-	defer src.Close()
 
 	var out *os.File
 	if offset > 0 {
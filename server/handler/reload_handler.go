@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/no-src/gofs/contract"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/reload"
+	"github.com/no-src/gofs/server"
+)
+
+type reloadHandler struct {
+	logger   *logger.Logger
+	reloader reload.Reloader
+}
+
+// NewReloadHandlerFunc returns a gin.HandlerFunc that re-parses the config file and applies the changes that can be applied live
+func NewReloadHandlerFunc(logger *logger.Logger, reloader reload.Reloader) gin.HandlerFunc {
+	return (&reloadHandler{
+		logger:   logger,
+		reloader: reloader,
+	}).Handle
+}
+
+func (h *reloadHandler) Handle(c *gin.Context) {
+	r, err := h.reloader.Reload()
+	if err != nil {
+		h.logger.Error(err, "reload the config error")
+		jsonWithAuditCode(c, server.NewErrorApiResult(-501, err.Error()))
+		return
+	}
+	jsonWithAuditCode(c, server.NewApiResult(contract.Success, contract.SuccessDesc, r))
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/no-src/gofs/contract"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/reload"
+	"github.com/no-src/gofs/server"
+)
+
+func TestReloadHandler(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	testCases := []struct {
+		name       string
+		reloader   reload.Reloader
+		expectCode contract.Code
+		expectData any
+	}{
+		{"reload success", &reloaderMock{result: reload.Result{Applied: []string{"log_level"}, RequireRestart: []string{"dest"}}}, contract.Success, map[string]any{"applied": []any{"log_level"}, "require_restart": []any{"dest"}}},
+		{"reload error", &reloaderMock{err: errors.New("reload error mock")}, -501, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.POST(server.ManageGroupRoute+server.ManageReloadRoute, NewReloadHandlerFunc(logger, tc.reloader))
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodPost, server.ManageGroupRoute+server.ManageReloadRoute, nil))
+
+			var result server.ApiResult
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Fatalf("parse the api result error => %v", err)
+			}
+			if result.Code != tc.expectCode {
+				t.Errorf("expect get code %d but get %d", tc.expectCode, result.Code)
+			}
+			if !reflect.DeepEqual(tc.expectData, result.Data) {
+				t.Errorf("expect get data %v but get %v", tc.expectData, result.Data)
+			}
+		})
+	}
+}
+
+type reloaderMock struct {
+	result reload.Result
+	err    error
+}
+
+func (r *reloaderMock) Reload() (reload.Result, error) {
+	return r.result, r.err
+}
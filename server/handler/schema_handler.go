@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/no-src/gofs/flag"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/server"
+)
+
+// schemaContentType the media type of the JSON Schema
+const schemaContentType = "application/schema+json"
+
+type schemaHandler struct {
+	logger *logger.Logger
+}
+
+// NewSchemaHandlerFunc returns a gin.HandlerFunc that shows the JSON Schema of the config file,
+// the schema is returned as it is rather than the ApiResult, so the editors can use it directly
+func NewSchemaHandlerFunc(logger *logger.Logger) gin.HandlerFunc {
+	return (&schemaHandler{
+		logger: logger,
+	}).Handle
+}
+
+func (h *schemaHandler) Handle(c *gin.Context) {
+	data, err := flag.JSONSchema()
+	if err != nil {
+		h.logger.Error(err, "generate the JSON Schema of the config error")
+		jsonWithAuditCode(c, server.NewErrorApiResult(-501, err.Error()))
+		return
+	}
+	c.Data(http.StatusOK, schemaContentType, data)
+}
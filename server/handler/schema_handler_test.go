@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/no-src/gofs/flag"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/server"
+)
+
+func TestSchemaHandler(t *testing.T) {
+	logger := logger.NewTestLogger()
+	defer logger.Close()
+
+	engine := gin.New()
+	engine.GET(server.ManageGroupRoute+server.ManageSchemaRoute, NewSchemaHandlerFunc(logger))
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, server.ManageGroupRoute+server.ManageSchemaRoute, nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expect get status code %d but get %d", http.StatusOK, w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != schemaContentType {
+		t.Errorf("expect get content type %s but get %s", schemaContentType, contentType)
+	}
+	var s flag.Schema
+	if err := json.Unmarshal(w.Body.Bytes(), &s); err != nil {
+		t.Fatalf("parse the schema error => %v", err)
+	}
+	if len(s.Schema) == 0 || s.Properties["source"] == nil {
+		t.Errorf("expect get the schema of the config but get %s", w.Body.String())
+	}
+}
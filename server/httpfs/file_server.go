@@ -1,12 +1,15 @@
 package httpfs
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"html/template"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/gzip"
@@ -17,7 +20,9 @@ import (
 	"github.com/no-src/gofs/core"
 	"github.com/no-src/gofs/driver/minio"
 	"github.com/no-src/gofs/driver/sftp"
+	"github.com/no-src/gofs/encrypt"
 	"github.com/no-src/gofs/internal/rate"
+	"github.com/no-src/gofs/internal/tlsutil"
 	"github.com/no-src/gofs/logger"
 	"github.com/no-src/gofs/report"
 	"github.com/no-src/gofs/server"
@@ -27,6 +32,15 @@ import (
 	"github.com/quic-go/quic-go/http3"
 )
 
+// loginSource the source of the login attempts of the file server
+const loginSource = "http"
+
+var (
+	errServerDecryptAnonymous = errors.New("the server decrypt route requires the authentication, please set some server users first")
+	errServerDecryptDest      = errors.New("the server decrypt route only supports the dest path of the local disk")
+	errServerDecryptPath      = errors.New("the encrypt path must be the source path or a subdirectory of it for the server decrypt route")
+)
+
 // StartFileServer start a file server by gin
 func StartFileServer(opt server.Option) error {
 	logger := opt.Logger
@@ -35,6 +49,10 @@ func StartFileServer(opt server.Option) error {
 
 	engine := gin.New()
 	engine.NoRoute(middleware.NoRoute)
+	if err := initTrustedProxies(engine, opt.TrustedProxies); err != nil {
+		opt.Init.DoneWithError(err)
+		return err
+	}
 
 	initCompress(engine, opt.EnableFileServerCompress)
 	initDefaultMiddleware(engine, logger, opt.Reporter)
@@ -63,6 +81,11 @@ func StartFileServer(opt server.Option) error {
 	}()
 
 	var err error
+	if opt.EnableTLS && len(opt.TLSClientCAFile) > 0 {
+		err = logger.ErrorIf(listenAndServeMutualTLS(opt, engine.Handler()), "running the mutual tls server error")
+		c <- err
+		return err
+	}
 	if opt.EnableTLS {
 		if opt.EnableHTTP3 {
 			err = logger.ErrorIf(http3.ListenAndServeTLS(opt.FileServerAddr, opt.TLSCertFile, opt.TLSKeyFile, engine.Handler()), "running the http3 server error")
@@ -81,6 +104,28 @@ func StartFileServer(opt server.Option) error {
 	return err
 }
 
+// listenAndServeMutualTLS start the https or http3 server that verifies the client certificates by the CA bundle
+func listenAndServeMutualTLS(opt server.Option, handler http.Handler) error {
+	tlsConfig, err := tlsutil.NewServerTLSConfig(opt.TLSCertFile, opt.TLSKeyFile, opt.TLSClientCAFile)
+	if err != nil {
+		return err
+	}
+	if opt.EnableHTTP3 {
+		srv := &http3.Server{
+			Addr:      opt.FileServerAddr,
+			Handler:   handler,
+			TLSConfig: http3.ConfigureTLSConfig(tlsConfig),
+		}
+		return srv.ListenAndServe()
+	}
+	srv := &http.Server{
+		Addr:      opt.FileServerAddr,
+		Handler:   handler,
+		TLSConfig: tlsConfig,
+	}
+	return srv.ListenAndServeTLS("", "")
+}
+
 // initEnvGinMode change default mode is release
 func initEnvGinMode() {
 	mode := os.Getenv(gin.EnvGinMode)
@@ -107,6 +152,18 @@ func initDefaultMiddleware(engine *gin.Engine, logger io.Writer, reporter report
 	}), gin.Recovery(), middleware.ApiStat(reporter))
 }
 
+// initTrustedProxies set the trusted proxies that are separated by commas, no proxy is trusted if it is empty,
+// then the client ip is always the remote ip of the connection and can't be forged by the X-Forwarded-For header
+func initTrustedProxies(engine *gin.Engine, trustedProxies string) error {
+	var proxies []string
+	for _, proxy := range strings.Split(trustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); len(proxy) > 0 {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return engine.SetTrustedProxies(proxies)
+}
+
 func initHTMLTemplate(engine *gin.Engine) error {
 	tmpl, err := template.ParseFS(server.Templates, server.ResourceTemplatePath)
 	if err != nil {
@@ -129,17 +186,31 @@ func initRoute(engine *gin.Engine, opt server.Option, logger *logger.Logger) err
 	dest := opt.Dest
 	reporter := opt.Reporter
 
+	enableOIDC := len(opt.OIDCIssuer) > 0
 	loginGroup := engine.Group(server.LoginGroupRoute)
 	loginGroup.GET(server.LoginIndexRoute, func(context *gin.Context) {
-		context.HTML(http.StatusOK, "login.html", nil)
+		context.HTML(http.StatusOK, "login.html", gin.H{"EnableOIDC": enableOIDC, "OIDCRoute": server.LoginOIDCFullRoute})
 	})
-	loginGroup.POST(server.LoginSignInRoute, handler.NewLoginHandlerFunc(opt.Users, logger))
+	store, err := newUserStore(opt)
+	if err != nil {
+		return err
+	}
+	loginGroup.POST(server.LoginSignInRoute, handler.NewLoginHandlerFunc(store, newLoginLimiter(opt.Limiter), logger))
+	if enableOIDC {
+		if err = initOIDCRoute(opt, logger, loginGroup); err != nil {
+			return err
+		}
+	}
 
 	rootGroup := engine.Group(server.RootGroupRoute)
 	wGroup := engine.Group(server.WriteGroupRoute)
 	manageGroup := engine.Group(server.ManageGroupRoute)
+	decryptGroup := engine.Group(server.DecryptGroupRoute)
 
-	initRouteAuth(opt, logger, rootGroup, wGroup, manageGroup)
+	initRouteAudit(opt, logger, rootGroup, wGroup, manageGroup, decryptGroup)
+	if err = initRouteAuth(opt, store, enableOIDC, logger, rootGroup, wGroup, manageGroup, decryptGroup); err != nil {
+		return err
+	}
 
 	rootGroup.GET(server.DefaultRoute, handler.NewDefaultHandlerFunc(logger))
 
@@ -160,8 +231,15 @@ func initRoute(engine *gin.Engine, opt server.Option, logger *logger.Logger) err
 	}
 
 	if dest.IsDisk() {
-		rootGroup.StaticFS(server.DestRoutePrefix, rate.NewHTTPDir(dest.Path().Base(), opt.MaxTranRate.Bytes(), logger))
+		destDir := rate.NewHTTPDir(dest.Path().Base(), opt.MaxTranRate.Bytes(), logger)
+		rootGroup.StaticFS(server.DestRoutePrefix, destDir)
 		enableFileApi = true
+
+		if opt.ServerDecrypt {
+			if err = initDecryptRoute(opt, logger, decryptGroup, destDir); err != nil {
+				return err
+			}
+		}
 	} else if dest.Is(core.SFTP) {
 		sftpDir, err := sftp.NewDir(dest.RemotePath().Base(), dest.Addr(), dest.SSHConfig(), opt.Retry, opt.MaxTranRate.Bytes(), logger)
 		if err != nil {
@@ -182,20 +260,115 @@ func initRoute(engine *gin.Engine, opt server.Option, logger *logger.Logger) err
 		enableFileApi = true
 	}
 
+	if opt.ServerDecrypt && !dest.IsDisk() {
+		return errServerDecryptDest
+	}
+
 	if enableFileApi {
 		rootGroup.GET(server.QueryRoute, handler.NewFileApiHandlerFunc(logger, http.Dir(source.Path().Base()), opt.ChunkSize.Bytes(), opt.CheckpointCount, hash))
 	}
 	return nil
 }
 
-func initRouteAuth(opt server.Option, logger *logger.Logger, rootGroup, wGroup, manageGroup *gin.RouterGroup) {
-	if len(opt.Users) > 0 {
-		rootGroup.Use(middleware.NewAuthHandlerFunc(logger, auth.ReadPerm))
-		wGroup.Use(middleware.NewAuthHandlerFunc(logger, auth.WritePerm))
-		manageGroup.Use(middleware.NewAuthHandlerFunc(logger, auth.ExecutePerm))
-	} else {
-		logger.Warn("the file server allows anonymous access, you should set some server users by the -users or -rand_user_count flag for security reasons")
+// initRouteAudit record the audit log of the requests, it must be registered before the auth middleware to record the denied requests
+func initRouteAudit(opt server.Option, logger *logger.Logger, groups ...*gin.RouterGroup) {
+	if opt.AuditLog == nil {
+		return
+	}
+	auditHandler := middleware.Audit(opt.AuditLog, logger)
+	for _, g := range groups {
+		g.Use(auditHandler)
+	}
+}
+
+func initRouteAuth(opt server.Option, store auth.UserStore, enableOIDC bool, logger *logger.Logger, rootGroup, wGroup, manageGroup, decryptGroup *gin.RouterGroup) error {
+	if len(store.Users()) > 0 || len(opt.CertUsers) > 0 || enableOIDC {
+		rootGroup.Use(middleware.NewAuthHandlerFunc(logger, auth.ReadPerm, opt.CertUsers, store))
+		wGroup.Use(middleware.NewAuthHandlerFunc(logger, auth.WritePerm, opt.CertUsers, store))
+		manageGroup.Use(middleware.NewAuthHandlerFunc(logger, auth.ExecutePerm, opt.CertUsers, store))
+		decryptGroup.Use(middleware.NewAuthHandlerFunc(logger, auth.ExecutePerm, opt.CertUsers, store))
+		return nil
+	}
+	if opt.ServerDecrypt {
+		// the decrypted files must never be served to the anonymous users
+		return errServerDecryptAnonymous
+	}
+	logger.Warn("the file server allows anonymous access, you should set some server users by the -users, -users_file or -rand_user_count flag for security reasons")
+	return nil
+}
+
+// initDecryptRoute register the route that downloads the decrypted files in the dest path,
+// the secret is held by the server and only the files in the encrypt path can be decrypted
+func initDecryptRoute(opt server.Option, logger *logger.Logger, decryptGroup *gin.RouterGroup, destDir http.FileSystem) error {
+	encryptRel, err := destEncryptRel(opt.Source.Path().Base(), opt.EncryptPath)
+	if err != nil {
+		return err
+	}
+	encOpt := encrypt.NewOption(opt.Config, logger)
+	encOpt.Decrypt = true
+	encOpt.DecryptPath = filepath.Join(opt.Dest.Path().Base(), filepath.FromSlash(encryptRel))
+	dec, err := encrypt.NewDecrypt(encOpt)
+	if err != nil {
+		return err
+	}
+	decryptGroup.GET(server.DecryptRoute, handler.NewDecryptHandlerFunc(logger, destDir, encryptRel, dec))
+	return nil
+}
+
+// destEncryptRel return the slash path of the encrypt path that is relative to the source path,
+// it is also the relative path of the encrypted files in the dest path, return empty if the encrypt path is not specified
+func destEncryptRel(sourcePath, encryptPath string) (string, error) {
+	if len(encryptPath) == 0 {
+		return "", nil
+	}
+	sourceAbs, err := filepath.Abs(sourcePath)
+	if err != nil {
+		return "", err
+	}
+	encryptAbs, err := filepath.Abs(encryptPath)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(sourceAbs, encryptAbs)
+	if err != nil {
+		return "", err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errServerDecryptPath
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// initOIDCRoute register the OpenID Connect login routes, the provider is discovered by the issuer url at startup
+func initOIDCRoute(opt server.Option, logger *logger.Logger, loginGroup *gin.RouterGroup) error {
+	groupPerms, err := auth.ParseGroupPerms(opt.OIDCGroupPerms)
+	if err != nil {
+		return err
+	}
+	defaultPerm := auth.ToPerm(opt.OIDCDefaultPerm)
+	if len(opt.OIDCDefaultPerm) > 0 && !defaultPerm.IsValid() {
+		return fmt.Errorf("invalid oidc default perm => %s", opt.OIDCDefaultPerm)
 	}
+	login, callback, err := handler.NewOIDCHandlerFuncs(context.Background(), handler.OIDCOption{
+		Issuer:       opt.OIDCIssuer,
+		ClientID:     opt.OIDCClientID,
+		ClientSecret: opt.OIDCClientSecret,
+		RedirectURL:  opt.OIDCRedirectURL,
+		Scopes:       strings.Split(opt.OIDCScopes, ","),
+		UserClaim:    opt.OIDCUserClaim,
+		GroupsClaim:  opt.OIDCGroupsClaim,
+		GroupPerms:   groupPerms,
+		DefaultPerm:  defaultPerm,
+	}, logger)
+	if err != nil {
+		return err
+	}
+	loginGroup.GET(server.LoginOIDCRoute, login)
+	loginGroup.GET(server.LoginOIDCCallbackRoute, callback)
+	return nil
 }
 
 func initManageRoute(opt server.Option, logger *logger.Logger, manageGroup *gin.RouterGroup, reporter report.Reporter) {
@@ -205,10 +378,14 @@ func initManageRoute(opt server.Option, logger *logger.Logger, manageGroup *gin.
 		}
 		pprof.RouteRegister(manageGroup, server.PProfRoutePrefix)
 		manageGroup.GET(server.ManageConfigRoute, handler.NewManageHandlerFunc(logger, opt.Config))
+		manageGroup.GET(server.ManageSchemaRoute, handler.NewSchemaHandlerFunc(logger))
 		if opt.EnableReport {
 			manageGroup.GET(server.ManageReportRoute, handler.NewReportHandlerFunc(logger, reporter))
 			reporter.Enable(true)
 		}
+		if opt.Reloader != nil {
+			manageGroup.POST(server.ManageReloadRoute, handler.NewReloadHandlerFunc(logger, opt.Reloader))
+		}
 	}
 }
 
@@ -234,3 +411,19 @@ var defaultLogFormatter = func(param gin.LogFormatterParams) string {
 		param.ErrorMessage,
 	)
 }
+
+// newLoginLimiter return the login limiter of the file server, never lock out if the limiter is nil
+func newLoginLimiter(limiter auth.LoginLimiter) auth.LoginLimiter {
+	if limiter == nil {
+		return auth.NewEmptyLoginLimiter()
+	}
+	return auth.WithSource(limiter, loginSource)
+}
+
+// newUserStore return the user store that is shared with the other components, create a new one if it is nil
+func newUserStore(opt server.Option) (auth.UserStore, error) {
+	if opt.UserStore != nil {
+		return opt.UserStore, nil
+	}
+	return auth.NewUserStore(opt.Users, opt.UsersFile)
+}
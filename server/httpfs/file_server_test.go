@@ -0,0 +1,79 @@
+package httpfs
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/server"
+	"github.com/no-src/gofs/server/handler"
+)
+
+func TestInitTrustedProxies_LoginLockout(t *testing.T) {
+	testCases := []struct {
+		name           string
+		trustedProxies string
+		expectLockout  bool
+	}{
+		{"no trusted proxy", "", true},
+		{"untrusted peer", "10.0.0.1, 192.168.0.0/16", true},
+		{"trusted peer", "127.0.0.1", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			maxAttempts := 3
+			limiter, err := auth.NewLoginLimiter(auth.LoginLimiterOption{MaxAttempts: maxAttempts, Lockout: time.Minute, MaxLockout: time.Hour})
+			if err != nil {
+				t.Fatalf("create login limiter error => %v", err)
+			}
+			store, _ := auth.NewUserStore(nil, "")
+			engine := gin.New()
+			if err = initTrustedProxies(engine, tc.trustedProxies); err != nil {
+				t.Fatalf("init trusted proxies error => %v", err)
+			}
+			engine.POST(server.LoginSignInFullRoute, handler.NewLoginHandlerFunc(store, limiter, logger.NewTestLogger()))
+			srv := httptest.NewServer(engine)
+			defer srv.Close()
+
+			client := &http.Client{
+				CheckRedirect: func(req *http.Request, via []*http.Request) error {
+					return http.ErrUseLastResponse
+				},
+			}
+			lockout := false
+			for i := 0; i <= maxAttempts; i++ {
+				// rotate the forwarded ip and the username, then only the remote ip can be locked out
+				data := url.Values{server.ParamUserName: {fmt.Sprintf("user%d", i)}, server.ParamPassword: {"wrong_password"}}
+				req, err := http.NewRequest(http.MethodPost, srv.URL+server.LoginSignInFullRoute, strings.NewReader(data.Encode()))
+				if err != nil {
+					t.Fatalf("create login request error => %v", err)
+				}
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				req.Header.Set("X-Forwarded-For", fmt.Sprintf("203.0.113.%d", i+1))
+				resp, err := client.Do(req)
+				if err != nil {
+					t.Fatalf("login error => %v", err)
+				}
+				resp.Body.Close()
+				lockout = resp.StatusCode == http.StatusTooManyRequests
+			}
+			if lockout != tc.expectLockout {
+				t.Errorf("expect lockout %v with the rotating X-Forwarded-For headers, but get %v", tc.expectLockout, lockout)
+			}
+		})
+	}
+}
+
+func TestInitTrustedProxies_ReturnError(t *testing.T) {
+	if err := initTrustedProxies(gin.New(), "not_an_ip"); err == nil {
+		t.Errorf("expect to get an error with the invalid trusted proxy, but get nil")
+	}
+}
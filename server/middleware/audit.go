@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/no-src/gofs/audit"
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/contract"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/server"
+)
+
+type auditHandler struct {
+	auditLog audit.AuditLog
+	logger   *logger.Logger
+}
+
+// Audit returns a middleware that records the audit log of the file access and mutation after the request is handled,
+// the unauthenticated requests are ignored
+func Audit(auditLog audit.AuditLog, logger *logger.Logger) gin.HandlerFunc {
+	return (&auditHandler{
+		auditLog: auditLog,
+		logger:   logger,
+	}).Handle
+}
+
+func (h *auditHandler) Handle(c *gin.Context) {
+	c.Next()
+
+	var userName string
+	if v, ok := c.Get(server.ContextUser); ok {
+		if user, ok := v.(*auth.SessionUser); ok && user != nil {
+			userName = user.UserName
+		}
+	} else if c.Writer.Status() == http.StatusUnauthorized {
+		return
+	}
+	op, path := h.operation(c)
+	if len(op) == 0 {
+		return
+	}
+	bytes := int64(max(c.Writer.Size(), 0))
+	if v, ok := c.Get(server.ContextAuditBytes); ok {
+		bytes, _ = v.(int64)
+	}
+	code := c.Writer.Status()
+	if v, ok := c.Get(server.ContextAuditCode); ok {
+		code, _ = v.(int)
+	}
+	r := audit.NewRecord(audit.SourceHTTP, userName, c.RemoteIP(), op, path, bytes, code)
+	// the client ip is different from the remote ip only if the request is forwarded by a trusted proxy
+	if clientIP := c.ClientIP(); clientIP != r.IP {
+		r.ForwardedIP = clientIP
+	}
+	h.logger.ErrorIf(h.auditLog.Write(r), "write the audit log error")
+}
+
+// operation return the audit operation and the path of the request, return an empty operation if the request need not be audited
+func (h *auditHandler) operation(c *gin.Context) (op string, path string) {
+	if v, ok := c.Get(server.ContextAuditOp); ok {
+		return v.(string), c.GetString(server.ContextAuditPath)
+	}
+	path = c.Request.URL.Path
+	switch {
+	case strings.HasPrefix(path, server.SourceRoutePrefix) || strings.HasPrefix(path, server.DestRoutePrefix):
+		// the directory is requested with the trailing slash, or redirected to it
+		if strings.HasSuffix(path, "/") || c.Writer.Status() == http.StatusMovedPermanently {
+			return audit.OpBrowse, path
+		}
+		return audit.OpDownload, path
+	case strings.HasPrefix(path, server.DecryptRoutePrefix):
+		return audit.OpDecrypt, path
+	case path == server.QueryRoute:
+		return audit.OpBrowse, "/" + strings.TrimPrefix(c.Query(contract.FsPath), "/")
+	case path == server.ManageGroupRoute+server.ManageConfigRoute:
+		return audit.OpManageConfig, path
+	case path == server.ManageGroupRoute+server.ManageReportRoute:
+		return audit.OpManageReport, path
+	case path == server.ManageGroupRoute+server.ManageReloadRoute:
+		return audit.OpManageReload, path
+	case path == server.ManageGroupRoute+server.ManageSchemaRoute:
+		return audit.OpManageSchema, path
+	case strings.HasPrefix(path, server.ManageGroupRoute+"/"):
+		return audit.OpManage, path
+	}
+	return "", path
+}
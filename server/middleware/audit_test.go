@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/no-src/gofs/audit"
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/server"
+)
+
+func TestAudit(t *testing.T) {
+	testCases := []struct {
+		name         string
+		url          string
+		login        bool
+		status       int
+		expectOp     string
+		expectPath   string
+		expectBytes  int64
+		expectCode   int
+		expectRecord bool
+	}{
+		{"download", "/source/hello.txt", true, http.StatusOK, audit.OpDownload, "/source/hello.txt", 5, http.StatusOK, true},
+		{"browse", "/dest/", true, http.StatusOK, audit.OpBrowse, "/dest/", 5, http.StatusOK, true},
+		{"browse redirect", "/dest/hello", true, http.StatusMovedPermanently, audit.OpBrowse, "/dest/hello", 5, http.StatusMovedPermanently, true},
+		{"query", "/query?path=source/hello", true, http.StatusOK, audit.OpBrowse, "/source/hello", 5, http.StatusOK, true},
+		{"manage config", "/manage/config", true, http.StatusOK, audit.OpManageConfig, "/manage/config", 5, http.StatusOK, true},
+		{"manage report", "/manage/report", true, http.StatusOK, audit.OpManageReport, "/manage/report", 5, http.StatusOK, true},
+		{"manage reload", "/manage/reload", true, http.StatusOK, audit.OpManageReload, "/manage/reload", 5, http.StatusOK, true},
+		{"manage schema", "/manage/schema", true, http.StatusOK, audit.OpManageSchema, "/manage/schema", 5, http.StatusOK, true},
+		{"manage pprof", "/manage/debug/pprof/", true, http.StatusOK, audit.OpManage, "/manage/debug/pprof/", 5, http.StatusOK, true},
+		{"decrypt", "/decrypt/secret/hello.txt", true, http.StatusOK, audit.OpDecrypt, "/decrypt/secret/hello.txt", 5, http.StatusOK, true},
+		{"push", "/w/push", true, http.StatusOK, "push_write", "/source/hello.txt", 10, 1, true},
+		{"permission denied", "/source/hello.txt", true, http.StatusUnauthorized, audit.OpDownload, "/source/hello.txt", 5, http.StatusUnauthorized, true},
+		{"unauthenticated", "/source/hello.txt", false, http.StatusUnauthorized, "", "", 0, 0, false},
+		{"anonymous", "/source/hello.txt", false, http.StatusOK, audit.OpDownload, "/source/hello.txt", 5, http.StatusOK, true},
+		{"ignored route", "/", true, http.StatusOK, "", "", 0, 0, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			auditLog := &testAuditLog{}
+			engine := gin.New()
+			engine.Use(Audit(auditLog, logger.NewTestLogger()))
+			engine.Use(func(c *gin.Context) {
+				if tc.login {
+					c.Set(server.ContextUser, &auth.SessionUser{UserName: "gofs"})
+				}
+				if c.Request.URL.Path == server.PushFullRoute {
+					c.Set(server.ContextAuditOp, "push_write")
+					c.Set(server.ContextAuditPath, "/source/hello.txt")
+					c.Set(server.ContextAuditBytes, int64(10))
+					c.Set(server.ContextAuditCode, 1)
+				}
+				c.String(tc.status, "hello")
+			})
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			req.RemoteAddr = "127.0.0.1:12345"
+			engine.ServeHTTP(w, req)
+
+			if len(auditLog.records) > 0 != tc.expectRecord {
+				t.Fatalf("expect to get the audit record %v, but get %d records", tc.expectRecord, len(auditLog.records))
+			}
+			if !tc.expectRecord {
+				return
+			}
+			r := auditLog.records[0]
+			expectUser := ""
+			if tc.login {
+				expectUser = "gofs"
+			}
+			if r.Source != audit.SourceHTTP || r.User != expectUser || r.IP != "127.0.0.1" || r.Op != tc.expectOp ||
+				r.Path != tc.expectPath || r.Bytes != tc.expectBytes || r.Code != tc.expectCode {
+				t.Errorf("get an unexpected audit record => %+v", r)
+			}
+		})
+	}
+}
+
+func TestAudit_ForwardedIP(t *testing.T) {
+	testCases := []struct {
+		name              string
+		trustedProxies    []string
+		expectForwardedIP string
+	}{
+		{"no trusted proxy", nil, ""},
+		{"untrusted peer", []string{"10.0.0.1"}, ""},
+		{"trusted peer", []string{"127.0.0.1"}, "203.0.113.1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			auditLog := &testAuditLog{}
+			engine := gin.New()
+			if err := engine.SetTrustedProxies(tc.trustedProxies); err != nil {
+				t.Fatalf("set trusted proxies error => %v", err)
+			}
+			engine.Use(Audit(auditLog, logger.NewTestLogger()))
+			engine.Use(func(c *gin.Context) {
+				c.Set(server.ContextUser, &auth.SessionUser{UserName: "gofs"})
+				c.String(http.StatusOK, "hello")
+			})
+			req := httptest.NewRequest(http.MethodGet, "/source/hello.txt", nil)
+			req.RemoteAddr = "127.0.0.1:12345"
+			req.Header.Set("X-Forwarded-For", "203.0.113.1")
+			engine.ServeHTTP(httptest.NewRecorder(), req)
+
+			if len(auditLog.records) != 1 {
+				t.Fatalf("expect to get 1 audit record, but get %d records", len(auditLog.records))
+			}
+			r := auditLog.records[0]
+			if r.IP != "127.0.0.1" || r.ForwardedIP != tc.expectForwardedIP {
+				t.Errorf("get an unexpected ip of the audit record, ip=%s forwarded_ip=%s", r.IP, r.ForwardedIP)
+			}
+		})
+	}
+}
+
+// testAuditLog store the audit records in memory
+type testAuditLog struct {
+	records []audit.Record
+	mu      sync.Mutex
+}
+
+func (l *testAuditLog) Write(r audit.Record) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records = append(l.records, r)
+	return nil
+}
+
+func (l *testAuditLog) Close() error {
+	return nil
+}
@@ -4,29 +4,37 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/no-src/gofs/auth"
 	"github.com/no-src/gofs/contract"
+	"github.com/no-src/gofs/internal/tlsutil"
 	"github.com/no-src/gofs/logger"
 	"github.com/no-src/gofs/server"
 )
 
 type authHandler struct {
-	logger *logger.Logger
-	perm   auth.Perm
+	logger    *logger.Logger
+	perm      auth.Perm
+	certUsers []*auth.User
+	store     auth.UserStore
 }
 
-// NewAuthHandlerFunc returns a middleware that checks whether the user is sign in
-func NewAuthHandlerFunc(logger *logger.Logger, perm string) gin.HandlerFunc {
+// NewAuthHandlerFunc returns a middleware that checks whether the user is sign in,
+// or the verified client certificate is mapped to one of the cert users,
+// the session user is resolved from the store every time, so the changes of the users take effect immediately
+func NewAuthHandlerFunc(logger *logger.Logger, perm string, certUsers []*auth.User, store auth.UserStore) gin.HandlerFunc {
 	p := auth.ToPermWithDefault(perm, auth.DefaultPerm)
 	if !p.IsValid() {
 		logger.Warn("the auth middleware get an invalid permission")
 	}
 	return (&authHandler{
-		logger: logger,
-		perm:   p,
+		logger:    logger,
+		perm:      p,
+		certUsers: certUsers,
+		store:     store,
 	}).Handle
 }
 
@@ -40,14 +48,56 @@ func (h *authHandler) Handle(c *gin.Context) {
 		obj := session.Get(server.SessionUser)
 		if obj != nil {
 			tmp := obj.(auth.SessionUser)
-			user = &tmp
+			user = h.resolve(&tmp)
 		}
 	}
+	if user == nil {
+		user = h.certUser(c)
+	}
 	if user == nil {
 		c.Abort()
 		c.Data(http.StatusUnauthorized, "text/html; charset=utf-8", []byte(fmt.Sprintf("<html><head><script>window.location.href='%s';</script></head></html>", server.LoginIndexFullRoute)))
-	} else if !h.perm.CheckTo(user.Perm) {
+		return
+	}
+	// set the login user before checking the permission, so the denied request can be audited with the user
+	c.Set(server.ContextUser, user)
+	if !h.perm.CheckTo(user.Perm) || !h.checkPath(c, user) {
 		c.Abort()
 		c.JSON(http.StatusUnauthorized, server.NewApiResult(contract.NoPermission, contract.NoPermissionDesc, nil))
 	}
 }
+
+// checkPath check the user has the permission of the requested source or dest path or not,
+// the other paths are checked by the handlers with the login user in the context
+func (h *authHandler) checkPath(c *gin.Context, user *auth.SessionUser) bool {
+	p := c.Request.URL.Path
+	if strings.HasPrefix(p, server.SourceRoutePrefix) || strings.HasPrefix(p, server.DestRoutePrefix) {
+		return user.CheckPath(p, h.perm)
+	}
+	return true
+}
+
+// resolve return the current info of the session user from the store,
+// return nil if the user is removed or the password is changed, the external user is returned as it is
+func (h *authHandler) resolve(user *auth.SessionUser) *auth.SessionUser {
+	if h.store == nil || user.External {
+		return user
+	}
+	for _, u := range h.store.Users() {
+		if u.UserName() == user.UserName && u.Password() == user.Password {
+			return auth.MapperToSessionUser(u)
+		}
+	}
+	return nil
+}
+
+// certUser return the user that is mapped from the verified client certificate of the request
+func (h *authHandler) certUser(c *gin.Context) *auth.SessionUser {
+	if len(h.certUsers) == 0 {
+		return nil
+	}
+	if u := auth.MatchCertUser(h.certUsers, tlsutil.VerifiedClientCert(c.Request.TLS)); u != nil {
+		return auth.MapperToSessionUser(u)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/no-src/gofs/auth"
+	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/server"
+)
+
+func TestAuthHandler_ReloadUsers(t *testing.T) {
+	testCases := []struct {
+		name         string
+		userName     string
+		external     bool
+		url          string
+		expectStatus int
+	}{
+		{"kept user", "alice", false, server.SourceRoutePrefix + "hello", http.StatusOK},
+		{"downgraded user", "alice", false, server.WriteGroupRoute + "/hello", http.StatusUnauthorized},
+		{"removed user", "bob", false, server.SourceRoutePrefix + "hello", http.StatusUnauthorized},
+		{"password changed user", "carol", false, server.SourceRoutePrefix + "hello", http.StatusUnauthorized},
+		{"external user", "dave", true, server.SourceRoutePrefix + "hello", http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			alice, _ := auth.NewUser(1, "alice", "alice_password", auth.FullPerm)
+			bob, _ := auth.NewUser(2, "bob", "bob_password", auth.ReadPerm)
+			carol, _ := auth.NewUser(3, "carol", "carol_password", auth.ReadPerm)
+			store, err := auth.NewUserStore([]*auth.User{alice, bob, carol}, "")
+			if err != nil {
+				t.Fatalf("create user store error => %v", err)
+			}
+			sessionStore, err := server.NewSessionStore("memory:")
+			if err != nil {
+				t.Fatalf("create session store error => %v", err)
+			}
+
+			engine := gin.New()
+			engine.Use(sessions.Sessions(server.SessionName, sessionStore))
+			engine.GET(server.LoginGroupRoute, func(c *gin.Context) {
+				user := &auth.SessionUser{UserName: tc.userName, Perm: auth.ReadPerm, External: true}
+				if !tc.external {
+					user = auth.MapperToSessionUser(store.Login(tc.userName, tc.userName+"_password"))
+				}
+				session := sessions.Default(c)
+				session.Set(server.SessionUser, *user)
+				if err := session.Save(); err != nil {
+					t.Errorf("save session error => %v", err)
+				}
+			})
+			handle := func(c *gin.Context) {
+				c.String(http.StatusOK, "hello")
+			}
+			rootGroup := engine.Group(server.RootGroupRoute)
+			rootGroup.Use(NewAuthHandlerFunc(logger.NewTestLogger(), auth.ReadPerm, nil, store))
+			rootGroup.GET(server.SourceRoutePrefix+"hello", handle)
+			wGroup := engine.Group(server.WriteGroupRoute)
+			wGroup.Use(NewAuthHandlerFunc(logger.NewTestLogger(), auth.WritePerm, nil, store))
+			wGroup.GET("/hello", handle)
+			srv := httptest.NewServer(engine)
+			defer srv.Close()
+
+			jar, _ := cookiejar.New(nil)
+			client := &http.Client{Jar: jar}
+			resp, err := client.Get(srv.URL + server.LoginGroupRoute)
+			if err != nil {
+				t.Fatalf("login error => %v", err)
+			}
+			resp.Body.Close()
+
+			// reload the users, downgrade alice, remove bob and change the password of carol
+			alice, _ = auth.NewUser(1, "alice", "alice_password", auth.ReadPerm)
+			carol, _ = auth.NewUser(2, "carol", "carol_new_password", auth.ReadPerm)
+			store.SetUsers([]*auth.User{alice, carol})
+
+			resp, err = client.Get(srv.URL + tc.url)
+			if err != nil {
+				t.Fatalf("request with the old session error => %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.expectStatus {
+				t.Errorf("expect get the status code %d with the old session, but get %d", tc.expectStatus, resp.StatusCode)
+			}
+		})
+	}
+}
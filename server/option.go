@@ -1,9 +1,11 @@
 package server
 
 import (
+	"github.com/no-src/gofs/audit"
 	"github.com/no-src/gofs/auth"
 	"github.com/no-src/gofs/conf"
 	"github.com/no-src/gofs/logger"
+	"github.com/no-src/gofs/reload"
 	"github.com/no-src/gofs/report"
 	"github.com/no-src/gofs/retry"
 	"github.com/no-src/gofs/wait"
@@ -13,22 +15,32 @@ import (
 type Option struct {
 	conf.Config
 
-	Init     wait.Done
-	Users    []*auth.User
-	Logger   *logger.Logger
-	Retry    retry.Retry
-	Reporter report.Reporter
+	Init      wait.Done
+	Users     []*auth.User
+	CertUsers []*auth.User
+	UserStore auth.UserStore
+	Logger    *logger.Logger
+	Retry     retry.Retry
+	Reporter  report.Reporter
+	Limiter   auth.LoginLimiter
+	AuditLog  audit.AuditLog
+	Reloader  reload.Reloader
 }
 
 // NewServerOption create an instance of the Option, store all the web server options
-func NewServerOption(c conf.Config, init wait.Done, users []*auth.User, logger *logger.Logger, r retry.Retry, reporter report.Reporter) Option {
+func NewServerOption(c conf.Config, init wait.Done, users []*auth.User, certUsers []*auth.User, store auth.UserStore, logger *logger.Logger, r retry.Retry, reporter report.Reporter, limiter auth.LoginLimiter, auditLog audit.AuditLog, reloader reload.Reloader) Option {
 	opt := Option{
-		Config:   c,
-		Init:     init,
-		Users:    users,
-		Logger:   logger,
-		Retry:    r,
-		Reporter: reporter,
+		Config:    c,
+		Init:      init,
+		Users:     users,
+		CertUsers: certUsers,
+		UserStore: store,
+		Logger:    logger,
+		Retry:     r,
+		Reporter:  reporter,
+		Limiter:   limiter,
+		AuditLog:  auditLog,
+		Reloader:  reloader,
 	}
 	return opt
 }
@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/no-src/gofs/audit"
+	"github.com/no-src/gofs/auth"
 	"github.com/no-src/gofs/conf"
 	"github.com/no-src/gofs/report"
 	"github.com/no-src/gofs/retry"
@@ -12,7 +14,7 @@ import (
 
 func TestNewServerOption(t *testing.T) {
 	retryWait := time.Second
-	opt := NewServerOption(conf.Config{}, wait.NewWaitDone(), nil, nil, retry.New(1, retryWait, false, nil), report.NewReporter())
+	opt := NewServerOption(conf.Config{}, wait.NewWaitDone(), nil, nil, nil, nil, retry.New(1, retryWait, false, nil), report.NewReporter(), auth.NewEmptyLoginLimiter(), audit.NewEmptyAuditLog(), nil)
 	if opt.Users != nil || opt.Logger != nil || opt.Retry.WaitTime() != retryWait {
 		t.Errorf("NewServerOption() error, option => %v", opt)
 	}
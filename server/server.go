@@ -26,6 +26,14 @@ const (
 	LoginSignInRoute = "/signin"
 	// LoginSignInFullRoute the full route of sign in api
 	LoginSignInFullRoute = LoginGroupRoute + LoginSignInRoute
+	// LoginOIDCRoute the route of the OpenID Connect login
+	LoginOIDCRoute = "/oidc"
+	// LoginOIDCFullRoute the full route of the OpenID Connect login
+	LoginOIDCFullRoute = LoginGroupRoute + LoginOIDCRoute
+	// LoginOIDCCallbackRoute the route of the OpenID Connect callback
+	LoginOIDCCallbackRoute = "/oidc/callback"
+	// LoginOIDCCallbackFullRoute the full route of the OpenID Connect callback
+	LoginOIDCCallbackFullRoute = LoginGroupRoute + LoginOIDCCallbackRoute
 	// WriteGroupRoute the group route of write api
 	WriteGroupRoute = "/w"
 	// PushRoute the route of push api
@@ -38,6 +46,16 @@ const (
 	ManageConfigRoute = "/config"
 	// ManageReportRoute the route of report api
 	ManageReportRoute = "/report"
+	// ManageReloadRoute the route of the reload api
+	ManageReloadRoute = "/reload"
+	// ManageSchemaRoute the route of the JSON Schema api of the config file
+	ManageSchemaRoute = "/schema"
+	// DecryptGroupRoute the group route of the decrypted file download
+	DecryptGroupRoute = "/decrypt"
+	// DecryptRoute the route of the decrypted file download, the path is relative to the dest path
+	DecryptRoute = "/*path"
+	// DecryptRoutePrefix the route prefix of the decrypted file download
+	DecryptRoutePrefix = DecryptGroupRoute + "/"
 	// PProfRoutePrefix the route prefix of pprof
 	PProfRoutePrefix = "pprof"
 )
@@ -62,6 +80,22 @@ const (
 	SessionName = "session_id"
 	// SessionUser the key of the session user
 	SessionUser = "user"
+	// ContextUser the key of the login user in the gin context
+	ContextUser = "gofs_user"
+	// ContextAuditOp the key of the audit operation in the gin context, it overrides the operation that is parsed from the request
+	ContextAuditOp = "gofs_audit_op"
+	// ContextAuditPath the key of the audit path in the gin context
+	ContextAuditPath = "gofs_audit_path"
+	// ContextAuditBytes the key of the audit bytes in the gin context, it overrides the size of the response body
+	ContextAuditBytes = "gofs_audit_bytes"
+	// ContextAuditCode the key of the audit result code in the gin context, it overrides the http status code
+	ContextAuditCode = "gofs_audit_code"
+	// SessionOIDCState the key of the OpenID Connect state in the session
+	SessionOIDCState = "oidc_state"
+	// SessionOIDCNonce the key of the OpenID Connect nonce in the session
+	SessionOIDCNonce = "oidc_nonce"
+	// SessionOIDCReturnUrl the key of the return url after the OpenID Connect login in the session
+	SessionOIDCReturnUrl = "oidc_return_url"
 )
 
 const (
@@ -13,6 +13,8 @@ import (
 	"github.com/gin-contrib/sessions/redis"
 	"github.com/no-src/log"
 	"github.com/no-src/nscache"
+	_ "github.com/no-src/nscache/memory"
+	_ "github.com/no-src/nscache/redis"
 )
 
 var (
@@ -43,6 +45,26 @@ func NewSessionStore(sessionConnection string) (sessions.Store, error) {
 	}
 }
 
+// NewSessionCache create a cache that uses the same backend as the session store, stored in memory or redis
+func NewSessionCache(sessionConnection string) (nscache.NSCache, error) {
+	connUrl, err := url.Parse(sessionConnection)
+	if err != nil {
+		return nil, fmt.Errorf("%w => %s", errors.Join(errInvalidSession, err), sessionConnection)
+	}
+	switch strings.ToLower(connUrl.Scheme) {
+	case "memory":
+		return nscache.NewCache("memory:")
+	case "redis":
+		_, _, address, password, db, _, err := parseRedisConnection(connUrl)
+		if err != nil {
+			return nil, err
+		}
+		return nscache.NewCache(fmt.Sprintf("redis://:%s@%s/%d", url.QueryEscape(password), address, db))
+	default:
+		return nil, fmt.Errorf("%w => %s", errUnsupportedSession, sessionConnection)
+	}
+}
+
 func redisSessionStore(redisUrl *url.URL, secret []byte) (sessions.Store, error) {
 	maxIdle, network, address, password, db, redisSecret, err := parseRedisConnection(redisUrl)
 	if err != nil {
@@ -83,3 +83,32 @@ func TestNewSessionStore_Invalid(t *testing.T) {
 		})
 	}
 }
+
+func TestNewSessionCache(t *testing.T) {
+	testCases := []struct {
+		conn      string
+		expectErr error
+	}{
+		{"memory:", nil},
+		{"redis://127.0.0.1:6379?password=redis_password&db=10", nil},
+		{"redis://127.0.0.1:6379?db=x", errInvalidRedisDB},
+		{"hello://127.0.0.1:8888", errUnsupportedSession},
+		{string([]byte{0x7f}), errInvalidSession},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.conn, func(t *testing.T) {
+			c, err := NewSessionCache(tc.conn)
+			if !errors.Is(err, tc.expectErr) {
+				t.Errorf("expect to get error [%v], but actual get error [%v]", tc.expectErr, err)
+			}
+			if err == nil {
+				if c == nil {
+					t.Errorf("get a nil session cache")
+				} else {
+					c.Close()
+				}
+			}
+		})
+	}
+}
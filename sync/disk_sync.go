@@ -211,8 +211,11 @@ func (s *diskSync) write(path, dest string) error {
 	if err != nil {
 		return err
 	}
+	removeTemp := func() error { return nil }
 	defer func() {
 		s.logger.ErrorIf(sourceFile.Close(), "[write] close the source file error")
+		// remove the encrypted temporary file after it is closed
+		removeTemp()
 	}()
 
 	sourceStat, err := sourceFile.Stat()
@@ -229,14 +232,38 @@ func (s *diskSync) write(path, dest string) error {
 	destSize := destStat.Size()
 
 	var offset int64
-	if s.enc.NeedEncrypt(path) {
+	// the source file to write, it is the encrypted temporary file for the block formats
+	writePath := path
+	if s.enc.NeedEncrypt(path) && !s.enc.IsBlockFormat() {
 		// ignore the size compare from encryption file because the size of encryption file may not equal to the source file
 		if s.hash.QuickCompare(s.forceChecksum, 0, 0, sourceStat.ModTime(), destStat.ModTime()) {
 			s.logger.Debug("[write] [ignored], the file modification time is unmodified => %s", path)
 			return nil
 		}
 	} else {
-		if s.hash.QuickCompare(s.forceChecksum, sourceSize, destSize, sourceStat.ModTime(), destStat.ModTime()) {
+		if s.enc.NeedEncrypt(path) {
+			// the size of the encryption file with the block format is deterministic
+			encryptedSize, _ := s.enc.EncryptedSize(sourceSize)
+			if s.hash.QuickCompare(s.forceChecksum, encryptedSize, destSize, sourceStat.ModTime(), destStat.ModTime()) {
+				s.logger.Debug("[write] [ignored], the file size and file modification time are both unmodified => %s", path)
+				return nil
+			}
+			// the unchanged blocks are encrypted to the same ciphertext, so compare the encrypted temporary file with the dest file by chunks
+			var tempPath string
+			tempPath, removeTemp, err = s.enc.CreateEncryptTemp(path)
+			if err != nil {
+				return err
+			}
+			s.logger.ErrorIf(sourceFile.Close(), "[write] close the source file error")
+			if sourceFile, err = os.Open(tempPath); err != nil {
+				return err
+			}
+			if sourceStat, err = sourceFile.Stat(); err != nil {
+				return err
+			}
+			writePath = tempPath
+			sourceSize = sourceStat.Size()
+		} else if s.hash.QuickCompare(s.forceChecksum, sourceSize, destSize, sourceStat.ModTime(), destStat.ModTime()) {
 			s.logger.Debug("[write] [ignored], the file size and file modification time are both unmodified => %s", path)
 			return nil
 		}
@@ -263,14 +290,15 @@ func (s *diskSync) write(path, dest string) error {
 		return err
 	}
 
-	reader := bufio.NewReader(rate.NewReader(sourceFile, s.maxTranRate, s.logger))
-	writer, err := s.enc.NewWriter(destFile, path, destStat.Name())
+	// truncate first before write to file, the encryption writer may write the header when it is created
+	err = destFile.Truncate(offset)
 	if err != nil {
 		return err
 	}
 
-	// truncate first before write to file
-	err = destFile.Truncate(offset)
+	reader := bufio.NewReader(rate.NewReader(sourceFile, s.maxTranRate, s.logger))
+	// the encrypted temporary file is written to the dest file directly
+	writer, err := s.enc.NewWriter(destFile, writePath, destStat.Name())
 	if err != nil {
 		return err
 	}
@@ -342,7 +370,11 @@ func (s *diskSync) buildDestAbsFile(sourceFileAbs string) (string, error) {
 		s.logger.Error(err, "parse rel path error, basePath=%s destPath=%s", s.sourceAbsPath, sourceFileRel)
 		return "", err
 	}
-	return filepath.Join(s.destAbsPath, sourceFileRel), nil
+	destFileRel, err := s.enc.EncryptRelPath(sourceFileRel)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.destAbsPath, destFileRel), nil
 }
 
 func (s *diskSync) IsDir(path string) (bool, error) {
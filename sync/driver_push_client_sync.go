@@ -194,7 +194,11 @@ func (s *driverPushClientSync) buildDestAbsFile(sourceFileAbs string) (string, e
 		return "", err
 	}
 
-	return filepath.ToSlash(filepath.Join(s.basePath, sourceFileRel)), nil
+	destFileRel, err := s.enc.EncryptRelPath(sourceFileRel)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(filepath.Join(s.basePath, destFileRel)), nil
 }
 
 func (s *driverPushClientSync) fileInfoCompare(sourcePath string) (equal bool) {
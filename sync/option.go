@@ -1,6 +1,9 @@
 package sync
 
 import (
+	"time"
+
+	"github.com/no-src/gofs/audit"
 	"github.com/no-src/gofs/auth"
 	"github.com/no-src/gofs/conf"
 	"github.com/no-src/gofs/core"
@@ -16,11 +19,16 @@ type Option struct {
 	Source                core.VFS
 	Dest                  core.VFS
 	EnableHTTP3           bool
+	EnableGrpcTransfer    bool
 	FileServerAddr        string
+	EnablePushServer      bool
 	EnableTLS             bool
 	TLSCertFile           string
 	TLSKeyFile            string
 	TLSInsecureSkipVerify bool
+	TLSClientCAFile       string
+	TLSClientCertFile     string
+	TLSClientKeyFile      string
 	EnableLogicallyDelete bool
 	ChunkSize             int64
 	CheckpointCount       int
@@ -32,28 +40,43 @@ type Option struct {
 	CopyLink              bool
 	CopyUnsafeLink        bool
 	TokenSecret           string
+	TokenAlgorithm        string
+	TokenKeyFile          string
+	TokenExpires          time.Duration
+	TokenRefreshExpires   time.Duration
 	Users                 []*auth.User
+	UsersFile             string
+	CertUsers             []*auth.User
+	UserStore             auth.UserStore
 	Retry                 retry.Retry
 	EncOpt                encrypt.Option
 	PathIgnore            ignore.PathIgnore
 	Reporter              report.Reporter
+	LoginLimiter          auth.LoginLimiter
+	AuditLog              audit.AuditLog
 	TaskConf              string
+	MonitorLogSize        int
 	Logger                *logger.Logger
 	SyncOnce              bool
 	SyncCron              string
 }
 
 // NewSyncOption create an instance of the Option, store all the sync component options
-func NewSyncOption(config conf.Config, users []*auth.User, r retry.Retry, pi ignore.PathIgnore, reporter report.Reporter, logger *logger.Logger) Option {
+func NewSyncOption(config conf.Config, users []*auth.User, certUsers []*auth.User, store auth.UserStore, r retry.Retry, pi ignore.PathIgnore, reporter report.Reporter, limiter auth.LoginLimiter, auditLog audit.AuditLog, logger *logger.Logger) Option {
 	opt := Option{
 		Source:                config.Source,
 		Dest:                  config.Dest,
 		EnableHTTP3:           config.EnableHTTP3,
+		EnableGrpcTransfer:    config.EnableGrpcTransfer,
 		FileServerAddr:        config.FileServerAddr,
+		EnablePushServer:      config.EnablePushServer,
 		EnableTLS:             config.EnableTLS,
 		TLSCertFile:           config.TLSCertFile,
 		TLSKeyFile:            config.TLSKeyFile,
 		TLSInsecureSkipVerify: config.TLSInsecureSkipVerify,
+		TLSClientCAFile:       config.TLSClientCAFile,
+		TLSClientCertFile:     config.TLSClientCertFile,
+		TLSClientKeyFile:      config.TLSClientKeyFile,
 		EnableLogicallyDelete: config.EnableLogicallyDelete,
 		ChunkSize:             config.ChunkSize.Bytes(),
 		CheckpointCount:       config.CheckpointCount,
@@ -65,12 +88,22 @@ func NewSyncOption(config conf.Config, users []*auth.User, r retry.Retry, pi ign
 		CopyLink:              config.CopyLink,
 		CopyUnsafeLink:        config.CopyUnsafeLink,
 		TokenSecret:           config.TokenSecret,
+		TokenAlgorithm:        config.TokenAlgorithm,
+		TokenKeyFile:          config.TokenKeyFile,
+		TokenExpires:          config.TokenExpires.Duration(),
+		TokenRefreshExpires:   config.TokenRefreshExpires.Duration(),
 		Users:                 users,
+		UsersFile:             config.UsersFile,
+		CertUsers:             certUsers,
+		UserStore:             store,
 		Retry:                 r,
 		EncOpt:                encrypt.NewOption(config, logger),
 		PathIgnore:            pi,
 		Reporter:              reporter,
+		LoginLimiter:          limiter,
+		AuditLog:              auditLog,
 		TaskConf:              config.TaskConf,
+		MonitorLogSize:        config.MonitorLogSize,
 		Logger:                logger,
 		SyncOnce:              config.SyncOnce,
 		SyncCron:              config.SyncCron,
@@ -0,0 +1,252 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/no-src/gofs/action"
+	apipush "github.com/no-src/gofs/api/push"
+	"github.com/no-src/gofs/contract"
+	"github.com/no-src/gofs/contract/push"
+	"github.com/no-src/gofs/internal/rate"
+	"github.com/no-src/nsgo/fsutil"
+)
+
+const (
+	// pushWindowSize the max number of the in-flight requests in a push stream
+	pushWindowSize = 8
+)
+
+var errUnexpectedPushReply = errors.New("receive an unexpected reply from the push server")
+
+// grpcSendPushData send the push data to the push server over the gRPC bidirectional stream,
+// retry once after login if the current token is unauthenticated
+func (pcs *pushClientSync) grpcSendPushData(pd push.PushData, act action.Action, path string) (err error) {
+	for i := 0; i < 2; i++ {
+		err = pcs.grpcSendPushDataOnce(pd, act, path)
+		if !pcs.client.IsUnauthenticated(err) {
+			return err
+		}
+		if err = pcs.client.Login(); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (pcs *pushClientSync) grpcSendPushDataOnce(pd push.PushData, act action.Action, path string) (err error) {
+	stream, err := pcs.client.Push()
+	if err != nil {
+		return err
+	}
+	s := &grpcPushStream{stream: stream}
+	defer func() {
+		if closeErr := s.close(); err == nil {
+			err = closeErr
+		}
+	}()
+	if act == action.WriteAction {
+		return pcs.grpcSendFileChunk(s, path, pd)
+	}
+	_, err = s.call(pd, nil)
+	return err
+}
+
+// grpcSendFileChunk compare the file with the dest file at first, then write the modified chunks and truncate the file finally,
+// the compare requests and the write requests are pipelined in the stream
+func (pcs *pushClientSync) grpcSendFileChunk(s *grpcPushStream, path string, pd push.PushData) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	ra := rate.NewReaderAt(f, pcs.maxTranRate, pcs.logger)
+	chunk := make([]byte, pcs.chunkSize)
+
+	n, err := ra.ReadAt(chunk, 0)
+	if fsutil.IsNonEOF(err) {
+		return err
+	}
+	var offset int64
+	if n > 0 {
+		pd.PushAction = push.CompareFileAndChunkPushAction
+		pd.Chunk = contract.Chunk{Offset: 0, Size: int64(n), Hash: pcs.hash.Hash(chunk[:n])}
+		reply, err := s.call(pd, nil)
+		// only send HashValues once
+		pd.FileInfo.HashValues = nil
+		if err != nil {
+			return err
+		}
+		switch contract.Code(reply.GetCode()) {
+		case contract.NotModified:
+			pcs.logger.Debug("push a file that not modified, ignore and abort next request => %s", path)
+			return nil
+		case contract.ChunkNotModified:
+			offset = int64(n)
+			// if the checkpoint compare result offset is greater than the next offset, then replace it
+			if hv := reply.GetHashValue(); hv != nil && hv.GetOffset() > offset {
+				offset = hv.GetOffset()
+			}
+			if offset, err = pcs.grpcCompareChunks(s, ra, pd, offset, chunk); err != nil {
+				return err
+			}
+		case contract.Modified:
+			offset = 0
+		default:
+			return fmt.Errorf("%w => %s", errUnexpectedPushReply, reply.GetMessage())
+		}
+	}
+
+	pd.PushAction = push.WritePushAction
+	for {
+		n, err = ra.ReadAt(chunk, offset)
+		if fsutil.IsNonEOF(err) {
+			return err
+		}
+		if n == 0 {
+			break
+		}
+		pd.Chunk = contract.Chunk{Offset: offset, Size: int64(n)}
+		if err = s.send(pd, chunk[:n]); err != nil {
+			return err
+		}
+		offset += int64(n)
+		if err = s.wait(pushWindowSize - 1); err != nil {
+			return err
+		}
+	}
+
+	// read to end, send a truncate request finally
+	pd.PushAction = push.TruncatePushAction
+	pd.Chunk = contract.Chunk{Offset: offset}
+	if err = s.send(pd, nil); err != nil {
+		return err
+	}
+	return s.wait(0)
+}
+
+// grpcCompareChunks compare the chunks from the offset with the pipelined requests, return the offset of the first modified chunk,
+// or the file size if all the chunks are not modified
+func (pcs *pushClientSync) grpcCompareChunks(s *grpcPushStream, ra io.ReaderAt, pd push.PushData, offset int64, chunk []byte) (int64, error) {
+	pd.PushAction = push.CompareChunkPushAction
+	var offsets []int64
+	next := offset
+	isEnd := false
+	for {
+		for !isEnd && len(offsets) < pushWindowSize {
+			n, err := ra.ReadAt(chunk, next)
+			if fsutil.IsNonEOF(err) {
+				return 0, err
+			}
+			if n == 0 {
+				isEnd = true
+				break
+			}
+			pd.Chunk = contract.Chunk{Offset: next, Size: int64(n), Hash: pcs.hash.Hash(chunk[:n])}
+			if err = s.send(pd, nil); err != nil {
+				return 0, err
+			}
+			offsets = append(offsets, next)
+			next += int64(n)
+		}
+		if len(offsets) == 0 {
+			return next, nil
+		}
+		reply, err := s.recv()
+		if err != nil {
+			return 0, err
+		}
+		current := offsets[0]
+		offsets = offsets[1:]
+		switch contract.Code(reply.GetCode()) {
+		case contract.ChunkNotModified:
+			pcs.logger.Debug("push a file chunk that not modified, continue to compare next chunk [%d]=> %s", current, pd.FileInfo.Path)
+		case contract.ChunkModified:
+			// discard the replies of the remaining compare requests, and write the file from the current chunk
+			return current, s.wait(0)
+		default:
+			return 0, fmt.Errorf("%w => %s", errUnexpectedPushReply, reply.GetMessage())
+		}
+	}
+}
+
+// grpcPushStream send the push requests and receive the replies in order
+type grpcPushStream struct {
+	stream  apipush.PushService_PushClient
+	id      uint64
+	pending []uint64
+}
+
+// send send the push request without waiting for the reply
+func (s *grpcPushStream) send(pd push.PushData, data []byte) error {
+	s.id++
+	if err := s.stream.Send(apipush.ToPushRequest(s.id, pd, data)); err != nil {
+		return err
+	}
+	s.pending = append(s.pending, s.id)
+	return nil
+}
+
+// recv receive the reply of the earliest in-flight request
+func (s *grpcPushStream) recv() (*apipush.PushReply, error) {
+	if len(s.pending) == 0 {
+		return nil, fmt.Errorf("%w => no in-flight request", errUnexpectedPushReply)
+	}
+	reply, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+	id := s.pending[0]
+	s.pending = s.pending[1:]
+	if reply.GetId() != id {
+		return nil, fmt.Errorf("%w => expect reply id %d, but get %d", errUnexpectedPushReply, id, reply.GetId())
+	}
+	if reply.GetCode() < 0 && !isCompareCode(contract.Code(reply.GetCode())) {
+		return nil, fmt.Errorf("%w => %s", errSendToPushServer, reply.GetMessage())
+	}
+	return reply, nil
+}
+
+// call send the push request and wait for the reply
+func (s *grpcPushStream) call(pd push.PushData, data []byte) (*apipush.PushReply, error) {
+	if err := s.send(pd, data); err != nil {
+		return nil, err
+	}
+	return s.recv()
+}
+
+// wait receive the replies until the number of the in-flight requests is not greater than n
+func (s *grpcPushStream) wait(n int) error {
+	for len(s.pending) > n {
+		if _, err := s.recv(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close close the send direction of the stream and wait for the server to finish the stream
+func (s *grpcPushStream) close() error {
+	if err := s.stream.CloseSend(); err != nil {
+		return err
+	}
+	for {
+		_, err := s.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func isCompareCode(code contract.Code) bool {
+	switch code {
+	case contract.NotModified, contract.ChunkNotModified, contract.Modified, contract.ChunkModified:
+		return true
+	}
+	return false
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/no-src/gofs/auth"
 	"github.com/no-src/gofs/contract"
 	"github.com/no-src/gofs/contract/push"
+	"github.com/no-src/gofs/internal/httpclient"
 	"github.com/no-src/gofs/internal/rate"
 	"github.com/no-src/gofs/server"
 	"github.com/no-src/gofs/server/client"
@@ -38,6 +39,8 @@ type pushClientSync struct {
 	currentUser *auth.User
 	client      apiclient.Client
 	httpClient  httputil.HttpClient
+
+	enableGrpcTransfer bool
 }
 
 // NewPushClientSync create an instance of the pushClientSync
@@ -60,7 +63,7 @@ func NewPushClientSync(opt Option) (Sync, error) {
 		return nil, err
 	}
 
-	httpClient, err := httputil.NewHttpClient(insecureSkipVerify, certFile, enableHTTP3)
+	httpClient, err := httpclient.NewHttpClient(insecureSkipVerify, certFile, opt.TLSClientCertFile, opt.TLSClientKeyFile, enableHTTP3)
 	if err != nil {
 		return nil, err
 	}
@@ -71,9 +74,11 @@ func NewPushClientSync(opt Option) (Sync, error) {
 	}
 	s := &pushClientSync{
 		diskSync:    *ds,
-		client:      apiclient.New(dest.Host(), dest.Port(), enableTLS, certFile, user),
+		client:      apiclient.New(dest.Host(), dest.Port(), enableTLS, certFile, opt.TLSClientCertFile, opt.TLSClientKeyFile, user),
 		currentUser: user,
 		httpClient:  httpClient,
+
+		enableGrpcTransfer: opt.EnableGrpcTransfer,
 	}
 
 	err = s.start()
@@ -89,7 +94,8 @@ func (pcs *pushClientSync) start() error {
 		return err
 	}
 	err = pcs.info()
-	if err == nil {
+	// keep the api client running to push the file changes over the gRPC api server
+	if err == nil && !pcs.enableGrpcTransfer {
 		pcs.client.Stop()
 	}
 	return err
@@ -284,6 +290,9 @@ func (pcs *pushClientSync) needGetFileTime(act action.Action) bool {
 }
 
 func (pcs *pushClientSync) sendPushData(pd push.PushData, act action.Action, path string) error {
+	if pcs.enableGrpcTransfer {
+		return pcs.grpcSendPushData(pd, act, path)
+	}
 	if act == action.WriteAction {
 		return pcs.sendFileChunk(path, pd)
 	}
@@ -410,6 +419,12 @@ func (pcs *pushClientSync) sendTruncate(path string, pd push.PushData, offset in
 	return err
 }
 
+func (pcs *pushClientSync) Close() {
+	if pcs.enableGrpcTransfer {
+		pcs.logger.ErrorIf(pcs.client.Stop(), "[push client sync] stop the api client error")
+	}
+}
+
 func (pcs *pushClientSync) needCheckHash(loopCount, dataLen int) bool {
 	return loopCount == 0 && dataLen > 0
 }
@@ -445,8 +460,7 @@ func (pcs *pushClientSync) checkApiResult(resp *http.Response) (code contract.Co
 	}
 
 	code = apiResult.Code
-	switch code {
-	case contract.NotModified, contract.ChunkNotModified, contract.Modified, contract.ChunkModified:
+	if isCompareCode(code) {
 		return code, hv, nil
 	}
 
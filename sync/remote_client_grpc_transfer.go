@@ -0,0 +1,158 @@
+package sync
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/no-src/gofs/api/apiclient"
+	"github.com/no-src/gofs/api/file"
+	"github.com/no-src/gofs/contract"
+	"github.com/no-src/gofs/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcClient return the started api client, login to the api server at the first time
+func (rs *remoteClientSync) grpcClient() (apiclient.Client, error) {
+	rs.apiClientMu.Lock()
+	defer rs.apiClientMu.Unlock()
+	if !rs.apiClientStarted {
+		if err := rs.apiClient.Start(); err != nil {
+			return nil, err
+		}
+		rs.apiClientStarted = true
+	}
+	return rs.apiClient, nil
+}
+
+// grpcRead read the remote file content from the offset by the gRPC FileService
+func (rs *remoteClientSync) grpcRead(path string, offset int64) (io.ReadCloser, error) {
+	remoteUrl, err := url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := rs.grpcClient()
+	if err != nil {
+		return nil, err
+	}
+	req := &file.ReadRequest{
+		Path:   rs.grpcPath(remoteUrl.Path),
+		Offset: offset,
+	}
+	var reader *grpcFileReader
+	for i := 0; i < 2; i++ {
+		var stream file.FileService_ReadClient
+		if stream, err = c.Read(req); err != nil {
+			return nil, toFileError(err)
+		}
+		reader = &grpcFileReader{stream: stream}
+		// receive the first reply to find out the error in advance
+		err = reader.fill()
+		if err == nil || errors.Is(err, io.EOF) {
+			return reader, nil
+		}
+		if !c.IsUnauthenticated(err) {
+			break
+		}
+		if err = c.Login(); err != nil {
+			return nil, err
+		}
+	}
+	return nil, toFileError(err)
+}
+
+// grpcList list the remote files in the directory by the gRPC FileService
+func (rs *remoteClientSync) grpcList(path string) (files []contract.FileInfo, err error) {
+	c, err := rs.grpcClient()
+	if err != nil {
+		return nil, err
+	}
+	req := &file.ListRequest{
+		Path:     rs.grpcPath(path),
+		NeedHash: true,
+	}
+	for i := 0; i < 2; i++ {
+		files, err = rs.grpcListOnce(c, req)
+		if !c.IsUnauthenticated(err) {
+			break
+		}
+		if err = c.Login(); err != nil {
+			return nil, err
+		}
+	}
+	return files, toFileError(err)
+}
+
+func (rs *remoteClientSync) grpcListOnce(c apiclient.Client, req *file.ListRequest) (files []contract.FileInfo, err error) {
+	stream, err := c.List(req)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		fi, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, contract.FileInfo{
+			Path:   fi.GetPath(),
+			IsDir:  contract.FsDirValue(fi.GetIsDir()),
+			Size:   fi.GetSize(),
+			Hash:   fi.GetHash(),
+			CTime:  fi.GetCTime(),
+			ATime:  fi.GetATime(),
+			MTime:  fi.GetMTime(),
+			LinkTo: fi.GetLinkTo(),
+		})
+	}
+}
+
+// grpcPath convert the file server path to the path that is relative to the source path of the remote disk server
+func (rs *remoteClientSync) grpcPath(path string) string {
+	path = strings.TrimPrefix(path, "/")
+	return strings.TrimPrefix(path, strings.Trim(server.SourceRoutePrefix, "/"))
+}
+
+// grpcFileReader read the file content from the stream of the gRPC FileService
+type grpcFileReader struct {
+	stream file.FileService_ReadClient
+	buf    []byte
+}
+
+func (r *grpcFileReader) Read(p []byte) (n int, err error) {
+	for len(r.buf) == 0 {
+		if err = r.fill(); err != nil {
+			return 0, err
+		}
+	}
+	n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// fill receive the next chunk of the file content
+func (r *grpcFileReader) fill() error {
+	reply, err := r.stream.Recv()
+	if err != nil {
+		return err
+	}
+	r.buf = reply.GetData()
+	return nil
+}
+
+func (r *grpcFileReader) Close() error {
+	return r.stream.CloseSend()
+}
+
+// toFileError convert the status error to the file system error, so the not exist error can be ignored by the caller
+func toFileError(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return os.ErrNotExist
+	}
+	return err
+}
@@ -11,12 +11,15 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/no-src/gofs/api/apiclient"
 	"github.com/no-src/gofs/auth"
 	"github.com/no-src/gofs/contract"
 	nsfs "github.com/no-src/gofs/fs"
 	"github.com/no-src/gofs/ignore"
+	"github.com/no-src/gofs/internal/httpclient"
 	"github.com/no-src/gofs/internal/rate"
 	"github.com/no-src/gofs/server"
 	"github.com/no-src/gofs/server/client"
@@ -44,6 +47,10 @@ type remoteClientSync struct {
 	maxTranRate           int64
 	httpClient            httputil.HttpClient
 	pi                    ignore.PathIgnore
+	enableGrpcTransfer    bool
+	apiClient             apiclient.Client
+	apiClientStarted      bool
+	apiClientMu           sync.Mutex
 }
 
 // NewRemoteClientSync create an instance of remoteClientSync to receive the file change message and execute it
@@ -53,6 +60,8 @@ func NewRemoteClientSync(opt Option) (Sync, error) {
 	dest := opt.Dest
 	pi := opt.PathIgnore
 	enableHTTP3 := opt.EnableHTTP3
+	enableGrpcTransfer := opt.EnableGrpcTransfer
+	enableTLS := opt.EnableTLS
 	certFile := opt.TLSCertFile
 	insecureSkipVerify := opt.TLSInsecureSkipVerify
 	users := opt.Users
@@ -72,7 +81,7 @@ func NewRemoteClientSync(opt Option) (Sync, error) {
 		return nil, err
 	}
 
-	httpClient, err := httputil.NewHttpClient(insecureSkipVerify, certFile, enableHTTP3)
+	httpClient, err := httpclient.NewHttpClient(insecureSkipVerify, certFile, opt.TLSClientCertFile, opt.TLSClientKeyFile, enableHTTP3)
 	if err != nil {
 		return nil, err
 	}
@@ -92,10 +101,14 @@ func NewRemoteClientSync(opt Option) (Sync, error) {
 		maxTranRate:           maxTranRate,
 		httpClient:            httpClient,
 		pi:                    pi,
+		enableGrpcTransfer:    enableGrpcTransfer,
 	}
 	if len(users) > 0 {
 		rs.currentUser = users[0]
 	}
+	if enableGrpcTransfer {
+		rs.apiClient = apiclient.New(source.Host(), source.Port(), enableTLS, certFile, opt.TLSClientCertFile, opt.TLSClientKeyFile, rs.currentUser)
+	}
 	return rs, nil
 }
 
@@ -199,17 +212,15 @@ func (rs *remoteClientSync) write(path, dest string) error {
 		return nil
 	}
 	var offset int64
-	rangeHeader := make(http.Header)
 	if hv != nil {
 		offset = hv.Offset
-		rangeHeader.Add("Range", fmt.Sprintf("bytes=%d-%d", offset, size))
 	}
-	resp, err := rs.httpGetWithAuth(path, rangeHeader)
+	body, err := rs.readRemoteFile(path, offset, size)
 	if err != nil {
 		return err
 	}
 	defer func() {
-		rs.logger.ErrorIf(resp.Body.Close(), "[remote client sync] [write] close the resp body error")
+		rs.logger.ErrorIf(body.Close(), "[remote client sync] [write] close the resp body error")
 	}()
 
 	destFile, err := fsutil.OpenRWFile(dest)
@@ -224,7 +235,7 @@ func (rs *remoteClientSync) write(path, dest string) error {
 		return err
 	}
 
-	reader := bufio.NewReader(rate.NewReader(resp.Body, rs.maxTranRate, rs.logger))
+	reader := bufio.NewReader(rate.NewReader(body, rs.maxTranRate, rs.logger))
 	writer := bufio.NewWriter(destFile)
 
 	// truncate first before write to file
@@ -247,6 +258,22 @@ func (rs *remoteClientSync) write(path, dest string) error {
 	return err
 }
 
+// readRemoteFile read the remote file content from the offset
+func (rs *remoteClientSync) readRemoteFile(path string, offset int64, size int64) (io.ReadCloser, error) {
+	if rs.enableGrpcTransfer {
+		return rs.grpcRead(path, offset)
+	}
+	rangeHeader := make(http.Header)
+	if offset > 0 {
+		rangeHeader.Add("Range", fmt.Sprintf("bytes=%d-%d", offset, size))
+	}
+	resp, err := rs.httpGetWithAuth(path, rangeHeader)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
 // chtimes change file times
 func (rs *remoteClientSync) chtimes(dest string, aTime, mTime time.Time) {
 	if err := os.Chtimes(dest, aTime, mTime); err != nil {
@@ -345,6 +372,14 @@ func (rs *remoteClientSync) SyncOnce(path string) error {
 
 func (rs *remoteClientSync) sync(serverAddr, path string) error {
 	rs.logger.Debug("remote client sync path => %s", path)
+	if rs.enableGrpcTransfer {
+		files, err := rs.grpcList(path)
+		if err != nil {
+			return err
+		}
+		rs.syncFiles(files, serverAddr, path)
+		return nil
+	}
 	reqValues := url.Values{}
 	reqValues.Add(contract.FsPath, path)
 	reqValues.Add(contract.FsNeedHash, contract.FsNeedHashValueTrue)
@@ -419,6 +454,12 @@ func (rs *remoteClientSync) syncSymlink(currentPath, realPath string) (err error
 	return rs.Symlink(realPath, currentPath)
 }
 
+func (rs *remoteClientSync) Close() {
+	if rs.apiClient != nil {
+		rs.logger.ErrorIf(rs.apiClient.Stop(), "[remote client sync] stop the api client error")
+	}
+}
+
 func (rs *remoteClientSync) buildDestAbsFile(sourceFileAbs string) (string, error) {
 	remoteUrl, err := url.Parse(sourceFileAbs)
 	if err != nil {
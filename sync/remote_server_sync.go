@@ -40,6 +40,7 @@ func NewRemoteServerSync(opt Option) (Sync, error) {
 	tokenSecret := opt.TokenSecret
 	users := opt.Users
 	taskConf := opt.TaskConf
+	monitorLogSize := opt.MonitorLogSize
 	logger := opt.Logger
 
 	ds, err := newDiskSync(opt)
@@ -76,7 +77,37 @@ func NewRemoteServerSync(opt Option) (Sync, error) {
 		rs.logger.Warn("create remote server sync warning, you should enable the file server with -server and -server_addr flags")
 	}
 
-	rs.server, err = apiserver.New(source.Host(), source.Port(), enableTLS, certFile, keyFile, tokenSecret, users, opt.Reporter, rs.serverAddr, logger, taskConf)
+	rs.server, err = apiserver.New(apiserver.Option{
+		IP:                    source.Host(),
+		Port:                  source.Port(),
+		EnableTLS:             enableTLS,
+		CertFile:              certFile,
+		KeyFile:               keyFile,
+		ClientCAFile:          opt.TLSClientCAFile,
+		TokenSecret:           tokenSecret,
+		TokenAlgorithm:        opt.TokenAlgorithm,
+		TokenKeyFile:          opt.TokenKeyFile,
+		TokenExpires:          opt.TokenExpires,
+		TokenRefreshExpires:   opt.TokenRefreshExpires,
+		Users:                 users,
+		UsersFile:             opt.UsersFile,
+		CertUsers:             opt.CertUsers,
+		UserStore:             opt.UserStore,
+		Reporter:              opt.Reporter,
+		LoginLimiter:          opt.LoginLimiter,
+		AuditLog:              opt.AuditLog,
+		HttpServerAddr:        rs.serverAddr,
+		Logger:                logger,
+		TaskConf:              taskConf,
+		MonitorLogSize:        monitorLogSize,
+		SourcePath:            rs.sourceAbsPath,
+		ChunkSize:             opt.ChunkSize,
+		CheckpointCount:       opt.CheckpointCount,
+		ChecksumAlgorithm:     opt.ChecksumAlgorithm,
+		MaxTranRate:           opt.MaxTranRate,
+		EnablePushServer:      opt.EnablePushServer,
+		EnableLogicallyDelete: opt.EnableLogicallyDelete,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/no-src/gofs/auth"
 	"github.com/no-src/gofs/core"
 )
 
@@ -54,27 +55,61 @@ func NewSync(opt Option) (Sync, error) {
 }
 
 func newSync(opt Option) (Sync, error) {
-	// the fields of option
-	source := opt.Source
-	dest := opt.Dest
-	copyLink, copyUnsafeLink := opt.CopyLink, opt.CopyUnsafeLink
-	opt.CopyLink, opt.CopyUnsafeLink = false, false
+	f, err := findSyncFactory(opt.Source, opt.Dest)
+	if err != nil {
+		return nil, err
+	}
+	if !f.link {
+		opt.CopyLink, opt.CopyUnsafeLink = false, false
+	}
+	return f.create(opt)
+}
+
+// Validate check the source and dest are supported by the sync component or not, and check the required options of them
+func Validate(source, dest core.VFS, chunkSize int64, users []*auth.User) error {
+	f, err := findSyncFactory(source, dest)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	if f.chunk && chunkSize <= 0 {
+		errs = append(errs, errInvalidChunkSize)
+	}
+	if f.user && len(users) == 0 {
+		errs = append(errs, errUserIsRequired)
+	}
+	return errors.Join(errs...)
+}
+
+// syncFactory create the sync component for the matched source and dest
+type syncFactory struct {
+	match  func(source, dest core.VFS) bool
+	create func(opt Option) (Sync, error)
+	// link the symbolic links can be copied
+	link bool
+	// chunk the chunk size is required
+	chunk bool
+	// user the user account is required
+	user bool
+}
+
+// syncFactories the supported combinations of the source and dest, the first matched one is used
+var syncFactories = []syncFactory{
+	{match: func(source, dest core.VFS) bool { return source.IsDisk() && dest.IsDisk() }, create: NewDiskSync, link: true},
+	{match: func(source, dest core.VFS) bool { return source.Is(core.RemoteDisk) }, create: NewRemoteSync},
+	{match: func(source, dest core.VFS) bool { return dest.Is(core.RemoteDisk) }, create: NewPushClientSync, chunk: true},
+	{match: func(source, dest core.VFS) bool { return source.IsDisk() && dest.Is(core.SFTP) }, create: NewSftpPushClientSync, chunk: true},
+	{match: func(source, dest core.VFS) bool { return source.Is(core.SFTP) && dest.IsDisk() }, create: NewSftpPullClientSync, chunk: true},
+	{match: func(source, dest core.VFS) bool { return source.IsDisk() && dest.Is(core.MinIO) }, create: NewMinIOPushClientSync, chunk: true, user: true},
+	{match: func(source, dest core.VFS) bool { return source.Is(core.MinIO) && dest.IsDisk() }, create: NewMinIOPullClientSync, chunk: true, user: true},
+}
 
-	if source.IsDisk() && dest.IsDisk() {
-		opt.CopyLink, opt.CopyUnsafeLink = copyLink, copyUnsafeLink
-		return NewDiskSync(opt)
-	} else if source.Is(core.RemoteDisk) {
-		return NewRemoteSync(opt)
-	} else if dest.Is(core.RemoteDisk) {
-		return NewPushClientSync(opt)
-	} else if source.IsDisk() && dest.Is(core.SFTP) {
-		return NewSftpPushClientSync(opt)
-	} else if source.Is(core.SFTP) && dest.IsDisk() {
-		return NewSftpPullClientSync(opt)
-	} else if source.IsDisk() && dest.Is(core.MinIO) {
-		return NewMinIOPushClientSync(opt)
-	} else if source.Is(core.MinIO) && dest.IsDisk() {
-		return NewMinIOPullClientSync(opt)
+// findSyncFactory find the sync factory that supports the source and dest
+func findSyncFactory(source, dest core.VFS) (syncFactory, error) {
+	for _, f := range syncFactories {
+		if f.match(source, dest) {
+			return f, nil
+		}
 	}
-	return nil, fmt.Errorf("%w source=>%s dest=>%s", errFileSystemUnsupported, source.Type().String(), dest.Type().String())
+	return syncFactory{}, fmt.Errorf("%w source=>%s dest=>%s", errFileSystemUnsupported, source.Type().String(), dest.Type().String())
 }